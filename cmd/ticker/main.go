@@ -1,12 +1,19 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -18,8 +25,12 @@ import (
 	"github.com/pengelbrecht/ticker/internal/checkpoint"
 	epiccontext "github.com/pengelbrecht/ticker/internal/context"
 	"github.com/pengelbrecht/ticker/internal/engine"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
+	"github.com/pengelbrecht/ticker/internal/inbox"
 	"github.com/pengelbrecht/ticker/internal/parallel"
+	"github.com/pengelbrecht/ticker/internal/revert"
 	"github.com/pengelbrecht/ticker/internal/runlog"
+	"github.com/pengelbrecht/ticker/internal/server"
 	"github.com/pengelbrecht/ticker/internal/ticks"
 	"github.com/pengelbrecht/ticker/internal/tui"
 	"github.com/pengelbrecht/ticker/internal/update"
@@ -232,6 +243,110 @@ Examples:
 	Run:  runContext,
 }
 
+var replayCmd = &cobra.Command{
+	Use:   "replay <run-id>",
+	Short: "Replay the recorded agent transcripts of a run",
+	Long: `Replay plays back what the agent did during a run, from the transcripts
+recorded when agent.record_transcripts is enabled in .ticker/config.json.
+
+Transcripts are stored in .ticker/runs/<run-id>/iter-<n>.jsonl.gz and are fed
+back through the same stream parser and TUI as a live run, with the original
+pacing. The run ID is the name of the run log (.ticker/runs/<run-id>.jsonl).
+
+Examples:
+  ticker replay 20250114-093012                      # Replay every iteration in real time
+  ticker replay 20250114-093012 --iteration 3        # Replay only iteration 3
+  ticker replay 20250114-093012 --speed 10           # Ten times faster
+  ticker replay 20250114-093012 --speed 0 --headless # Print the output without delays`,
+	Args: cobra.ExactArgs(1),
+	Run:  runReplay,
+}
+
+var attachCmd = &cobra.Command{
+	Use:   "attach [run-id]",
+	Short: "Show the TUI for a running headless run",
+	Long: `Attach opens the TUI for a ticker run started with --headless, for
+example on a server or in CI. Every headless run listens on a control socket
+in .ticker/runs/<run-id>.sock while it runs; without a run ID, attach picks
+the only one. Use --addr to connect to a run started with --serve instead.
+
+The TUI catches up on the current iteration and then follows the run live.
+Pausing pauses the run, and quitting stops it after the current iteration,
+unless --read-only is given.
+
+Examples:
+  ticker attach                          # Attach to the only headless run
+  ticker attach 20250114-093012          # Attach to a specific run
+  ticker attach --addr :8080 --read-only # Watch a run started with --serve :8080`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runAttach,
+}
+
+var inboxCmd = &cobra.Command{
+	Use:   "inbox",
+	Short: "List tasks awaiting a human",
+	Long: `Inbox lists every open task awaiting a human, across epics, grouped by
+what it is waiting for. Each task shows why the agent handed it off, how long
+ago, and the agent's note before that.
+
+Respond with the subcommands:
+  ticker inbox approve <task-id>            # Accept work awaiting approval, review, content or checkpoint
+  ticker inbox reject <task-id> [feedback]  # Send it back to the agent with feedback
+  ticker inbox answer <task-id> [answer]    # Answer a question (awaiting input or escalation)
+  ticker inbox take <task-id> [note]        # Take the task over yourself (awaiting work)
+
+reject and answer ask for the text when it isn't given.`,
+	Args: cobra.NoArgs,
+	Run:  runInbox,
+}
+
+var inboxApproveCmd = &cobra.Command{
+	Use:   "approve <task-id>",
+	Short: "Approve a task awaiting approval, review, content, checkpoint or work",
+	Args:  cobra.ExactArgs(1),
+	Run:   runInboxApprove,
+}
+
+var inboxRejectCmd = &cobra.Command{
+	Use:   "reject <task-id> [feedback]",
+	Short: "Send a task back to the agent with feedback",
+	Args:  cobra.RangeArgs(1, 2),
+	Run:   runInboxReject,
+}
+
+var inboxAnswerCmd = &cobra.Command{
+	Use:   "answer <task-id> [answer]",
+	Short: "Answer a task awaiting input or escalation",
+	Args:  cobra.RangeArgs(1, 2),
+	Run:   runInboxAnswer,
+}
+
+var inboxTakeCmd = &cobra.Command{
+	Use:   "take <task-id> [note]",
+	Short: "Take over a task as human work",
+	Args:  cobra.RangeArgs(1, 2),
+	Run:   runInboxTake,
+}
+
+var revertCmd = &cobra.Command{
+	Use:   "revert <task-id>",
+	Short: "Revert the commits an agent made for a task and reopen it",
+	Long: `Revert undoes exactly the commits ticker recorded for a task, in one
+revert commit on the epic's worktree branch (if it has a worktree) or the
+current branch. The task is reopened with a note telling the agent to redo
+it rather than restore the reverted work.
+
+Later commits that touch the same files may depend on the reverted work.
+Revert lists them and stops unless --force is given.
+
+Examples:
+  ticker revert abc123            # Revert task abc123's commits
+  ticker revert abc123 --dry-run  # Show what would be reverted
+  ticker revert abc123 --force    # Revert even if later commits touch the same files`,
+	Args: cobra.ExactArgs(1),
+	Run:  runRevert,
+}
+
 func init() {
 	// Run command flags
 	runCmd.Flags().IntP("max-iterations", "n", 50, "Maximum number of iterations")
@@ -243,6 +358,8 @@ func init() {
 	runCmd.Flags().Bool("jsonl", false, "Output JSON Lines format (requires --headless)")
 	runCmd.Flags().Bool("skip-verify", true, "Skip git verification after task completion (default: true)")
 	runCmd.Flags().Bool("verify-only", false, "Run verification without the agent (for debugging)")
+	runCmd.Flags().Bool("dry-run", false, "Show the task order, prompts and verifiers a run would use, without running the agent")
+	runCmd.Flags().String("dry-run-dir", "", "Write dry-run prompts to files in this directory instead of printing them")
 	runCmd.Flags().Bool("worktree", false, "Run epic(s) in isolated git worktree")
 	runCmd.Flags().Int("parallel", 0, "Max parallel epics (default: number of epics)")
 	runCmd.Flags().Bool("watch", false, "Watch mode: idle when no tasks available instead of exiting")
@@ -252,18 +369,44 @@ func init() {
 	runCmd.Flags().Bool("include-standalone", false, "Include standalone tasks (no parent epic) in auto mode")
 	runCmd.Flags().Bool("include-orphans", false, "Include orphaned tasks (parent epic closed) in auto mode")
 	runCmd.Flags().Bool("all", false, "Include all task types (standalone + orphans) in auto mode")
+	runCmd.Flags().String("agent", "", "Default agent backend (claude, codex, a command agent from .ticker/config.json, or scripted:<scenario.json>); overrides agent.default")
+	runCmd.Flags().String("serve", "", "Serve a control API and event stream on this address (:port, host:port or unix:<path>; requires --headless)")
+	runCmd.Flags().Bool("serve-remote", false, "Allow --serve on a non-loopback address (the API has no authentication)")
+
+	// Resume command flags
+	resumeCmd.Flags().Bool("skip-verify", true, "Skip git verification after task completion (default: true)")
+	resumeCmd.Flags().String("agent", "", "Default agent backend (claude, codex, a command agent from .ticker/config.json, or scripted:<scenario.json>); overrides agent.default")
 
 	// Context command flags
 	contextCmd.Flags().Bool("show", false, "Display existing context (error if none exists)")
 	contextCmd.Flags().Bool("refresh", false, "Force regeneration even if context exists")
 	contextCmd.Flags().Bool("delete", false, "Remove the context file")
 
+	// Replay command flags
+	replayCmd.Flags().IntP("iteration", "i", 0, "Replay only this iteration (default: all recorded)")
+	replayCmd.Flags().Float64("speed", 1, "Playback speed multiplier (0 = no delays)")
+	replayCmd.Flags().Bool("headless", false, "Print the replayed output instead of showing the TUI")
+
+	// Attach command flags
+	attachCmd.Flags().String("addr", "", "Connect to a run started with --serve at this address instead of a run's socket")
+	attachCmd.Flags().Bool("read-only", false, "Only watch: pausing and quitting don't affect the run")
+
+	// Revert command flags
+	revertCmd.Flags().Bool("force", false, "Revert even if later commits touch the same files")
+	revertCmd.Flags().Bool("dry-run", false, "Show what would be reverted without changing anything")
+
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(resumeCmd)
 	rootCmd.AddCommand(checkpointsCmd)
 	rootCmd.AddCommand(upgradeCmd)
 	rootCmd.AddCommand(mergeCmd)
 	rootCmd.AddCommand(contextCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(attachCmd)
+
+	inboxCmd.AddCommand(inboxApproveCmd, inboxRejectCmd, inboxAnswerCmd, inboxTakeCmd)
+	rootCmd.AddCommand(inboxCmd)
+	rootCmd.AddCommand(revertCmd)
 }
 
 func main() {
@@ -283,6 +426,8 @@ func runRun(cmd *cobra.Command, args []string) {
 	maxTaskRetries, _ := cmd.Flags().GetInt("max-task-retries")
 	skipVerify, _ := cmd.Flags().GetBool("skip-verify")
 	verifyOnly, _ := cmd.Flags().GetBool("verify-only")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	dryRunDir, _ := cmd.Flags().GetString("dry-run-dir")
 	useWorktree, _ := cmd.Flags().GetBool("worktree")
 	maxParallel, _ := cmd.Flags().GetInt("parallel")
 	watch, _ := cmd.Flags().GetBool("watch")
@@ -292,6 +437,9 @@ func runRun(cmd *cobra.Command, args []string) {
 	includeStandalone, _ := cmd.Flags().GetBool("include-standalone")
 	includeOrphans, _ := cmd.Flags().GetBool("include-orphans")
 	includeAll, _ := cmd.Flags().GetBool("all")
+	agentName, _ := cmd.Flags().GetString("agent")
+	serveAddr, _ := cmd.Flags().GetString("serve")
+	serveRemote, _ := cmd.Flags().GetBool("serve-remote")
 
 	// --all is shorthand for standalone + orphans
 	if includeAll {
@@ -315,6 +463,12 @@ func runRun(cmd *cobra.Command, args []string) {
 		os.Exit(ExitError)
 	}
 
+	// --serve requires --headless (the TUI has its own controls)
+	if serveAddr != "" && !headless {
+		fmt.Fprintln(os.Stderr, "Error: --serve requires --headless")
+		os.Exit(ExitError)
+	}
+
 	// --auto implies --watch (continuous operation)
 	if auto {
 		watch = true
@@ -328,12 +482,26 @@ func runRun(cmd *cobra.Command, args []string) {
 		fmt.Fprintln(os.Stderr, "Warning: --poll has no effect without --watch or --auto")
 	}
 
+	if dryRunDir != "" && !dryRun {
+		fmt.Fprintln(os.Stderr, "Error: --dry-run-dir requires --dry-run")
+		os.Exit(ExitError)
+	}
+	if dryRun && verifyOnly {
+		fmt.Fprintln(os.Stderr, "Error: --dry-run and --verify-only are mutually exclusive")
+		os.Exit(ExitError)
+	}
+
 	// Handle --verify-only mode (no epic required, runs in current directory)
 	if verifyOnly {
 		runVerifyOnly()
 		return
 	}
 
+	// Handle --dry-run mode (nothing is changed and no agent runs)
+	if dryRun {
+		os.Exit(runDryRun(args, auto, maxParallel, maxIterations, skipVerify, agentName, dryRunDir))
+	}
+
 	// Validate --parallel flag
 	if maxParallel < 0 {
 		fmt.Fprintln(os.Stderr, "Error: --parallel must be >= 0")
@@ -534,7 +702,7 @@ func runRun(cmd *cobra.Command, args []string) {
 		if !headless {
 			fmt.Fprintln(os.Stderr, "Note: TUI mode not supported for standalone tasks. Use --headless.")
 		}
-		runStandaloneTask(standaloneTask, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, jsonl, includeStandalone, includeOrphans)
+		runStandaloneTask(standaloneTask, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, jsonl, includeStandalone, includeOrphans, agentName)
 		return
 	}
 
@@ -569,6 +737,11 @@ func runRun(cmd *cobra.Command, args []string) {
 		maxParallel = 0
 	}
 
+	if serveAddr != "" && len(epicIDs) > 1 {
+		fmt.Fprintln(os.Stderr, "Error: --serve supports a single epic")
+		os.Exit(ExitError)
+	}
+
 	// Handle multiple epics
 	if len(epicIDs) > 1 {
 		// Multiple epics - use ParallelRunner
@@ -576,9 +749,9 @@ func runRun(cmd *cobra.Command, args []string) {
 			maxParallel = len(epicIDs)
 		}
 		if !headless {
-			runParallelWithTUI(epicIDs, epicTitles, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, maxParallel)
+			runParallelWithTUI(epicIDs, epicTitles, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, maxParallel, agentName)
 		} else {
-			runParallelHeadless(epicIDs, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, maxParallel, jsonl)
+			runParallelHeadless(epicIDs, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, maxParallel, jsonl, agentName)
 		}
 		return
 	}
@@ -589,19 +762,36 @@ func runRun(cmd *cobra.Command, args []string) {
 
 	// TUI mode (default)
 	if !headless {
-		runWithTUI(epicID, epicTitle, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, useWorktree, watch, watchTimeout, watchPollInterval, debounceInterval, auto, includeStandalone, includeOrphans)
+		runWithTUI(epicID, epicTitle, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, useWorktree, watch, watchTimeout, watchPollInterval, debounceInterval, auto, includeStandalone, includeOrphans, agentName)
 		return
 	}
 
+	// Control API, shared by every epic the loop below runs. Each run
+	// listens on its own socket for `ticker attach`; --serve adds a listener.
+	srv := server.New(ticks.NewClient(), checkpoint.NewManager())
+	if serveAddr != "" {
+		if serveRemote {
+			srv.AllowRemote()
+		}
+		if err := startServer(srv, serveAddr, serveRemote, jsonl); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(ExitError)
+		}
+	}
+	exit := func(code int) {
+		srv.Close()
+		os.Exit(code)
+	}
+
 	// Headless mode - run in a loop if auto mode with standalone/orphan support
 	ticksClientLoop := ticks.NewClient()
 
 	for {
-		exitCode := runHeadless(epicID, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, useWorktree, jsonl, watch, watchTimeout, watchPollInterval, debounceInterval)
+		exitCode := runHeadless(epicID, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, useWorktree, jsonl, watch, watchTimeout, watchPollInterval, debounceInterval, agentName, srv)
 
 		// If not in auto mode with continuation support, exit immediately
 		if !auto || (!includeStandalone && !includeOrphans) {
-			os.Exit(exitCode)
+			exit(exitCode)
 		}
 
 		// Epic completed - check for more work using shared function
@@ -612,7 +802,7 @@ func runRun(cmd *cobra.Command, args []string) {
 			} else {
 				fmt.Println("[AUTO] No more epics or tasks found")
 			}
-			os.Exit(exitCode)
+			exit(exitCode)
 		}
 
 		if nextWork.IsStandalone {
@@ -622,7 +812,9 @@ func runRun(cmd *cobra.Command, args []string) {
 			} else {
 				fmt.Printf("[AUTO] Switching to standalone task: [%s] %s\n", nextWork.Task.ID, nextWork.Task.Title)
 			}
-			runStandaloneTask(nextWork.Task, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, jsonl, includeStandalone, includeOrphans)
+			// Standalone tasks don't run an engine the server can follow
+			srv.Close()
+			runStandaloneTask(nextWork.Task, maxIterations, maxCost, checkpointInterval, maxTaskRetries, skipVerify, jsonl, includeStandalone, includeOrphans, agentName)
 			return // runStandaloneTask exits on its own
 		}
 
@@ -637,6 +829,28 @@ func runRun(cmd *cobra.Command, args []string) {
 	}
 }
 
+// startServer serves the --serve control API on addr in the background.
+func startServer(srv *server.Server, addr string, allowRemote, jsonl bool) error {
+	l, err := server.Listen(addr, allowRemote)
+	if errors.Is(err, server.ErrNotLoopback) {
+		return fmt.Errorf("could not listen on %s: %w (pass --serve-remote to allow it)", addr, err)
+	}
+	if err != nil {
+		return fmt.Errorf("could not listen on %s: %w", addr, err)
+	}
+	go func() {
+		if err := srv.Serve(l); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: control API stopped: %v\n", err)
+		}
+	}()
+	if jsonl {
+		fmt.Printf(`{"type":"serve","address":%q}`+"\n", l.Addr().String())
+	} else {
+		fmt.Printf("[SERVE] Control API listening on %s\n", l.Addr())
+	}
+	return nil
+}
+
 // validateEpicIDs checks that all epic IDs exist, are open, and are unique.
 func validateEpicIDs(client *ticks.Client, epicIDs []string) error {
 	seen := make(map[string]bool)
@@ -659,7 +873,7 @@ func validateEpicIDs(client *ticks.Client, epicIDs []string) error {
 	return nil
 }
 
-func runParallelWithTUI(epicIDs, epicTitles []string, maxIterations int, maxCost float64, checkpointInterval, maxTaskRetries int, skipVerify bool, maxParallel int) {
+func runParallelWithTUI(epicIDs, epicTitles []string, maxIterations int, maxCost float64, checkpointInterval, maxTaskRetries int, skipVerify bool, maxParallel int, agentName string) {
 	// Create context with signal handling
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -727,6 +941,18 @@ func runParallelWithTUI(epicIDs, epicTitles []string, maxIterations int, maxCost
 		MaxCost:       maxCost,                      // Shared cost limit
 	})
 
+	// Each epic's engine gets its own wake channel, so a handoff answered in
+	// the TUI only wakes the engine running the task's epic
+	wakeChans := make(map[string]chan struct{}, len(epicIDs))
+	engineWakeChans := make(map[string]<-chan struct{}, len(epicIDs))
+	for _, epicID := range epicIDs {
+		wakeChans[epicID] = make(chan struct{}, 1)
+		engineWakeChans[epicID] = wakeChans[epicID]
+	}
+
+	ticksClient := ticks.NewClient()
+	handoffs := &tuiHandoffs{ticks: ticksClient}
+
 	// Create TUI model with first epic as initial
 	pauseChan := make(chan bool, 1)
 	m := tui.New(tui.Config{
@@ -735,20 +961,26 @@ func runParallelWithTUI(epicIDs, epicTitles []string, maxIterations int, maxCost
 		MaxCost:      maxCost,
 		MaxIteration: maxIterations,
 		PauseChan:    pauseChan,
+		Handoff:      handoffs,
+		Diff:         commitDiff,
 	})
 
 	// Create program
 	p := tea.NewProgram(m, tea.WithAltScreen())
 
-	// Check claude availability
-	claudeAgent := agent.NewClaudeAgent()
-	if !claudeAgent.Available() {
-		fmt.Fprintln(os.Stderr, "Error: claude CLI not found. Please install Claude Code.")
+	// Load agent registry and check default agent availability
+	agents, _, err := loadAgentRegistry(agentName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	verifyConfig, err := loadVerificationConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(ExitError)
 	}
 
 	// Engine factory creates a new engine for each epic
-	ticksClient := ticks.NewClient()
 	checkpointMgr := checkpoint.NewManager()
 
 	// Helper to load tasks for an epic (defined before factory so it can be used in callbacks)
@@ -757,29 +989,7 @@ func runParallelWithTUI(epicIDs, epicTitles []string, maxIterations int, maxCost
 		if err != nil {
 			return
 		}
-		// Build map of task statuses to filter closed blockers
-		taskStatus := make(map[string]string, len(tasks))
-		for _, t := range tasks {
-			taskStatus[t.ID] = t.Status
-		}
-		taskInfos := make([]tui.TaskInfo, len(tasks))
-		for i, t := range tasks {
-			// Filter BlockedBy to only include open blockers
-			var openBlockers []string
-			for _, blockerID := range t.BlockedBy {
-				if taskStatus[blockerID] == "open" {
-					openBlockers = append(openBlockers, blockerID)
-				}
-			}
-			taskInfos[i] = tui.TaskInfo{
-				ID:        t.ID,
-				Title:     t.Title,
-				Status:    tui.TaskStatus(t.Status),
-				BlockedBy: openBlockers,
-				Awaiting:  t.GetAwaitingType(),
-			}
-		}
-		p.Send(tui.EpicTasksUpdateMsg{EpicID: epicID, Tasks: taskInfos})
+		p.Send(tui.EpicTasksUpdateMsg{EpicID: epicID, Tasks: taskInfos(tasks)})
 
 		// Fetch and send RunRecords for closed tasks
 		for _, t := range tasks {
@@ -791,100 +1001,88 @@ func runParallelWithTUI(epicIDs, epicTitles []string, maxIterations int, maxCost
 		}
 	}
 
+	// A handoff answered in the TUI is ready for the agent again; refresh
+	// its epic's tab and wake that epic's engine
+	handoffs.onResponded = func(taskID string) {
+		task, err := ticksClient.GetTask(taskID)
+		if err != nil {
+			return
+		}
+		go loadTasksForEpic(task.Parent)
+		if wakeChan, ok := wakeChans[task.Parent]; ok {
+			wake(wakeChan)
+		}
+	}
+
+	promptBuilder := loadPromptBuilder(false)
+	var runLogs parallelRunLogs
 	engineFactory := func(epicID string) *engine.Engine {
-		claudeAgent := agent.NewClaudeAgent()
+		cliAgent, _ := agents.Get("")
 		eng := engine.NewEngine(
-			claudeAgent,
+			cliAgent,
 			ticksClient,
 			sharedBudget,
 			checkpointMgr,
 		)
-
-		// Set up context generation
-		contextStore := epiccontext.NewStore()
-		contextGenerator, err := epiccontext.NewGenerator(claudeAgent)
-		if err == nil {
-			eng.SetContextComponents(contextStore, contextGenerator)
-		}
-
-		if !skipVerify {
-			if isVerificationEnabled() {
-				eng.EnableVerification()
-			}
-		}
+		// Warnings would garble the TUI
+		runLogs.add(epicID, configureEngine(eng, agents, cliAgent, engineOptions{
+			epicID:       epicID,
+			mode:         "parallel",
+			skipVerify:   skipVerify,
+			verification: verifyConfig,
+			quiet:        true,
+			prompts:      promptBuilder,
+		}))
 
 		// Track previous snapshot state for delta-based TUI updates (per-engine)
 		var prevOutput string
 
-		// Rich streaming callback - converts AgentStateSnapshot to TUI messages
-		eng.OnAgentState = func(snap agent.AgentStateSnapshot) {
-			// Send text deltas (only new content since last update)
-			if snap.Output != prevOutput {
-				delta := snap.Output[len(prevOutput):]
-				if delta != "" {
-					p.Send(tui.EpicOutputMsg{EpicID: epicID, Text: delta})
+		// Forward engine events to the TUI as per-epic messages
+		eng.Subscribe(func(ev engine.Event) {
+			switch ev := ev.(type) {
+			case engine.AgentStateEvent:
+				// Send text deltas (only new content since last update)
+				snap := ev.Snapshot
+				if snap.Output != prevOutput {
+					delta := snap.Output[len(prevOutput):]
+					if delta != "" {
+						p.Send(tui.EpicOutputMsg{EpicID: epicID, Text: delta})
+					}
+					prevOutput = snap.Output
 				}
-				prevOutput = snap.Output
-			}
-		}
-
-		// Note: OnOutput callback not set in TUI mode - OnAgentState handles streaming
-
-		eng.OnIterationStart = func(ctx engine.IterationContext) {
-			prevOutput = "" // Reset for new iteration
-			p.Send(tui.EpicIterationStartMsg{
-				EpicID:    epicID,
-				Iteration: ctx.Iteration,
-				TaskID:    ctx.Task.ID,
-				TaskTitle: ctx.Task.Title,
-			})
-		}
-
-		eng.OnIterationEnd = func(result *engine.IterationResult) {
-			p.Send(tui.EpicIterationEndMsg{
-				EpicID:    epicID,
-				Iteration: result.Iteration,
-				Cost:      result.Cost,
-				Tokens:    result.TokensIn + result.TokensOut,
-			})
-			loadTasksForEpic(epicID)
-		}
-
-		eng.OnVerificationStart = func(taskID string) {
-			p.Send(tui.VerifyStartMsg{TaskID: taskID})
-		}
-
-		eng.OnVerificationEnd = func(taskID string, results *verify.Results) {
-			summary := ""
-			passed := true
-			if results != nil {
-				passed = results.AllPassed
-				summary = results.Summary()
+			case engine.IterationStartEvent:
+				prevOutput = "" // Reset for new iteration
+				p.Send(tui.EpicIterationStartMsg{
+					EpicID:    epicID,
+					Iteration: ev.Context.Iteration,
+					TaskID:    ev.Context.Task.ID,
+					TaskTitle: ev.Context.Task.Title,
+				})
+			case engine.IterationEndEvent:
+				p.Send(tui.EpicIterationEndMsg{
+					EpicID:    epicID,
+					Iteration: ev.Result.Iteration,
+					Cost:      ev.Result.Cost,
+					Tokens:    ev.Result.TokensIn + ev.Result.TokensOut,
+				})
+				loadTasksForEpic(epicID)
+			case engine.VerificationStartEvent:
+				p.Send(tui.VerifyStartMsg{TaskID: ev.TaskID})
+			case engine.VerificationEndEvent:
+				p.Send(verifyResultMsg(ev))
+				loadTasksForEpic(epicID)
+			case engine.ContextGeneratingEvent:
+				p.Send(tui.EpicContextGeneratingMsg{EpicID: ev.EpicID, TaskCount: ev.TaskCount})
+			case engine.ContextGeneratedEvent:
+				p.Send(tui.EpicContextGeneratedMsg{EpicID: ev.EpicID, Tokens: ev.Tokens})
+			case engine.ContextLoadedEvent:
+				p.Send(tui.EpicContextLoadedMsg{EpicID: ev.EpicID})
+			case engine.ContextSkippedEvent:
+				p.Send(tui.EpicContextSkippedMsg{EpicID: ev.EpicID, Reason: ev.Reason})
+			case engine.ContextFailedEvent:
+				p.Send(tui.EpicContextFailedMsg{EpicID: ev.EpicID, Error: ev.Error})
 			}
-			p.Send(tui.VerifyResultMsg{
-				TaskID:  taskID,
-				Passed:  passed,
-				Summary: summary,
-			})
-			loadTasksForEpic(epicID)
-		}
-
-		// Context generation callbacks for multi-epic TUI
-		eng.OnContextGenerating = func(eid string, taskCount int) {
-			p.Send(tui.EpicContextGeneratingMsg{EpicID: eid, TaskCount: taskCount})
-		}
-		eng.OnContextGenerated = func(eid string, tokenCount int) {
-			p.Send(tui.EpicContextGeneratedMsg{EpicID: eid, Tokens: tokenCount})
-		}
-		eng.OnContextLoaded = func(eid string, _ string) {
-			p.Send(tui.EpicContextLoadedMsg{EpicID: eid})
-		}
-		eng.OnContextSkipped = func(eid string, reason string) {
-			p.Send(tui.EpicContextSkippedMsg{EpicID: eid, Reason: reason})
-		}
-		eng.OnContextFailed = func(eid string, errMsg string) {
-			p.Send(tui.EpicContextFailedMsg{EpicID: eid, Error: errMsg})
-		}
+		})
 
 		return eng
 	}
@@ -897,6 +1095,7 @@ func runParallelWithTUI(epicIDs, epicTitles []string, maxIterations int, maxCost
 		WorktreeManager: wtManager,
 		MergeManager:    mergeManager,
 		EngineFactory:   engineFactory,
+		WakeChans:       engineWakeChans,
 		EngineConfig: engine.RunConfig{
 			MaxIterations:   maxIterations,
 			MaxCost:         maxCost,
@@ -954,6 +1153,7 @@ func runParallelWithTUI(epicIDs, epicTitles []string, maxIterations int, maxCost
 		}
 
 		result, err := runner.Run(ctx)
+		runLogs.endAll(result)
 		if err != nil {
 			p.Send(tui.ErrorMsg{Err: err})
 			return
@@ -976,7 +1176,7 @@ func runParallelWithTUI(epicIDs, epicTitles []string, maxIterations int, maxCost
 	cancel()
 }
 
-func runParallelHeadless(epicIDs []string, maxIterations int, maxCost float64, checkpointInterval, maxTaskRetries int, skipVerify bool, maxParallel int, jsonl bool) {
+func runParallelHeadless(epicIDs []string, maxIterations int, maxCost float64, checkpointInterval, maxTaskRetries int, skipVerify bool, maxParallel int, jsonl bool, agentName string) {
 	// Create context with signal handling
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -1054,10 +1254,15 @@ func runParallelHeadless(epicIDs []string, maxIterations int, maxCost float64, c
 		MaxCost:       maxCost,
 	})
 
-	// Check claude availability
-	claudeAgent := agent.NewClaudeAgent()
-	if !claudeAgent.Available() {
-		fmt.Fprintf(os.Stderr, "[ERROR] claude CLI not found - please install Claude Code\n")
+	// Load agent registry and check default agent availability
+	agents, _, err := loadAgentRegistry(agentName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
+		os.Exit(ExitError)
+	}
+	verifyConfig, err := loadVerificationConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
 		os.Exit(ExitError)
 	}
 
@@ -1065,107 +1270,31 @@ func runParallelHeadless(epicIDs []string, maxIterations int, maxCost float64, c
 	ticksClient := ticks.NewClient()
 	checkpointMgr := checkpoint.NewManager()
 
+	promptBuilder := loadPromptBuilder(jsonl)
+	var runLogs parallelRunLogs
 	engineFactory := func(epicID string) *engine.Engine {
-		claudeAgent := agent.NewClaudeAgent()
+		cliAgent, _ := agents.Get("")
 		eng := engine.NewEngine(
-			claudeAgent,
+			cliAgent,
 			ticksClient,
 			sharedBudget,
 			checkpointMgr,
 		)
-
-		// Set up context generation (use discard logger in jsonl mode)
-		contextStore := epiccontext.NewStore()
-		var contextGenOpts []epiccontext.GeneratorOption
-		if jsonl {
-			contextGenOpts = append(contextGenOpts, epiccontext.WithLogger(slog.New(slog.DiscardHandler)))
-		}
-		contextGenerator, err := epiccontext.NewGenerator(claudeAgent, contextGenOpts...)
-		if err == nil {
-			eng.SetContextComponents(contextStore, contextGenerator)
-		}
-
-		if !skipVerify {
-			if isVerificationEnabled() {
-				eng.EnableVerification()
-			}
-		}
+		runLogs.add(epicID, configureEngine(eng, agents, cliAgent, engineOptions{
+			epicID:       epicID,
+			mode:         "parallel",
+			headless:     true,
+			skipVerify:   skipVerify,
+			verification: verifyConfig,
+			quiet:        jsonl,
+			prompts:      promptBuilder,
+		}))
 
 		// Get the output formatter for this epic
 		out := outputs[epicID]
 
-		// Track verification pass status for task_complete output
-		var verifyPassed bool = true
-
-		// Set up task-level callbacks for headless output
-		eng.OnOutput = func(chunk string) {
-			if out != nil {
-				out.Output(chunk)
-			}
-		}
-
-		eng.OnIterationStart = func(iterCtx engine.IterationContext) {
-			verifyPassed = true // Reset for new task
-			if out != nil {
-				out.Task(iterCtx.Task, iterCtx.Iteration)
-				if iterCtx.EpicContext != "" {
-					out.ContextInjected(iterCtx.Task.ID, iterCtx.EpicContext)
-				}
-			}
-		}
-
-		eng.OnSignal = func(sig engine.Signal, reason string) {
-			if out != nil {
-				out.Signal(sig, reason)
-			}
-		}
-
-		eng.OnVerificationStart = func(taskID string) {
-			if out != nil {
-				out.VerifyStart(taskID)
-			}
-		}
-
-		eng.OnVerificationEnd = func(taskID string, results *verify.Results) {
-			if results != nil {
-				verifyPassed = results.AllPassed
-			}
-			if out != nil {
-				out.VerifyEnd(taskID, results)
-				out.TaskComplete(taskID, verifyPassed)
-			}
-		}
-
-		// Context generation callbacks for multi-epic headless mode
-		eng.OnContextGenerating = func(eid string, taskCount int) {
-			if out != nil {
-				out.ContextGenerating(eid, taskCount)
-			}
-		}
-		eng.OnContextGenerated = func(eid string, tokenCount int) {
-			if out != nil {
-				out.ContextGenerated(eid, tokenCount)
-			}
-		}
-		eng.OnContextLoaded = func(eid string, content string) {
-			if out != nil {
-				out.ContextLoaded(eid, content)
-			}
-		}
-		eng.OnContextSkipped = func(eid string, reason string) {
-			if out != nil {
-				out.ContextSkipped(eid, reason)
-			}
-		}
-		eng.OnContextFailed = func(eid string, errMsg string) {
-			if out != nil {
-				out.ContextFailed(eid, errMsg)
-			}
-		}
-		eng.OnContextActive = func(eid string) {
-			if out != nil {
-				out.ContextActive(eid)
-			}
+		if out != nil {
+			eng.Subscribe(out.Handle)
 		}
 
 		return eng
@@ -1266,6 +1395,7 @@ func runParallelHeadless(epicIDs []string, maxIterations int, maxCost float64, c
 	}
 
 	result, err := runner.Run(ctx)
+	runLogs.endAll(result)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "[ERROR] %v\n", err)
 		os.Exit(ExitError)
@@ -1334,10 +1464,117 @@ func runParallelHeadless(epicIDs []string, maxIterations int, maxCost float64, c
 	os.Exit(ExitError)
 }
 
-func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, checkpointInterval, maxTaskRetries int, skipVerify, useWorktree, watch bool, watchTimeout, watchPollInterval, debounceInterval time.Duration, auto, includeStandalone, includeOrphans bool) {
+// taskInfos converts tasks for the TUI task list. BlockedBy only keeps
+// blockers that are still open.
+func taskInfos(tasks []ticks.Task) []tui.TaskInfo {
+	taskStatus := make(map[string]string, len(tasks))
+	for _, t := range tasks {
+		taskStatus[t.ID] = t.Status
+	}
+	infos := make([]tui.TaskInfo, len(tasks))
+	for i, t := range tasks {
+		var openBlockers []string
+		for _, blockerID := range t.BlockedBy {
+			if taskStatus[blockerID] == "open" {
+				openBlockers = append(openBlockers, blockerID)
+			}
+		}
+		infos[i] = tui.TaskInfo{
+			ID:        t.ID,
+			Title:     t.Title,
+			Status:    tui.TaskStatus(t.Status),
+			BlockedBy: openBlockers,
+			Awaiting:  t.GetAwaitingType(),
+		}
+	}
+	return infos
+}
+
+// tuiHandoffs answers the TUI's handoff review panel from the ticks client.
+type tuiHandoffs struct {
+	ticks *ticks.Client
+
+	// onResponded is called with the task's ID after a response went through.
+	onResponded func(taskID string)
+}
+
+// wake makes an idle watch-mode engine check for tasks right away. It never
+// blocks: a wake-up that is already pending covers this one.
+func wake(wakeChan chan<- struct{}) {
+	select {
+	case wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// LoadHandoff returns what the review panel shows about taskID: the signal's
+// reason (the agent's latest note), its earlier notes and the last run.
+func (h *tuiHandoffs) LoadHandoff(taskID string) (*tui.Handoff, error) {
+	task, err := h.ticks.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := h.ticks.GetAgentNotes(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("reading notes of %s: %w", taskID, err)
+	}
+
+	handoff := &tui.Handoff{
+		TaskID:   task.ID,
+		Title:    task.Title,
+		Awaiting: task.GetAwaitingType(),
+	}
+	if n := len(notes); n > 0 {
+		handoff.Reason = notes[n-1].Content
+		for _, note := range notes[:n-1] {
+			handoff.Notes = append(handoff.Notes, note.Content)
+		}
+	}
+	// A missing run record only leaves the panel without run details
+	if record, err := h.ticks.GetRunRecord(taskID); err == nil {
+		handoff.RunRecord = record
+	}
+	return handoff, nil
+}
+
+// RespondHandoff sends the human's verdict or answer for taskID.
+func (h *tuiHandoffs) RespondHandoff(taskID string, action tui.HandoffAction, text string) error {
+	var err error
+	switch action {
+	case tui.HandoffApprove:
+		_, err = inbox.Approve(h.ticks, taskID)
+	case tui.HandoffReject:
+		_, err = inbox.Reject(h.ticks, taskID, text)
+	case tui.HandoffAnswer:
+		_, err = inbox.Answer(h.ticks, taskID, text)
+	default:
+		err = fmt.Errorf("unknown handoff action %q", action)
+	}
+	if err != nil {
+		return err
+	}
+	if h.onResponded != nil {
+		h.onResponded(taskID)
+	}
+	return nil
+}
+
+// commitDiff loads diffs for the TUI diff viewer from the repository in the
+// current directory. Commits made in worktrees share its object store.
+func commitDiff(from, to string) (string, error) {
+	return gitutil.Diff(context.Background(), ".", from, to)
+}
+
+func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, checkpointInterval, maxTaskRetries int, skipVerify, useWorktree, watch bool, watchTimeout, watchPollInterval, debounceInterval time.Duration, auto, includeStandalone, includeOrphans bool, agentName string) {
 	// Create pause channel for TUI <-> engine communication
 	pauseChan := make(chan bool, 1)
 
+	// Wakes watch mode when a handoff is answered from the TUI
+	wakeChan := make(chan struct{}, 1)
+
+	ticksClient := ticks.NewClient()
+	handoffs := &tuiHandoffs{ticks: ticksClient}
+
 	// Create TUI model
 	m := tui.New(tui.Config{
 		EpicID:       epicID,
@@ -1345,6 +1582,8 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 		MaxCost:      maxCost,
 		MaxIteration: maxIterations,
 		PauseChan:    pauseChan,
+		Handoff:      handoffs,
+		Diff:         commitDiff,
 	})
 
 	// Create program
@@ -1355,13 +1594,17 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 	defer cancel()
 
 	// Initialize engine components
-	claudeAgent := agent.NewClaudeAgent()
-	if !claudeAgent.Available() {
-		fmt.Fprintln(os.Stderr, "Error: claude CLI not found. Please install Claude Code.")
+	agents, cliAgent, err := loadAgentRegistry(agentName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	verifyConfig, err := loadVerificationConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(ExitError)
 	}
 
-	ticksClient := ticks.NewClient()
 	budgetTracker := budget.NewTracker(budget.Limits{
 		MaxIterations: maxIterations,
 		MaxCost:       maxCost,
@@ -1369,33 +1612,13 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 	checkpointMgr := checkpoint.NewManager()
 
 	// Create engine
-	eng := engine.NewEngine(claudeAgent, ticksClient, budgetTracker, checkpointMgr)
-
-	// Set up context generation
-	contextStore := epiccontext.NewStore()
-	contextGenerator, err := epiccontext.NewGenerator(claudeAgent)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: could not create context generator: %v\n", err)
-	} else {
-		eng.SetContextComponents(contextStore, contextGenerator)
-	}
-
-	// Set up verification runner (unless --skip-verify)
-	if !skipVerify {
-		if isVerificationEnabled() {
-			eng.EnableVerification()
-		}
-	}
-
-	// Create run logger
-	runLogger, err := runlog.New(epicID)
-	if err != nil {
-		// Log warning but continue without run logging
-		fmt.Fprintf(os.Stderr, "Warning: could not create run log: %v\n", err)
-	} else {
-		eng.SetRunLog(runLogger)
-		runLogger.LogRunStart("tui", false)
-	}
+	eng := engine.NewEngine(cliAgent, ticksClient, budgetTracker, checkpointMgr)
+	runLogger := configureEngine(eng, agents, cliAgent, engineOptions{
+		epicID:       epicID,
+		mode:         "tui",
+		skipVerify:   skipVerify,
+		verification: verifyConfig,
+	})
 
 	// Helper to refresh task list in TUI
 	refreshTasks := func() {
@@ -1403,29 +1626,7 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 		if err != nil {
 			return // Silently ignore errors
 		}
-		// Build map of task statuses to filter closed blockers
-		taskStatus := make(map[string]string, len(tasks))
-		for _, t := range tasks {
-			taskStatus[t.ID] = t.Status
-		}
-		taskInfos := make([]tui.TaskInfo, len(tasks))
-		for i, t := range tasks {
-			// Filter BlockedBy to only include open blockers
-			var openBlockers []string
-			for _, blockerID := range t.BlockedBy {
-				if taskStatus[blockerID] == "open" {
-					openBlockers = append(openBlockers, blockerID)
-				}
-			}
-			taskInfos[i] = tui.TaskInfo{
-				ID:        t.ID,
-				Title:     t.Title,
-				Status:    tui.TaskStatus(t.Status),
-				BlockedBy: openBlockers,
-				Awaiting:  t.GetAwaitingType(),
-			}
-		}
-		p.Send(tui.TasksUpdateMsg{Tasks: taskInfos})
+		p.Send(tui.TasksUpdateMsg{Tasks: taskInfos(tasks)})
 
 		// Fetch and send RunRecords for closed tasks
 		for _, t := range tasks {
@@ -1440,6 +1641,13 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 	// Initial task list load
 	go refreshTasks()
 
+	// A handoff answered in the TUI is ready for the agent again; don't
+	// make watch mode wait for its next poll
+	handoffs.onResponded = func(string) {
+		go refreshTasks()
+		wake(wakeChan)
+	}
+
 	// Start file watcher for external tick changes
 	ticksWatcher := engine.NewTicksWatcher("")
 	defer ticksWatcher.Close()
@@ -1451,145 +1659,51 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 		}()
 	}
 
-	// Wire engine callbacks to send TUI messages
-
-	// Track previous snapshot state for delta-based TUI updates
-	var prevOutput, prevThinking string
-	var prevToolID string
-
-	// Rich streaming callback - converts AgentStateSnapshot to TUI messages
-	eng.OnAgentState = func(snap agent.AgentStateSnapshot) {
-		// Send text deltas (only new content since last update)
-		if snap.Output != prevOutput {
-			delta := snap.Output[len(prevOutput):]
-			if delta != "" {
-				p.Send(tui.AgentTextMsg{Text: delta})
-			}
-			prevOutput = snap.Output
+	// Forward engine events to the TUI
+	forwardAgentState, resetAgentState := newAgentStateForwarder(p.Send)
+	eng.Subscribe(func(ev engine.Event) {
+		switch ev := ev.(type) {
+		case engine.AgentStateEvent:
+			forwardAgentState(ev.Snapshot)
+		case engine.IterationStartEvent:
+			// Reset delta tracking state for new iteration
+			resetAgentState()
+			p.Send(tui.IterationStartMsg{
+				Iteration: ev.Context.Iteration,
+				TaskID:    ev.Context.Task.ID,
+				TaskTitle: ev.Context.Task.Title,
+			})
+		case engine.IterationEndEvent:
+			p.Send(tui.IterationEndMsg{
+				Iteration: ev.Result.Iteration,
+				Cost:      ev.Result.Cost,
+				Tokens:    ev.Result.TokensIn + ev.Result.TokensOut,
+			})
+			// Refresh task list after each iteration
+			go refreshTasks()
+		case engine.SignalEvent:
+			p.Send(tui.SignalMsg{Signal: ev.Signal.String(), Reason: ev.Reason})
+		case engine.VerificationStartEvent:
+			p.Send(tui.VerifyStartMsg{TaskID: ev.TaskID})
+		case engine.VerificationEndEvent:
+			p.Send(verifyResultMsg(ev))
+			// Refresh task list after verification (task may have been reopened)
+			go refreshTasks()
+		case engine.ContextGeneratingEvent:
+			p.Send(tui.ContextGeneratingMsg{EpicID: ev.EpicID, TaskCount: ev.TaskCount})
+		case engine.ContextGeneratedEvent:
+			p.Send(tui.ContextGeneratedMsg{EpicID: ev.EpicID, Tokens: ev.Tokens})
+		case engine.ContextLoadedEvent:
+			p.Send(tui.ContextLoadedMsg{EpicID: ev.EpicID})
+		case engine.ContextSkippedEvent:
+			p.Send(tui.ContextSkippedMsg{EpicID: ev.EpicID, Reason: ev.Reason})
+		case engine.ContextFailedEvent:
+			p.Send(tui.ContextFailedMsg{EpicID: ev.EpicID, Error: ev.Error})
+		case engine.IdleEvent:
+			// Watch mode is waiting for tasks
+			p.Send(tui.IdleMsg{})
 		}
-
-		// Send thinking deltas
-		if snap.Thinking != prevThinking {
-			delta := snap.Thinking[len(prevThinking):]
-			if delta != "" {
-				p.Send(tui.AgentThinkingMsg{Text: delta})
-			}
-			prevThinking = snap.Thinking
-		}
-
-		// Send tool activity updates
-		if snap.ActiveTool != nil && snap.ActiveTool.ID != prevToolID {
-			// New tool started
-			p.Send(tui.AgentToolStartMsg{
-				ID:   snap.ActiveTool.ID,
-				Name: snap.ActiveTool.Name,
-			})
-			prevToolID = snap.ActiveTool.ID
-		} else if snap.ActiveTool == nil && prevToolID != "" {
-			// Tool ended - find it in history to get duration and error status
-			for _, tool := range snap.ToolHistory {
-				if tool.ID == prevToolID {
-					p.Send(tui.AgentToolEndMsg{
-						ID:       tool.ID,
-						Name:     tool.Name,
-						Duration: tool.Duration,
-						IsError:  tool.IsError,
-					})
-					break
-				}
-			}
-			prevToolID = ""
-		}
-
-		// Send metrics update (including model name)
-		p.Send(tui.AgentMetricsMsg{
-			InputTokens:         snap.Metrics.InputTokens,
-			OutputTokens:        snap.Metrics.OutputTokens,
-			CacheReadTokens:     snap.Metrics.CacheReadTokens,
-			CacheCreationTokens: snap.Metrics.CacheCreationTokens,
-			CostUSD:             snap.Metrics.CostUSD,
-			Model:               snap.Model,
-		})
-
-		// Send status update
-		p.Send(tui.AgentStatusMsg{
-			Status: snap.Status,
-			Error:  snap.ErrorMsg,
-		})
-	}
-
-	// Note: OnOutput callback not set in TUI mode - OnAgentState handles streaming
-
-	eng.OnIterationStart = func(ctx engine.IterationContext) {
-		// Reset delta tracking state for new iteration
-		prevOutput = ""
-		prevThinking = ""
-		prevToolID = ""
-
-		p.Send(tui.IterationStartMsg{
-			Iteration: ctx.Iteration,
-			TaskID:    ctx.Task.ID,
-			TaskTitle: ctx.Task.Title,
-		})
-	}
-
-	eng.OnIterationEnd = func(result *engine.IterationResult) {
-		p.Send(tui.IterationEndMsg{
-			Iteration: result.Iteration,
-			Cost:      result.Cost,
-			Tokens:    result.TokensIn + result.TokensOut,
-		})
-		// Refresh task list after each iteration
-		go refreshTasks()
-	}
-
-	eng.OnSignal = func(sig engine.Signal, reason string) {
-		p.Send(tui.SignalMsg{Signal: sig.String(), Reason: reason})
-	}
-
-	// Verification callbacks for TUI status display
-	eng.OnVerificationStart = func(taskID string) {
-		p.Send(tui.VerifyStartMsg{TaskID: taskID})
-	}
-
-	eng.OnVerificationEnd = func(taskID string, results *verify.Results) {
-		// Build summary from results
-		summary := ""
-		passed := true
-		if results != nil {
-			passed = results.AllPassed
-			summary = results.Summary()
-		}
-		p.Send(tui.VerifyResultMsg{
-			TaskID:  taskID,
-			Passed:  passed,
-			Summary: summary,
-		})
-		// Refresh task list after verification (task may have been reopened)
-		go refreshTasks()
-	}
-
-	// Context generation callbacks for TUI status display
-	eng.OnContextGenerating = func(epicID string, taskCount int) {
-		p.Send(tui.ContextGeneratingMsg{EpicID: epicID, TaskCount: taskCount})
-	}
-	eng.OnContextGenerated = func(epicID string, tokenCount int) {
-		p.Send(tui.ContextGeneratedMsg{EpicID: epicID, Tokens: tokenCount})
-	}
-	eng.OnContextLoaded = func(epicID string, _ string) {
-		p.Send(tui.ContextLoadedMsg{EpicID: epicID})
-	}
-	eng.OnContextSkipped = func(epicID string, reason string) {
-		p.Send(tui.ContextSkippedMsg{EpicID: epicID, Reason: reason})
-	}
-	eng.OnContextFailed = func(epicID string, errMsg string) {
-		p.Send(tui.ContextFailedMsg{EpicID: epicID, Error: errMsg})
-	}
-
-	// Set up OnIdle callback for watch mode TUI updates
-	eng.OnIdle = func() {
-		p.Send(tui.IdleMsg{})
-	}
+	})
 
 	// Run engine in background with auto-continuation support
 	go func() {
@@ -1605,6 +1719,7 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 				CheckpointEvery:   checkpointInterval,
 				MaxTaskRetries:    maxTaskRetries,
 				PauseChan:         pauseChan,
+				WakeChan:          wakeChan,
 				UseWorktree:       useWorktree,
 				Watch:             watch,
 				WatchTimeout:      watchTimeout,
@@ -1615,26 +1730,8 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 			result, err := eng.Run(ctx, config)
 
 			// Log run end
-			if runLogger != nil {
-				if result != nil {
-					signalStr := ""
-					if result.Signal != engine.SignalNone {
-						signalStr = result.Signal.String()
-					}
-					runLogger.LogRunEnd(runlog.RunEndData{
-						ExitReason:     result.ExitReason,
-						Iterations:     result.Iterations,
-						CompletedTasks: result.CompletedTasks,
-						TotalTokens:    result.TotalTokens,
-						TotalCost:      result.TotalCost,
-						Duration:       result.Duration,
-						Signal:         signalStr,
-						SignalReason:   result.SignalReason,
-					})
-				}
-				runLogger.Close()
-				runLogger = nil // Don't close again
-			}
+			endRunLog(runLogger, result)
+			runLogger = nil // Don't close again
 
 			if err != nil {
 				p.Send(tui.ErrorMsg{Err: err})
@@ -1671,8 +1768,13 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 			if nextWork.IsStandalone {
 				p.Send(tui.GlobalStatusMsg{Message: fmt.Sprintf("[AUTO] Switching to standalone task: [%s] %s", nextWork.Task.ID, nextWork.Task.Title)})
 
-				// Run standalone task using the same pattern as runStandaloneTask but with TUI output
-				runStandaloneInTUI(ctx, p, nextWork.Task, ticksClient, claudeAgent, budgetTracker, checkpointMgr, skipVerify, includeStandalone, includeOrphans)
+				// Run standalone task using the same pattern as runStandaloneTask but with TUI output,
+				// in a run log of its own (the epic's was ended above)
+				standaloneLog := startRunLog(eng, "", "tui", false, true)
+				standaloneResult := runStandaloneInTUI(ctx, p, eng, nextWork.Task, ticksClient, budgetTracker, verifyConfig, skipVerify, includeStandalone, includeOrphans)
+				endRunLog(standaloneLog, standaloneResult)
+				totalIterations += standaloneResult.Iterations
+				totalCost += standaloneResult.TotalCost
 
 				// After standalone tasks complete, check for more epics
 				nextWork = findNextWork(ticksClient, includeStandalone, includeOrphans)
@@ -1687,8 +1789,9 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 				}
 			}
 
-			// Continue with next epic
+			// Continue with next epic, in a new run log
 			currentEpicID = nextWork.EpicID
+			runLogger = startRunLog(eng, currentEpicID, "tui", false, true)
 			p.Send(tui.GlobalStatusMsg{Message: fmt.Sprintf("[AUTO] Continuing with epic: [%s] %s", nextWork.EpicID, nextWork.EpicTitle)})
 
 			// Refresh task list for the new epic
@@ -1706,9 +1809,95 @@ func runWithTUI(epicID, epicTitle string, maxIterations int, maxCost float64, ch
 	cancel()
 }
 
+// verifyResultMsg converts a verification result event into a TUI message.
+// No results (nothing was verified) counts as passed.
+func verifyResultMsg(ev engine.VerificationEndEvent) tui.VerifyResultMsg {
+	msg := tui.VerifyResultMsg{TaskID: ev.TaskID, Passed: true}
+	if ev.Results != nil {
+		msg.Passed = ev.Results.AllPassed
+		msg.Summary = ev.Results.Summary()
+	}
+	return msg
+}
+
+// newAgentStateForwarder returns a function that converts agent state
+// snapshots into TUI messages passed to send, and a reset func to call at the
+// start of each iteration.
+func newAgentStateForwarder(send func(tea.Msg)) (forward func(agent.AgentStateSnapshot), reset func()) {
+	// Track previous snapshot state for delta-based TUI updates
+	var prevOutput, prevThinking string
+	var prevToolID string
+
+	forward = func(snap agent.AgentStateSnapshot) {
+		// Send text deltas (only new content since last update)
+		if snap.Output != prevOutput {
+			delta := snap.Output[len(prevOutput):]
+			if delta != "" {
+				send(tui.AgentTextMsg{Text: delta})
+			}
+			prevOutput = snap.Output
+		}
+
+		// Send thinking deltas
+		if snap.Thinking != prevThinking {
+			delta := snap.Thinking[len(prevThinking):]
+			if delta != "" {
+				send(tui.AgentThinkingMsg{Text: delta})
+			}
+			prevThinking = snap.Thinking
+		}
+
+		// Send tool activity updates
+		if snap.ActiveTool != nil && snap.ActiveTool.ID != prevToolID {
+			// New tool started
+			send(tui.AgentToolStartMsg{
+				ID:   snap.ActiveTool.ID,
+				Name: snap.ActiveTool.Name,
+			})
+			prevToolID = snap.ActiveTool.ID
+		} else if snap.ActiveTool == nil && prevToolID != "" {
+			// Tool ended - find it in history to get duration and error status
+			for _, tool := range snap.ToolHistory {
+				if tool.ID == prevToolID {
+					send(tui.AgentToolEndMsg{
+						ID:       tool.ID,
+						Name:     tool.Name,
+						Duration: tool.Duration,
+						IsError:  tool.IsError,
+					})
+					break
+				}
+			}
+			prevToolID = ""
+		}
+
+		// Send metrics update (including model name)
+		send(tui.AgentMetricsMsg{
+			InputTokens:         snap.Metrics.InputTokens,
+			OutputTokens:        snap.Metrics.OutputTokens,
+			CacheReadTokens:     snap.Metrics.CacheReadTokens,
+			CacheCreationTokens: snap.Metrics.CacheCreationTokens,
+			CostUSD:             snap.Metrics.CostUSD,
+			Model:               snap.Model,
+		})
+
+		// Send status update
+		send(tui.AgentStatusMsg{
+			Status: snap.Status,
+			Error:  snap.ErrorMsg,
+		})
+	}
+	reset = func() {
+		prevOutput = ""
+		prevThinking = ""
+		prevToolID = ""
+	}
+	return forward, reset
+}
+
 // runHeadless runs an epic in headless mode and returns the exit code.
 // Returns ExitSuccess, ExitMaxIterations, ExitEject, ExitBlocked, or ExitError.
-func runHeadless(epicID string, maxIterations int, maxCost float64, checkpointInterval, maxTaskRetries int, skipVerify, useWorktree, jsonl, watch bool, watchTimeout, watchPollInterval, debounceInterval time.Duration) int {
+func runHeadless(epicID string, maxIterations int, maxCost float64, checkpointInterval, maxTaskRetries int, skipVerify, useWorktree, jsonl, watch bool, watchTimeout, watchPollInterval, debounceInterval time.Duration, agentName string, srv *server.Server) int {
 	// Create context with signal handling
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -1725,9 +1914,14 @@ func runHeadless(epicID string, maxIterations int, maxCost float64, checkpointIn
 	}()
 
 	// Initialize components
-	claudeAgent := agent.NewClaudeAgent()
-	if !claudeAgent.Available() {
-		out.Error(fmt.Errorf("claude CLI not found - please install Claude Code"))
+	agents, cliAgent, err := loadAgentRegistry(agentName)
+	if err != nil {
+		out.Error(err)
+		return ExitError
+	}
+	verifyConfig, err := loadVerificationConfig()
+	if err != nil {
+		out.Error(err)
 		return ExitError
 	}
 
@@ -1746,108 +1940,18 @@ func runHeadless(epicID string, maxIterations int, maxCost float64, checkpointIn
 	}
 
 	// Create and configure engine
-	eng := engine.NewEngine(claudeAgent, ticksClient, budgetTracker, checkpointMgr)
-
-	// Set up context generation (use discard logger in jsonl mode)
-	contextStore := epiccontext.NewStore()
-	var contextGenOpts []epiccontext.GeneratorOption
-	if jsonl {
-		contextGenOpts = append(contextGenOpts, epiccontext.WithLogger(slog.New(slog.DiscardHandler)))
-	}
-	contextGenerator, err := epiccontext.NewGenerator(claudeAgent, contextGenOpts...)
-	if err != nil {
-		if !jsonl {
-			fmt.Fprintf(os.Stderr, "Warning: could not create context generator: %v\n", err)
-		}
-	} else {
-		eng.SetContextComponents(contextStore, contextGenerator)
-	}
-
-	// Set up verification runner (unless --skip-verify)
-	if !skipVerify {
-		if isVerificationEnabled() {
-			eng.EnableVerification()
-		}
-	}
-
-	// Create run logger
-	runLogger, err := runlog.New(epicID)
-	if err != nil {
-		// Log warning but continue without run logging
-		if !jsonl {
-			fmt.Fprintf(os.Stderr, "Warning: could not create run log: %v\n", err)
-		}
-	} else {
-		eng.SetRunLog(runLogger)
-		runLogger.LogRunStart("headless", true)
-	}
-
-	// Track verification pass status for task_complete output
-	var verifyPassed bool = true
-
-	// Set up output callback for headless mode
-	eng.OnOutput = func(chunk string) {
-		out.Output(chunk)
-	}
-
-	eng.OnIterationStart = func(iterCtx engine.IterationContext) {
-		verifyPassed = true // Reset for new task
-		out.Task(iterCtx.Task, iterCtx.Iteration)
-		if iterCtx.EpicContext != "" {
-			out.ContextInjected(iterCtx.Task.ID, iterCtx.EpicContext)
-		}
-	}
-
-	eng.OnIterationEnd = func(result *engine.IterationResult) {
-		// Token counts are only in final summary, not per iteration
-	}
-
-	eng.OnSignal = func(sig engine.Signal, reason string) {
-		out.Signal(sig, reason)
-	}
-
-	// Verification callbacks for headless mode
-	eng.OnVerificationStart = func(taskID string) {
-		out.VerifyStart(taskID)
-	}
-
-	eng.OnVerificationEnd = func(taskID string, results *verify.Results) {
-		if results != nil {
-			verifyPassed = results.AllPassed
-			out.VerifyEnd(taskID, results)
-		}
-		// Output task complete after verification
-		out.TaskComplete(taskID, verifyPassed)
-	}
-
-	// Set up OnIdle callback for watch mode headless output
-	eng.OnIdle = func() {
-		if jsonl {
-			fmt.Println(`{"type":"idle","message":"waiting for tasks"}`)
-		} else {
-			fmt.Println("[IDLE] No tasks available, waiting...")
-		}
-	}
+	eng := engine.NewEngine(cliAgent, ticksClient, budgetTracker, checkpointMgr)
+	runLogger := configureEngine(eng, agents, cliAgent, engineOptions{
+		epicID:       epicID,
+		mode:         "headless",
+		headless:     true,
+		skipVerify:   skipVerify,
+		verification: verifyConfig,
+		quiet:        jsonl,
+	})
 
-	// Context generation callbacks for headless mode
-	eng.OnContextGenerating = func(epicID string, taskCount int) {
-		out.ContextGenerating(epicID, taskCount)
-	}
-	eng.OnContextGenerated = func(epicID string, tokenCount int) {
-		out.ContextGenerated(epicID, tokenCount)
-	}
-	eng.OnContextLoaded = func(epicID string, content string) {
-		out.ContextLoaded(epicID, content)
-	}
-	eng.OnContextSkipped = func(epicID string, reason string) {
-		out.ContextSkipped(epicID, reason)
-	}
-	eng.OnContextFailed = func(epicID string, errMsg string) {
-		out.ContextFailed(epicID, errMsg)
-	}
-	eng.OnContextActive = func(epicID string) {
-		out.ContextActive(epicID)
-	}
+	// Write engine events as headless output
+	eng.Subscribe(out.Handle)
 
 	// Output start
 	out.Start(epic, maxIterations, maxCost)
@@ -1876,29 +1980,39 @@ func runHeadless(epicID string, maxIterations int, maxCost float64, checkpointIn
 		DebounceInterval:  debounceInterval,
 	}
 
+	// Let the control API (`ticker attach`, --serve) follow and steer this run
+	var detach func(exitReason string)
+	if srv != nil {
+		runID := ""
+		if runLogger != nil {
+			runID = runLogger.RunID()
+			if l, err := server.Listen("unix:"+runLogger.SocketPath(), false); err != nil {
+				if !jsonl {
+					fmt.Fprintf(os.Stderr, "Warning: could not open control socket: %v\n", err)
+				}
+			} else {
+				go srv.Serve(l)
+				defer l.Close()
+			}
+		}
+		detach = srv.Attach(server.Run{RunID: runID, EpicID: epicID, EpicTitle: epic.Title, Engine: eng, Budget: budgetTracker}, &config)
+	}
+
 	result, err := eng.Run(ctx, config)
 
-	// Log run end
-	if runLogger != nil {
+	if detach != nil {
+		exitReason := ""
 		if result != nil {
-			signalStr := ""
-			if result.Signal != engine.SignalNone {
-				signalStr = result.Signal.String()
-			}
-			runLogger.LogRunEnd(runlog.RunEndData{
-				ExitReason:     result.ExitReason,
-				Iterations:     result.Iterations,
-				CompletedTasks: result.CompletedTasks,
-				TotalTokens:    result.TotalTokens,
-				TotalCost:      result.TotalCost,
-				Duration:       result.Duration,
-				Signal:         signalStr,
-				SignalReason:   result.SignalReason,
-			})
+			exitReason = result.ExitReason
+		} else if err != nil {
+			exitReason = err.Error()
 		}
-		runLogger.Close()
+		detach(exitReason)
 	}
 
+	// Log run end
+	endRunLog(runLogger, result)
+
 	if err != nil {
 		out.Error(err)
 		return ExitError
@@ -1926,6 +2040,8 @@ func runHeadless(epicID string, maxIterations int, maxCost float64, checkpointIn
 
 func runResume(cmd *cobra.Command, args []string) {
 	checkpointID := args[0]
+	agentName, _ := cmd.Flags().GetString("agent")
+	skipVerify, _ := cmd.Flags().GetBool("skip-verify")
 
 	// Load checkpoint
 	checkpointMgr := checkpoint.NewManager()
@@ -1964,9 +2080,14 @@ func runResume(cmd *cobra.Command, args []string) {
 	}()
 
 	// Initialize components
-	claudeAgent := agent.NewClaudeAgent()
-	if !claudeAgent.Available() {
-		fmt.Fprintln(os.Stderr, "Error: claude CLI not found. Please install Claude Code.")
+	agents, cliAgent, err := loadAgentRegistry(agentName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	verifyConfig, err := loadVerificationConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(ExitError)
 	}
 
@@ -1977,27 +2098,26 @@ func runResume(cmd *cobra.Command, args []string) {
 	})
 
 	// Create and configure engine
-	eng := engine.NewEngine(claudeAgent, ticksClient, budgetTracker, checkpointMgr)
-
-	// Set up context generation
-	contextStore := epiccontext.NewStore()
-	contextGenerator, err := epiccontext.NewGenerator(claudeAgent)
-	if err == nil {
-		eng.SetContextComponents(contextStore, contextGenerator)
-	}
-
-	eng.OnOutput = func(chunk string) {
-		fmt.Print(chunk)
-	}
-
-	eng.OnIterationStart = func(ctx engine.IterationContext) {
-		fmt.Printf("\n=== Iteration %d: [%s] %s ===\n", ctx.Iteration, ctx.Task.ID, ctx.Task.Title)
-	}
+	eng := engine.NewEngine(cliAgent, ticksClient, budgetTracker, checkpointMgr)
+	runLogger := configureEngine(eng, agents, cliAgent, engineOptions{
+		epicID:       cp.EpicID,
+		mode:         "resume",
+		headless:     true,
+		skipVerify:   skipVerify,
+		verification: verifyConfig,
+	})
 
-	eng.OnIterationEnd = func(result *engine.IterationResult) {
-		fmt.Printf("\n--- Iteration %d complete (tokens: %d, cost: $%.4f) ---\n",
-			result.Iteration, result.TokensIn+result.TokensOut, result.Cost)
-	}
+	eng.Subscribe(func(ev engine.Event) {
+		switch ev := ev.(type) {
+		case engine.OutputEvent:
+			fmt.Print(ev.Text)
+		case engine.IterationStartEvent:
+			fmt.Printf("\n=== Iteration %d: [%s] %s ===\n", ev.Context.Iteration, ev.Context.Task.ID, ev.Context.Task.Title)
+		case engine.IterationEndEvent:
+			fmt.Printf("\n--- Iteration %d complete (tokens: %d, cost: $%.4f) ---\n",
+				ev.Result.Iteration, ev.Result.TokensIn+ev.Result.TokensOut, ev.Result.Cost)
+		}
+	})
 
 	// Run with resume
 	config := engine.RunConfig{
@@ -2006,6 +2126,7 @@ func runResume(cmd *cobra.Command, args []string) {
 	}
 
 	result, err := eng.Run(ctx, config)
+	endRunLog(runLogger, result)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(ExitError)
@@ -2060,143 +2181,1144 @@ func runCheckpoints(cmd *cobra.Command, args []string) {
 	}
 }
 
-// autoSelectEpics uses tk to find up to max ready epics.
-// Returns epic IDs sorted by priority.
-func autoSelectEpics(max int) ([]string, error) {
-	ticksClient := ticks.NewClient()
-	epics, err := ticksClient.ListReadyEpics()
+func runInbox(cmd *cobra.Command, args []string) {
+	groups, err := inbox.List(ticks.NewClient())
 	if err != nil {
-		return nil, err
+		fmt.Fprintf(os.Stderr, "Error listing awaiting tasks: %v\n", err)
+		os.Exit(ExitError)
 	}
-	if len(epics) == 0 {
-		return nil, nil
+	printInbox(os.Stdout, groups, time.Now())
+}
+
+// printInbox writes the inbox groups as of now.
+func printInbox(w io.Writer, groups []inbox.Group, now time.Time) {
+	if len(groups) == 0 {
+		fmt.Fprintln(w, "Inbox is empty: no tasks are awaiting a human")
+		return
+	}
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Awaiting %s (%d)\n", g.Awaiting, len(g.Items))
+		for _, item := range g.Items {
+			task := item.Task
+			where := formatAge(now.Sub(task.UpdatedAt))
+			if task.Parent != "" {
+				where = "epic " + task.Parent + ", " + where
+			}
+			fmt.Fprintf(w, "  %-8s %s (%s)\n", task.ID, task.Title, where)
+			if item.Reason != "" {
+				fmt.Fprintf(w, "  %-8s %s\n", "", oneLine(item.Reason, 100))
+			}
+			if item.LastNote != "" {
+				fmt.Fprintf(w, "  %-8s Last note: %s\n", "", oneLine(item.LastNote, 89))
+			}
+		}
 	}
+}
 
-	// Take up to max epics
-	count := len(epics)
-	if count > max {
-		count = max
+// formatAge formats how long ago something happened, in its largest unit.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
 	}
+}
 
-	ids := make([]string, count)
-	for i := 0; i < count; i++ {
-		ids[i] = epics[i].ID
+// oneLine returns the first line of s, cut to max runes.
+func oneLine(s string, max int) string {
+	line, _, more := strings.Cut(strings.TrimSpace(s), "\n")
+	runes := []rune(line)
+	if len(runes) > max {
+		return string(runes[:max-3]) + "..."
 	}
-	return ids, nil
+	if more {
+		return line + " ..."
+	}
+	return line
 }
 
-// WorkItem represents the next piece of work to run - either an epic or a standalone task.
-type WorkItem struct {
-	// Epic fields (mutually exclusive with Task)
+func runInboxApprove(cmd *cobra.Command, args []string) {
+	result, err := inbox.Approve(ticks.NewClient(), args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	printVerdictOutcome(args[0], "Approved", result)
+}
+
+func runInboxReject(cmd *cobra.Command, args []string) {
+	client := ticks.NewClient()
+	feedback := optionalArg(args, 1)
+	if feedback == "" {
+		showReason(client, args[0])
+		feedback = prompt("Feedback for the agent: ")
+	}
+	result, err := inbox.Reject(client, args[0], feedback)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	printVerdictOutcome(args[0], "Rejected", result)
+}
+
+func runInboxAnswer(cmd *cobra.Command, args []string) {
+	client := ticks.NewClient()
+	answer := optionalArg(args, 1)
+	if answer == "" {
+		showReason(client, args[0])
+		answer = prompt("Answer: ")
+	}
+	result, err := inbox.Answer(client, args[0], answer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	printVerdictOutcome(args[0], "Answered", result)
+}
+
+func runInboxTake(cmd *cobra.Command, args []string) {
+	if err := inbox.TakeOver(ticks.NewClient(), args[0], optionalArg(args, 1)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	fmt.Printf("Took over %s: it is awaiting work and agents will skip it\n", args[0])
+}
+
+// printVerdictOutcome reports where a task went after a response.
+func printVerdictOutcome(taskID, action string, result ticks.VerdictResult) {
+	if result.ShouldClose {
+		fmt.Printf("%s %s: task closed\n", action, taskID)
+	} else {
+		fmt.Printf("%s %s: back to the agent\n", action, taskID)
+	}
+}
+
+// showReason prints the task and the note the agent handed it off with,
+// as context for a prompt.
+func showReason(client *ticks.Client, taskID string) {
+	task, err := client.GetTask(taskID)
+	if err != nil {
+		return
+	}
+	fmt.Printf("[%s] %s (awaiting %s)\n", task.ID, task.Title, task.GetAwaitingType())
+	if notes, err := client.GetAgentNotes(taskID); err == nil && len(notes) > 0 {
+		fmt.Printf("\n%s\n\n", notes[len(notes)-1].Content)
+	}
+}
+
+// prompt asks for a line of input on stdin.
+func prompt(label string) string {
+	fmt.Print(label)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+// optionalArg returns args[i], or "" if there are fewer args.
+func optionalArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
+
+func runRevert(cmd *cobra.Command, args []string) {
+	force, _ := cmd.Flags().GetBool("force")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	taskID := args[0]
+
+	client := ticks.NewClient()
+	task, err := client.GetTask(taskID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	dir, err := revertDir(task)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+
+	plan, err := revert.NewPlan(client, dir, taskID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	printRevertPlan(os.Stdout, plan)
+
+	if len(plan.Dependents) > 0 && !force && !dryRun {
+		fmt.Fprintln(os.Stderr, "\nLater commits touch the same files and may depend on this work.")
+		fmt.Fprintln(os.Stderr, "Revert those tasks first, or use --force to revert anyway.")
+		os.Exit(ExitError)
+	}
+	if dryRun {
+		return
+	}
+
+	commit, err := revert.Revert(client, plan)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+	fmt.Printf("\nReverted %s in %s; the task is open again\n", taskID, gitutil.ShortSHA(commit))
+}
+
+// revertDir returns where a task's commits are: its epic's worktree if it
+// has one, otherwise the current directory.
+func revertDir(task *ticks.Task) (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if task.Parent == "" {
+		return dir, nil
+	}
+	wtManager, err := worktree.NewManager(dir)
+	if err != nil {
+		return dir, nil // not the repo root; revert in place
+	}
+	if wt, err := wtManager.Get(task.Parent); err == nil && wt != nil {
+		return wt.Path, nil
+	}
+	return dir, nil
+}
+
+// printRevertPlan describes what reverting a task will do.
+func printRevertPlan(w io.Writer, plan *revert.Plan) {
+	fmt.Fprintf(w, "Task [%s] %s\n", plan.Task.ID, plan.Task.Title)
+	fmt.Fprintf(w, "  Directory: %s\n", plan.Dir)
+	commits := make([]string, len(plan.Commits))
+	for i, c := range plan.Commits {
+		commits[i] = gitutil.ShortSHA(c)
+	}
+	fmt.Fprintf(w, "  Commits:   %s\n", strings.Join(commits, " "))
+	fmt.Fprintf(w, "  Files:     %s\n", strings.Join(plan.Files, ", "))
+
+	if len(plan.Dependents) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nLater commits touching the same files (%d):\n", len(plan.Dependents))
+	for _, d := range plan.Dependents {
+		by := ""
+		if d.TaskID != "" {
+			by = fmt.Sprintf(" (task %s)", d.TaskID)
+		}
+		fmt.Fprintf(w, "  %s %s%s\n", gitutil.ShortSHA(d.Commit), d.Subject, by)
+		fmt.Fprintf(w, "          %s\n", strings.Join(d.Files, ", "))
+	}
+}
+
+func runReplay(cmd *cobra.Command, args []string) {
+	iteration, _ := cmd.Flags().GetInt("iteration")
+	speed, _ := cmd.Flags().GetFloat64("speed")
+	headless, _ := cmd.Flags().GetBool("headless")
+
+	if speed < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --speed must not be negative")
+		os.Exit(ExitError)
+	}
+
+	runsDir := filepath.Join(".ticker", "runs")
+	plan, err := loadReplay(runsDir, args[0], iteration)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitError)
+	}
+
+	if headless {
+		if err := replayHeadless(context.Background(), os.Stdout, runsDir, plan, speed); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(ExitError)
+		}
+		return
+	}
+	replayWithTUI(runsDir, plan, speed)
+}
+
+// replayIteration is one recorded iteration to replay.
+type replayIteration struct {
+	Iteration int
+	TaskID    string
+	TaskTitle string
+}
+
+// replayPlan describes what `ticker replay` plays back for a run.
+type replayPlan struct {
+	RunID      string
+	EpicID     string
+	Iterations []replayIteration
+	Tasks      []tui.TaskInfo // tasks of the replayed iterations, in order
+}
+
+// loadReplay finds the recorded transcripts of runID in runsDir and pairs
+// them with the tasks from the run log. iteration > 0 limits the plan to
+// that iteration.
+func loadReplay(runsDir, runID string, iteration int) (*replayPlan, error) {
+	recorded, err := runlog.ListTranscripts(runsDir, runID)
+	if err != nil {
+		return nil, fmt.Errorf("listing transcripts: %w", err)
+	}
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no transcripts recorded for run %s (enable agent.record_transcripts in .ticker/config.json)", runID)
+	}
+
+	// The run log is optional: without it iterations are replayed untitled
+	events, err := runlog.ReadEvents(filepath.Join(runsDir, runID+".jsonl"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading run log: %w", err)
+	}
+
+	plan := &replayPlan{RunID: runID}
+	starts := make(map[int]runlog.IterationStartData)
+	completed := make(map[string]bool)
+	for _, ev := range events {
+		switch ev.Type {
+		case runlog.EventRunStart:
+			var data runlog.RunStartData
+			if json.Unmarshal(ev.Data, &data) == nil {
+				plan.EpicID = data.EpicID
+			}
+		case runlog.EventIterationStart:
+			var data runlog.IterationStartData
+			if json.Unmarshal(ev.Data, &data) == nil {
+				starts[data.Iteration] = data
+			}
+		case runlog.EventTaskCompleted:
+			var data runlog.TaskCompletedData
+			if json.Unmarshal(ev.Data, &data) == nil {
+				completed[data.TaskID] = true
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, n := range recorded {
+		if iteration > 0 && n != iteration {
+			continue
+		}
+		start := starts[n]
+		plan.Iterations = append(plan.Iterations, replayIteration{Iteration: n, TaskID: start.TaskID, TaskTitle: start.TaskTitle})
+		if start.TaskID == "" || seen[start.TaskID] {
+			continue
+		}
+		seen[start.TaskID] = true
+		status := tui.TaskStatusOpen
+		if completed[start.TaskID] {
+			status = tui.TaskStatusClosed
+		}
+		plan.Tasks = append(plan.Tasks, tui.TaskInfo{ID: start.TaskID, Title: start.TaskTitle, Status: status})
+	}
+	if len(plan.Iterations) == 0 {
+		return nil, fmt.Errorf("no transcript recorded for iteration %d of run %s (recorded: %v)", iteration, runID, recorded)
+	}
+	return plan, nil
+}
+
+// replayHeadless prints the output of each iteration in plan to w as it is
+// replayed.
+func replayHeadless(ctx context.Context, w io.Writer, runsDir string, plan *replayPlan, speed float64) error {
+	for _, it := range plan.Iterations {
+		if it.TaskID != "" {
+			fmt.Fprintf(w, "=== Iteration %d: [%s] %s ===\n", it.Iteration, it.TaskID, it.TaskTitle)
+		} else {
+			fmt.Fprintf(w, "=== Iteration %d ===\n", it.Iteration)
+		}
+
+		r, err := runlog.OpenTranscript(runsDir, plan.RunID, it.Iteration)
+		if err != nil {
+			return err
+		}
+		var printed int
+		snap, err := agent.ReplayTranscript(ctx, r, speed, func(snap agent.AgentStateSnapshot) {
+			if len(snap.Output) > printed {
+				fmt.Fprint(w, snap.Output[printed:])
+				printed = len(snap.Output)
+			}
+		})
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("iteration %d: %w", it.Iteration, err)
+		}
+
+		fmt.Fprintf(w, "\n--- %d tools, %d tokens, $%.4f ---\n\n",
+			len(snap.ToolHistory), snap.Metrics.InputTokens+snap.Metrics.OutputTokens, snap.Metrics.CostUSD)
+	}
+	return nil
+}
+
+// replayWithTUI plays plan back in the TUI until the user quits.
+func replayWithTUI(runsDir string, plan *replayPlan, speed float64) {
+	m := tui.New(tui.Config{
+		EpicID:    plan.EpicID,
+		EpicTitle: "Replay of run " + plan.RunID,
+	})
+	p := tea.NewProgram(m, tea.WithAltScreen())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	forwardAgentState, resetAgentState := newAgentStateForwarder(p.Send)
+
+	go func() {
+		p.Send(tui.TasksUpdateMsg{Tasks: plan.Tasks})
+
+		var cost float64
+		for _, it := range plan.Iterations {
+			resetAgentState()
+			p.Send(tui.IterationStartMsg{Iteration: it.Iteration, TaskID: it.TaskID, TaskTitle: it.TaskTitle})
+
+			r, err := runlog.OpenTranscript(runsDir, plan.RunID, it.Iteration)
+			if err != nil {
+				p.Send(tui.ErrorMsg{Err: err})
+				continue
+			}
+			snap, err := agent.ReplayTranscript(ctx, r, speed, forwardAgentState)
+			r.Close()
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				p.Send(tui.ErrorMsg{Err: fmt.Errorf("iteration %d: %w", it.Iteration, err)})
+			}
+
+			cost += snap.Metrics.CostUSD
+			p.Send(tui.IterationEndMsg{
+				Iteration: it.Iteration,
+				Cost:      snap.Metrics.CostUSD,
+				Tokens:    snap.Metrics.InputTokens + snap.Metrics.OutputTokens,
+			})
+		}
+		p.Send(tui.RunCompleteMsg{Reason: "Replay finished", Iterations: len(plan.Iterations), Cost: cost})
+	}()
+
+	if _, err := p.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
+		os.Exit(ExitError)
+	}
+}
+
+// runAttach implements the 'ticker attach' command: it connects the TUI to a
+// running headless run, by run ID, --addr, or the only run with a socket.
+func runAttach(cmd *cobra.Command, args []string) {
+	addr, _ := cmd.Flags().GetString("addr")
+	readOnly, _ := cmd.Flags().GetBool("read-only")
+
+	if addr != "" && len(args) > 0 {
+		fmt.Fprintln(os.Stderr, "Error: pass either a run ID or --addr, not both")
+		os.Exit(ExitError)
+	}
+	if addr == "" {
+		runID := ""
+		if len(args) > 0 {
+			runID = args[0]
+		}
+		var err error
+		addr, err = attachAddr(filepath.Join(".ticker", "runs"), runID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(ExitError)
+		}
+	}
+
+	client := server.NewClient(addr)
+	status, err := client.Status(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: could not connect to %s: %v\n", addr, err)
+		os.Exit(ExitError)
+	}
+	attachWithTUI(client, status, readOnly)
+}
+
+// attachAddr returns the control socket address of runID in runsDir, or of
+// the only run with a socket if runID is empty.
+func attachAddr(runsDir, runID string) (string, error) {
+	if runID != "" {
+		path := runlog.SocketPath(runsDir, runID)
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("run %s has no control socket (only running headless runs can be attached to)", runID)
+		}
+		return "unix:" + path, nil
+	}
+
+	runIDs, err := runlog.ListSockets(runsDir)
+	if err != nil {
+		return "", fmt.Errorf("listing runs: %w", err)
+	}
+	switch len(runIDs) {
+	case 0:
+		return "", fmt.Errorf("no running headless run found in %s", runsDir)
+	case 1:
+		return "unix:" + runlog.SocketPath(runsDir, runIDs[0]), nil
+	default:
+		return "", fmt.Errorf("several runs to choose from, pass a run ID: %s", strings.Join(runIDs, ", "))
+	}
+}
+
+// attachWithTUI shows the TUI for a run served by client. Pausing goes to
+// the run and quitting stops it after the current iteration, unless
+// readOnly.
+func attachWithTUI(client *server.Client, status server.Status, readOnly bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := tui.Config{
+		EpicID:    status.EpicID,
+		EpicTitle: status.EpicTitle,
+		Diff:      commitDiff,
+	}
+	if status.Budget != nil {
+		cfg.MaxCost = status.Budget.MaxCost
+		cfg.MaxIteration = status.Budget.MaxIterations
+	}
+	var pauseChan chan bool
+	if !readOnly {
+		pauseChan = make(chan bool, 1)
+		cfg.PauseChan = pauseChan
+	}
+	p := tea.NewProgram(tui.New(cfg), tea.WithAltScreen())
+
+	// Forward pause toggles to the run
+	if pauseChan != nil {
+		go func() {
+			for paused := range pauseChan {
+				if err := client.SetPaused(ctx, paused); err != nil {
+					p.Send(tui.GlobalStatusMsg{Message: fmt.Sprintf("Pause failed: %v", err)})
+				}
+			}
+		}()
+	}
+
+	// Task list comes from the run's epic; run records from the local ticks
+	ticksClient := ticks.NewClient()
+	refreshTasks := func() {
+		tasks, err := client.Tasks(ctx)
+		if err != nil {
+			return
+		}
+		p.Send(tui.TasksUpdateMsg{Tasks: taskInfos(tasks)})
+		for _, t := range tasks {
+			if t.Status == "closed" {
+				if record, err := ticksClient.GetRunRecord(t.ID); err == nil && record != nil {
+					p.Send(tui.TaskRunRecordMsg{TaskID: t.ID, RunRecord: record})
+				}
+			}
+		}
+	}
+
+	go func() {
+		handle := attachMessages(p.Send, func() { go refreshTasks() })
+		if err := client.Events(ctx, handle); err != nil && ctx.Err() == nil {
+			p.Send(tui.GlobalStatusMsg{Message: fmt.Sprintf("Disconnected: %v", err)})
+		}
+	}()
+
+	if _, err := p.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
+		os.Exit(ExitError)
+	}
+	cancel()
+
+	if readOnly {
+		return
+	}
+	status, err := client.Status(context.Background())
+	if err != nil || status.State == server.StateFinished {
+		return
+	}
+	if err := client.Stop(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not stop the run: %v\n", err)
+		return
+	}
+	fmt.Println("Run will stop after the current iteration.")
+}
+
+// attachMessages returns a handler that translates a run's event stream
+// into TUI messages for send. refreshTasks is called when the task list
+// may have changed.
+func attachMessages(send func(tea.Msg), refreshTasks func()) func(server.Message) {
+	return func(msg server.Message) {
+		switch msg.Type {
+		case "snapshot":
+			var d server.SnapshotData
+			if json.Unmarshal(msg.Data, &d) != nil {
+				return
+			}
+			st := d.Status
+			// Seed the totals of the iterations already done
+			if st.Budget != nil && (st.Budget.Cost > 0 || st.Budget.TokensIn+st.Budget.TokensOut > 0) {
+				send(tui.IterationEndMsg{Cost: st.Budget.Cost, Tokens: st.Budget.TokensIn + st.Budget.TokensOut})
+			}
+			if st.CurrentTask != nil {
+				send(tui.IterationStartMsg{Iteration: st.Iteration, TaskID: st.CurrentTask.ID, TaskTitle: st.CurrentTask.Title})
+			}
+			if d.Output != "" {
+				send(tui.AgentTextMsg{Text: d.Output})
+			}
+			if d.Agent != nil {
+				sendAgentState(send, *d.Agent)
+			}
+			// A requested pause shows as paused, like pressing p locally
+			if st.State == server.StatePaused || st.PauseRequested {
+				send(tui.PausedMsg{Paused: true})
+			}
+			switch st.State {
+			case server.StateIdle:
+				send(tui.IdleMsg{})
+			case server.StateFinished:
+				send(tui.RunCompleteMsg{Reason: st.ExitReason, Iterations: st.Iteration})
+			}
+			refreshTasks()
+
+		case "iteration_start":
+			var d server.IterationStartData
+			if json.Unmarshal(msg.Data, &d) == nil {
+				send(tui.IterationStartMsg{Iteration: d.Iteration, TaskID: d.TaskID, TaskTitle: d.TaskTitle})
+				refreshTasks()
+			}
+
+		case "iteration_end":
+			var d server.IterationEndData
+			if json.Unmarshal(msg.Data, &d) == nil {
+				send(tui.IterationEndMsg{Iteration: d.Iteration, Cost: d.Cost, Tokens: d.TokensIn + d.TokensOut})
+				refreshTasks()
+			}
+
+		case "output":
+			var d server.OutputData
+			if json.Unmarshal(msg.Data, &d) == nil && d.Text != "" {
+				send(tui.AgentTextMsg{Text: d.Text})
+			}
+
+		case "agent_state":
+			var d server.AgentStateData
+			if json.Unmarshal(msg.Data, &d) == nil {
+				sendAgentState(send, d)
+			}
+
+		case "signal":
+			var d server.SignalData
+			if json.Unmarshal(msg.Data, &d) == nil {
+				send(tui.SignalMsg{Signal: d.Signal, Reason: d.Reason})
+			}
+
+		case "verification_start":
+			var d server.VerificationData
+			if json.Unmarshal(msg.Data, &d) == nil {
+				send(tui.VerifyStartMsg{TaskID: d.TaskID})
+			}
+
+		case "verification_end":
+			var d server.VerificationData
+			if json.Unmarshal(msg.Data, &d) == nil {
+				send(tui.VerifyResultMsg{TaskID: d.TaskID, Passed: d.Passed, Summary: d.Summary})
+				refreshTasks()
+			}
+
+		case "context_generating", "context_generated", "context_loaded", "context_skipped", "context_failed":
+			var d server.ContextData
+			if json.Unmarshal(msg.Data, &d) != nil {
+				return
+			}
+			switch msg.Type {
+			case "context_generating":
+				send(tui.ContextGeneratingMsg{EpicID: msg.EpicID, TaskCount: d.TaskCount})
+			case "context_generated":
+				send(tui.ContextGeneratedMsg{EpicID: msg.EpicID, Tokens: d.Tokens})
+			case "context_loaded":
+				send(tui.ContextLoadedMsg{EpicID: msg.EpicID})
+			case "context_skipped":
+				send(tui.ContextSkippedMsg{EpicID: msg.EpicID, Reason: d.Reason})
+			case "context_failed":
+				send(tui.ContextFailedMsg{EpicID: msg.EpicID, Error: d.Error})
+			}
+
+		case "idle":
+			send(tui.IdleMsg{})
+
+		case "pause":
+			var d server.PauseData
+			if json.Unmarshal(msg.Data, &d) == nil {
+				send(tui.PausedMsg{Paused: d.Paused})
+			}
+
+		case "run_end":
+			var d server.RunData
+			if json.Unmarshal(msg.Data, &d) == nil {
+				send(tui.RunCompleteMsg{Reason: d.ExitReason})
+			}
+		}
+	}
+}
+
+// sendAgentState sends the TUI messages for a change in agent state.
+func sendAgentState(send func(tea.Msg), d server.AgentStateData) {
+	if d.Thinking != "" {
+		send(tui.AgentThinkingMsg{Text: d.Thinking})
+	}
+	if d.ToolEnd != nil {
+		send(tui.AgentToolEndMsg{
+			ID:       d.ToolEnd.ID,
+			Name:     d.ToolEnd.Name,
+			Duration: time.Duration(d.ToolEnd.DurationMS) * time.Millisecond,
+			IsError:  d.ToolEnd.IsError,
+		})
+	}
+	if d.ToolStart != nil {
+		send(tui.AgentToolStartMsg{ID: d.ToolStart.ID, Name: d.ToolStart.Name})
+	}
+	send(tui.AgentMetricsMsg{
+		InputTokens:         d.Metrics.InputTokens,
+		OutputTokens:        d.Metrics.OutputTokens,
+		CacheReadTokens:     d.Metrics.CacheReadTokens,
+		CacheCreationTokens: d.Metrics.CacheCreationTokens,
+		CostUSD:             d.Metrics.CostUSD,
+		Model:               d.Model,
+	})
+	if d.Status != "" {
+		send(tui.AgentStatusMsg{Status: agent.RunStatus(d.Status), Error: d.Error})
+	}
+}
+
+// autoSelectEpics uses tk to find up to max ready epics.
+// Returns epic IDs sorted by priority.
+func autoSelectEpics(max int) ([]string, error) {
+	ticksClient := ticks.NewClient()
+	epics, err := ticksClient.ListReadyEpics()
+	if err != nil {
+		return nil, err
+	}
+	if len(epics) == 0 {
+		return nil, nil
+	}
+
+	// Take up to max epics
+	count := len(epics)
+	if count > max {
+		count = max
+	}
+
+	ids := make([]string, count)
+	for i := 0; i < count; i++ {
+		ids[i] = epics[i].ID
+	}
+	return ids, nil
+}
+
+// WorkItem represents the next piece of work to run - either an epic or a standalone task.
+type WorkItem struct {
+	// Epic fields (mutually exclusive with Task)
 	EpicID    string
 	EpicTitle string
 
 	// Task fields (for standalone/orphan tasks)
 	Task *ticks.Task
 
-	// IsStandalone is true when this is a standalone/orphan task (not an epic)
-	IsStandalone bool
+	// IsStandalone is true when this is a standalone/orphan task (not an epic)
+	IsStandalone bool
+}
+
+// findNextWork finds the next available work item (epic or standalone task).
+// Priority: epics first, then standalone tasks, then orphan tasks.
+func findNextWork(client *ticks.Client, includeStandalone, includeOrphans bool) *WorkItem {
+	// Try to find an epic first
+	epicIDs, _ := autoSelectEpics(1)
+	if len(epicIDs) > 0 {
+		epic, err := client.GetEpic(epicIDs[0])
+		if err == nil {
+			return &WorkItem{
+				EpicID:       epic.ID,
+				EpicTitle:    epic.Title,
+				IsStandalone: false,
+			}
+		}
+	}
+
+	// Try standalone tasks
+	if includeStandalone {
+		task, _ := client.NextTaskWithOptions(ticks.StandaloneOnly())
+		if task != nil {
+			return &WorkItem{
+				Task:         task,
+				IsStandalone: true,
+			}
+		}
+	}
+
+	// Try orphaned tasks
+	if includeOrphans {
+		task, _ := client.NextTaskWithOptions(ticks.OrphanedOnly())
+		if task != nil {
+			return &WorkItem{
+				Task:         task,
+				IsStandalone: true,
+			}
+		}
+	}
+
+	return nil
+}
+
+// runPicker shows the interactive epic picker and returns the selected epic
+func runPicker() *tui.EpicInfo {
+	ticksClient := ticks.NewClient()
+
+	// Get ready epics
+	epics, err := ticksClient.ListReadyEpics()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing epics: %v\n", err)
+		os.Exit(ExitError)
+	}
+
+	if len(epics) == 0 {
+		fmt.Fprintln(os.Stderr, "No ready epics found")
+		os.Exit(0)
+	}
+
+	// Convert to EpicInfo with task counts
+	epicInfos := make([]tui.EpicInfo, len(epics))
+	for i, e := range epics {
+		tasks, _ := ticksClient.ListTasks(e.ID)
+		epicInfos[i] = tui.EpicInfo{
+			ID:       e.ID,
+			Title:    e.Title,
+			Priority: e.Priority,
+			Tasks:    len(tasks),
+		}
+	}
+
+	// Run picker
+	p := tui.NewPicker(epicInfos)
+	program := tea.NewProgram(p, tea.WithAltScreen())
+
+	model, err := program.Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running picker: %v\n", err)
+		os.Exit(ExitError)
+	}
+
+	picker := model.(tui.Picker)
+	return picker.Selected()
+}
+
+// loadVerificationConfig loads verification settings (including command
+// verifiers) from .ticker/config.json.
+// Returns nil (defaults: git check only) if the config is missing, and an
+// error if it is invalid, which stops the run rather than skipping checks.
+func loadVerificationConfig() (*verify.Config, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := verify.LoadConfig(dir)
+	if err != nil {
+		return nil, fmt.Errorf("loading verification config: %w", err)
+	}
+	return config, nil
+}
+
+// loadEscalationConfig loads the escalation ladder from .ticker/config.json.
+// Returns nil (no escalation) if the config is missing, and an error if it
+// is invalid.
+func loadEscalationConfig() (*verify.EscalationConfig, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := verify.LoadEscalationConfig(dir)
+	if err != nil {
+		return nil, fmt.Errorf("loading escalation config: %w", err)
+	}
+	return config, nil
+}
+
+// loadContextConfig loads context generation settings from .ticker/config.json.
+// Returns nil (defaults) if the config is missing or invalid.
+func loadContextConfig() *verify.ContextConfig {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+
+	config, err := verify.LoadContextConfig(dir)
+	if err != nil {
+		return nil
+	}
+	return config
+}
+
+// contextGeneratorOptions returns generator options for the configured model,
+// timeout and size. quiet discards generator logs (jsonl mode).
+func contextGeneratorOptions(cfg *verify.ContextConfig, quiet bool) []epiccontext.GeneratorOption {
+	opts := []epiccontext.GeneratorOption{
+		epiccontext.WithModel(cfg.GetGenerationModel()),
+		epiccontext.WithUpdateModel(cfg.GetUpdateModel()),
+		epiccontext.WithTimeout(cfg.GetGenerationTimeout()),
+		epiccontext.WithMaxTokens(cfg.GetMaxTokens()),
+	}
+	if dir, err := os.Getwd(); err == nil {
+		text, err := epiccontext.LoadPromptTemplate(dir)
+		if err != nil && !quiet {
+			fmt.Fprintf(os.Stderr, "Warning: using built-in context prompt template: %v\n", err)
+		}
+		opts = append(opts, epiccontext.WithPromptTemplate(text))
+	}
+	if quiet {
+		opts = append(opts, epiccontext.WithLogger(slog.New(slog.DiscardHandler)))
+	}
+	return opts
+}
+
+// contextRefreshPolicy returns when stored context is regenerated.
+func contextRefreshPolicy(cfg *verify.ContextConfig) epiccontext.RefreshPolicy {
+	return epiccontext.RefreshPolicy{
+		MaxAge:     time.Duration(cfg.GetAutoRefreshDays()) * 24 * time.Hour,
+		MaxCommits: cfg.GetRefreshAfterCommits(),
+	}
+}
+
+// engineOptions are the per-mode settings for configureEngine.
+type engineOptions struct {
+	// epicID, mode and headless are recorded in the run log. No run log is
+	// created if mode is empty.
+	epicID   string
+	mode     string
+	headless bool
+
+	// skipVerify leaves verification off (--skip-verify)
+	skipVerify bool
+
+	// verification is the run's verification config (see
+	// loadVerificationConfig); nil applies the defaults
+	verification *verify.Config
+
+	// quiet suppresses warnings (--jsonl, or while a TUI is running)
+	quiet bool
+
+	// prompts is a prompt builder shared by several engines; loaded if nil
+	prompts *engine.PromptBuilder
+}
+
+// configureEngine applies .ticker/config.json to a new engine. Every run mode
+// uses it, so they all get the same agent selection, escalation, prompts,
+// notes compaction, session continuation, epic context, verification and
+// run log with transcripts. Returns the run log (nil if none), which the
+// caller ends with endRunLog.
+func configureEngine(eng *engine.Engine, agents *agent.Registry, cliAgent agent.Agent, opts engineOptions) *runlog.Logger {
+	eng.SetAgentRegistry(agents)
+
+	// Continue without escalation if its config is invalid
+	escalation, err := loadEscalationConfig()
+	if err != nil && !opts.quiet {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	eng.SetEscalation(escalation)
+
+	prompts := opts.prompts
+	if prompts == nil {
+		prompts = loadPromptBuilder(opts.quiet)
+	}
+	eng.SetPromptBuilder(prompts)
+	setupNotesCompaction(eng)
+	if isSessionContinuationEnabled() {
+		eng.EnableSessionContinuation()
+	}
+
+	// Context generation logs to a discard logger when quiet
+	if err := setupEpicContext(eng, cliAgent, opts.quiet); err != nil && !opts.quiet {
+		fmt.Fprintf(os.Stderr, "Warning: could not create context generator: %v\n", err)
+	}
+
+	// Set up verification runner (unless --skip-verify)
+	if !opts.skipVerify && opts.verification.IsEnabled() {
+		eng.EnableVerification()
+		eng.SetVerificationConfig(opts.verification)
+	}
+
+	if opts.mode == "" {
+		return nil
+	}
+	return startRunLog(eng, opts.epicID, opts.mode, opts.headless, opts.quiet)
 }
 
-// findNextWork finds the next available work item (epic or standalone task).
-// Priority: epics first, then standalone tasks, then orphan tasks.
-func findNextWork(client *ticks.Client, includeStandalone, includeOrphans bool) *WorkItem {
-	// Try to find an epic first
-	epicIDs, _ := autoSelectEpics(1)
-	if len(epicIDs) > 0 {
-		epic, err := client.GetEpic(epicIDs[0])
-		if err == nil {
-			return &WorkItem{
-				EpicID:       epic.ID,
-				EpicTitle:    epic.Title,
-				IsStandalone: false,
-			}
+// startRunLog starts a new run log for eng, which records the engine's
+// events (and transcripts, if enabled) from then on. Returns nil if the log
+// can't be created; the run continues without one.
+func startRunLog(eng *engine.Engine, epicID, mode string, headless, quiet bool) *runlog.Logger {
+	runLogger, err := runlog.New(epicID)
+	if err != nil {
+		// Continue without run logging
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "Warning: could not create run log: %v\n", err)
 		}
+		eng.SetRunLog(nil)
+		return nil
 	}
+	eng.SetRunLog(runLogger)
+	runLogger.LogRunStart(mode, headless)
+	if isTranscriptRecordingEnabled() {
+		eng.EnableTranscripts()
+	}
+	return runLogger
+}
 
-	// Try standalone tasks
-	if includeStandalone {
-		task, _ := client.NextTaskWithOptions(ticks.StandaloneOnly())
-		if task != nil {
-			return &WorkItem{
-				Task:         task,
-				IsStandalone: true,
-			}
+// endRunLog logs the end of the run from result (if any) and closes l.
+// Does nothing if l is nil.
+func endRunLog(l *runlog.Logger, result *engine.RunResult) {
+	if l == nil {
+		return
+	}
+	if result != nil {
+		signalStr := ""
+		if result.Signal != engine.SignalNone {
+			signalStr = result.Signal.String()
 		}
+		l.LogRunEnd(runlog.RunEndData{
+			ExitReason:     result.ExitReason,
+			Iterations:     result.Iterations,
+			CompletedTasks: result.CompletedTasks,
+			TotalTokens:    result.TotalTokens,
+			TotalCost:      result.TotalCost,
+			Duration:       result.Duration,
+			Signal:         signalStr,
+			SignalReason:   result.SignalReason,
+		})
 	}
+	l.Close()
+}
 
-	// Try orphaned tasks
-	if includeOrphans {
-		task, _ := client.NextTaskWithOptions(ticks.OrphanedOnly())
-		if task != nil {
-			return &WorkItem{
-				Task:         task,
-				IsStandalone: true,
+// parallelRunLogs keeps the run log of each epic in a parallel run.
+type parallelRunLogs struct {
+	mu   sync.Mutex
+	logs map[string]*runlog.Logger
+}
+
+// add records the run log of epicID (nil is ignored).
+func (r *parallelRunLogs) add(epicID string, l *runlog.Logger) {
+	if l == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.logs == nil {
+		r.logs = make(map[string]*runlog.Logger)
+	}
+	r.logs[epicID] = l
+}
+
+// endAll ends every epic's run log with its result from result (which may
+// be nil if the run failed).
+func (r *parallelRunLogs) endAll(result *parallel.ParallelResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for epicID, l := range r.logs {
+		var epicResult *engine.RunResult
+		if result != nil {
+			if status := result.Statuses[epicID]; status != nil {
+				epicResult = status.Result
 			}
 		}
+		endRunLog(l, epicResult)
 	}
+	r.logs = nil
+}
 
+// setupEpicContext enables epic context generation on eng using a, with
+// settings from .ticker/config.json. Does nothing if context is disabled.
+func setupEpicContext(eng *engine.Engine, a agent.Agent, quiet bool) error {
+	cfg := loadContextConfig()
+	if !cfg.IsEnabled() {
+		return nil
+	}
+	generator, err := epiccontext.NewGenerator(a, contextGeneratorOptions(cfg, quiet)...)
+	if err != nil {
+		return err
+	}
+	eng.SetContextComponents(epiccontext.NewStore(), generator)
+	eng.SetContextRefresh(contextRefreshPolicy(cfg))
+	if cfg.IsUpdateAfterTask() {
+		eng.EnableContextUpdates()
+	}
+	if cfg.IsRepoMapEnabled() {
+		eng.EnableRepoMap(epiccontext.RefreshPolicy{MaxCommits: cfg.GetRepoMapRefreshCommits()})
+	}
 	return nil
 }
 
-// runPicker shows the interactive epic picker and returns the selected epic
-func runPicker() *tui.EpicInfo {
-	ticksClient := ticks.NewClient()
-
-	// Get ready epics
-	epics, err := ticksClient.ListReadyEpics()
+// loadPromptBuilder loads the iteration prompt templates from .ticker/prompts
+// with template variables from .ticker/config.json. Invalid templates fall
+// back to the built-in ones, with a warning on stderr unless quiet.
+func loadPromptBuilder(quiet bool) *engine.PromptBuilder {
+	dir, err := os.Getwd()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error listing epics: %v\n", err)
-		os.Exit(ExitError)
+		return engine.NewPromptBuilder()
 	}
 
-	if len(epics) == 0 {
-		fmt.Fprintln(os.Stderr, "No ready epics found")
-		os.Exit(0)
+	pb, err := engine.LoadPromptBuilder(dir)
+	if err != nil && !quiet {
+		fmt.Fprintf(os.Stderr, "Warning: using built-in prompt templates: %v\n", err)
+	}
+	cfg, err := verify.LoadPromptsConfig(dir)
+	if err != nil && !quiet {
+		fmt.Fprintf(os.Stderr, "Warning: error loading prompts config: %v\n", err)
 	}
+	pb.SetVars(cfg.GetVars())
+	pb.SetTokenBudget(cfg.GetMaxTokens())
+	return pb
+}
 
-	// Convert to EpicInfo with task counts
-	epicInfos := make([]tui.EpicInfo, len(epics))
-	for i, e := range epics {
-		tasks, _ := ticksClient.ListTasks(e.ID)
-		epicInfos[i] = tui.EpicInfo{
-			ID:       e.ID,
-			Title:    e.Title,
-			Priority: e.Priority,
-			Tasks:    len(tasks),
-		}
+// setupNotesCompaction enables epic notes compaction on eng if
+// prompts.compact_notes_tokens is set in .ticker/config.json.
+func setupNotesCompaction(eng *engine.Engine) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return
 	}
 
-	// Run picker
-	p := tui.NewPicker(epicInfos)
-	program := tea.NewProgram(p, tea.WithAltScreen())
+	// Config errors are reported when the prompt templates are loaded
+	cfg, _ := verify.LoadPromptsConfig(dir)
+	if threshold := cfg.GetCompactNotesTokens(); threshold > 0 {
+		eng.EnableNotesCompaction(threshold, cfg.GetCompactionModel())
+	}
+}
 
-	model, err := program.Run()
+// isSessionContinuationEnabled checks whether agent.continue_sessions is set
+// in .ticker/config.json.
+func isSessionContinuationEnabled() bool {
+	dir, err := os.Getwd()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error running picker: %v\n", err)
-		os.Exit(ExitError)
+		return false
 	}
 
-	picker := model.(tui.Picker)
-	return picker.Selected()
+	config, err := verify.LoadAgentConfig(dir)
+	if err != nil {
+		// Config errors are reported when the agent registry is loaded
+		return false
+	}
+	return config.GetContinueSessions()
 }
 
-// isVerificationEnabled checks if verification should be enabled.
-// Returns false if verification is disabled via config.
-func isVerificationEnabled() bool {
+// isTranscriptRecordingEnabled reports whether agent.record_transcripts is set.
+func isTranscriptRecordingEnabled() bool {
 	dir, err := os.Getwd()
 	if err != nil {
 		return false
 	}
 
-	// Check config
-	config, err := verify.LoadConfig(dir)
+	config, err := verify.LoadAgentConfig(dir)
 	if err != nil {
-		// Config error - log but continue without verification
-		fmt.Fprintf(os.Stderr, "Warning: error loading verification config: %v\n", err)
+		// Config errors are reported when the agent registry is loaded
 		return false
 	}
-	return config.IsEnabled()
+	return config.GetRecordTranscripts()
 }
 
 // runVerifyOnly runs verification without the agent (--verify-only mode).
@@ -2222,15 +3344,21 @@ func runVerifyOnly() {
 		os.Exit(ExitSuccess)
 	}
 
-	// Create GitVerifier
-	gitVerifier := verify.NewGitVerifier(dir)
-	if gitVerifier == nil {
+	// GitVerifier first, then configured command verifiers
+	var verifiers []verify.Verifier
+	if gitVerifier := verify.NewGitVerifier(dir); gitVerifier != nil {
+		verifiers = append(verifiers, gitVerifier)
+	} else {
 		fmt.Println("Not a git repository - GitVerifier not available")
+	}
+	verifiers = append(verifiers, config.CommandVerifiers(dir)...)
+	if len(verifiers) == 0 {
 		os.Exit(ExitSuccess)
 	}
 
 	// Create runner and run verification
-	runner := verify.NewRunner(dir, gitVerifier)
+	runner := verify.NewRunner(dir, verifiers...)
+	runner.SetConcurrency(config.GetMaxParallel())
 	ctx := context.Background()
 	results := runner.Run(ctx, "", "") // Empty task ID and output for verify-only
 
@@ -2244,6 +3372,119 @@ func runVerifyOnly() {
 	os.Exit(1) // Exit code 1 for verification failure
 }
 
+// runDryRun prints what a run of epicIDs (or the auto-selected epics) would
+// do: context generation, task order, prompts and verifiers. Nothing in .tick
+// is changed and no agent is started. Returns the exit code.
+func runDryRun(epicIDs []string, auto bool, maxParallel, maxIterations int, skipVerify bool, agentName, promptDir string) int {
+	if len(epicIDs) == 0 && auto {
+		selectCount := max(maxParallel, 1)
+		selected, err := autoSelectEpics(selectCount)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error auto-selecting epics: %v\n", err)
+			return ExitError
+		}
+		epicIDs = selected
+	}
+	if len(epicIDs) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --dry-run needs an epic (or --auto with a ready epic)")
+		return ExitError
+	}
+
+	agents, err := newAgentRegistry(agentName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+	defaultAgent, err := agents.Get("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+	verifyConfig, err := loadVerificationConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitError
+	}
+
+	ticksClient := ticks.NewClient()
+	for i, epicID := range epicIDs {
+		eng := engine.NewEngine(defaultAgent, ticksClient, budget.NewTracker(budget.Limits{}), checkpoint.NewManager())
+		configureEngine(eng, agents, defaultAgent, engineOptions{skipVerify: skipVerify, verification: verifyConfig})
+
+		result, err := eng.DryRun(engine.RunConfig{EpicID: epicID, MaxIterations: maxIterations, SkipVerify: skipVerify})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: dry run of epic %s: %v\n", epicID, err)
+			return ExitError
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		if err := printDryRun(os.Stdout, result, promptDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitError
+		}
+	}
+	return ExitSuccess
+}
+
+// printDryRun writes a dry-run report to w. Prompts are printed in full, or
+// written to promptDir as <epic>-<iteration>-<task>.md if it is set.
+func printDryRun(w io.Writer, result *engine.DryRunResult, promptDir string) error {
+	fmt.Fprintf(w, "Dry run for epic %s\n", result.EpicID)
+	fmt.Fprintf(w, "Epic context: %s\n", result.Context)
+	fmt.Fprintf(w, "Codebase map: %s\n", result.RepoMap)
+
+	if promptDir != "" {
+		if err := os.MkdirAll(promptDir, 0755); err != nil {
+			return fmt.Errorf("creating prompt directory: %w", err)
+		}
+	}
+
+	fmt.Fprintln(w)
+	if len(result.Iterations) == 0 {
+		fmt.Fprintln(w, "No ready tasks.")
+	}
+	for _, it := range result.Iterations {
+		fmt.Fprintf(w, "Iteration %d: [%s] %s\n", it.Iteration, it.Task.ID, it.Task.Title)
+		fmt.Fprintf(w, "  Agent:     %s\n", it.Agent)
+		fmt.Fprintf(w, "  Prompt:    ~%d tokens", it.Stats.Tokens)
+		if it.Stats.NotesOmitted > 0 {
+			fmt.Fprintf(w, " (%d older notes omitted)", it.Stats.NotesOmitted)
+		}
+		fmt.Fprintln(w)
+		if len(it.Verifiers) > 0 {
+			fmt.Fprintf(w, "  Verifiers: %s\n", strings.Join(it.Verifiers, ", "))
+		} else {
+			fmt.Fprintln(w, "  Verifiers: none")
+		}
+
+		if promptDir != "" {
+			path := filepath.Join(promptDir, fmt.Sprintf("%s-%02d-%s.md", result.EpicID, it.Iteration, it.Task.ID))
+			if err := os.WriteFile(path, []byte(it.Prompt), 0644); err != nil {
+				return fmt.Errorf("writing prompt: %w", err)
+			}
+			fmt.Fprintf(w, "  Prompt written to %s\n", path)
+		} else {
+			fmt.Fprintf(w, "\n--- prompt ---\n%s\n--- end prompt ---\n\n", strings.TrimRight(it.Prompt, "\n"))
+		}
+	}
+
+	if len(result.Remaining) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Not reached:")
+		for _, t := range result.Remaining {
+			reason := t.Status
+			if t.IsAwaitingHuman() {
+				reason = "awaiting " + t.GetAwaitingType()
+			} else if len(t.BlockedBy) > 0 {
+				reason = "blocked by " + strings.Join(t.BlockedBy, ", ")
+			}
+			fmt.Fprintf(w, "  [%s] %s (%s)\n", t.ID, t.Title, reason)
+		}
+	}
+	return nil
+}
+
 // runMerge attempts to merge a previously conflicted epic's worktree branch.
 func runMerge(cmd *cobra.Command, args []string) {
 	epicID := args[0]
@@ -2348,87 +3589,44 @@ func runMerge(cmd *cobra.Command, args []string) {
 	os.Exit(ExitSuccess)
 }
 
-// runStandaloneInTUI runs standalone tasks with output sent to the TUI.
-// This is used when auto mode switches from epic to standalone task processing.
-func runStandaloneInTUI(ctx context.Context, p *tea.Program, initialTask *ticks.Task, ticksClient *ticks.Client, claudeAgent *agent.ClaudeAgent, budgetTracker *budget.Tracker, checkpointMgr *checkpoint.Manager, skipVerify, includeStandalone, includeOrphans bool) {
+// runStandaloneInTUI runs standalone tasks through eng, whose events the TUI
+// already follows. This is used when auto mode switches from epic to
+// standalone task processing. Returns a summary of the run for its run log.
+func runStandaloneInTUI(ctx context.Context, p *tea.Program, eng *engine.Engine, initialTask *ticks.Task, ticksClient *ticks.Client, budgetTracker *budget.Tracker, verifyConfig *verify.Config, skipVerify, includeStandalone, includeOrphans bool) *engine.RunResult {
+	summary := &engine.RunResult{ExitReason: "standalone run finished"}
 	currentTask := initialTask
 
 	for currentTask != nil {
 		// Check context cancellation
 		if ctx.Err() != nil {
-			return
+			summary.ExitReason = "context cancelled"
+			return summary
 		}
 
 		// Check budget limits
-		if shouldStop, _ := budgetTracker.ShouldStop(); shouldStop {
-			return
+		if shouldStop, reason := budgetTracker.ShouldStop(); shouldStop {
+			summary.ExitReason = reason
+			return summary
 		}
 
 		p.Send(tui.GlobalStatusMsg{Message: fmt.Sprintf("Running standalone task: [%s] %s", currentTask.ID, currentTask.Title)})
 
-		// Build prompt using the prompt builder
-		promptBuilder := engine.NewPromptBuilder()
-
-		// Get task notes for human feedback context
-		humanNotes, _ := ticksClient.GetHumanNotes(currentTask.ID)
-
-		// Try to get parent epic info for context (even if closed/orphaned)
-		var parentEpic *ticks.Epic
-		var epicNotes []string
-		if currentTask.Parent != "" {
-			if epic, err := ticksClient.GetEpic(currentTask.Parent); err == nil {
-				parentEpic = epic
-				epicNotes, _ = ticksClient.GetNotes(currentTask.Parent)
-			}
-		}
-
-		iterCtx := engine.IterationContext{
-			Iteration:     1,
-			Epic:          parentEpic,
-			Task:          currentTask,
-			EpicNotes:     epicNotes,
-			HumanFeedback: humanNotes,
-		}
-
-		prompt := promptBuilder.Build(iterCtx)
-
-		// Mark task as in_progress before starting
-		_ = ticksClient.SetStatus(currentTask.ID, "in_progress")
-
-		// Run the agent
-		agentResult, err := claudeAgent.Run(ctx, prompt, agent.RunOpts{
-			Timeout: 30 * time.Minute,
-		})
-
-		if err != nil {
+		summary.Iterations++
+		result := eng.RunTask(ctx, currentTask, summary.Iterations, 30*time.Minute)
+		summary.TotalTokens += result.TokensIn + result.TokensOut
+		summary.TotalCost += result.Cost
+		if err := standaloneRunError(result); err != nil {
 			p.Send(tui.ErrorMsg{Err: err})
-			return
-		}
-
-		// Send output to TUI
-		p.Send(tui.AgentTextMsg{Text: agentResult.Output})
-
-		// Update budget tracking
-		budgetTracker.Add(agentResult.TokensIn, agentResult.TokensOut, agentResult.Cost)
-
-		// Store run record on task
-		if agentResult.Record != nil {
-			_ = ticksClient.SetRunRecord(currentTask.ID, agentResult.Record)
-		}
-
-		// Parse signals from output
-		signal, signalReason := engine.ParseSignals(agentResult.Output)
-
-		if signal != engine.SignalNone && signal != engine.SignalComplete {
-			handleStandaloneSignal(ticksClient, currentTask, signal, signalReason)
+			summary.ExitReason = "error: " + err.Error()
+			return summary
 		}
 
 		// Check if task was closed
 		updatedTask, err := ticksClient.GetTask(currentTask.ID)
 		if err == nil && updatedTask.Status == "closed" {
 			// Run verification if enabled
-			if !skipVerify && isVerificationEnabled() {
-				passed := runStandaloneVerification(ctx, currentTask.ID, agentResult.Output)
+			if !skipVerify && verifyConfig.IsEnabled() {
+				passed := runStandaloneVerification(ctx, verifyConfig, currentTask, result.Output)
 				if !passed {
 					_ = ticksClient.ReopenTask(currentTask.ID)
 					continue // Retry the same task
@@ -2447,12 +3645,13 @@ func runStandaloneInTUI(ctx context.Context, p *tea.Program, initialTask *ticks.
 
 		currentTask = nextTask
 	}
+	return summary
 }
 
 // runStandaloneTask runs a single standalone or orphan task (task without active parent epic).
 // Unlike epic-based runs, this directly processes one task at a time and then looks for the next.
 // includeStandalone and includeOrphans control which task types to continue picking up after each completion.
-func runStandaloneTask(initialTask *ticks.Task, maxIterations int, maxCost float64, checkpointInterval, maxTaskRetries int, skipVerify, jsonl, includeStandalone, includeOrphans bool) {
+func runStandaloneTask(initialTask *ticks.Task, maxIterations int, maxCost float64, checkpointInterval, maxTaskRetries int, skipVerify, jsonl, includeStandalone, includeOrphans bool, agentName string) {
 	// Create context with signal handling
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -2469,9 +3668,14 @@ func runStandaloneTask(initialTask *ticks.Task, maxIterations int, maxCost float
 	}()
 
 	// Initialize components
-	claudeAgent := agent.NewClaudeAgent()
-	if !claudeAgent.Available() {
-		out.Error(fmt.Errorf("claude CLI not found - please install Claude Code"))
+	agents, cliAgent, err := loadAgentRegistry(agentName)
+	if err != nil {
+		out.Error(err)
+		os.Exit(ExitError)
+	}
+	verifyConfig, err := loadVerificationConfig()
+	if err != nil {
+		out.Error(err)
 		os.Exit(ExitError)
 	}
 
@@ -2483,37 +3687,28 @@ func runStandaloneTask(initialTask *ticks.Task, maxIterations int, maxCost float
 	checkpointMgr := checkpoint.NewManager()
 
 	// Create engine for running iterations
-	eng := engine.NewEngine(claudeAgent, ticksClient, budgetTracker, checkpointMgr)
-
-	// Set up context generation (use discard logger in jsonl mode)
-	contextStore := epiccontext.NewStore()
-	var contextGenOpts []epiccontext.GeneratorOption
-	if jsonl {
-		contextGenOpts = append(contextGenOpts, epiccontext.WithLogger(slog.New(slog.DiscardHandler)))
-	}
-	contextGenerator, err := epiccontext.NewGenerator(claudeAgent, contextGenOpts...)
-	if err == nil {
-		eng.SetContextComponents(contextStore, contextGenerator)
-	}
-
-	// Set up verification runner (unless --skip-verify)
-	if !skipVerify {
-		if isVerificationEnabled() {
-			eng.EnableVerification()
-		}
-	}
+	eng := engine.NewEngine(cliAgent, ticksClient, budgetTracker, checkpointMgr)
+	runLogger := configureEngine(eng, agents, cliAgent, engineOptions{
+		mode:         "standalone",
+		headless:     true,
+		skipVerify:   skipVerify,
+		verification: verifyConfig,
+		quiet:        jsonl,
+	})
 
 	// Track verification pass status for task_complete output
 	var verifyPassed bool = true
 
-	// Set up output callbacks
-	eng.OnOutput = func(chunk string) {
-		out.Output(chunk)
-	}
-
-	eng.OnSignal = func(sig engine.Signal, reason string) {
-		out.Signal(sig, reason)
-	}
+	// Agent output and signals come from the engine (see Engine.RunTask);
+	// verification and task completion are reported by the loop below
+	eng.Subscribe(func(ev engine.Event) {
+		switch ev := ev.(type) {
+		case engine.OutputEvent:
+			out.Output(ev.Text)
+		case engine.SignalEvent:
+			out.Signal(ev.Signal, ev.Reason)
+		}
+	})
 
 	// Output start message
 	if jsonl {
@@ -2551,49 +3746,12 @@ func runStandaloneTask(initialTask *ticks.Task, maxIterations int, maxCost float
 			fmt.Printf("[TASK] %s - %s (iteration %d)\n", currentTask.ID, currentTask.Title, iteration)
 		}
 
-		// Build prompt using the prompt builder
-		promptBuilder := engine.NewPromptBuilder()
-
-		// Get task notes for human feedback context
-		humanNotes, _ := ticksClient.GetHumanNotes(currentTask.ID)
-
-		// Try to get parent epic info for context (even if closed/orphaned)
-		var parentEpic *ticks.Epic
-		var epicNotes []string
-		if currentTask.Parent != "" {
-			if epic, err := ticksClient.GetEpic(currentTask.Parent); err == nil {
-				parentEpic = epic
-				epicNotes, _ = ticksClient.GetNotes(currentTask.Parent)
-			}
-		}
-
-		iterCtx := engine.IterationContext{
-			Iteration:     iteration,
-			Epic:          parentEpic,
-			Task:          currentTask,
-			EpicNotes:     epicNotes,
-			HumanFeedback: humanNotes,
-		}
-
-		prompt := promptBuilder.Build(iterCtx)
-
-		// Mark task as in_progress before starting (enables crash recovery)
-		if err := ticksClient.SetStatus(currentTask.ID, "in_progress"); err != nil {
-			// Log but continue - status update is not critical
-			if jsonl {
-				fmt.Printf(`{"type":"warning","message":"could not mark %s as in_progress: %v"}`+"\n", currentTask.ID, err)
-			} else {
-				fmt.Printf("[WARN] Could not mark %s as in_progress: %v\n", currentTask.ID, err)
-			}
-		}
-
-		// Run the agent
-		agentResult, err := claudeAgent.Run(ctx, prompt, agent.RunOpts{
-			Timeout: 30 * time.Minute,
-			Stream:  nil, // Use callback instead
-		})
-
-		if err != nil {
+		// Run the task through the engine, which streams output and signals
+		// to the subscriber above and records the run on the task
+		result := eng.RunTask(ctx, currentTask, iteration, 30*time.Minute)
+		totalCost += result.Cost
+		totalTokens += result.TokensIn + result.TokensOut
+		if err := standaloneRunError(result); err != nil {
 			if jsonl {
 				fmt.Printf(`{"type":"error","error":"%s"}`+"\n", err.Error())
 			} else {
@@ -2602,37 +3760,12 @@ func runStandaloneTask(initialTask *ticks.Task, maxIterations int, maxCost float
 			break
 		}
 
-		// Stream output
-		out.Output(agentResult.Output)
-
-		// Update budget tracking
-		budgetTracker.Add(agentResult.TokensIn, agentResult.TokensOut, agentResult.Cost)
-		totalCost += agentResult.Cost
-		totalTokens += agentResult.TokensIn + agentResult.TokensOut
-
-		// Store run record on task (enables viewing conversation history)
-		if agentResult.Record != nil {
-			_ = ticksClient.SetRunRecord(currentTask.ID, agentResult.Record)
-		}
-
-		// Parse signals from output
-		signal, signalReason := engine.ParseSignals(agentResult.Output)
-
-		if signal != engine.SignalNone {
-			out.Signal(signal, signalReason)
-
-			// Handle handoff signals by setting awaiting state
-			if signal != engine.SignalComplete {
-				handleStandaloneSignal(ticksClient, currentTask, signal, signalReason)
-			}
-		}
-
 		// Check if task was closed
 		updatedTask, err := ticksClient.GetTask(currentTask.ID)
 		if err == nil && updatedTask.Status == "closed" {
 			// Run verification if enabled
-			if !skipVerify && isVerificationEnabled() {
-				verifyPassed = runStandaloneVerification(ctx, currentTask.ID, agentResult.Output)
+			if !skipVerify && verifyConfig.IsEnabled() {
+				verifyPassed = runStandaloneVerification(ctx, verifyConfig, currentTask, result.Output)
 				if !verifyPassed {
 					// Reopen the task if verification failed
 					_ = ticksClient.ReopenTask(currentTask.ID)
@@ -2672,42 +3805,48 @@ func runStandaloneTask(initialTask *ticks.Task, maxIterations int, maxCost float
 		fmt.Printf("[COMPLETE] %d iterations, $%.4f, %d tokens\n", iteration, totalCost, totalTokens)
 	}
 
+	endRunLog(runLogger, &engine.RunResult{
+		ExitReason:  "standalone run finished",
+		Iterations:  iteration,
+		TotalTokens: totalTokens,
+		TotalCost:   totalCost,
+	})
 	os.Exit(ExitSuccess)
 }
 
-// handleStandaloneSignal processes a handoff signal for a standalone task.
-func handleStandaloneSignal(client *ticks.Client, task *ticks.Task, sig engine.Signal, context string) {
-	// Map signals to awaiting states
-	awaitingMap := map[engine.Signal]string{
-		engine.SignalEject:           "work",
-		engine.SignalBlocked:         "input",
-		engine.SignalApprovalNeeded:  "approval",
-		engine.SignalInputNeeded:     "input",
-		engine.SignalReviewRequested: "review",
-		engine.SignalContentReview:   "content",
-		engine.SignalEscalate:        "escalation",
-		engine.SignalCheckpoint:      "checkpoint",
-	}
-
-	if awaiting, ok := awaitingMap[sig]; ok {
-		_ = client.SetAwaiting(task.ID, awaiting, context)
+// standaloneRunError returns why a standalone iteration failed, or nil.
+func standaloneRunError(result *engine.IterationResult) error {
+	if result.IsTimeout {
+		return agent.ErrTimeout
 	}
+	return result.Error
 }
 
-// runStandaloneVerification runs verification for a standalone task.
-func runStandaloneVerification(ctx context.Context, taskID string, agentOutput string) bool {
+// runStandaloneVerification runs verification for a standalone task,
+// applying any verification rules declared in its description.
+func runStandaloneVerification(ctx context.Context, config *verify.Config, task *ticks.Task, agentOutput string) bool {
 	dir, err := os.Getwd()
 	if err != nil {
 		return true // Skip verification on error
 	}
 
-	gitVerifier := verify.NewGitVerifier(dir)
-	if gitVerifier == nil {
-		return true // Not a git repo, skip verification
+	var verifiers []verify.Verifier
+	if gitVerifier := verify.NewGitVerifier(dir); gitVerifier != nil {
+		verifiers = append(verifiers, gitVerifier)
+	}
+	verifiers = append(verifiers, config.CommandVerifiers(dir)...)
+	rules, err := verify.ParseTaskRules(task.Description)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring task verification rules: %v\n", err)
+	}
+	verifiers = rules.Apply(dir, verifiers)
+	if len(verifiers) == 0 {
+		return true // Nothing to verify
 	}
 
-	runner := verify.NewRunner(dir, gitVerifier)
-	results := runner.Run(ctx, taskID, agentOutput)
+	runner := verify.NewRunner(dir, verifiers...)
+	runner.SetConcurrency(config.GetMaxParallel())
+	results := runner.Run(ctx, task.ID, agentOutput)
 
 	return results == nil || results.AllPassed
 }
@@ -2802,6 +3941,11 @@ func runContext(cmd *cobra.Command, args []string) {
 			os.Exit(ExitError)
 		}
 		fmt.Print(content)
+		if meta, err := store.LoadMetadata(epicID); err == nil {
+			if reason := contextRefreshPolicy(loadContextConfig()).StaleReason(meta, "", time.Now()); reason != "" {
+				fmt.Fprintf(os.Stderr, "\nNote: context is stale (%s) and will be regenerated on the next run. Use --refresh to regenerate now.\n", reason)
+			}
+		}
 		os.Exit(ExitSuccess)
 	}
 
@@ -2809,12 +3953,62 @@ func runContext(cmd *cobra.Command, args []string) {
 	generateContext(epicID, epic, ticksClient, store, refresh)
 }
 
+// newAgentRegistry builds the agent registry from .ticker/config.json, with
+// override (from --agent) as the default if set. Agents may be uninstalled.
+func newAgentRegistry(override string) (*agent.Registry, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := verify.LoadAgentConfig(dir)
+	if err != nil {
+		return nil, fmt.Errorf("loading agent config: %w", err)
+	}
+
+	agents := agent.NewRegistry(cfg)
+	if override != "" {
+		if strings.HasPrefix(override, agent.ScriptedPrefix) {
+			// Load the scenario now so a bad file is reported up front
+			if _, err := agents.Get(override); err != nil {
+				return nil, err
+			}
+		}
+		if !agents.Has(override) {
+			return nil, fmt.Errorf("unknown agent %q (available: %s)", override, strings.Join(agents.Names(), ", "))
+		}
+		agents.SetDefault(override)
+	}
+	return agents, nil
+}
+
+// loadAgentRegistry builds the agent registry from .ticker/config.json.
+// A non-empty override (from --agent) replaces the configured default agent.
+// Returns the registry along with the default agent, which must be installed.
+func loadAgentRegistry(override string) (*agent.Registry, agent.Agent, error) {
+	agents, err := newAgentRegistry(override)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defaultAgent, err := agents.Get("")
+	if err != nil {
+		return nil, nil, err
+	}
+	if !defaultAgent.Available() {
+		if defaultAgent.Name() == "claude" {
+			return nil, nil, fmt.Errorf("claude CLI not found - please install Claude Code")
+		}
+		return nil, nil, fmt.Errorf("%s CLI not found - please install it and ensure it is on your PATH", defaultAgent.Name())
+	}
+	return agents, defaultAgent, nil
+}
+
 // generateContext handles the context generation process with progress output.
 func generateContext(epicID string, epic *ticks.Epic, ticksClient *ticks.Client, store *epiccontext.Store, isRefresh bool) {
-	// Check claude availability
-	claudeAgent := agent.NewClaudeAgent()
-	if !claudeAgent.Available() {
-		fmt.Fprintln(os.Stderr, "Error: claude CLI not found. Please install Claude Code.")
+	// Use the configured default agent
+	_, contextAgent, err := loadAgentRegistry("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(ExitError)
 	}
 
@@ -2832,11 +4026,18 @@ func generateContext(epicID string, epic *ticks.Epic, ticksClient *ticks.Client,
 	}
 
 	// Create generator
-	generator, err := epiccontext.NewGenerator(claudeAgent)
+	contextConfig := loadContextConfig()
+	generator, err := epiccontext.NewGenerator(contextAgent, contextGeneratorOptions(contextConfig, false)...)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating generator: %v\n", err)
 		os.Exit(ExitError)
 	}
+	// Reuse the shared codebase map if a run already generated it
+	if contextConfig.IsRepoMapEnabled() {
+		if repoMap, err := store.Load(epiccontext.RepoMapID); err == nil {
+			generator.SetRepoMap(repoMap)
+		}
+	}
 
 	// Progress output
 	if isRefresh {
@@ -2878,6 +4079,9 @@ func generateContext(epicID string, epic *ticks.Epic, ticksClient *ticks.Client,
 		fmt.Fprintf(os.Stderr, "Error saving context: %v\n", err)
 		os.Exit(ExitError)
 	}
+	if err := store.SaveMetadata(epicID, generator.Metadata("", len(tasks))); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not save context metadata: %v\n", err)
+	}
 
 	// Estimate token count (rough: ~4 chars per token for English text)
 	estimatedTokens := len(content) / 4
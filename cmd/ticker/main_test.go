@@ -2,10 +2,29 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/checkpoint"
+	"github.com/pengelbrecht/ticker/internal/engine"
+	"github.com/pengelbrecht/ticker/internal/inbox"
+	"github.com/pengelbrecht/ticker/internal/revert"
+	"github.com/pengelbrecht/ticker/internal/runlog"
+	"github.com/pengelbrecht/ticker/internal/server"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+	"github.com/pengelbrecht/ticker/internal/tui"
 )
 
 // TestFlagParsing tests that the CLI flags are correctly defined and parsed.
@@ -203,6 +222,84 @@ func TestWorktreeFlagParsing(t *testing.T) {
 	}
 }
 
+// TestDryRunFlagParsing tests that the dry-run flags are correctly defined.
+func TestDryRunFlagParsing(t *testing.T) {
+	for _, name := range []string{"dry-run", "dry-run-dir"} {
+		if runCmd.Flags().Lookup(name) == nil {
+			t.Errorf("--%s flag not registered", name)
+		}
+	}
+}
+
+// TestPrintDryRun tests the dry-run report, with prompts printed or written
+// to files.
+func TestPrintDryRun(t *testing.T) {
+	result := &engine.DryRunResult{
+		EpicID:  "abc",
+		Context: "generate (2 tasks)",
+		RepoMap: "disabled",
+		Iterations: []engine.DryRunIteration{{
+			Iteration: 1,
+			Task:      &ticks.Task{ID: "t1", Title: "First"},
+			Agent:     "claude",
+			Prompt:    "# Iteration 1\nDo the thing\n",
+			Stats:     engine.PromptStats{Tokens: 7},
+			Verifiers: []string{"git", "test"},
+		}},
+		Remaining: []ticks.Task{{ID: "t2", Title: "Second", Status: "open", BlockedBy: []string{"t9"}}},
+	}
+
+	var buf bytes.Buffer
+	if err := printDryRun(&buf, result, ""); err != nil {
+		t.Fatalf("printDryRun() error = %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Epic context: generate (2 tasks)", "[t1] First", "Verifiers: git, test", "Do the thing", "[t2] Second (blocked by t9)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	dir := filepath.Join(t.TempDir(), "prompts")
+	buf.Reset()
+	if err := printDryRun(&buf, result, dir); err != nil {
+		t.Fatalf("printDryRun() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "Do the thing") {
+		t.Error("prompt should be written to a file, not printed")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "abc-01-t1.md"))
+	if err != nil {
+		t.Fatalf("reading prompt file: %v", err)
+	}
+	if string(data) != result.Iterations[0].Prompt {
+		t.Errorf("prompt file = %q, want %q", data, result.Iterations[0].Prompt)
+	}
+}
+
+// TestAgentFlagParsing tests that the --agent flag is correctly defined.
+// An empty default defers to agent.default in .ticker/config.json.
+func TestAgentFlagParsing(t *testing.T) {
+	flag := runCmd.Flags().Lookup("agent")
+	if flag == nil {
+		t.Fatal("--agent flag not registered")
+	}
+	if flag.DefValue != "" {
+		t.Errorf("--agent default value = %q, want %q", flag.DefValue, "")
+	}
+}
+
+// TestLoadAgentRegistry_UnknownAgent tests that an unknown --agent is rejected.
+func TestLoadAgentRegistry_UnknownAgent(t *testing.T) {
+	_, _, err := loadAgentRegistry("gpt-pilot")
+	if err == nil {
+		t.Fatal("loadAgentRegistry() with unknown agent should return error")
+	}
+	if !strings.Contains(err.Error(), "unknown agent") {
+		t.Errorf("error = %q, want unknown agent error", err)
+	}
+}
+
 // TestValidateEpicIDs_Duplicates tests the validateEpicIDs function logic.
 // Note: Full duplicate detection is tested via unit test since the CLI validates
 // epic existence before checking for duplicates (which is correct behavior).
@@ -295,3 +392,510 @@ func TestNegativeParallelFlag(t *testing.T) {
 		t.Errorf("expected error to mention parallel, got: %s", stderr.String())
 	}
 }
+
+// writeReplayRun records a run with transcripts for iterations 1 and 2 and
+// returns its runs directory and run ID.
+func writeReplayRun(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	logger, err := runlog.NewWithWorkDir("epic-1", dir)
+	if err != nil {
+		t.Fatalf("NewWithWorkDir() error = %v", err)
+	}
+	defer logger.Close()
+
+	logger.LogRunStart("headless", true)
+	logger.LogIterationStart(1, "task-1", "First task")
+	logger.LogTaskCompleted("task-1", true)
+	logger.LogIterationStart(2, "task-2", "Second task")
+	for i, text := range []string{"Did the first task.", "Working on the second."} {
+		w, err := logger.CreateTranscript(i + 1)
+		if err != nil {
+			t.Fatalf("CreateTranscript() error = %v", err)
+		}
+		tw := agent.NewTranscriptWriter(w)
+		fmt.Fprintln(tw, `{"type":"stream_event","event":{"type":"content_block_start","index":0,"content_block":{"type":"text"}}}`)
+		fmt.Fprintf(tw, `{"type":"stream_event","event":{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":%q}}}`+"\n", text)
+		fmt.Fprintln(tw, `{"type":"result","subtype":"success","total_cost_usd":0.5,"usage":{"input_tokens":10,"output_tokens":5}}`)
+		w.Close()
+	}
+	return filepath.Join(dir, ".ticker", "runs"), logger.RunID()
+}
+
+func TestLoadReplay(t *testing.T) {
+	runsDir, runID := writeReplayRun(t)
+
+	plan, err := loadReplay(runsDir, runID, 0)
+	if err != nil {
+		t.Fatalf("loadReplay() error = %v", err)
+	}
+	if plan.EpicID != "epic-1" {
+		t.Errorf("EpicID = %q, want %q", plan.EpicID, "epic-1")
+	}
+	if len(plan.Iterations) != 2 || plan.Iterations[1].TaskID != "task-2" || plan.Iterations[1].TaskTitle != "Second task" {
+		t.Errorf("Iterations = %+v, want iterations 1 and 2 with their tasks", plan.Iterations)
+	}
+	if len(plan.Tasks) != 2 || plan.Tasks[0].Status != tui.TaskStatusClosed || plan.Tasks[1].Status != tui.TaskStatusOpen {
+		t.Errorf("Tasks = %+v, want task-1 closed and task-2 open", plan.Tasks)
+	}
+
+	plan, err = loadReplay(runsDir, runID, 2)
+	if err != nil || len(plan.Iterations) != 1 || plan.Iterations[0].Iteration != 2 {
+		t.Errorf("loadReplay(iteration 2) = %+v, %v, want only iteration 2", plan, err)
+	}
+
+	if _, err := loadReplay(runsDir, runID, 7); err == nil {
+		t.Error("loadReplay() should fail for an iteration without a transcript")
+	}
+	if _, err := loadReplay(runsDir, "20000101-000000", 0); err == nil || !strings.Contains(err.Error(), "record_transcripts") {
+		t.Errorf("loadReplay() error = %v, want a hint to enable agent.record_transcripts", err)
+	}
+}
+
+func TestReplayHeadless(t *testing.T) {
+	runsDir, runID := writeReplayRun(t)
+	plan, err := loadReplay(runsDir, runID, 0)
+	if err != nil {
+		t.Fatalf("loadReplay() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := replayHeadless(context.Background(), &buf, runsDir, plan, 0); err != nil {
+		t.Fatalf("replayHeadless() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"=== Iteration 1: [task-1] First task ===",
+		"Did the first task.",
+		"=== Iteration 2: [task-2] Second task ===",
+		"Working on the second.",
+		"15 tokens, $0.5000",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestAttachAddr(t *testing.T) {
+	runsDir := t.TempDir()
+
+	if _, err := attachAddr(runsDir, ""); err == nil || !strings.Contains(err.Error(), "no running headless run") {
+		t.Errorf("attachAddr() with no runs error = %v, want no running run", err)
+	}
+	if _, err := attachAddr(runsDir, "20250114-093012"); err == nil {
+		t.Error("attachAddr() should fail for a run without a socket")
+	}
+
+	for _, id := range []string{"20250114-093012", "20250115-101500"} {
+		l, err := server.Listen("unix:"+runlog.SocketPath(runsDir, id), false)
+		if err != nil {
+			t.Fatalf("Listen() error = %v", err)
+		}
+		defer l.Close()
+		if id == "20250114-093012" {
+			addr, err := attachAddr(runsDir, "")
+			if want := "unix:" + runlog.SocketPath(runsDir, id); err != nil || addr != want {
+				t.Errorf("attachAddr() with one run = %q, %v, want %q", addr, err, want)
+			}
+		}
+	}
+
+	if _, err := attachAddr(runsDir, ""); err == nil || !strings.Contains(err.Error(), "20250115-101500, 20250114-093012") {
+		t.Errorf("attachAddr() with two runs error = %v, want both run IDs listed", err)
+	}
+	addr, err := attachAddr(runsDir, "20250114-093012")
+	if want := "unix:" + runlog.SocketPath(runsDir, "20250114-093012"); err != nil || addr != want {
+		t.Errorf("attachAddr(run ID) = %q, %v, want %q", addr, err, want)
+	}
+}
+
+func TestAttachMessages(t *testing.T) {
+	msg := func(typ string, data any) server.Message {
+		raw, _ := json.Marshal(data)
+		return server.Message{Type: typ, EpicID: "epic-1", Data: raw}
+	}
+
+	tests := []struct {
+		name        string
+		msg         server.Message
+		want        []tea.Msg
+		wantRefresh bool
+	}{
+		{
+			name: "snapshot mid-iteration",
+			msg: msg("snapshot", server.SnapshotData{
+				Status: server.Status{
+					State:          server.StateRunning,
+					Iteration:      2,
+					CurrentTask:    &server.TaskRef{ID: "t2", Title: "Second"},
+					PauseRequested: true,
+					Budget:         &server.Budget{TokensIn: 100, TokensOut: 50, Cost: 0.25},
+				},
+				Output: "so far",
+				Agent:  &server.AgentStateData{Status: "thinking", Thinking: "hmm"},
+			}),
+			want: []tea.Msg{
+				tui.IterationEndMsg{Cost: 0.25, Tokens: 150},
+				tui.IterationStartMsg{Iteration: 2, TaskID: "t2", TaskTitle: "Second"},
+				tui.AgentTextMsg{Text: "so far"},
+				tui.AgentThinkingMsg{Text: "hmm"},
+				tui.AgentMetricsMsg{},
+				tui.AgentStatusMsg{Status: agent.StatusThinking},
+				tui.PausedMsg{Paused: true},
+			},
+			wantRefresh: true,
+		},
+		{
+			name: "snapshot of finished run",
+			msg:  msg("snapshot", server.SnapshotData{Status: server.Status{State: server.StateFinished, Iteration: 4, ExitReason: "all tasks completed"}}),
+			want: []tea.Msg{
+				tui.RunCompleteMsg{Reason: "all tasks completed", Iterations: 4},
+			},
+			wantRefresh: true,
+		},
+		{
+			name: "tool switch",
+			msg: msg("agent_state", server.AgentStateData{
+				Status:    "tool_use",
+				ToolEnd:   &server.ToolData{ID: "a", Name: "Read", DurationMS: 1500},
+				ToolStart: &server.ToolData{ID: "b", Name: "Edit"},
+				Metrics:   server.MetricsData{InputTokens: 10},
+			}),
+			want: []tea.Msg{
+				tui.AgentToolEndMsg{ID: "a", Name: "Read", Duration: 1500 * time.Millisecond},
+				tui.AgentToolStartMsg{ID: "b", Name: "Edit"},
+				tui.AgentMetricsMsg{InputTokens: 10},
+				tui.AgentStatusMsg{Status: agent.StatusToolUse},
+			},
+		},
+		{
+			name:        "iteration end",
+			msg:         msg("iteration_end", server.IterationEndData{Iteration: 2, TokensIn: 10, TokensOut: 5, Cost: 0.1}),
+			want:        []tea.Msg{tui.IterationEndMsg{Iteration: 2, Cost: 0.1, Tokens: 15}},
+			wantRefresh: true,
+		},
+		{
+			name: "output",
+			msg:  msg("output", server.OutputData{Text: "hello"}),
+			want: []tea.Msg{tui.AgentTextMsg{Text: "hello"}},
+		},
+		{
+			name: "context skipped",
+			msg:  msg("context_skipped", server.ContextData{Reason: "single-task epic"}),
+			want: []tea.Msg{tui.ContextSkippedMsg{EpicID: "epic-1", Reason: "single-task epic"}},
+		},
+		{
+			name: "pause",
+			msg:  msg("pause", server.PauseData{Paused: false}),
+			want: []tea.Msg{tui.PausedMsg{Paused: false}},
+		},
+		{
+			name: "run end",
+			msg:  msg("run_end", server.RunData{ExitReason: "stopped by request"}),
+			want: []tea.Msg{tui.RunCompleteMsg{Reason: "stopped by request"}},
+		},
+		{
+			name: "unknown",
+			msg:  msg("budget", server.BudgetData{}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []tea.Msg
+			refreshed := false
+			handle := attachMessages(func(m tea.Msg) { got = append(got, m) }, func() { refreshed = true })
+			handle(tt.msg)
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messages = %#v, want %#v", got, tt.want)
+			}
+			if refreshed != tt.wantRefresh {
+				t.Errorf("refreshed tasks = %v, want %v", refreshed, tt.wantRefresh)
+			}
+		})
+	}
+}
+
+func TestPrintInbox(t *testing.T) {
+	now := time.Date(2025, 1, 14, 12, 0, 0, 0, time.UTC)
+	groups := []inbox.Group{
+		{Awaiting: "input", Items: []inbox.Item{{
+			Task:     ticks.Task{ID: "t1", Title: "Set up database", Parent: "e1", UpdatedAt: now.Add(-3 * time.Hour)},
+			Reason:   "Which database should I use?\nPostgres or SQLite.",
+			LastNote: "Scaffolded the service",
+		}}},
+		{Awaiting: "approval", Items: []inbox.Item{{
+			Task: ticks.Task{ID: "t2", Title: "Deploy", UpdatedAt: now.Add(-30 * time.Second)},
+		}}},
+	}
+
+	var buf bytes.Buffer
+	printInbox(&buf, groups, now)
+	want := `Awaiting input (1)
+  t1       Set up database (epic e1, 3h ago)
+           Which database should I use? ...
+           Last note: Scaffolded the service
+
+Awaiting approval (1)
+  t2       Deploy (just now)
+`
+	if buf.String() != want {
+		t.Errorf("printInbox() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	printInbox(&buf, nil, now)
+	if !strings.Contains(buf.String(), "Inbox is empty") {
+		t.Errorf("printInbox(nil) = %q, want an empty inbox message", buf.String())
+	}
+}
+
+func TestPrintRevertPlan(t *testing.T) {
+	plan := &revert.Plan{
+		Task:    &ticks.Task{ID: "t1", Title: "Add login"},
+		Dir:     "/repo/.worktrees/e1",
+		Commits: []string{"1111111aaaaaaa", "2222222bbbbbbb"},
+		Files:   []string{"login.go", "login_test.go"},
+	}
+
+	var buf bytes.Buffer
+	printRevertPlan(&buf, plan)
+	want := `Task [t1] Add login
+  Directory: /repo/.worktrees/e1
+  Commits:   1111111 2222222
+  Files:     login.go, login_test.go
+`
+	if buf.String() != want {
+		t.Errorf("printRevertPlan() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	plan.Dependents = []revert.Dependent{
+		{Commit: "3333333ccccccc", Subject: "Add logout", TaskID: "t2", Files: []string{"login.go"}},
+		{Commit: "4444444ddddddd", Subject: "Fix typo", Files: []string{"login_test.go"}},
+	}
+	buf.Reset()
+	printRevertPlan(&buf, plan)
+	want += `
+Later commits touching the same files (2):
+  3333333 Add logout (task t2)
+          login.go
+  4444444 Fix typo
+          login_test.go
+`
+	if buf.String() != want {
+		t.Errorf("printRevertPlan() with dependents =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{10 * time.Second, "just now"},
+		{5 * time.Minute, "5m ago"},
+		{90 * time.Minute, "1h ago"},
+		{50 * time.Hour, "2d ago"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+// TestResumeSkipVerifyFlag tests that resume takes --skip-verify like run.
+func TestResumeSkipVerifyFlag(t *testing.T) {
+	flag := resumeCmd.Flags().Lookup("skip-verify")
+	if flag == nil {
+		t.Fatal("--skip-verify flag not registered on resume")
+	}
+	if want := runCmd.Flags().Lookup("skip-verify").DefValue; flag.DefValue != want {
+		t.Errorf("resume --skip-verify default = %q, want %q like run", flag.DefValue, want)
+	}
+}
+
+// transcriptAgent writes a line to the transcript, like claude's raw stream.
+type transcriptAgent struct{}
+
+func (transcriptAgent) Name() string    { return "transcript" }
+func (transcriptAgent) Available() bool { return true }
+
+func (transcriptAgent) Run(ctx context.Context, prompt string, opts agent.RunOpts) (*agent.Result, error) {
+	if opts.Transcript != nil {
+		fmt.Fprintln(opts.Transcript, `{"type":"system","subtype":"init"}`)
+	}
+	return &agent.Result{Output: "done"}, nil
+}
+
+// oneTaskTicks is an engine.TicksClient for an epic with a single task.
+type oneTaskTicks struct {
+	task   ticks.Task
+	picked bool
+}
+
+func (o *oneTaskTicks) GetEpic(epicID string) (*ticks.Epic, error) {
+	return &ticks.Epic{ID: epicID, Title: "Epic", Type: "epic"}, nil
+}
+func (o *oneTaskTicks) GetTask(taskID string) (*ticks.Task, error) { return &o.task, nil }
+func (o *oneTaskTicks) NextTask(epicID string) (*ticks.Task, error) {
+	if o.picked {
+		return nil, nil
+	}
+	o.picked = true
+	return &o.task, nil
+}
+func (o *oneTaskTicks) ListTasks(epicID string) ([]ticks.Task, error) {
+	return []ticks.Task{o.task}, nil
+}
+func (o *oneTaskTicks) HasOpenTasks(epicID string) (bool, error)                   { return false, nil }
+func (o *oneTaskTicks) CloseTask(taskID, reason string) error                      { return nil }
+func (o *oneTaskTicks) CloseEpic(epicID, reason string) error                      { return nil }
+func (o *oneTaskTicks) ReopenTask(taskID string) error                             { return nil }
+func (o *oneTaskTicks) AddNote(issueID, message string, extraArgs ...string) error { return nil }
+func (o *oneTaskTicks) GetNotes(epicID string) ([]string, error)                   { return nil, nil }
+func (o *oneTaskTicks) GetHumanNotes(issueID string) ([]ticks.Note, error)         { return nil, nil }
+func (o *oneTaskTicks) SetStatus(issueID, status string) error                     { return nil }
+func (o *oneTaskTicks) SetAwaiting(taskID, awaiting, note string) error            { return nil }
+func (o *oneTaskTicks) SetRunRecord(taskID string, record *agent.RunRecord) error {
+	return nil
+}
+func (o *oneTaskTicks) GetRunRecord(taskID string) (*agent.RunRecord, error) { return nil, nil }
+
+// TestConfigureEngine_ParallelTranscripts tests that engines set up for a
+// parallel run get their own run log and record transcripts.
+func TestConfigureEngine_ParallelTranscripts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".ticker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"agent": {"record_transcripts": true}}`
+	if err := os.WriteFile(filepath.Join(dir, ".ticker", "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	agents := agent.NewRegistry(nil)
+	agents.Register("transcript", func() agent.Agent { return transcriptAgent{} })
+	agents.SetDefault("transcript")
+
+	var runLogs parallelRunLogs
+	sharedBudget := budget.NewTracker(budget.Limits{})
+	for _, epicID := range []string{"epic-a", "epic-b"} {
+		eng := engine.NewEngine(transcriptAgent{}, &oneTaskTicks{task: ticks.Task{ID: epicID + "-1", Title: "Task"}}, sharedBudget, checkpoint.NewManager())
+		runLogs.add(epicID, configureEngine(eng, agents, transcriptAgent{}, engineOptions{
+			epicID:     epicID,
+			mode:       "parallel",
+			headless:   true,
+			skipVerify: true,
+			quiet:      true,
+		}))
+		if _, err := eng.Run(context.Background(), engine.RunConfig{EpicID: epicID, MaxIterations: 1}); err != nil {
+			t.Fatalf("Run(%s) error = %v", epicID, err)
+		}
+	}
+
+	runIDs := make([]string, 0, len(runLogs.logs))
+	for _, l := range runLogs.logs {
+		runIDs = append(runIDs, l.RunID())
+	}
+	runLogs.endAll(nil)
+
+	if len(runIDs) != 2 || runIDs[0] == runIDs[1] {
+		t.Fatalf("run IDs = %v, want one run log per epic", runIDs)
+	}
+	for _, runID := range runIDs {
+		iterations, err := runlog.ListTranscripts(filepath.Join(".ticker", "runs"), runID)
+		if err != nil || len(iterations) != 1 {
+			t.Errorf("ListTranscripts(%s) = %v, %v; want iteration 1", runID, iterations, err)
+		}
+	}
+}
+
+// TestStartRunLog_AfterEpicRun tests that standalone tasks run after an
+// epic's run log has ended are recorded in a run log of their own.
+func TestStartRunLog_AfterEpicRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".ticker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"agent": {"record_transcripts": true}}`
+	if err := os.WriteFile(filepath.Join(dir, ".ticker", "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	agents := agent.NewRegistry(nil)
+	agents.Register("transcript", func() agent.Agent { return transcriptAgent{} })
+	agents.SetDefault("transcript")
+
+	task := ticks.Task{ID: "epic-a-1", Title: "Task"}
+	eng := engine.NewEngine(transcriptAgent{}, &oneTaskTicks{task: task}, budget.NewTracker(budget.Limits{}), checkpoint.NewManager())
+	epicLog := configureEngine(eng, agents, transcriptAgent{}, engineOptions{
+		epicID:     "epic-a",
+		mode:       "tui",
+		skipVerify: true,
+		quiet:      true,
+	})
+	result, err := eng.Run(context.Background(), engine.RunConfig{EpicID: "epic-a", MaxIterations: 1})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	endRunLog(epicLog, result)
+
+	standaloneLog := startRunLog(eng, "", "tui", false, true)
+	if standaloneLog == nil || eng.RunLog() != standaloneLog {
+		t.Fatal("startRunLog() did not set a new run log on the engine")
+	}
+	eng.RunTask(context.Background(), &ticks.Task{ID: "task-s", Title: "Standalone"}, 1, time.Minute)
+	endRunLog(standaloneLog, &engine.RunResult{ExitReason: "standalone run finished", Iterations: 1})
+
+	if standaloneLog.RunID() == epicLog.RunID() {
+		t.Fatalf("standalone run ID = epic run ID %s", epicLog.RunID())
+	}
+	iterations, err := runlog.ListTranscripts(filepath.Join(".ticker", "runs"), standaloneLog.RunID())
+	if err != nil || len(iterations) != 1 {
+		t.Errorf("ListTranscripts(%s) = %v, %v; want iteration 1", standaloneLog.RunID(), iterations, err)
+	}
+}
+
+// TestLoadVerificationConfig_Invalid tests that an invalid verification
+// config is an error rather than verification quietly turned off.
+func TestLoadVerificationConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".ticker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"verification": {"max_parallel": 0}}`
+	if err := os.WriteFile(filepath.Join(dir, ".ticker", "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	cfg, err := loadVerificationConfig()
+	if err == nil || !strings.Contains(err.Error(), "max_parallel") {
+		t.Fatalf("loadVerificationConfig() = %v, %v; want max_parallel error", cfg, err)
+	}
+}
+
+// TestLoadEscalationConfig_Invalid tests that an invalid escalation config
+// is returned as an error, for configureEngine to report unless quiet.
+func TestLoadEscalationConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".ticker"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"escalation": {"tiers": [{"timeout": "1s"}]}}`
+	if err := os.WriteFile(filepath.Join(dir, ".ticker", "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	cfg, err := loadEscalationConfig()
+	if err == nil || !strings.Contains(err.Error(), "tier 1") {
+		t.Fatalf("loadEscalationConfig() = %v, %v; want tier 1 error", cfg, err)
+	}
+}
@@ -3,6 +3,8 @@ package agent
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"time"
 )
 
@@ -10,6 +12,10 @@ import (
 // The Result will contain partial output captured before the timeout.
 var ErrTimeout = errors.New("agent timed out")
 
+// ErrReadOnlyUnsupported is returned for a ReadOnly run by an agent that
+// can't keep the run from modifying files.
+var ErrReadOnlyUnsupported = errors.New("agent cannot run read-only")
+
 // Agent defines the interface for AI coding agents.
 type Agent interface {
 	// Name returns the agent's display name.
@@ -23,6 +29,36 @@ type Agent interface {
 	Run(ctx context.Context, prompt string, opts RunOpts) (*Result, error)
 }
 
+// SessionResumer is implemented by agents that can continue an earlier
+// session (see RunOpts.ResumeSession) instead of starting from scratch.
+type SessionResumer interface {
+	Agent
+
+	// CanResume reports whether sessions can be resumed with this agent.
+	CanResume() bool
+}
+
+// CanResume reports whether a supports resuming sessions.
+func CanResume(a Agent) bool {
+	r, ok := a.(SessionResumer)
+	return ok && r.CanResume()
+}
+
+// ReadOnlyRunner is implemented by agents that may be unable to honor
+// RunOpts.ReadOnly. Agents that don't implement it can run read-only.
+type ReadOnlyRunner interface {
+	Agent
+
+	// CanRunReadOnly reports whether runs can be kept from modifying files.
+	CanRunReadOnly() bool
+}
+
+// CanRunReadOnly reports whether a can honor RunOpts.ReadOnly.
+func CanRunReadOnly(a Agent) bool {
+	r, ok := a.(ReadOnlyRunner)
+	return !ok || r.CanRunReadOnly()
+}
+
 // RunOpts configures an agent run.
 type RunOpts struct {
 	// Stream receives chunks of output for real-time display.
@@ -45,6 +81,33 @@ type RunOpts struct {
 	// WorkDir is the working directory for the agent.
 	// If empty, the current working directory is used.
 	WorkDir string
+
+	// Model overrides the agent's configured model for this run (if supported).
+	// If empty, the agent's default model is used.
+	Model string
+
+	// TaskID and EpicID identify the task the run works on and its epic.
+	// Empty for runs outside a task (e.g. context generation) or epic.
+	TaskID string
+	EpicID string
+
+	// PersistSession keeps the session on disk so a later run can resume it
+	// (if supported). Ignored by agents that don't implement SessionResumer.
+	PersistSession bool
+
+	// ResumeSession continues the session with this ID, sending prompt as a
+	// follow-up message. Only honored by agents where CanResume reports true.
+	ResumeSession string
+
+	// ReadOnly asks the agent not to modify files, e.g. for review runs.
+	// Agents that can't enforce it (see CanRunReadOnly) refuse the run with
+	// ErrReadOnlyUnsupported.
+	ReadOnly bool
+
+	// Transcript receives a copy of the agent's raw output stream (claude's
+	// stream-json), e.g. a TranscriptWriter. Agents without a stream that
+	// StreamParser understands ignore it.
+	Transcript io.Writer
 }
 
 // Result contains the output and metrics from an agent run.
@@ -68,3 +131,53 @@ type Result struct {
 	// May be nil if the agent doesn't support structured output.
 	Record *RunRecord
 }
+
+// newUpdateNotifier returns an update callback for stream parsers that
+// notifies both StateCallback and the legacy Stream channel.
+func newUpdateNotifier(state *AgentState, opts RunOpts) func() {
+	var prevOutputLen int // Track output length for delta streaming
+
+	return func() {
+		snap := state.Snapshot()
+
+		// Call StateCallback if set (preferred API)
+		if opts.StateCallback != nil {
+			opts.StateCallback(snap)
+		}
+
+		// Stream output deltas to legacy Stream channel if set (backward compat)
+		if opts.Stream != nil && len(snap.Output) > prevOutputLen {
+			delta := snap.Output[prevOutputLen:]
+			select {
+			case opts.Stream <- delta:
+				prevOutputLen = len(snap.Output)
+			default:
+				// Channel full, skip this delta (will be included in next)
+			}
+		}
+	}
+}
+
+// resultFromState builds a Result from the accumulated agent state.
+func resultFromState(state *AgentState, duration time.Duration) *Result {
+	snap := state.Snapshot()
+	record := state.ToRecord()
+
+	return &Result{
+		Output:    snap.Output,
+		TokensIn:  snap.Metrics.InputTokens,
+		TokensOut: snap.Metrics.OutputTokens,
+		Cost:      snap.Metrics.CostUSD,
+		Duration:  duration,
+		Record:    &record,
+	}
+}
+
+// timeoutResult builds a partial Result for a run that hit its timeout.
+// The record is marked unsuccessful with a timeout error message.
+func timeoutResult(state *AgentState, timeout time.Duration, duration time.Duration) *Result {
+	result := resultFromState(state, duration)
+	result.Record.Success = false
+	result.Record.ErrorMsg = fmt.Sprintf("timed out after %v", timeout)
+	return result
+}
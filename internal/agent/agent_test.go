@@ -2,7 +2,10 @@ package agent
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -167,3 +170,157 @@ func TestErrTimeout(t *testing.T) {
 		t.Errorf("ErrTimeout.Error() = %q, want %q", ErrTimeout.Error(), "agent timed out")
 	}
 }
+
+func TestClaudeAgent_args(t *testing.T) {
+	agent := &ClaudeAgent{}
+
+	args := agent.args("do it", "", RunOpts{})
+	if args[len(args)-1] != "do it" {
+		t.Errorf("prompt should be last arg, got %q", args[len(args)-1])
+	}
+	for _, arg := range args {
+		if arg == "--model" {
+			t.Error("--model should be omitted when no model is set")
+		}
+	}
+
+	got := strings.Join(agent.args("do it", "sonnet", RunOpts{}), " ")
+	if !strings.HasSuffix(got, "--model sonnet do it") {
+		t.Errorf("args() = %q, want --model sonnet before prompt", got)
+	}
+}
+
+func TestClaudeAgent_args_Session(t *testing.T) {
+	agent := &ClaudeAgent{}
+
+	tests := []struct {
+		name     string
+		opts     RunOpts
+		contains []string
+		excludes []string
+	}{
+		{"fresh run", RunOpts{}, []string{"--no-session-persistence"}, []string{"--resume"}},
+		{"persisted", RunOpts{PersistSession: true}, nil, []string{"--no-session-persistence", "--resume"}},
+		{"resumed", RunOpts{ResumeSession: "sess-1"}, []string{"--resume sess-1"}, []string{"--no-session-persistence"}},
+		{"normal run", RunOpts{}, []string{"--dangerously-skip-permissions"}, []string{"--permission-mode"}},
+		{"read only", RunOpts{ReadOnly: true}, []string{"--permission-mode plan", "--disallowedTools"}, []string{"--dangerously-skip-permissions"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(agent.args("fix it", "", tt.opts), " ")
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("args() = %q, want %q", got, want)
+				}
+			}
+			for _, bad := range tt.excludes {
+				if strings.Contains(got, bad) {
+					t.Errorf("args() = %q, should not contain %q", got, bad)
+				}
+			}
+		})
+	}
+}
+
+func TestCanResume(t *testing.T) {
+	if !CanResume(NewClaudeAgent()) {
+		t.Error("CanResume(claude) = false, want true")
+	}
+	if CanResume(NewCodexAgent()) {
+		t.Error("CanResume(codex) = true, want false")
+	}
+}
+
+func TestClaudeAgent_modelChain(t *testing.T) {
+	tests := []struct {
+		name      string
+		agent     *ClaudeAgent
+		requested string
+		want      []string
+	}{
+		{"cli default", &ClaudeAgent{}, "", []string{""}},
+		{"agent default", &ClaudeAgent{Model: "opus"}, "", []string{"opus"}},
+		{"requested overrides default", &ClaudeAgent{Model: "opus"}, "haiku", []string{"haiku"}},
+		{"fallbacks appended", &ClaudeAgent{Model: "opus", FallbackModels: []string{"sonnet", "haiku"}}, "", []string{"opus", "sonnet", "haiku"}},
+		{"duplicates skipped", &ClaudeAgent{Model: "opus", FallbackModels: []string{"opus", "", "sonnet"}}, "", []string{"opus", "sonnet"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.agent.modelChain(tt.requested)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("modelChain() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryableModelError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{"", false},
+		{"API Error: 529 {\"type\":\"overloaded_error\"}", true},
+		{"API Error: 429 rate_limit_error", true},
+		{"Claude AI usage limit reached|1760000000", true},
+		{"Rate limit exceeded", true},
+		{"permission denied on main.go:429", false},
+		{"tests failed", false},
+	}
+
+	for _, tt := range tests {
+		if got := isRetryableModelError(tt.msg); got != tt.want {
+			t.Errorf("isRetryableModelError(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestClaudeAgent_Run_FallbackOnOverload(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake claude binary requires a POSIX shell")
+	}
+
+	// Fake claude: opus is overloaded, anything else succeeds
+	script := `#!/bin/sh
+case "$*" in
+*"--model opus"*)
+  echo '{"type":"system","subtype":"init","session_id":"s1","model":"claude-opus-4-5-20251101"}'
+  echo '{"type":"result","subtype":"success","is_error":true,"result":"API Error: 529 Overloaded","num_turns":1}'
+  exit 1
+  ;;
+esac
+echo '{"type":"system","subtype":"init","session_id":"s2","model":"claude-sonnet-4-20250514"}'
+echo '{"type":"stream_event","event":{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}}'
+echo '{"type":"stream_event","event":{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"done"}}}'
+echo '{"type":"result","subtype":"success","result":"done","num_turns":1,"total_cost_usd":0.02,"usage":{"input_tokens":10,"output_tokens":2}}'
+`
+	bin := filepath.Join(t.TempDir(), "claude")
+	if err := os.WriteFile(bin, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := &ClaudeAgent{Command: bin, Model: "opus", FallbackModels: []string{"sonnet"}}
+	result, err := agent.Run(context.Background(), "work", RunOpts{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Output != "done" {
+		t.Errorf("Output = %q, want %q", result.Output, "done")
+	}
+	if result.Record.Model != "claude-sonnet-4-20250514" {
+		t.Errorf("Record.Model = %q, want sonnet", result.Record.Model)
+	}
+	if strings.Join(result.Record.FallbackFrom, ",") != "opus" {
+		t.Errorf("Record.FallbackFrom = %q, want [opus]", result.Record.FallbackFrom)
+	}
+
+	// Without fallbacks the overload error is returned
+	agent.FallbackModels = nil
+	if _, err := agent.Run(context.Background(), "work", RunOpts{}); err == nil {
+		t.Error("Run() without fallback should return overload error")
+	} else if !strings.Contains(err.Error(), "Overloaded") {
+		t.Errorf("error = %q, want overload message", err)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
+	"strings"
 	"time"
 )
 
@@ -12,6 +14,14 @@ import (
 type ClaudeAgent struct {
 	// Command is the path to the claude binary. Defaults to "claude".
 	Command string
+
+	// Model is the default model passed via --model (e.g. "opus", "sonnet").
+	// Empty string uses the CLI's configured default. RunOpts.Model overrides it.
+	Model string
+
+	// FallbackModels are tried in order when a run fails because the
+	// current model is rate-limited or overloaded.
+	FallbackModels []string
 }
 
 // NewClaudeAgent creates a new Claude Code agent with default settings.
@@ -24,6 +34,11 @@ func (a *ClaudeAgent) Name() string {
 	return "claude"
 }
 
+// CanResume returns true: claude sessions can be continued with --resume.
+func (a *ClaudeAgent) CanResume() bool {
+	return true
+}
+
 // Available checks if the claude CLI is installed and accessible.
 func (a *ClaudeAgent) Available() bool {
 	_, err := exec.LookPath(a.command())
@@ -31,29 +46,60 @@ func (a *ClaudeAgent) Available() bool {
 }
 
 // Run executes claude with the given prompt.
-// Uses --dangerously-skip-permissions for autonomous operation.
+// Uses --dangerously-skip-permissions for autonomous operation (plan mode
+// for read-only runs).
 // Uses --output-format stream-json for structured streaming output.
+// If the model is rate-limited or overloaded, the run is retried with each
+// of FallbackModels in turn.
 func (a *ClaudeAgent) Run(ctx context.Context, prompt string, opts RunOpts) (*Result, error) {
 	start := time.Now()
 
-	// Apply timeout if specified
+	// Apply timeout if specified (covers all fallback attempts)
 	if opts.Timeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
 		defer cancel()
 	}
 
-	args := []string{
-		"--dangerously-skip-permissions",
-		"--print",
-		"--output-format", "stream-json",
-		"--include-partial-messages",
-		"--verbose",
-		"--no-session-persistence",
-		prompt,
+	models := a.modelChain(opts.Model)
+
+	var tried []string
+	var spent Result // tokens and cost of failed attempts
+	for i, model := range models {
+		result, retryable, err := a.runModel(ctx, start, prompt, model, opts)
+		if retryable && i < len(models)-1 && ctx.Err() == nil {
+			if model == "" {
+				model = "default"
+			}
+			tried = append(tried, model)
+			if result != nil {
+				spent.TokensIn += result.TokensIn
+				spent.TokensOut += result.TokensOut
+				spent.Cost += result.Cost
+			}
+			continue
+		}
+
+		if result != nil {
+			result.TokensIn += spent.TokensIn
+			result.TokensOut += spent.TokensOut
+			result.Cost += spent.Cost
+			if result.Record != nil {
+				result.Record.FallbackFrom = tried
+			}
+		}
+		return result, err
 	}
 
-	cmd := exec.CommandContext(ctx, a.command(), args...)
+	// Unreachable: modelChain always returns at least one entry
+	return nil, fmt.Errorf("claude: no model to run")
+}
+
+// runModel runs claude once with the given model ("" = CLI default).
+// retryable reports whether the failure looks like a rate-limit or overload
+// that a different model might not hit.
+func (a *ClaudeAgent) runModel(ctx context.Context, start time.Time, prompt, model string, opts RunOpts) (result *Result, retryable bool, err error) {
+	cmd := exec.CommandContext(ctx, a.command(), a.args(prompt, model, opts)...)
 
 	// Set working directory if specified
 	if opts.WorkDir != "" {
@@ -64,87 +110,145 @@ func (a *ClaudeAgent) Run(ctx context.Context, prompt string, opts RunOpts) (*Re
 
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
-		return nil, fmt.Errorf("create stdout pipe: %w", err)
+		return nil, false, fmt.Errorf("create stdout pipe: %w", err)
 	}
 	cmd.Stderr = &stderr
 
 	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("start claude: %w", err)
+		return nil, false, fmt.Errorf("start claude: %w", err)
 	}
 
 	// Create state and parser for structured streaming
 	state := &AgentState{}
-	var prevOutputLen int // Track output length for delta streaming
-
-	// Build update callback that notifies both StateCallback and legacy Stream
-	onUpdate := func() {
-		snap := state.Snapshot()
-
-		// Call StateCallback if set (preferred API)
-		if opts.StateCallback != nil {
-			opts.StateCallback(snap)
-		}
-
-		// Stream output deltas to legacy Stream channel if set (backward compat)
-		if opts.Stream != nil && len(snap.Output) > prevOutputLen {
-			delta := snap.Output[prevOutputLen:]
-			select {
-			case opts.Stream <- delta:
-				prevOutputLen = len(snap.Output)
-			default:
-				// Channel full, skip this delta (will be included in next)
-			}
-		}
-	}
+	onUpdate := newUpdateNotifier(state, opts)
 
 	parser := NewStreamParser(state, onUpdate)
 
-	// Parse stream-json output
-	parseErr := parser.Parse(stdoutPipe)
+	// Parse stream-json output, recording it if asked
+	var stdout io.Reader = stdoutPipe
+	if opts.Transcript != nil {
+		stdout = io.TeeReader(stdoutPipe, opts.Transcript)
+	}
+	parseErr := parser.Parse(stdout)
 
 	// Wait for command to complete
 	waitErr := cmd.Wait()
 
 	duration := time.Since(start)
 
+	// The init event reports the model in use; fall back to the requested one
+	state.mu.Lock()
+	if state.Model == "" {
+		state.Model = model
+	}
+	streamErr := ""
+	if state.Status == StatusError {
+		streamErr = state.ErrorMsg
+	}
+	state.mu.Unlock()
+
 	// Handle errors - but capture partial output for timeouts
 	if waitErr != nil {
 		if ctx.Err() == context.DeadlineExceeded {
 			// Return partial result with timeout error
-			snap := state.Snapshot()
-			record := state.ToRecord()
-			record.Success = false
-			record.ErrorMsg = fmt.Sprintf("timed out after %v", opts.Timeout)
-			return &Result{
-				Output:    snap.Output,
-				TokensIn:  snap.Metrics.InputTokens,
-				TokensOut: snap.Metrics.OutputTokens,
-				Cost:      snap.Metrics.CostUSD,
-				Duration:  duration,
-				Record:    &record,
-			}, ErrTimeout
+			return timeoutResult(state, opts.Timeout, duration), false, ErrTimeout
 		}
 		if ctx.Err() == context.Canceled {
-			return nil, fmt.Errorf("claude cancelled")
+			return nil, false, fmt.Errorf("claude cancelled")
+		}
+		retryable := isRetryableModelError(streamErr) || isRetryableModelError(stderr.String())
+		if streamErr != "" {
+			return resultFromState(state, duration), retryable, fmt.Errorf("claude exited with error: %w: %s\nstderr: %s", waitErr, streamErr, stderr.String())
 		}
-		return nil, fmt.Errorf("claude exited with error: %w\nstderr: %s", waitErr, stderr.String())
+		return resultFromState(state, duration), retryable, fmt.Errorf("claude exited with error: %w\nstderr: %s", waitErr, stderr.String())
 	}
 	if parseErr != nil {
-		return nil, fmt.Errorf("parse stream output: %w", parseErr)
+		return nil, false, fmt.Errorf("parse stream output: %w", parseErr)
+	}
+
+	// Some API errors are reported in the result event with a zero exit code
+	if isRetryableModelError(streamErr) {
+		return resultFromState(state, duration), true, fmt.Errorf("claude: %s", streamErr)
 	}
 
 	// Build result from parsed state
-	snap := state.Snapshot()
-	record := state.ToRecord()
-
-	return &Result{
-		Output:    snap.Output,
-		TokensIn:  snap.Metrics.InputTokens,
-		TokensOut: snap.Metrics.OutputTokens,
-		Cost:      snap.Metrics.CostUSD,
-		Duration:  duration,
-		Record:    &record,
-	}, nil
+	return resultFromState(state, duration), false, nil
+}
+
+// args builds the claude CLI arguments for a run with the given model.
+// Sessions are not persisted unless opts asks to keep or resume one.
+func (a *ClaudeAgent) args(prompt, model string, opts RunOpts) []string {
+	var args []string
+	if opts.ReadOnly {
+		// Plan mode lets the agent read and search but not edit
+		args = append(args, "--permission-mode", "plan", "--disallowedTools", "Edit,Write,NotebookEdit")
+	} else {
+		args = append(args, "--dangerously-skip-permissions")
+	}
+	args = append(args,
+		"--print",
+		"--output-format", "stream-json",
+		"--include-partial-messages",
+		"--verbose",
+	)
+	if opts.ResumeSession != "" {
+		args = append(args, "--resume", opts.ResumeSession)
+	} else if !opts.PersistSession {
+		args = append(args, "--no-session-persistence")
+	}
+	if model != "" {
+		args = append(args, "--model", model)
+	}
+	// Prompt is the final positional argument
+	return append(args, prompt)
+}
+
+// modelChain returns the models to try in order: the requested model (or the
+// agent default), then any fallbacks not already in the chain.
+// Always returns at least one entry; "" means the CLI default.
+func (a *ClaudeAgent) modelChain(requested string) []string {
+	primary := requested
+	if primary == "" {
+		primary = a.Model
+	}
+
+	chain := []string{primary}
+	seen := map[string]bool{primary: true}
+	for _, m := range a.FallbackModels {
+		if m == "" || seen[m] {
+			continue
+		}
+		seen[m] = true
+		chain = append(chain, m)
+	}
+	return chain
+}
+
+// retryableModelErrors are substrings of API errors that indicate the model
+// is temporarily unavailable rather than the task having failed.
+var retryableModelErrors = []string{
+	"rate limit",
+	"rate_limit",
+	"overloaded",
+	"too many requests",
+	"usage limit reached",
+	"api error: 429",
+	"api error: 529",
+}
+
+// isRetryableModelError reports whether msg describes a rate-limit or
+// overload error worth retrying on a different model.
+func isRetryableModelError(msg string) bool {
+	if msg == "" {
+		return false
+	}
+	lower := strings.ToLower(msg)
+	for _, s := range retryableModelErrors {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
 }
 
 // command returns the claude binary path.
@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// CodexAgent implements the Agent interface for the OpenAI Codex CLI.
+type CodexAgent struct {
+	// Command is the path to the codex binary. Defaults to "codex".
+	Command string
+
+	// Model specifies which model to use (e.g., "gpt-5-codex", "o4-mini").
+	// Empty string uses Codex's configured default.
+	Model string
+
+	// FullAuto uses the sandboxed --full-auto preset instead of
+	// --dangerously-bypass-approvals-and-sandbox.
+	FullAuto bool
+}
+
+// NewCodexAgent creates a new Codex agent with default settings.
+func NewCodexAgent() *CodexAgent {
+	return &CodexAgent{
+		Command: "codex",
+	}
+}
+
+// Name returns "codex".
+func (a *CodexAgent) Name() string {
+	return "codex"
+}
+
+// Available checks if the codex CLI is installed and accessible.
+func (a *CodexAgent) Available() bool {
+	_, err := exec.LookPath(a.command())
+	return err == nil
+}
+
+// Run executes codex with the given prompt and returns the result.
+// Uses `codex exec --json` for structured streaming.
+func (a *CodexAgent) Run(ctx context.Context, prompt string, opts RunOpts) (*Result, error) {
+	start := time.Now()
+
+	// Apply timeout if specified
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, a.command(), a.args(prompt, opts)...)
+
+	// Set working directory if specified
+	if opts.WorkDir != "" {
+		cmd.Dir = opts.WorkDir
+	}
+
+	var stderr bytes.Buffer
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("create stdout pipe: %w", err)
+	}
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start codex: %w", err)
+	}
+
+	// Create state and parser for structured streaming.
+	// Codex does not report the model in its event stream, so seed it
+	// from configuration for cost estimation and run records.
+	state := &AgentState{Model: a.model(opts)}
+	onUpdate := newUpdateNotifier(state, opts)
+
+	parser := NewCodexStreamParser(state, onUpdate)
+
+	// Parse JSONL output
+	parseErr := parser.Parse(stdoutPipe)
+
+	// Wait for command to complete
+	waitErr := cmd.Wait()
+
+	duration := time.Since(start)
+
+	// Handle errors - but capture partial output for timeouts
+	if waitErr != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return timeoutResult(state, opts.Timeout, duration), ErrTimeout
+		}
+		if ctx.Err() == context.Canceled {
+			return nil, fmt.Errorf("codex cancelled")
+		}
+		return nil, fmt.Errorf("codex exited with error: %w\nstderr: %s", waitErr, stderr.String())
+	}
+	if parseErr != nil {
+		return nil, fmt.Errorf("parse stream output: %w", parseErr)
+	}
+
+	return resultFromState(state, duration), nil
+}
+
+// args builds the codex exec arguments for a non-interactive run.
+func (a *CodexAgent) args(prompt string, opts RunOpts) []string {
+	args := []string{"exec", "--json"}
+
+	if opts.ReadOnly {
+		args = append(args, "--sandbox", "read-only")
+	} else if a.FullAuto {
+		args = append(args, "--full-auto")
+	} else {
+		args = append(args, "--dangerously-bypass-approvals-and-sandbox")
+	}
+
+	if model := a.model(opts); model != "" {
+		args = append(args, "--model", model)
+	}
+
+	if opts.WorkDir != "" {
+		args = append(args, "--cd", opts.WorkDir)
+	}
+
+	// Prompt is the final positional argument
+	return append(args, prompt)
+}
+
+// model returns the model for this run: RunOpts.Model, else the agent default.
+func (a *CodexAgent) model(opts RunOpts) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	return a.Model
+}
+
+// command returns the codex binary path.
+func (a *CodexAgent) command() string {
+	if a.Command != "" {
+		return a.Command
+	}
+	return "codex"
+}
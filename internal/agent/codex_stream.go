@@ -0,0 +1,278 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/budget"
+)
+
+// codexDefaultModel is assumed for cost estimation when no model is configured.
+const codexDefaultModel = "gpt-5-codex"
+
+// CodexStreamParser parses Codex's `exec --json` JSONL output and updates AgentState.
+type CodexStreamParser struct {
+	state    *AgentState
+	onUpdate func() // Called after each state change
+
+	// Track in-flight tool items by ID
+	activeItems map[string]*ToolActivity
+}
+
+// NewCodexStreamParser creates a parser that updates the given state.
+// The onUpdate callback is invoked after each state change (for TUI refresh).
+func NewCodexStreamParser(state *AgentState, onUpdate func()) *CodexStreamParser {
+	return &CodexStreamParser{
+		state:       state,
+		onUpdate:    onUpdate,
+		activeItems: make(map[string]*ToolActivity),
+	}
+}
+
+// Parse reads JSON lines from r and updates state.
+// Blocks until r is exhausted or returns an error.
+func (p *CodexStreamParser) Parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // 1MB max line
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		p.parseLine(line)
+	}
+
+	return scanner.Err()
+}
+
+// codexEvent is the envelope shared by all Codex JSONL events.
+type codexEvent struct {
+	Type     string          `json:"type"`
+	ThreadID string          `json:"thread_id"`
+	Item     json.RawMessage `json:"item"`
+	Usage    struct {
+		InputTokens       int `json:"input_tokens"`
+		CachedInputTokens int `json:"cached_input_tokens"`
+		OutputTokens      int `json:"output_tokens"`
+	} `json:"usage"`
+	Message string          `json:"message"`
+	Error   json.RawMessage `json:"error"`
+}
+
+// codexItem is the payload of item.* events. Fields are populated
+// depending on the item type.
+type codexItem struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+
+	// agent_message, reasoning
+	Text string `json:"text"`
+
+	// command_execution
+	Command          string `json:"command"`
+	AggregatedOutput string `json:"aggregated_output"`
+	ExitCode         *int   `json:"exit_code"`
+
+	// file_change
+	Changes []struct {
+		Path string `json:"path"`
+		Kind string `json:"kind"`
+	} `json:"changes"`
+
+	// mcp_tool_call
+	Server string `json:"server"`
+	Tool   string `json:"tool"`
+
+	// web_search
+	Query string `json:"query"`
+}
+
+func (p *CodexStreamParser) parseLine(line []byte) {
+	var event codexEvent
+	if err := json.Unmarshal(line, &event); err != nil {
+		return // Skip malformed lines
+	}
+
+	switch event.Type {
+	case "thread.started":
+		p.handleThreadStarted(event.ThreadID)
+
+	case "turn.started":
+		p.state.mu.Lock()
+		p.state.Status = StatusThinking
+		p.state.NumTurns++
+		p.state.mu.Unlock()
+		p.notify()
+
+	case "turn.completed":
+		p.handleTurnCompleted(event)
+
+	case "turn.failed", "error":
+		p.state.mu.Lock()
+		p.state.Status = StatusError
+		p.state.ErrorMsg = codexErrorMessage(event)
+		p.state.mu.Unlock()
+		p.notify()
+
+	case "item.started", "item.updated", "item.completed":
+		p.handleItem(event.Type, event.Item)
+	}
+}
+
+func (p *CodexStreamParser) handleThreadStarted(threadID string) {
+	p.state.mu.Lock()
+	p.state.SessionID = threadID
+	p.state.StartedAt = time.Now()
+	p.state.Status = StatusStarting
+	p.state.mu.Unlock()
+	p.notify()
+}
+
+func (p *CodexStreamParser) handleTurnCompleted(event codexEvent) {
+	p.state.mu.Lock()
+	p.state.Status = StatusComplete
+	p.state.Metrics.InputTokens += event.Usage.InputTokens
+	p.state.Metrics.OutputTokens += event.Usage.OutputTokens
+	p.state.Metrics.CacheReadTokens += event.Usage.CachedInputTokens
+	if !p.state.StartedAt.IsZero() {
+		p.state.Metrics.DurationMS = int(time.Since(p.state.StartedAt).Milliseconds())
+	}
+
+	// Codex doesn't report cost, so estimate it from token usage
+	model := p.state.Model
+	if model == "" {
+		model = codexDefaultModel
+	}
+	p.state.Metrics.CostUSD = budget.EstimateCostForModel(model,
+		p.state.Metrics.InputTokens, p.state.Metrics.OutputTokens)
+	p.state.mu.Unlock()
+	p.notify()
+}
+
+func (p *CodexStreamParser) handleItem(eventType string, itemData json.RawMessage) {
+	var item codexItem
+	if err := json.Unmarshal(itemData, &item); err != nil {
+		return
+	}
+
+	switch item.Type {
+	case "agent_message":
+		if eventType != "item.completed" {
+			return
+		}
+		p.state.mu.Lock()
+		if p.state.Output.Len() > 0 {
+			p.state.Output.WriteString("\n\n")
+		}
+		p.state.Output.WriteString(item.Text)
+		p.state.Status = StatusWriting
+		p.state.mu.Unlock()
+		p.notify()
+
+	case "reasoning":
+		if eventType != "item.completed" || item.Text == "" {
+			return
+		}
+		p.state.mu.Lock()
+		if p.state.Thinking.Len() > 0 {
+			p.state.Thinking.WriteString("\n\n")
+		}
+		p.state.Thinking.WriteString(item.Text)
+		p.state.Status = StatusThinking
+		p.state.mu.Unlock()
+		p.notify()
+
+	case "command_execution":
+		isError := item.ExitCode != nil && *item.ExitCode != 0
+		p.handleToolItem(eventType, item.ID, "command", item.Command, item.AggregatedOutput, isError || item.Status == "failed")
+
+	case "file_change":
+		changes := make([]string, 0, len(item.Changes))
+		for _, c := range item.Changes {
+			changes = append(changes, c.Kind+": "+c.Path)
+		}
+		p.handleToolItem(eventType, item.ID, "file_change", strings.Join(changes, ", "), "", item.Status == "failed")
+
+	case "mcp_tool_call":
+		p.handleToolItem(eventType, item.ID, "mcp", item.Server+"."+item.Tool, "", item.Status == "failed")
+
+	case "web_search":
+		p.handleToolItem(eventType, item.ID, "web_search", item.Query, "", false)
+	}
+}
+
+// handleToolItem tracks a tool-like item through its started/updated/completed
+// lifecycle. Items that only emit item.completed are recorded directly. The
+// parser's own activity is never shared: state gets a copy, so it can be
+// updated without holding state.mu.
+func (p *CodexStreamParser) handleToolItem(eventType, id, name, input, output string, isError bool) {
+	activity, ok := p.activeItems[id]
+	if !ok {
+		activity = &ToolActivity{
+			ID:        id,
+			Name:      name,
+			Input:     input,
+			StartedAt: time.Now(),
+		}
+	}
+	if output != "" {
+		activity.Output = output
+	}
+
+	switch eventType {
+	case "item.started", "item.updated":
+		p.activeItems[id] = activity
+
+		tool := *activity
+		p.state.mu.Lock()
+		p.state.ActiveTool = &tool
+		p.state.Status = StatusToolUse
+		p.state.mu.Unlock()
+		p.notify()
+
+	case "item.completed":
+		activity.Duration = time.Since(activity.StartedAt)
+		activity.IsError = isError
+		delete(p.activeItems, id)
+
+		p.state.mu.Lock()
+		p.state.ToolHistory = append(p.state.ToolHistory, *activity)
+		p.state.ActiveTool = nil
+		p.state.mu.Unlock()
+		p.notify()
+	}
+}
+
+// codexErrorMessage extracts an error message from turn.failed and error
+// events. Codex reports errors either as a top-level message, a string, or
+// an object with a message field.
+func codexErrorMessage(event codexEvent) string {
+	if len(event.Error) > 0 {
+		var s string
+		if err := json.Unmarshal(event.Error, &s); err == nil && s != "" {
+			return s
+		}
+		var obj struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(event.Error, &obj); err == nil && obj.Message != "" {
+			return obj.Message
+		}
+	}
+	if event.Message != "" {
+		return event.Message
+	}
+	return event.Type
+}
+
+// notify invokes the onUpdate callback if set.
+func (p *CodexStreamParser) notify() {
+	if p.onUpdate != nil {
+		p.onUpdate()
+	}
+}
@@ -0,0 +1,322 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodexAgent_Name(t *testing.T) {
+	agent := NewCodexAgent()
+	if got := agent.Name(); got != "codex" {
+		t.Errorf("Name() = %q, want %q", got, "codex")
+	}
+}
+
+func TestCodexAgent_Available_CustomCommand(t *testing.T) {
+	agent := &CodexAgent{Command: "nonexistent-codex-binary-xyz"}
+	if agent.Available() {
+		t.Error("Available() = true for nonexistent command, want false")
+	}
+}
+
+func TestCodexAgent_command(t *testing.T) {
+	if got := (&CodexAgent{}).command(); got != "codex" {
+		t.Errorf("command() = %q, want %q", got, "codex")
+	}
+	if got := (&CodexAgent{Command: "/opt/codex"}).command(); got != "/opt/codex" {
+		t.Errorf("command() = %q, want %q", got, "/opt/codex")
+	}
+}
+
+func TestCodexAgent_args(t *testing.T) {
+	tests := []struct {
+		name  string
+		agent *CodexAgent
+		opts  RunOpts
+		want  []string
+	}{
+		{
+			name:  "defaults",
+			agent: &CodexAgent{},
+			want:  []string{"exec", "--json", "--dangerously-bypass-approvals-and-sandbox", "do it"},
+		},
+		{
+			name:  "full auto with model and workdir",
+			agent: &CodexAgent{Model: "o4-mini", FullAuto: true},
+			opts:  RunOpts{WorkDir: "/tmp/work"},
+			want:  []string{"exec", "--json", "--full-auto", "--model", "o4-mini", "--cd", "/tmp/work", "do it"},
+		},
+		{
+			name:  "read only",
+			agent: &CodexAgent{FullAuto: true},
+			opts:  RunOpts{ReadOnly: true},
+			want:  []string{"exec", "--json", "--sandbox", "read-only", "do it"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(tt.agent.args("do it", tt.opts), " ")
+			want := strings.Join(tt.want, " ")
+			if got != want {
+				t.Errorf("args() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestCodexStreamParser_ThreadStarted(t *testing.T) {
+	state := &AgentState{}
+	parser := NewCodexStreamParser(state, nil)
+
+	parser.parseLine([]byte(`{"type":"thread.started","thread_id":"test-123"}`))
+
+	if state.SessionID != "test-123" {
+		t.Errorf("SessionID = %q, want %q", state.SessionID, "test-123")
+	}
+	if state.Status != StatusStarting {
+		t.Errorf("Status = %q, want %q", state.Status, StatusStarting)
+	}
+}
+
+func TestCodexStreamParser_CommandExecution(t *testing.T) {
+	state := &AgentState{}
+	parser := NewCodexStreamParser(state, nil)
+
+	parser.parseLine([]byte(`{"type":"item.started","item":{"id":"1","type":"command_execution","command":"ls","status":"in_progress"}}`))
+	if state.Status != StatusToolUse {
+		t.Errorf("Status = %q, want %q", state.Status, StatusToolUse)
+	}
+	if state.ActiveTool == nil || state.ActiveTool.Input != "ls" {
+		t.Fatalf("ActiveTool = %+v, want command ls", state.ActiveTool)
+	}
+
+	parser.parseLine([]byte(`{"type":"item.completed","item":{"id":"1","type":"command_execution","command":"ls","aggregated_output":"file.go","exit_code":2,"status":"failed"}}`))
+	if state.ActiveTool != nil {
+		t.Error("ActiveTool should be nil after completion")
+	}
+	if len(state.ToolHistory) != 1 {
+		t.Fatalf("len(ToolHistory) = %d, want 1", len(state.ToolHistory))
+	}
+	tool := state.ToolHistory[0]
+	if tool.Output != "file.go" {
+		t.Errorf("Output = %q, want %q", tool.Output, "file.go")
+	}
+	if !tool.IsError {
+		t.Error("IsError = false for non-zero exit code, want true")
+	}
+}
+
+func TestCodexStreamParser_ConcurrentSnapshots(t *testing.T) {
+	state := &AgentState{}
+	parser := NewCodexStreamParser(state, nil)
+
+	var lines []string
+	for i := range 50 {
+		id := fmt.Sprint(i)
+		lines = append(lines,
+			`{"type":"item.started","item":{"id":"`+id+`","type":"command_execution","command":"ls","status":"in_progress"}}`,
+			`{"type":"item.updated","item":{"id":"`+id+`","type":"command_execution","command":"ls","aggregated_output":"a","status":"in_progress"}}`,
+			`{"type":"item.completed","item":{"id":"`+id+`","type":"command_execution","command":"ls","aggregated_output":"ab","exit_code":0,"status":"completed"}}`,
+		)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- parser.Parse(strings.NewReader(strings.Join(lines, "\n")))
+	}()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(state.ToolHistory) != 50 {
+				t.Errorf("len(ToolHistory) = %d, want 50", len(state.ToolHistory))
+			}
+			return
+		default:
+			state.Snapshot()
+		}
+	}
+}
+
+func TestCodexStreamParser_CompletedOnlyItems(t *testing.T) {
+	state := &AgentState{}
+	parser := NewCodexStreamParser(state, nil)
+
+	parser.parseLine([]byte(`{"type":"item.completed","item":{"id":"2","type":"file_change","changes":[{"path":"a.go","kind":"add"},{"path":"b.go","kind":"update"}],"status":"completed"}}`))
+	parser.parseLine([]byte(`{"type":"item.completed","item":{"id":"3","type":"mcp_tool_call","server":"docs","tool":"search","status":"completed"}}`))
+
+	if len(state.ToolHistory) != 2 {
+		t.Fatalf("len(ToolHistory) = %d, want 2", len(state.ToolHistory))
+	}
+	if got := state.ToolHistory[0].Input; got != "add: a.go, update: b.go" {
+		t.Errorf("file_change Input = %q, want %q", got, "add: a.go, update: b.go")
+	}
+	if got := state.ToolHistory[1].Input; got != "docs.search" {
+		t.Errorf("mcp_tool_call Input = %q, want %q", got, "docs.search")
+	}
+}
+
+func TestCodexStreamParser_TurnCompleted(t *testing.T) {
+	state := &AgentState{Model: "o4-mini"}
+	parser := NewCodexStreamParser(state, nil)
+
+	parser.parseLine([]byte(`{"type":"turn.completed","usage":{"input_tokens":1000000,"cached_input_tokens":500,"output_tokens":1000000}}`))
+
+	if state.Status != StatusComplete {
+		t.Errorf("Status = %q, want %q", state.Status, StatusComplete)
+	}
+	if state.Metrics.InputTokens != 1000000 {
+		t.Errorf("InputTokens = %d, want %d", state.Metrics.InputTokens, 1000000)
+	}
+	if state.Metrics.CacheReadTokens != 500 {
+		t.Errorf("CacheReadTokens = %d, want %d", state.Metrics.CacheReadTokens, 500)
+	}
+	// o4-mini: $1.10 in + $4.40 out per 1M tokens
+	if state.Metrics.CostUSD < 5.49 || state.Metrics.CostUSD > 5.51 {
+		t.Errorf("CostUSD = %f, want 5.50", state.Metrics.CostUSD)
+	}
+}
+
+func TestCodexStreamParser_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"turn failed object", `{"type":"turn.failed","error":{"message":"rate limited"}}`, "rate limited"},
+		{"error string", `{"type":"error","error":"boom"}`, "boom"},
+		{"error message", `{"type":"error","message":"stream disconnected"}`, "stream disconnected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &AgentState{}
+			parser := NewCodexStreamParser(state, nil)
+			parser.parseLine([]byte(tt.line))
+
+			if state.Status != StatusError {
+				t.Errorf("Status = %q, want %q", state.Status, StatusError)
+			}
+			if state.ErrorMsg != tt.want {
+				t.Errorf("ErrorMsg = %q, want %q", state.ErrorMsg, tt.want)
+			}
+		})
+	}
+}
+
+// writeFakeCodex writes a shell script that records its arguments and
+// replays the recorded codex JSONL fixture on stdout.
+func writeFakeCodex(t *testing.T) (bin, argsFile string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake codex binary requires a POSIX shell")
+	}
+
+	fixture, err := filepath.Abs(filepath.Join("testdata", "codex_exec.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	bin = filepath.Join(dir, "codex")
+	argsFile = filepath.Join(dir, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > '" + argsFile + "'\ncat '" + fixture + "'\n"
+	if err := os.WriteFile(bin, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return bin, argsFile
+}
+
+func TestCodexAgent_Run_FakeBinary(t *testing.T) {
+	bin, argsFile := writeFakeCodex(t)
+	agent := &CodexAgent{Command: bin, Model: "gpt-5-codex"}
+
+	var updates int
+	workDir := t.TempDir()
+	result, err := agent.Run(context.Background(), "fix the bug", RunOpts{
+		WorkDir:       workDir,
+		StateCallback: func(AgentStateSnapshot) { updates++ },
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if !strings.Contains(result.Output, "<promise>COMPLETE</promise>") {
+		t.Errorf("Output = %q, want COMPLETE signal", result.Output)
+	}
+	if result.TokensIn != 24763 || result.TokensOut != 122 {
+		t.Errorf("tokens = %d/%d, want 24763/122", result.TokensIn, result.TokensOut)
+	}
+	if result.Cost <= 0 {
+		t.Errorf("Cost = %f, want > 0", result.Cost)
+	}
+	if updates == 0 {
+		t.Error("StateCallback was never called")
+	}
+
+	record := result.Record
+	if record == nil {
+		t.Fatal("Record is nil")
+	}
+	if record.SessionID != "0199a213-81c0-7800-8aa1-bbab2a035a53" {
+		t.Errorf("Record.SessionID = %q", record.SessionID)
+	}
+	if record.Model != "gpt-5-codex" {
+		t.Errorf("Record.Model = %q, want %q", record.Model, "gpt-5-codex")
+	}
+	if !record.Success {
+		t.Error("Record.Success = false, want true")
+	}
+	if len(record.Tools) != 2 {
+		t.Errorf("len(Record.Tools) = %d, want 2", len(record.Tools))
+	}
+	if record.Thinking != "**Inspecting the repository layout**" {
+		t.Errorf("Record.Thinking = %q", record.Thinking)
+	}
+
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantArgs := "exec\n--json\n--dangerously-bypass-approvals-and-sandbox\n--model\ngpt-5-codex\n--cd\n" + workDir + "\nfix the bug\n"
+	if string(args) != wantArgs {
+		t.Errorf("codex args = %q, want %q", args, wantArgs)
+	}
+}
+
+func TestCodexAgent_Run_Timeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake codex binary requires a POSIX shell")
+	}
+
+	bin := filepath.Join(t.TempDir(), "codex")
+	script := "#!/bin/sh\necho '{\"type\":\"thread.started\",\"thread_id\":\"t1\"}'\nexec sleep 10\n"
+	if err := os.WriteFile(bin, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := &CodexAgent{Command: bin}
+	result, err := agent.Run(context.Background(), "slow", RunOpts{Timeout: 200 * time.Millisecond})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Run() error = %v, want ErrTimeout", err)
+	}
+	if result == nil || result.Record == nil {
+		t.Fatal("Run() should return partial result on timeout")
+	}
+	if result.Record.SessionID != "t1" {
+		t.Errorf("Record.SessionID = %q, want %q", result.Record.SessionID, "t1")
+	}
+	if result.Record.Success {
+		t.Error("Record.Success = true on timeout, want false")
+	}
+}
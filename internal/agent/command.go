@@ -0,0 +1,430 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/budget"
+)
+
+// Prompt delivery modes for CommandAgent.
+const (
+	PromptViaArgv  = "argv"
+	PromptViaStdin = "stdin"
+)
+
+// Output formats for CommandAgent.
+const (
+	OutputText  = "text"
+	OutputJSONL = "jsonl"
+)
+
+// Placeholders substituted into CommandAgent args.
+const (
+	placeholderPrompt  = "{{prompt}}"
+	placeholderWorkDir = "{{workdir}}"
+	placeholderModel   = "{{model}}"
+)
+
+// CommandConfig configures a generic CLI agent from .ticker/config.json.
+//
+// Example (aider):
+//
+//	{
+//	  "command": "aider",
+//	  "args": ["--yes-always", "--no-stream", "--message", "{{prompt}}"],
+//	  "output": "text"
+//	}
+type CommandConfig struct {
+	// Command is the binary to execute (required).
+	Command string `json:"command"`
+
+	// Args are passed to the command. {{prompt}}, {{workdir}} and {{model}}
+	// are replaced with the iteration's prompt, working directory and model.
+	Args []string `json:"args,omitempty"`
+
+	// PromptVia is "argv" or "stdin". Defaults to "argv" when Args contain
+	// {{prompt}}, otherwise "stdin".
+	PromptVia *string `json:"prompt_via,omitempty"`
+
+	// Output is "text" (stdout is the response) or "jsonl" (one JSON event
+	// per line, mapped via Fields). Default "text".
+	Output *string `json:"output,omitempty"`
+
+	// Fields maps JSONL event fields to agent state (jsonl output only).
+	Fields *CommandFields `json:"fields,omitempty"`
+
+	// Model is substituted for {{model}} and used to estimate cost when the
+	// output doesn't report one.
+	Model *string `json:"model,omitempty"`
+}
+
+// CommandFields maps JSONL event fields to agent state. Each value is a
+// dot-separated path into the event object (e.g. "usage.input_tokens").
+// Empty paths are ignored.
+type CommandFields struct {
+	// Text is appended to the output as it arrives.
+	Text string `json:"text,omitempty"`
+
+	// Thinking is appended to the thinking stream.
+	Thinking string `json:"thinking,omitempty"`
+
+	// TokensIn, TokensOut and Cost are totals; the last value seen wins.
+	TokensIn  string `json:"tokens_in,omitempty"`
+	TokensOut string `json:"tokens_out,omitempty"`
+	Cost      string `json:"cost,omitempty"`
+
+	// SessionID identifies the agent session, if the CLI reports one.
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// GetPromptVia returns how the prompt is delivered (default depends on Args).
+func (c *CommandConfig) GetPromptVia() string {
+	if c.PromptVia != nil && *c.PromptVia != "" {
+		return *c.PromptVia
+	}
+	if c.hasPromptArg() {
+		return PromptViaArgv
+	}
+	return PromptViaStdin
+}
+
+// hasPromptArg reports whether any of Args contains {{prompt}}.
+func (c *CommandConfig) hasPromptArg() bool {
+	for _, arg := range c.Args {
+		if strings.Contains(arg, placeholderPrompt) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetOutput returns the output format (default "text").
+func (c *CommandConfig) GetOutput() string {
+	if c.Output == nil || *c.Output == "" {
+		return OutputText
+	}
+	return *c.Output
+}
+
+// Validate checks that the command config is usable.
+func (c *CommandConfig) Validate() error {
+	if c.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+	switch c.GetPromptVia() {
+	case PromptViaArgv:
+		// The prompt would never reach the agent
+		if !c.hasPromptArg() {
+			return fmt.Errorf("prompt_via %q requires an arg containing %s", PromptViaArgv, placeholderPrompt)
+		}
+	case PromptViaStdin:
+	default:
+		return fmt.Errorf("prompt_via must be %q or %q, got %q", PromptViaArgv, PromptViaStdin, c.GetPromptVia())
+	}
+	switch c.GetOutput() {
+	case OutputText:
+	case OutputJSONL:
+		if c.Fields == nil || c.Fields.Text == "" {
+			return fmt.Errorf("jsonl output requires fields.text")
+		}
+	default:
+		return fmt.Errorf("output must be %q or %q, got %q", OutputText, OutputJSONL, c.GetOutput())
+	}
+	return nil
+}
+
+// CommandAgent implements the Agent interface for an arbitrary CLI agent
+// described by a CommandConfig.
+type CommandAgent struct {
+	name   string
+	config CommandConfig
+}
+
+// NewCommandAgent creates a command agent registered under name.
+func NewCommandAgent(name string, cfg CommandConfig) *CommandAgent {
+	return &CommandAgent{name: name, config: cfg}
+}
+
+// Name returns the configured agent name.
+func (a *CommandAgent) Name() string {
+	return a.name
+}
+
+// Available checks if the configured command is installed and accessible.
+func (a *CommandAgent) Available() bool {
+	if a.config.Command == "" {
+		return false
+	}
+	_, err := exec.LookPath(a.config.Command)
+	return err == nil
+}
+
+// CanRunReadOnly returns false: nothing stops an arbitrary command from
+// writing files.
+func (a *CommandAgent) CanRunReadOnly() bool {
+	return false
+}
+
+// Run executes the configured command with the given prompt.
+func (a *CommandAgent) Run(ctx context.Context, prompt string, opts RunOpts) (*Result, error) {
+	if opts.ReadOnly {
+		return nil, fmt.Errorf("%s: %w", a.name, ErrReadOnlyUnsupported)
+	}
+
+	start := time.Now()
+
+	// Apply timeout if specified
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, a.config.Command, a.args(prompt, opts)...)
+
+	// Set working directory if specified
+	if opts.WorkDir != "" {
+		cmd.Dir = opts.WorkDir
+	}
+
+	if a.config.GetPromptVia() == PromptViaStdin {
+		cmd.Stdin = strings.NewReader(prompt)
+	}
+
+	var stderr bytes.Buffer
+
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("create stdout pipe: %w", err)
+	}
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", a.name, err)
+	}
+
+	state := &AgentState{StartedAt: time.Now(), Status: StatusStarting, Model: a.model(opts)}
+	onUpdate := newUpdateNotifier(state, opts)
+
+	parser := &commandOutputParser{
+		state:    state,
+		onUpdate: onUpdate,
+		format:   a.config.GetOutput(),
+		fields:   a.config.Fields,
+	}
+
+	parseErr := parser.Parse(stdoutPipe)
+
+	// Wait for command to complete
+	waitErr := cmd.Wait()
+
+	duration := time.Since(start)
+	parser.finish(duration)
+
+	// Handle errors - but capture partial output for timeouts
+	if waitErr != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return timeoutResult(state, opts.Timeout, duration), ErrTimeout
+		}
+		if ctx.Err() == context.Canceled {
+			return nil, fmt.Errorf("%s cancelled", a.name)
+		}
+		return nil, fmt.Errorf("%s exited with error: %w\nstderr: %s", a.name, waitErr, stderr.String())
+	}
+	if parseErr != nil {
+		return nil, fmt.Errorf("parse %s output: %w", a.name, parseErr)
+	}
+
+	return resultFromState(state, duration), nil
+}
+
+// args expands placeholders in the configured args.
+func (a *CommandAgent) args(prompt string, opts RunOpts) []string {
+	replacer := strings.NewReplacer(
+		placeholderPrompt, prompt,
+		placeholderWorkDir, opts.WorkDir,
+		placeholderModel, a.model(opts),
+	)
+
+	args := make([]string, len(a.config.Args))
+	for i, arg := range a.config.Args {
+		args[i] = replacer.Replace(arg)
+	}
+	return args
+}
+
+// model returns the model for this run: RunOpts.Model, else the configured one.
+func (a *CommandAgent) model(opts RunOpts) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	if a.config.Model != nil {
+		return *a.config.Model
+	}
+	return ""
+}
+
+// commandOutputParser turns a command agent's stdout into AgentState updates.
+type commandOutputParser struct {
+	state    *AgentState
+	onUpdate func()
+	format   string
+	fields   *CommandFields
+
+	sawCost bool
+}
+
+// Parse reads r until EOF, updating state after each line.
+func (p *commandOutputParser) Parse(r io.Reader) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			if p.format == OutputJSONL {
+				p.parseJSONLine([]byte(line))
+			} else {
+				p.appendText(line)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// appendText adds raw stdout text to the output stream.
+func (p *commandOutputParser) appendText(text string) {
+	p.state.mu.Lock()
+	p.state.Output.WriteString(text)
+	p.state.Status = StatusWriting
+	p.state.mu.Unlock()
+	p.notify()
+}
+
+// parseJSONLine applies the field mapping to a single JSONL event.
+// Lines that aren't JSON objects are ignored.
+func (p *commandOutputParser) parseJSONLine(line []byte) {
+	line = bytes.TrimSpace(line)
+	if len(line) == 0 {
+		return
+	}
+	var event map[string]any
+	if err := json.Unmarshal(line, &event); err != nil {
+		return
+	}
+
+	f := p.fields
+	changed := false
+
+	p.state.mu.Lock()
+	if s, ok := lookupString(event, f.Text); ok && s != "" {
+		p.state.Output.WriteString(s)
+		p.state.Status = StatusWriting
+		changed = true
+	}
+	if s, ok := lookupString(event, f.Thinking); ok && s != "" {
+		p.state.Thinking.WriteString(s)
+		p.state.Status = StatusThinking
+		changed = true
+	}
+	if s, ok := lookupString(event, f.SessionID); ok && s != "" {
+		p.state.SessionID = s
+		changed = true
+	}
+	if n, ok := lookupNumber(event, f.TokensIn); ok {
+		p.state.Metrics.InputTokens = int(n)
+		changed = true
+	}
+	if n, ok := lookupNumber(event, f.TokensOut); ok {
+		p.state.Metrics.OutputTokens = int(n)
+		changed = true
+	}
+	if n, ok := lookupNumber(event, f.Cost); ok {
+		p.state.Metrics.CostUSD = n
+		p.sawCost = true
+		changed = true
+	}
+	p.state.mu.Unlock()
+
+	if changed {
+		p.notify()
+	}
+}
+
+// finish marks the run complete and estimates cost if the output didn't
+// report one but a model is known.
+func (p *commandOutputParser) finish(duration time.Duration) {
+	p.state.mu.Lock()
+	p.state.Status = StatusComplete
+	p.state.NumTurns = 1
+	p.state.Metrics.DurationMS = int(duration.Milliseconds())
+	if !p.sawCost && p.state.Model != "" {
+		p.state.Metrics.CostUSD = budget.EstimateCostForModel(p.state.Model,
+			p.state.Metrics.InputTokens, p.state.Metrics.OutputTokens)
+	}
+	p.state.mu.Unlock()
+	p.notify()
+}
+
+// notify invokes the onUpdate callback if set.
+func (p *commandOutputParser) notify() {
+	if p.onUpdate != nil {
+		p.onUpdate()
+	}
+}
+
+// lookupPath walks a dot-separated path through nested JSON objects.
+func lookupPath(event map[string]any, path string) (any, bool) {
+	if path == "" {
+		return nil, false
+	}
+	var cur any = event
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// lookupString returns the string at path.
+func lookupString(event map[string]any, path string) (string, bool) {
+	v, ok := lookupPath(event, path)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// lookupNumber returns the number at path, accepting numeric strings.
+func lookupNumber(event map[string]any, path string) (float64, bool) {
+	v, ok := lookupPath(event, path)
+	if !ok {
+		return 0, false
+	}
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,249 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeScript writes an executable shell script to a temp dir.
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake agent scripts require a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "agent.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestCommandConfig_GetPromptVia(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  CommandConfig
+		want string
+	}{
+		{"prompt placeholder implies argv", CommandConfig{Args: []string{"--message", "{{prompt}}"}}, PromptViaArgv},
+		{"no placeholder implies stdin", CommandConfig{Args: []string{"--quiet"}}, PromptViaStdin},
+		{"explicit stdin", CommandConfig{Args: []string{"{{prompt}}"}, PromptVia: strPtr("stdin")}, PromptViaStdin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.GetPromptVia(); got != tt.want {
+				t.Errorf("GetPromptVia() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CommandConfig
+		wantErr bool
+	}{
+		{"minimal", CommandConfig{Command: "aider"}, false},
+		{"missing command", CommandConfig{}, true},
+		{"bad prompt_via", CommandConfig{Command: "x", PromptVia: strPtr("file")}, true},
+		{"argv without prompt arg", CommandConfig{Command: "x", Args: []string{"--quiet"}, PromptVia: strPtr("argv")}, true},
+		{"argv with prompt arg", CommandConfig{Command: "x", Args: []string{"--message={{prompt}}"}, PromptVia: strPtr("argv")}, false},
+		{"bad output", CommandConfig{Command: "x", Output: strPtr("xml")}, true},
+		{"jsonl without text field", CommandConfig{Command: "x", Output: strPtr("jsonl")}, true},
+		{"jsonl with text field", CommandConfig{Command: "x", Output: strPtr("jsonl"), Fields: &CommandFields{Text: "text"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCommandAgent_args(t *testing.T) {
+	a := NewCommandAgent("local", CommandConfig{
+		Command: "llm",
+		Args:    []string{"-m", "{{model}}", "--cwd={{workdir}}", "{{prompt}}"},
+		Model:   strPtr("gpt-4o"),
+	})
+	got := strings.Join(a.args("hi", RunOpts{WorkDir: "/w"}), " ")
+	want := "-m gpt-4o --cwd=/w hi"
+	if got != want {
+		t.Errorf("args() = %q, want %q", got, want)
+	}
+}
+
+func TestCommandAgent_Run_TextStdin(t *testing.T) {
+	// Echo the prompt back from stdin and emit a completion signal
+	script := writeScript(t, "echo \"got: $(cat)\"\necho '<promise>COMPLETE</promise>'\n")
+	a := NewCommandAgent("echoer", CommandConfig{Command: script})
+
+	var snaps []AgentStateSnapshot
+	result, err := a.Run(context.Background(), "do the thing", RunOpts{
+		StateCallback: func(s AgentStateSnapshot) { snaps = append(snaps, s) },
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := "got: do the thing\n<promise>COMPLETE</promise>\n"
+	if result.Output != want {
+		t.Errorf("Output = %q, want %q", result.Output, want)
+	}
+	if len(snaps) < 2 {
+		t.Errorf("StateCallback called %d times, want streaming updates", len(snaps))
+	}
+	if result.Record == nil || !result.Record.Success {
+		t.Error("Record.Success = false, want true")
+	}
+}
+func TestCommandAgent_Run_JSONL(t *testing.T) {
+	script := writeScript(t, `cat <<'JSONL'
+{"type":"start","session":"s-42"}
+{"type":"delta","message":{"text":"Hello "}}
+not json
+{"type":"delta","message":{"text":"world"},"reasoning":"hmm"}
+{"type":"done","usage":{"in":1200,"out":"300"},"cost_usd":0.02}
+JSONL
+`)
+	a := NewCommandAgent("jsonish", CommandConfig{
+		Command: script,
+		Output:  strPtr(OutputJSONL),
+		Fields: &CommandFields{
+			Text:      "message.text",
+			Thinking:  "reasoning",
+			TokensIn:  "usage.in",
+			TokensOut: "usage.out",
+			Cost:      "cost_usd",
+			SessionID: "session",
+		},
+	})
+
+	result, err := a.Run(context.Background(), "prompt", RunOpts{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Output != "Hello world" {
+		t.Errorf("Output = %q, want %q", result.Output, "Hello world")
+	}
+	if result.TokensIn != 1200 || result.TokensOut != 300 {
+		t.Errorf("tokens = %d/%d, want 1200/300", result.TokensIn, result.TokensOut)
+	}
+	if result.Cost != 0.02 {
+		t.Errorf("Cost = %f, want 0.02", result.Cost)
+	}
+	if result.Record.SessionID != "s-42" {
+		t.Errorf("SessionID = %q, want %q", result.Record.SessionID, "s-42")
+	}
+	if result.Record.Thinking != "hmm" {
+		t.Errorf("Thinking = %q, want %q", result.Record.Thinking, "hmm")
+	}
+}
+
+func TestCommandAgent_Run_EstimatesCostFromModel(t *testing.T) {
+	script := writeScript(t, `echo '{"text":"ok","in":1000000,"out":0}'`+"\n")
+	a := NewCommandAgent("est", CommandConfig{
+		Command: script,
+		Output:  strPtr(OutputJSONL),
+		Fields:  &CommandFields{Text: "text", TokensIn: "in", TokensOut: "out"},
+		Model:   strPtr("gpt-4o"),
+	})
+
+	result, err := a.Run(context.Background(), "prompt", RunOpts{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	// gpt-4o: $2.50 per 1M input tokens
+	if result.Cost < 2.49 || result.Cost > 2.51 {
+		t.Errorf("Cost = %f, want 2.50", result.Cost)
+	}
+}
+
+func TestCommandAgent_Run_Failure(t *testing.T) {
+	script := writeScript(t, "echo 'bad things' >&2\nexit 3\n")
+	a := NewCommandAgent("failing", CommandConfig{Command: script})
+
+	_, err := a.Run(context.Background(), "prompt", RunOpts{})
+	if err == nil {
+		t.Fatal("Run() should return error for non-zero exit")
+	}
+	if !strings.Contains(err.Error(), "bad things") {
+		t.Errorf("error = %q, want stderr included", err)
+	}
+}
+
+func TestCommandAgent_Run_Timeout(t *testing.T) {
+	script := writeScript(t, "echo partial\nexec sleep 10\n")
+	a := NewCommandAgent("slow", CommandConfig{Command: script})
+
+	result, err := a.Run(context.Background(), "prompt", RunOpts{Timeout: 200 * time.Millisecond})
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Run() error = %v, want ErrTimeout", err)
+	}
+	if result == nil || result.Output != "partial\n" {
+		t.Errorf("partial result = %+v, want output %q", result, "partial\n")
+	}
+}
+
+func TestCommandAgent_Run_ReadOnly(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	script := writeScript(t, "touch "+marker+"\n")
+	a := NewCommandAgent("writer", CommandConfig{Command: script})
+
+	_, err := a.Run(context.Background(), "prompt", RunOpts{ReadOnly: true})
+	if !errors.Is(err, ErrReadOnlyUnsupported) {
+		t.Fatalf("Run() error = %v, want ErrReadOnlyUnsupported", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Run() ran the command for a read-only run")
+	}
+}
+
+func TestNewRegistry_CommandAgents(t *testing.T) {
+	r := NewRegistry(&Config{
+		Commands: map[string]*CommandConfig{
+			"aider": {Command: "aider", Args: []string{"--message", "{{prompt}}"}},
+		},
+	})
+
+	a, err := r.Get("aider")
+	if err != nil {
+		t.Fatalf("Get(aider) error = %v", err)
+	}
+	if _, ok := a.(*CommandAgent); !ok {
+		t.Errorf("Get(aider) = %T, want *CommandAgent", a)
+	}
+	if a.Name() != "aider" {
+		t.Errorf("Name() = %q, want %q", a.Name(), "aider")
+	}
+}
+
+func TestConfig_Validate_CommandAgents(t *testing.T) {
+	shadow := &Config{Commands: map[string]*CommandConfig{"claude": {Command: "x"}}}
+	if err := shadow.Validate(); err == nil {
+		t.Error("Validate() should reject command agent named like a built-in")
+	}
+
+	invalid := &Config{Commands: map[string]*CommandConfig{"x": {}}}
+	if err := invalid.Validate(); err == nil {
+		t.Error("Validate() should reject command agent without command")
+	}
+
+	def := "aider"
+	ok := &Config{Default: &def, Commands: map[string]*CommandConfig{"aider": {Command: "aider"}}}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
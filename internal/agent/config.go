@@ -0,0 +1,177 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultAgentName is the agent used when nothing else selects one.
+const DefaultAgentName = "claude"
+
+// BuiltinAgents are the names of the built-in agents, in order of preference.
+var BuiltinAgents = []string{"claude", "codex"}
+
+// Config holds agent selection configuration from the "agent" section of
+// .ticker/config.json.
+//
+// Example:
+//
+//	{
+//	  "agent": {
+//	    "default": "claude",
+//	    "continue_sessions": true,
+//	    "claude": {"model": "opus", "fallback_models": ["sonnet"]},
+//	    "codex": {"model": "o4-mini", "full_auto": true},
+//	    "commands": {
+//	      "aider": {"command": "aider", "args": ["--yes-always", "--message", "{{prompt}}"]}
+//	    }
+//	  }
+//	}
+type Config struct {
+	// Default is the agent used when neither the task nor its epic selects
+	// one (default "claude").
+	Default *string `json:"default,omitempty"`
+
+	// ContinueSessions resumes the agent's previous session when a task is
+	// retried, sending a short follow-up prompt instead of the full one
+	// (default false). Only agents that can resume sessions are affected.
+	ContinueSessions *bool `json:"continue_sessions,omitempty"`
+
+	// RecordTranscripts saves the raw output stream of every iteration next
+	// to the run log, for `ticker replay` (default false).
+	RecordTranscripts *bool `json:"record_transcripts,omitempty"`
+
+	// Claude configures the built-in claude backend.
+	Claude *ClaudeConfig `json:"claude,omitempty"`
+
+	// Codex configures the built-in codex backend.
+	Codex *CodexConfig `json:"codex,omitempty"`
+
+	// Commands defines additional agents backed by arbitrary CLIs, keyed by
+	// the name used to select them.
+	Commands map[string]*CommandConfig `json:"commands,omitempty"`
+}
+
+// ClaudeConfig holds settings for the claude backend.
+type ClaudeConfig struct {
+	// Command overrides the claude binary path (default "claude").
+	Command *string `json:"command,omitempty"`
+
+	// Model is passed to claude via --model (default "" = CLI default).
+	Model *string `json:"model,omitempty"`
+
+	// FallbackModels are tried in order when the model is rate-limited or
+	// overloaded (e.g. ["sonnet", "haiku"]).
+	FallbackModels []string `json:"fallback_models,omitempty"`
+}
+
+// CodexConfig holds settings for the codex backend.
+type CodexConfig struct {
+	// Command overrides the codex binary path (default "codex").
+	Command *string `json:"command,omitempty"`
+
+	// Model is passed to codex via --model (default "" = codex's default).
+	Model *string `json:"model,omitempty"`
+
+	// FullAuto selects the sandboxed --full-auto preset (default false).
+	FullAuto *bool `json:"full_auto,omitempty"`
+}
+
+// GetDefault returns the default agent name (default "claude").
+func (c *Config) GetDefault() string {
+	if c == nil || c.Default == nil || *c.Default == "" {
+		return DefaultAgentName
+	}
+	return *c.Default
+}
+
+// GetContinueSessions returns whether retries resume the previous session (default false).
+func (c *Config) GetContinueSessions() bool {
+	return c != nil && c.ContinueSessions != nil && *c.ContinueSessions
+}
+
+// GetRecordTranscripts returns whether iteration transcripts are recorded (default false).
+func (c *Config) GetRecordTranscripts() bool {
+	return c != nil && c.RecordTranscripts != nil && *c.RecordTranscripts
+}
+
+// Validate checks that command agents are well-formed and don't shadow
+// built-in agents, and that the configured default agent is known to the
+// registry built from this config. Returns nil if valid.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	for name, cmd := range c.Commands {
+		if slices.Contains(BuiltinAgents, name) {
+			return fmt.Errorf("command agent %q conflicts with built-in agent", name)
+		}
+		if cmd == nil {
+			return fmt.Errorf("command agent %q: missing config", name)
+		}
+		if err := cmd.Validate(); err != nil {
+			return fmt.Errorf("command agent %q: %w", name, err)
+		}
+	}
+	r := NewRegistry(c)
+	if !r.Has(c.GetDefault()) {
+		return fmt.Errorf("unknown default agent %q (available: %s)", c.GetDefault(), r.namesList())
+	}
+	return nil
+}
+
+// ValidateReadOnlyAgent checks that name is known to the registry built from
+// this config and can run read-only (see CanRunReadOnly), e.g. for use as a
+// reviewer. Scripted agents are accepted without loading their scenario.
+func (c *Config) ValidateReadOnlyAgent(name string) error {
+	if strings.HasPrefix(name, ScriptedPrefix) {
+		return nil
+	}
+	r := NewRegistry(c)
+	if !r.Has(name) {
+		return fmt.Errorf("unknown agent %q (available: %s)", name, r.namesList())
+	}
+	a, err := r.Get(name)
+	if err != nil {
+		return err
+	}
+	if !CanRunReadOnly(a) {
+		return fmt.Errorf("agent %q cannot run read-only", name)
+	}
+	return nil
+}
+
+// newClaudeFromConfig creates a ClaudeAgent with settings from cfg applied.
+func newClaudeFromConfig(cfg *ClaudeConfig) *ClaudeAgent {
+	a := NewClaudeAgent()
+	if cfg == nil {
+		return a
+	}
+	if cfg.Command != nil && *cfg.Command != "" {
+		a.Command = *cfg.Command
+	}
+	if cfg.Model != nil {
+		a.Model = *cfg.Model
+	}
+	a.FallbackModels = append([]string(nil), cfg.FallbackModels...)
+	return a
+}
+
+// newCodexFromConfig creates a CodexAgent with settings from cfg applied.
+func newCodexFromConfig(cfg *CodexConfig) *CodexAgent {
+	a := NewCodexAgent()
+	if cfg == nil {
+		return a
+	}
+	if cfg.Command != nil && *cfg.Command != "" {
+		a.Command = *cfg.Command
+	}
+	if cfg.Model != nil {
+		a.Model = *cfg.Model
+	}
+	if cfg.FullAuto != nil {
+		a.FullAuto = *cfg.FullAuto
+	}
+	return a
+}
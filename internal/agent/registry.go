@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Factory creates a new Agent instance.
+type Factory func() Agent
+
+// Registry maps agent names to factories and resolves which agent
+// should run a given piece of work. It is safe for concurrent use.
+type Registry struct {
+	mu          sync.RWMutex
+	factories   map[string]Factory
+	defaultName string
+}
+
+// NewRegistry creates a registry with the built-in backends (claude, codex)
+// and any command agents, configured from cfg. A nil cfg uses defaults for
+// everything.
+func NewRegistry(cfg *Config) *Registry {
+	r := &Registry{
+		factories:   make(map[string]Factory),
+		defaultName: cfg.GetDefault(),
+	}
+
+	var claudeCfg *ClaudeConfig
+	var codexCfg *CodexConfig
+	if cfg != nil {
+		claudeCfg = cfg.Claude
+		codexCfg = cfg.Codex
+	}
+
+	r.Register("claude", func() Agent { return newClaudeFromConfig(claudeCfg) })
+	r.Register("codex", func() Agent { return newCodexFromConfig(codexCfg) })
+
+	if cfg != nil {
+		for name, cmdCfg := range cfg.Commands {
+			if cmdCfg == nil {
+				continue
+			}
+			cmdCfg := *cmdCfg
+			r.Register(name, func() Agent { return NewCommandAgent(name, cmdCfg) })
+		}
+	}
+
+	return r
+}
+
+// Register adds or replaces the factory for name.
+func (r *Registry) Register(name string, f Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[name] = f
+}
+
+// SetDefault changes the agent used when no explicit name is given.
+func (r *Registry) SetDefault(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.defaultName = name
+}
+
+// Default returns the name of the default agent.
+func (r *Registry) Default() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultName
+}
+
+// Has reports whether an agent is registered under name.
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.factories[name]
+	return ok
+}
+
+// Names returns the registered agent names in sorted order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get creates the agent registered under name.
+// An empty name resolves to the default agent. A name of the form
+// scripted:<file> loads a ScriptedAgent from the scenario file on first use;
+// later lookups return the same agent, so it keeps its place in the scenario.
+func (r *Registry) Get(name string) (Agent, error) {
+	if name == "" {
+		name = r.Default()
+	}
+
+	r.mu.RLock()
+	f, ok := r.factories[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		if path, scripted := strings.CutPrefix(name, ScriptedPrefix); scripted {
+			return r.registerScripted(name, path)
+		}
+		return nil, fmt.Errorf("unknown agent %q (available: %s)", name, r.namesList())
+	}
+	return f(), nil
+}
+
+// registerScripted loads the scenario at path and registers its agent
+// under name.
+func (r *Registry) registerScripted(name, path string) (Agent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Another caller may have loaded it in the meantime
+	if f, ok := r.factories[name]; ok {
+		return f(), nil
+	}
+
+	scenario, err := LoadScenario(path)
+	if err != nil {
+		return nil, err
+	}
+	a := NewScriptedAgent(scenario)
+	r.factories[name] = func() Agent { return a }
+	return a, nil
+}
+
+// Resolve returns the agent for the first non-empty name in order of
+// precedence (e.g. task, then epic), falling back to the default agent.
+func (r *Registry) Resolve(names ...string) (Agent, error) {
+	for _, name := range names {
+		if name != "" {
+			return r.Get(name)
+		}
+	}
+	return r.Get("")
+}
+
+// namesList returns registered names as a comma-separated string for errors.
+func (r *Registry) namesList() string {
+	return strings.Join(r.Names(), ", ")
+}
@@ -0,0 +1,139 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewRegistry_BuiltinAgents(t *testing.T) {
+	r := NewRegistry(nil)
+
+	if got := r.Default(); got != "claude" {
+		t.Errorf("Default() = %q, want %q", got, "claude")
+	}
+	if got := strings.Join(r.Names(), ","); got != "claude,codex" {
+		t.Errorf("Names() = %q, want %q", got, "claude,codex")
+	}
+
+	for _, name := range []string{"claude", "codex"} {
+		a, err := r.Get(name)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", name, err)
+		}
+		if a.Name() != name {
+			t.Errorf("Get(%q).Name() = %q", name, a.Name())
+		}
+	}
+}
+
+func TestNewRegistry_AppliesConfig(t *testing.T) {
+	codex := "codex"
+	model := "o4-mini"
+	fullAuto := true
+	claudeCmd := "/opt/claude"
+	claudeModel := "opus"
+	r := NewRegistry(&Config{
+		Default: &codex,
+		Claude:  &ClaudeConfig{Command: &claudeCmd, Model: &claudeModel, FallbackModels: []string{"sonnet"}},
+		Codex:   &CodexConfig{Model: &model, FullAuto: &fullAuto},
+	})
+
+	a, err := r.Get("")
+	if err != nil {
+		t.Fatalf("Get(\"\") error = %v", err)
+	}
+	ca, ok := a.(*CodexAgent)
+	if !ok {
+		t.Fatalf("Get(\"\") = %T, want *CodexAgent", a)
+	}
+	if ca.Model != "o4-mini" || !ca.FullAuto {
+		t.Errorf("CodexAgent = %+v, want model o4-mini with FullAuto", ca)
+	}
+
+	cl, _ := r.Get("claude")
+	if got := cl.(*ClaudeAgent).Command; got != "/opt/claude" {
+		t.Errorf("ClaudeAgent.Command = %q, want %q", got, "/opt/claude")
+	}
+	if got := cl.(*ClaudeAgent).modelChain(""); strings.Join(got, ",") != "opus,sonnet" {
+		t.Errorf("ClaudeAgent.modelChain() = %q, want [opus sonnet]", got)
+	}
+}
+
+func TestRegistry_GetUnknown(t *testing.T) {
+	r := NewRegistry(nil)
+	_, err := r.Get("nope")
+	if err == nil {
+		t.Fatal("Get() with unknown name should return error")
+	}
+	if !strings.Contains(err.Error(), "claude, codex") {
+		t.Errorf("error = %q, want available agents listed", err)
+	}
+}
+
+func TestRegistry_GetScripted(t *testing.T) {
+	r := NewRegistry(nil)
+	name := ScriptedPrefix + "testdata/scenario.json"
+
+	a, err := r.Get(name)
+	if err != nil {
+		t.Fatalf("Get(%q) error = %v", name, err)
+	}
+	if a.Name() != "ci" {
+		t.Errorf("Get(%q).Name() = %q, want %q", name, a.Name(), "ci")
+	}
+	if !r.Has(name) {
+		t.Errorf("Has(%q) = false after Get", name)
+	}
+	again, _ := r.Get(name)
+	if again != a {
+		t.Error("Get() should return the same scripted agent so it keeps its place")
+	}
+
+	if _, err := r.Get(ScriptedPrefix + "testdata/missing.json"); err == nil {
+		t.Error("Get() with a missing scenario should return error")
+	}
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	r := NewRegistry(nil)
+
+	tests := []struct {
+		name  string
+		names []string
+		want  string
+	}{
+		{"no selection uses default", nil, "claude"},
+		{"empty selections use default", []string{"", ""}, "claude"},
+		{"first non-empty wins", []string{"codex", "claude"}, "codex"},
+		{"falls through empty", []string{"", "codex"}, "codex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := r.Resolve(tt.names...)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if a.Name() != tt.want {
+				t.Errorf("Resolve() = %q, want %q", a.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	var nilCfg *Config
+	if err := nilCfg.Validate(); err != nil {
+		t.Errorf("nil Validate() = %v, want nil", err)
+	}
+
+	bad := "gpt-pilot"
+	if err := (&Config{Default: &bad}).Validate(); err == nil {
+		t.Error("Validate() with unknown default should return error")
+	}
+
+	good := "codex"
+	if err := (&Config{Default: &good}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,369 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScriptedPrefix selects a ScriptedAgent by scenario file, as in
+// --agent scripted:testdata/scenario.json.
+const ScriptedPrefix = "scripted:"
+
+// Scenario scripts what a ScriptedAgent does on each run. It is loaded from
+// a JSON file:
+//
+//	{
+//	  "steps": [
+//	    {"match": "Generate Epic Context", "output": "<epic_context>...</epic_context>"},
+//	    {"output": "Done.", "commands": ["tk close {{task}} --reason done"], "signal": "COMPLETE",
+//	     "tools": [{"name": "Bash", "input": "go test ./..."}], "tokens_in": 1200, "tokens_out": 300},
+//	    {"timeout": true}
+//	  ]
+//	}
+type Scenario struct {
+	// Name is the agent's display name (default "scripted").
+	Name string `json:"name,omitempty"`
+
+	// Model is reported in the agent state (default "scripted").
+	Model string `json:"model,omitempty"`
+
+	// Loop starts over from the first step once all steps are used.
+	// Otherwise runs past the last step fail.
+	Loop bool `json:"loop,omitempty"`
+
+	// Steps are used one per run, in order.
+	Steps []ScriptStep `json:"steps"`
+}
+
+// ScriptStep is what a ScriptedAgent does on one run. Events are emitted in
+// order: thinking, tools, commands, then output.
+type ScriptStep struct {
+	// Match is a regular expression the prompt must match for this step to
+	// be used. A run uses the first unused step that matches, so steps for
+	// context generation or review can be picked out of the iteration steps.
+	Match string `json:"match,omitempty"`
+
+	// Output is the response text.
+	Output string `json:"output,omitempty"`
+
+	// Thinking is emitted to the thinking stream before anything else.
+	Thinking string `json:"thinking,omitempty"`
+
+	// Signal is appended to the output as <promise>Signal</promise>,
+	// e.g. "COMPLETE" or "INPUT_NEEDED: Which database?".
+	Signal string `json:"signal,omitempty"`
+
+	// Tools are tool invocations reported in the agent state. Nothing is run.
+	Tools []ScriptTool `json:"tools,omitempty"`
+
+	// Commands are shell commands run in the working directory (skipped for
+	// read-only runs) and reported as Bash tool calls. {{task}} and {{epic}}
+	// are replaced with the run's task and epic IDs (RunOpts.TaskID and
+	// EpicID), {{workdir}} with the working directory.
+	Commands []string `json:"commands,omitempty"`
+
+	// TokensIn, TokensOut and Cost are the reported usage.
+	TokensIn  int     `json:"tokens_in,omitempty"`
+	TokensOut int     `json:"tokens_out,omitempty"`
+	Cost      float64 `json:"cost,omitempty"`
+
+	// Delay is a pause before each event (e.g. "200ms"), so runs take time
+	// and can hit the run's timeout.
+	Delay string `json:"delay,omitempty"`
+
+	// Timeout makes the run end as if it timed out, after its events.
+	Timeout bool `json:"timeout,omitempty"`
+
+	// Error makes the run fail with this message, after its events.
+	Error string `json:"error,omitempty"`
+}
+
+// ScriptTool is a tool invocation reported by a ScriptStep.
+type ScriptTool struct {
+	Name     string `json:"name"`
+	Input    string `json:"input,omitempty"`
+	Output   string `json:"output,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Error    bool   `json:"error,omitempty"`
+}
+
+// LoadScenario reads and validates a scenario file.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading scenario: %w", err)
+	}
+	var s Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing scenario %s: %w", path, err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Validate checks that the scenario has steps and that their patterns and
+// durations parse.
+func (s *Scenario) Validate() error {
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("no steps")
+	}
+	for i, step := range s.Steps {
+		if _, err := regexp.Compile(step.Match); err != nil {
+			return fmt.Errorf("step %d: invalid match: %w", i+1, err)
+		}
+		if _, err := parseScriptDuration(step.Delay); err != nil {
+			return fmt.Errorf("step %d: invalid delay: %w", i+1, err)
+		}
+		for _, tool := range step.Tools {
+			if tool.Name == "" {
+				return fmt.Errorf("step %d: tool name is required", i+1)
+			}
+			if _, err := parseScriptDuration(tool.Duration); err != nil {
+				return fmt.Errorf("step %d: tool %s: invalid duration: %w", i+1, tool.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseScriptDuration parses d, allowing "" for zero.
+func parseScriptDuration(d string) (time.Duration, error) {
+	if d == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(d)
+}
+
+// ScriptedAgent implements the Agent interface by playing back a Scenario,
+// for testing ticker setups without a real agent. One agent keeps its place
+// in the scenario across runs and is safe for concurrent use.
+type ScriptedAgent struct {
+	scenario *Scenario
+
+	mu   sync.Mutex
+	used []bool
+}
+
+// NewScriptedAgent creates an agent that plays back s.
+func NewScriptedAgent(s *Scenario) *ScriptedAgent {
+	return &ScriptedAgent{scenario: s, used: make([]bool, len(s.Steps))}
+}
+
+// Name returns the scenario's name, or "scripted".
+func (a *ScriptedAgent) Name() string {
+	if a.scenario.Name != "" {
+		return a.scenario.Name
+	}
+	return "scripted"
+}
+
+// Available always returns true.
+func (a *ScriptedAgent) Available() bool {
+	return true
+}
+
+// Run plays back the next step of the scenario that matches prompt.
+func (a *ScriptedAgent) Run(ctx context.Context, prompt string, opts RunOpts) (*Result, error) {
+	start := time.Now()
+
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	step, err := a.next(prompt)
+	if err != nil {
+		return nil, err
+	}
+	delay, _ := parseScriptDuration(step.Delay)
+
+	model := a.scenario.Model
+	if opts.Model != "" {
+		model = opts.Model
+	} else if model == "" {
+		model = "scripted"
+	}
+	state := &AgentState{StartedAt: time.Now(), Status: StatusStarting, Model: model}
+	onUpdate := newUpdateNotifier(state, opts)
+	onUpdate()
+
+	// emit waits out the step's delay, then applies an event to the state
+	emit := func(apply func()) error {
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+		state.mu.Lock()
+		apply()
+		state.mu.Unlock()
+		onUpdate()
+		return nil
+	}
+
+	err = a.play(ctx, state, step, prompt, opts, emit)
+	duration := time.Since(start)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return timeoutResult(state, opts.Timeout, duration), ErrTimeout
+		}
+		return nil, fmt.Errorf("%s cancelled", a.Name())
+	}
+
+	state.mu.Lock()
+	state.NumTurns = 1
+	state.Metrics.InputTokens = step.TokensIn
+	state.Metrics.OutputTokens = step.TokensOut
+	state.Metrics.CostUSD = step.Cost
+	state.Metrics.DurationMS = int(duration.Milliseconds())
+	if step.Error != "" {
+		state.Status = StatusError
+		state.ErrorMsg = step.Error
+	} else {
+		state.Status = StatusComplete
+	}
+	state.mu.Unlock()
+	onUpdate()
+
+	switch {
+	case step.Timeout:
+		return timeoutResult(state, opts.Timeout, duration), ErrTimeout
+	case step.Error != "":
+		return nil, fmt.Errorf("%s: %s", a.Name(), step.Error)
+	}
+	return resultFromState(state, duration), nil
+}
+
+// play emits the step's events to state. Returns an error only if ctx ends
+// first.
+func (a *ScriptedAgent) play(ctx context.Context, state *AgentState, step ScriptStep, prompt string, opts RunOpts, emit func(func()) error) error {
+	if step.Thinking != "" {
+		if err := emit(func() {
+			state.Thinking.WriteString(step.Thinking)
+			state.Status = StatusThinking
+		}); err != nil {
+			return err
+		}
+	}
+
+	// useTool reports a tool call: active while run executes, then in history
+	useTool := func(tool ToolActivity, run func() (output string, isError bool, err error)) error {
+		if err := emit(func() {
+			tool.StartedAt = time.Now()
+			state.ActiveTool = &tool
+			state.Status = StatusToolUse
+		}); err != nil {
+			return err
+		}
+		output, isError, err := run()
+		if err != nil {
+			return err
+		}
+		return emit(func() {
+			tool.Output = output
+			tool.IsError = isError
+			tool.Duration = time.Since(tool.StartedAt)
+			state.ToolHistory = append(state.ToolHistory, tool)
+			state.ActiveTool = nil
+		})
+	}
+
+	for i, t := range step.Tools {
+		d, _ := parseScriptDuration(t.Duration)
+		err := useTool(ToolActivity{ID: fmt.Sprintf("tool_%d", i+1), Name: t.Name, Input: t.Input}, func() (string, bool, error) {
+			return t.Output, t.Error, sleepContext(ctx, d)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if !opts.ReadOnly {
+		for i, c := range step.Commands {
+			command := expandScriptCommand(c, opts)
+			err := useTool(ToolActivity{ID: fmt.Sprintf("command_%d", i+1), Name: "Bash", Input: command}, func() (string, bool, error) {
+				output, err := runScriptCommand(ctx, command, opts.WorkDir)
+				return output, err != nil, ctx.Err()
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	text := step.Output
+	if step.Signal != "" {
+		if text != "" {
+			text += "\n\n"
+		}
+		text += "<promise>" + step.Signal + "</promise>"
+	}
+	if text != "" {
+		return emit(func() {
+			state.Output.WriteString(text)
+			state.Status = StatusWriting
+		})
+	}
+	return nil
+}
+
+// next claims the first unused step matching prompt.
+func (a *ScriptedAgent) next(prompt string) (ScriptStep, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for pass := 0; pass < 2; pass++ {
+		for i, step := range a.scenario.Steps {
+			if a.used[i] {
+				continue
+			}
+			if step.Match != "" && !regexp.MustCompile(step.Match).MatchString(prompt) {
+				continue
+			}
+			a.used[i] = true
+			return step, nil
+		}
+		if !a.scenario.Loop {
+			break
+		}
+		a.used = make([]bool, len(a.scenario.Steps))
+	}
+	return ScriptStep{}, fmt.Errorf("%s: no scenario step left for this prompt", a.Name())
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// expandScriptCommand replaces the placeholders in a scenario command with
+// the run's task, epic and working directory.
+func expandScriptCommand(command string, opts RunOpts) string {
+	return strings.NewReplacer("{{task}}", opts.TaskID, "{{epic}}", opts.EpicID, "{{workdir}}", opts.WorkDir).Replace(command)
+}
+
+// runScriptCommand runs command with sh in dir, returning its combined output.
+func runScriptCommand(ctx context.Context, command, dir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
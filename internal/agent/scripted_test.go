@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadScenario(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"valid", `{"steps": [{"output": "ok", "delay": "10ms", "tools": [{"name": "Bash", "duration": "1s"}]}]}`, ""},
+		{"no steps", `{"steps": []}`, "no steps"},
+		{"bad json", `{"steps": [`, "parsing scenario"},
+		{"bad match", `{"steps": [{"match": "("}]}`, "invalid match"},
+		{"bad delay", `{"steps": [{"delay": "soon"}]}`, "invalid delay"},
+		{"tool without name", `{"steps": [{"tools": [{"input": "x"}]}]}`, "tool name is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "scenario.json")
+			if err := os.WriteFile(path, []byte(tt.json), 0644); err != nil {
+				t.Fatalf("failed to write scenario: %v", err)
+			}
+			_, err := LoadScenario(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("LoadScenario() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadScenario() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadScenario("testdata/scenario.json"); err != nil {
+		t.Errorf("LoadScenario(testdata/scenario.json) error = %v", err)
+	}
+}
+
+func TestScriptedAgent_Run(t *testing.T) {
+	scenario, err := LoadScenario("testdata/scenario.json")
+	if err != nil {
+		t.Fatalf("LoadScenario() error = %v", err)
+	}
+	a := NewScriptedAgent(scenario)
+	if a.Name() != "ci" || !a.Available() {
+		t.Errorf("Name() = %q, Available() = %v, want ci and true", a.Name(), a.Available())
+	}
+
+	var snaps []AgentStateSnapshot
+	result, err := a.Run(context.Background(), "# Iteration 1", RunOpts{
+		StateCallback: func(s AgentStateSnapshot) { snaps = append(snaps, s) },
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	// The context step is skipped because its match fails
+	if want := "Implemented the change.\n\n<promise>COMPLETE</promise>"; result.Output != want {
+		t.Errorf("Output = %q, want %q", result.Output, want)
+	}
+	if result.TokensIn != 1200 || result.TokensOut != 300 || result.Cost != 0.02 {
+		t.Errorf("usage = %d/%d/%v, want 1200/300/0.02", result.TokensIn, result.TokensOut, result.Cost)
+	}
+	if result.Record == nil || len(result.Record.Tools) != 1 || result.Record.Tools[0].Name != "Read" {
+		t.Errorf("Record.Tools = %+v, want one Read call", result.Record)
+	}
+
+	var sawThinking, sawTool bool
+	for _, s := range snaps {
+		sawThinking = sawThinking || s.Status == StatusThinking
+		sawTool = sawTool || (s.ActiveTool != nil && s.ActiveTool.Name == "Read")
+	}
+	if !sawThinking || !sawTool {
+		t.Errorf("snapshots missing thinking (%v) or active tool (%v)", sawThinking, sawTool)
+	}
+	if last := snaps[len(snaps)-1]; last.Status != StatusComplete {
+		t.Errorf("last snapshot Status = %s, want %s", last.Status, StatusComplete)
+	}
+
+	// The context step is still available for a matching prompt
+	result, err = a.Run(context.Background(), "# Generate Epic Context", RunOpts{})
+	if err != nil || !strings.Contains(result.Output, "<epic_context>") {
+		t.Errorf("Run(context prompt) = %v, %v, want the context step", result, err)
+	}
+
+	result, err = a.Run(context.Background(), "# Iteration 2", RunOpts{})
+	if err != nil || !strings.Contains(result.Output, "<promise>INPUT_NEEDED: Postgres or SQLite?</promise>") {
+		t.Errorf("Run() = %v, %v, want the input-needed step", result, err)
+	}
+
+	if _, err := a.Run(context.Background(), "# Iteration 3", RunOpts{}); err == nil {
+		t.Error("Run() past the last step should return an error")
+	}
+}
+
+func TestScriptedAgent_Loop(t *testing.T) {
+	a := NewScriptedAgent(&Scenario{Loop: true, Steps: []ScriptStep{{Output: "one"}, {Output: "two"}}})
+
+	var outputs []string
+	for range 3 {
+		result, err := a.Run(context.Background(), "prompt", RunOpts{})
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		outputs = append(outputs, result.Output)
+	}
+	if got := strings.Join(outputs, ","); got != "one,two,one" {
+		t.Errorf("outputs = %s, want one,two,one", got)
+	}
+}
+
+func TestScriptedAgent_Commands(t *testing.T) {
+	dir := t.TempDir()
+	a := NewScriptedAgent(&Scenario{Steps: []ScriptStep{
+		{Commands: []string{"echo {{task}} {{epic}} > out.txt", "exit 3"}, Output: "done"},
+		{Commands: []string{"touch readonly.txt"}},
+	}})
+	prompt := "Run `tk close other --reason \"<solution summary>\"`"
+
+	// The IDs come from the run options, not the prompt's instructions
+	result, err := a.Run(context.Background(), prompt, RunOpts{WorkDir: dir, TaskID: "abc", EpicID: "xyz"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+	if err != nil {
+		t.Fatalf("command did not run: %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "abc xyz" {
+		t.Errorf("command wrote %q, want %q", got, "abc xyz")
+	}
+	tools := result.Record.Tools
+	if len(tools) != 2 || tools[0].IsError || !tools[1].IsError {
+		t.Errorf("Record.Tools = %+v, want two Bash calls, the second failing", tools)
+	}
+
+	// Commands don't run for read-only runs
+	if _, err := a.Run(context.Background(), prompt, RunOpts{WorkDir: dir, ReadOnly: true}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "readonly.txt")); err == nil {
+		t.Error("read-only run should not run commands")
+	}
+}
+
+func TestScriptedAgent_Failures(t *testing.T) {
+	tests := []struct {
+		name    string
+		step    ScriptStep
+		timeout time.Duration
+		wantErr error
+	}{
+		{"scripted timeout", ScriptStep{Output: "partial", Timeout: true}, time.Minute, ErrTimeout},
+		{"run timeout", ScriptStep{Output: "slow", Delay: "1s"}, 20 * time.Millisecond, ErrTimeout},
+		{"error", ScriptStep{Error: "rate limited"}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewScriptedAgent(&Scenario{Steps: []ScriptStep{tt.step}})
+			result, err := a.Run(context.Background(), "prompt", RunOpts{Timeout: tt.timeout})
+			if err == nil {
+				t.Fatal("Run() should return an error")
+			}
+			if tt.wantErr == nil {
+				if !strings.Contains(err.Error(), tt.step.Error) {
+					t.Errorf("Run() error = %v, want %q", err, tt.step.Error)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Run() error = %v, want %v", err, tt.wantErr)
+			}
+			if result == nil || result.Record == nil || result.Record.Success {
+				t.Errorf("Run() result = %+v, want a partial unsuccessful result", result)
+			}
+		})
+	}
+}
@@ -131,6 +131,19 @@ type RunRecord struct {
 	Success  bool   `json:"success"`
 	NumTurns int    `json:"num_turns"`
 	ErrorMsg string `json:"error_msg,omitempty"`
+
+	// FallbackFrom lists models that were tried first and failed with a
+	// rate-limit or overload error before Model produced this record.
+	FallbackFrom []string `json:"fallback_from,omitempty"`
+
+	// BaseCommit and HeadCommit are the repository HEAD before and after the
+	// run, so the changes it committed can be diffed. Set by the engine.
+	BaseCommit string `json:"base_commit,omitempty"`
+	HeadCommit string `json:"head_commit,omitempty"`
+
+	// Commits are the commits made on the task across all its runs, oldest
+	// first, so they can be reverted (see ticker revert).
+	Commits []string `json:"commits,omitempty"`
 }
 
 // ToolRecord is a serializable record of a tool invocation.
@@ -382,6 +395,7 @@ func (p *StreamParser) handleStreamEvent(eventData json.RawMessage) {
 func (p *StreamParser) handleResult(line []byte) {
 	var raw struct {
 		Subtype    string  `json:"subtype"`
+		IsError    bool    `json:"is_error"`
 		Result     string  `json:"result"`
 		DurationMS int     `json:"duration_ms"`
 		NumTurns   int     `json:"num_turns"`
@@ -399,7 +413,7 @@ func (p *StreamParser) handleResult(line []byte) {
 	}
 
 	p.state.mu.Lock()
-	if raw.Subtype == "success" {
+	if raw.Subtype == "success" && !raw.IsError {
 		p.state.Status = StatusComplete
 	} else {
 		p.state.Status = StatusError
@@ -316,3 +316,23 @@ func TestStreamParser_ThinkingThenTextNoExtraSeparator(t *testing.T) {
 		t.Errorf("Thinking = %q, want %q", snap.Thinking, expectedThinking)
 	}
 }
+
+func TestStreamParser_ResultIsError(t *testing.T) {
+	// API errors arrive as a "success" result flagged with is_error
+	input := `{"type":"system","subtype":"init","session_id":"abc","model":"claude-opus-4-5-20251101"}
+{"type":"result","subtype":"success","is_error":true,"result":"API Error: 529 Overloaded","duration_ms":10,"num_turns":1,"total_cost_usd":0}`
+
+	state := &AgentState{}
+	parser := NewStreamParser(state, nil)
+	if err := parser.Parse(strings.NewReader(input)); err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	snap := state.Snapshot()
+	if snap.Status != StatusError {
+		t.Errorf("Status = %q, want %q", snap.Status, StatusError)
+	}
+	if snap.ErrorMsg != "API Error: 529 Overloaded" {
+		t.Errorf("ErrorMsg = %q, want API error", snap.ErrorMsg)
+	}
+}
@@ -0,0 +1,131 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// transcriptClockType marks the timing lines a TranscriptWriter inserts
+// between stream lines. StreamParser ignores unknown event types, so a
+// transcript is still valid stream-json.
+const transcriptClockType = "ticker_elapsed"
+
+// transcriptClockResolution is the minimum time between timing lines.
+const transcriptClockResolution = 10 * time.Millisecond
+
+// transcriptClock is a timing line: ms since the transcript started.
+type transcriptClock struct {
+	Type      string `json:"type"`
+	ElapsedMS int64  `json:"elapsed_ms"`
+}
+
+// TranscriptWriter records an agent's raw output stream (see
+// RunOpts.Transcript) line by line, adding timing lines so ReplayTranscript
+// can reproduce the original pacing.
+type TranscriptWriter struct {
+	w     io.Writer
+	start time.Time
+	last  time.Duration
+	buf   []byte
+}
+
+// NewTranscriptWriter returns a TranscriptWriter writing to w. Timing starts now.
+func NewTranscriptWriter(w io.Writer) *TranscriptWriter {
+	return &TranscriptWriter{w: w, start: time.Now()}
+}
+
+// Write buffers p and writes out every complete line, preceded by a timing
+// line if time has passed since the last one.
+func (t *TranscriptWriter) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+	for {
+		i := bytes.IndexByte(t.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := t.writeLine(t.buf[:i+1]); err != nil {
+			return len(p), err
+		}
+		t.buf = t.buf[i+1:]
+	}
+}
+
+// Flush writes any trailing partial line.
+func (t *TranscriptWriter) Flush() error {
+	if len(t.buf) == 0 {
+		return nil
+	}
+	line := append(t.buf, '\n')
+	t.buf = nil
+	return t.writeLine(line)
+}
+
+func (t *TranscriptWriter) writeLine(line []byte) error {
+	if elapsed := time.Since(t.start); elapsed-t.last >= transcriptClockResolution {
+		t.last = elapsed
+		clock, _ := json.Marshal(transcriptClock{Type: transcriptClockType, ElapsedMS: elapsed.Milliseconds()})
+		if _, err := t.w.Write(append(clock, '\n')); err != nil {
+			return err
+		}
+	}
+	_, err := t.w.Write(line)
+	return err
+}
+
+// ReplayTranscript feeds a transcript recorded by TranscriptWriter through
+// StreamParser, calling onUpdate with a snapshot after every state change.
+// speed scales the recorded pacing (1 is real time, 2 twice as fast); 0
+// replays as fast as possible. Returns the final state.
+func ReplayTranscript(ctx context.Context, r io.Reader, speed float64, onUpdate func(AgentStateSnapshot)) (AgentStateSnapshot, error) {
+	state := &AgentState{StartedAt: time.Now()}
+	parser := NewStreamParser(state, func() {
+		if onUpdate != nil {
+			onUpdate(state.Snapshot())
+		}
+	})
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024) // 1MB max line, as in Parse
+
+	var clock time.Duration
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		if elapsed, ok := parseTranscriptClock(line); ok {
+			if speed > 0 && elapsed > clock {
+				if err := sleepContext(ctx, time.Duration(float64(elapsed-clock)/speed)); err != nil {
+					return state.Snapshot(), err
+				}
+			}
+			clock = elapsed
+			continue
+		}
+		parser.parseLine(line)
+		if err := ctx.Err(); err != nil {
+			return state.Snapshot(), err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return state.Snapshot(), fmt.Errorf("reading transcript: %w", err)
+	}
+	return state.Snapshot(), nil
+}
+
+// parseTranscriptClock returns the elapsed time of a timing line.
+func parseTranscriptClock(line []byte) (time.Duration, bool) {
+	if !bytes.Contains(line, []byte(transcriptClockType)) {
+		return 0, false
+	}
+	var clock transcriptClock
+	if err := json.Unmarshal(line, &clock); err != nil || clock.Type != transcriptClockType {
+		return 0, false
+	}
+	return time.Duration(clock.ElapsedMS) * time.Millisecond, true
+}
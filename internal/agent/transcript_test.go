@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const transcriptStream = `{"type":"system","subtype":"init","session_id":"rec-1","model":"opus"}
+{"type":"stream_event","event":{"type":"content_block_start","index":0,"content_block":{"type":"text"}}}
+{"type":"stream_event","event":{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}}
+{"type":"stream_event","event":{"type":"content_block_stop","index":0}}
+{"type":"stream_event","event":{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"tool_1","name":"Read"}}}
+{"type":"stream_event","event":{"type":"content_block_stop","index":1}}
+{"type":"result","subtype":"success","result":"Hello","duration_ms":1000,"num_turns":1,"total_cost_usd":0.01,"usage":{"input_tokens":100,"output_tokens":20}}
+`
+
+func TestTranscriptWriter_Replay(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewTranscriptWriter(&buf)
+
+	// Write in uneven chunks, pausing so timing lines are recorded
+	lines := strings.SplitAfter(transcriptStream, "\n")
+	for i, line := range lines {
+		if i == 3 {
+			time.Sleep(30 * time.Millisecond)
+		}
+		half := len(line) / 2
+		w.Write([]byte(line[:half]))
+		w.Write([]byte(line[half:]))
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	recorded := buf.String()
+	for _, line := range lines {
+		if line != "" && !strings.Contains(recorded, line) {
+			t.Errorf("transcript is missing line %q", line)
+		}
+	}
+	if !strings.Contains(recorded, `"type":"ticker_elapsed"`) {
+		t.Error("transcript should contain timing lines")
+	}
+
+	var updates int
+	snap, err := ReplayTranscript(context.Background(), strings.NewReader(recorded), 0, func(AgentStateSnapshot) {
+		updates++
+	})
+	if err != nil {
+		t.Fatalf("ReplayTranscript() error = %v", err)
+	}
+	if snap.Output != "Hello" {
+		t.Errorf("Output = %q, want %q", snap.Output, "Hello")
+	}
+	if snap.SessionID != "rec-1" || snap.Model != "opus" {
+		t.Errorf("SessionID, Model = %q, %q, want rec-1, opus", snap.SessionID, snap.Model)
+	}
+	if len(snap.ToolHistory) != 1 || snap.ToolHistory[0].Name != "Read" {
+		t.Errorf("ToolHistory = %+v, want one Read", snap.ToolHistory)
+	}
+	if snap.Metrics.InputTokens != 100 || snap.Metrics.CostUSD != 0.01 {
+		t.Errorf("Metrics = %+v, want 100 input tokens and $0.01", snap.Metrics)
+	}
+	if updates == 0 {
+		t.Error("onUpdate was never called")
+	}
+}
+
+func TestTranscriptWriter_FlushPartialLine(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewTranscriptWriter(&buf)
+	w.Write([]byte(`{"type":"result"}`))
+	if buf.Len() != 0 {
+		t.Errorf("partial line written before Flush: %q", buf.String())
+	}
+	w.Flush()
+	if !strings.HasSuffix(buf.String(), "{\"type\":\"result\"}\n") {
+		t.Errorf("Flush() wrote %q, want the line terminated", buf.String())
+	}
+}
+
+func TestReplayTranscript_Speed(t *testing.T) {
+	transcript := `{"type":"ticker_elapsed","elapsed_ms":0}
+{"type":"system","subtype":"init","session_id":"s","model":"opus"}
+{"type":"ticker_elapsed","elapsed_ms":400}
+{"type":"result","subtype":"success","result":"done"}
+`
+	tests := []struct {
+		name     string
+		speed    float64
+		min, max time.Duration
+	}{
+		{"real time", 1, 350 * time.Millisecond, 2 * time.Second},
+		{"accelerated", 4, 80 * time.Millisecond, 350 * time.Millisecond},
+		{"instant", 0, 0, 80 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			snap, err := ReplayTranscript(context.Background(), strings.NewReader(transcript), tt.speed, nil)
+			elapsed := time.Since(start)
+			if err != nil {
+				t.Fatalf("ReplayTranscript() error = %v", err)
+			}
+			if snap.Status != StatusComplete {
+				t.Errorf("Status = %q, want %q", snap.Status, StatusComplete)
+			}
+			if elapsed < tt.min || elapsed > tt.max {
+				t.Errorf("replay took %v, want between %v and %v", elapsed, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestReplayTranscript_Cancelled(t *testing.T) {
+	transcript := `{"type":"ticker_elapsed","elapsed_ms":60000}
+{"type":"result","subtype":"success","result":"done"}
+`
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := ReplayTranscript(ctx, strings.NewReader(transcript), 1, nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("ReplayTranscript() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestClaudeAgent_Run_Transcript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	// A fake claude that prints a canned stream regardless of arguments
+	dir := t.TempDir()
+	script := filepath.Join(dir, "claude")
+	content := "#!/bin/sh\ncat <<'EOF'\n" + transcriptStream + "EOF\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	a := &ClaudeAgent{Command: script}
+	result, err := a.Run(context.Background(), "prompt", RunOpts{Transcript: &buf})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Output != "Hello" {
+		t.Errorf("Output = %q, want %q", result.Output, "Hello")
+	}
+	if buf.String() != transcriptStream {
+		t.Errorf("Transcript = %q, want the raw stream", buf.String())
+	}
+}
@@ -1,6 +1,6 @@
 package budget
 
-// ModelPricing contains the pricing information for a Claude model.
+// ModelPricing contains the pricing information for a model.
 // Prices are in USD per 1 million tokens.
 type ModelPricing struct {
 	Name        string  // Model name/identifier
@@ -8,9 +8,9 @@ type ModelPricing struct {
 	OutputPer1M float64 // Cost per 1M output tokens in USD
 }
 
-// Claude model pricing as of 2024.
+// Model pricing as of 2024 (OpenAI entries as of 2025).
 // Prices are in USD per 1 million tokens.
-// Source: https://www.anthropic.com/pricing
+// Sources: https://www.anthropic.com/pricing, https://openai.com/api/pricing
 var (
 	// Claude 4.5 Opus (most capable)
 	Claude45Opus = ModelPricing{
@@ -68,6 +68,41 @@ var (
 		OutputPer1M: 1.25,
 	}
 
+	// OpenAI GPT-5 Codex (default model for the Codex CLI)
+	GPT5Codex = ModelPricing{
+		Name:        "gpt-5-codex",
+		InputPer1M:  1.25,
+		OutputPer1M: 10.00,
+	}
+
+	// OpenAI GPT-5
+	GPT5 = ModelPricing{
+		Name:        "gpt-5",
+		InputPer1M:  1.25,
+		OutputPer1M: 10.00,
+	}
+
+	// OpenAI o4-mini
+	O4Mini = ModelPricing{
+		Name:        "o4-mini",
+		InputPer1M:  1.10,
+		OutputPer1M: 4.40,
+	}
+
+	// OpenAI GPT-4.1
+	GPT41 = ModelPricing{
+		Name:        "gpt-4.1",
+		InputPer1M:  2.00,
+		OutputPer1M: 8.00,
+	}
+
+	// OpenAI GPT-4o
+	GPT4o = ModelPricing{
+		Name:        "gpt-4o",
+		InputPer1M:  2.50,
+		OutputPer1M: 10.00,
+	}
+
 	// DefaultPricing is used when the model is unknown.
 	// Uses Claude 3.5 Sonnet pricing as a reasonable default.
 	DefaultPricing = Claude35Sonnet
@@ -115,6 +150,13 @@ var ModelPricingTable = map[string]ModelPricing{
 	"claude-3-haiku-20240307": Claude3Haiku,
 	"claude-3-haiku":          Claude3Haiku,
 	"haiku":                   Claude3Haiku,
+
+	// OpenAI (Codex CLI)
+	"gpt-5-codex": GPT5Codex,
+	"gpt-5":       GPT5,
+	"o4-mini":     O4Mini,
+	"gpt-4.1":     GPT41,
+	"gpt-4o":      GPT4o,
 }
 
 // GetPricing returns the pricing for the given model name.
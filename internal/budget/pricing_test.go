@@ -42,6 +42,10 @@ func TestGetPricing_KnownModels(t *testing.T) {
 		// Claude 3 Haiku
 		{"claude-3-haiku-20240307", 0.25, 1.25},
 		{"haiku", 0.25, 1.25},
+
+		// OpenAI (Codex CLI)
+		{"gpt-5-codex", 1.25, 10.00},
+		{"o4-mini", 1.10, 4.40},
 	}
 
 	for _, tt := range tests {
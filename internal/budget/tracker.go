@@ -28,6 +28,19 @@ type Usage struct {
 	TokensOut  int
 	Cost       float64
 	StartTime  time.Time
+
+	// Resumed is the subset of usage from iterations that continued an
+	// earlier agent session instead of starting fresh.
+	Resumed ResumedUsage
+}
+
+// ResumedUsage tracks consumption of resumed-session iterations, so their
+// cost can be compared with fresh iterations.
+type ResumedUsage struct {
+	Iterations int
+	TokensIn   int
+	TokensOut  int
+	Cost       float64
 }
 
 // EpicUsage tracks usage for a single epic.
@@ -88,6 +101,35 @@ func (t *Tracker) Add(tokensIn, tokensOut int, cost float64) {
 	t.usage.Cost += cost
 }
 
+// AddResumed is like Add for an iteration that resumed an earlier agent
+// session. The usage counts toward the totals and is also tracked in
+// Usage.Resumed.
+func (t *Tracker) AddResumed(tokensIn, tokensOut int, cost float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.usage.Iterations++
+	t.usage.TokensIn += tokensIn
+	t.usage.TokensOut += tokensOut
+	t.usage.Cost += cost
+
+	t.usage.Resumed.Iterations++
+	t.usage.Resumed.TokensIn += tokensIn
+	t.usage.Resumed.TokensOut += tokensOut
+	t.usage.Resumed.Cost += cost
+}
+
+// AddUsage accumulates token and cost usage without counting an iteration,
+// for agent runs outside an iteration (reviews, notes digests).
+func (t *Tracker) AddUsage(tokensIn, tokensOut int, cost float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.usage.TokensIn += tokensIn
+	t.usage.TokensOut += tokensOut
+	t.usage.Cost += cost
+}
+
 // AddIteration increments only the iteration counter without adding tokens/cost.
 func (t *Tracker) AddIteration() {
 	t.mu.Lock()
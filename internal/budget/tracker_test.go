@@ -84,6 +84,48 @@ func TestTracker_AddIteration(t *testing.T) {
 	}
 }
 
+func TestTracker_AddUsage(t *testing.T) {
+	tracker := NewTracker(Limits{})
+
+	tracker.Add(1000, 200, 0.10)
+	tracker.AddUsage(300, 100, 0.05)
+	usage := tracker.Usage()
+
+	if usage.Iterations != 1 {
+		t.Errorf("Iterations = %d, want 1", usage.Iterations)
+	}
+	if usage.TokensIn != 1300 || usage.TokensOut != 300 {
+		t.Errorf("tokens = %d/%d, want 1300/300", usage.TokensIn, usage.TokensOut)
+	}
+	if usage.Cost < 0.149 || usage.Cost > 0.151 {
+		t.Errorf("Cost = %f, want 0.15", usage.Cost)
+	}
+}
+
+func TestTracker_AddResumed(t *testing.T) {
+	tracker := NewTracker(Limits{})
+
+	tracker.Add(1000, 200, 0.10)
+	tracker.AddResumed(100, 50, 0.01)
+	usage := tracker.Usage()
+
+	if usage.Iterations != 2 {
+		t.Errorf("Iterations = %d, want 2", usage.Iterations)
+	}
+	if usage.TokensIn != 1100 {
+		t.Errorf("TokensIn = %d, want 1100", usage.TokensIn)
+	}
+	if usage.Resumed.Iterations != 1 {
+		t.Errorf("Resumed.Iterations = %d, want 1", usage.Resumed.Iterations)
+	}
+	if usage.Resumed.TokensIn != 100 || usage.Resumed.TokensOut != 50 {
+		t.Errorf("Resumed tokens = %d/%d, want 100/50", usage.Resumed.TokensIn, usage.Resumed.TokensOut)
+	}
+	if usage.Resumed.Cost != 0.01 {
+		t.Errorf("Resumed.Cost = %f, want 0.01", usage.Resumed.Cost)
+	}
+}
+
 func TestUsage_TotalTokens(t *testing.T) {
 	usage := Usage{TokensIn: 100, TokensOut: 50}
 	if usage.TotalTokens() != 150 {
@@ -9,12 +9,20 @@ import (
 	"time"
 
 	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
 	"github.com/pengelbrecht/ticker/internal/ticks"
 )
 
 // epicContextTagPattern extracts content from <epic_context> tags.
 var epicContextTagPattern = regexp.MustCompile(`(?s)<epic_context>\s*(.*?)\s*</epic_context>`)
 
+// repoMapTagPattern extracts content from <repo_map> tags.
+var repoMapTagPattern = regexp.MustCompile(`(?s)<repo_map>\s*(.*?)\s*</repo_map>`)
+
+// RepoMapID is the store ID of the repository-wide codebase map, saved as
+// _repo.md alongside epic contexts. Tick IDs never start with "_".
+const RepoMapID = "_repo"
+
 // DefaultTimeout is the default timeout for context generation.
 const DefaultTimeout = 5 * time.Minute
 
@@ -24,7 +32,10 @@ type Generator struct {
 	promptBuilder *PromptBuilder
 	timeout       time.Duration
 	logger        *slog.Logger
-	maxTokens     int // stored for creating prompt builder with correct value
+	maxTokens     int    // stored for creating prompt builder with correct value
+	model         string // "" = the agent's default model
+	updateModel   string // model for incremental updates ("" = same as model)
+	template      string // generation prompt template ("" = built-in)
 }
 
 // GeneratorOption configures a Generator.
@@ -44,6 +55,29 @@ func WithMaxTokens(tokens int) GeneratorOption {
 	}
 }
 
+// WithModel sets the model used for generation (default "" = agent default).
+func WithModel(model string) GeneratorOption {
+	return func(g *Generator) {
+		g.model = model
+	}
+}
+
+// WithUpdateModel sets the model used for incremental updates after a task
+// completes (default "" = the generation model).
+func WithUpdateModel(model string) GeneratorOption {
+	return func(g *Generator) {
+		g.updateModel = model
+	}
+}
+
+// WithPromptTemplate replaces the built-in generation prompt template (see
+// LoadPromptTemplate).
+func WithPromptTemplate(text string) GeneratorOption {
+	return func(g *Generator) {
+		g.template = text
+	}
+}
+
 // WithLogger sets the logger for the generator.
 func WithLogger(logger *slog.Logger) GeneratorOption {
 	return func(g *Generator) {
@@ -66,7 +100,7 @@ func NewGenerator(a agent.Agent, opts ...GeneratorOption) (*Generator, error) {
 	}
 
 	// Create prompt builder with the configured maxTokens
-	pb, err := NewPromptBuilder(PromptWithMaxTokens(g.maxTokens))
+	pb, err := NewPromptBuilder(PromptWithMaxTokens(g.maxTokens), PromptWithTemplate(g.template))
 	if err != nil {
 		return nil, fmt.Errorf("creating prompt builder: %w", err)
 	}
@@ -100,6 +134,7 @@ func (g *Generator) Generate(ctx context.Context, epic *ticks.Epic, tasks []tick
 	// Run the agent with timeout
 	result, err := g.agent.Run(ctx, prompt, agent.RunOpts{
 		Timeout: g.timeout,
+		Model:   g.model,
 	})
 	if err != nil {
 		// Log the failure
@@ -125,6 +160,143 @@ func (g *Generator) Generate(ctx context.Context, epic *ticks.Epic, tasks []tick
 	return content, nil
 }
 
+// Usage is the token usage and cost of a generator's agent run, for the
+// caller's budget.
+type Usage struct {
+	TokensIn  int
+	TokensOut int
+	Cost      float64
+}
+
+// usageOf returns the usage of an agent run.
+func usageOf(result *agent.Result) Usage {
+	return Usage{TokensIn: result.TokensIn, TokensOut: result.TokensOut, Cost: result.Cost}
+}
+
+// SetRepoMap sets the codebase map included in epic context generation
+// prompts (see RepoMapID). Empty means none.
+func (g *Generator) SetRepoMap(content string) {
+	g.promptBuilder.SetRepoMap(content)
+}
+
+// GenerateRepoMap runs the AI agent to generate the repository-wide codebase
+// map: layout, build and test commands, and conventions shared by all epics.
+// The agent's usage is returned even if its output is unusable.
+func (g *Generator) GenerateRepoMap(ctx context.Context) (string, Usage, error) {
+	g.logger.Info("repo map generation started")
+
+	startTime := time.Now()
+
+	prompt, err := g.promptBuilder.BuildRepoMap()
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("building prompt: %w", err)
+	}
+
+	result, err := g.agent.Run(ctx, prompt, agent.RunOpts{
+		Timeout: g.timeout,
+		Model:   g.model,
+	})
+	if err != nil {
+		g.logger.Error("repo map generation failed",
+			"error", err,
+			"duration", time.Since(startTime),
+		)
+		return "", Usage{}, fmt.Errorf("running agent: %w", err)
+	}
+
+	g.logger.Info("repo map generation completed",
+		"duration", time.Since(startTime),
+		"tokens_in", result.TokensIn,
+		"tokens_out", result.TokensOut,
+		"cost_usd", result.Cost,
+	)
+
+	content := result.Output
+	if matches := repoMapTagPattern.FindStringSubmatch(content); len(matches) >= 2 {
+		content = matches[1]
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", usageOf(result), fmt.Errorf("agent returned empty repo map")
+	}
+	return content, usageOf(result), nil
+}
+
+// TaskChange describes a completed task for an incremental context update.
+type TaskChange struct {
+	ID          string
+	Title       string
+	CloseReason string
+	Diff        string // changes made for the task
+}
+
+// Update runs the AI agent to revise existing context for an epic after a
+// task completes, rewriting only the sections the task's changes affect.
+// Returns the full updated document and the agent's usage, which is
+// returned even if its output is unusable.
+func (g *Generator) Update(ctx context.Context, epic *ticks.Epic, current string, change TaskChange) (string, Usage, error) {
+	if epic == nil {
+		return "", Usage{}, fmt.Errorf("epic is required")
+	}
+
+	g.logger.Info("context update started",
+		"epic_id", epic.ID,
+		"task_id", change.ID,
+	)
+
+	startTime := time.Now()
+
+	prompt, err := g.promptBuilder.BuildUpdate(epic, current, change)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("building prompt: %w", err)
+	}
+
+	model := g.updateModel
+	if model == "" {
+		model = g.model
+	}
+	result, err := g.agent.Run(ctx, prompt, agent.RunOpts{
+		Timeout: g.timeout,
+		Model:   model,
+	})
+	if err != nil {
+		g.logger.Error("context update failed",
+			"epic_id", epic.ID,
+			"task_id", change.ID,
+			"error", err,
+			"duration", time.Since(startTime),
+		)
+		return "", Usage{}, fmt.Errorf("running agent: %w", err)
+	}
+
+	g.logger.Info("context update completed",
+		"epic_id", epic.ID,
+		"task_id", change.ID,
+		"duration", time.Since(startTime),
+		"tokens_in", result.TokensIn,
+		"tokens_out", result.TokensOut,
+		"cost_usd", result.Cost,
+	)
+
+	content := extractEpicContext(result.Output)
+	if content == "" {
+		return "", usageOf(result), fmt.Errorf("agent returned empty context")
+	}
+	return content, usageOf(result), nil
+}
+
+// Metadata describes a context generated now from taskCount tasks, with
+// HEAD of the repository at dir recorded for staleness checks.
+func (g *Generator) Metadata(dir string, taskCount int) Metadata {
+	return Metadata{
+		GeneratedAt: time.Now(),
+		GitCommit:   gitutil.HeadCommit(dir),
+		Agent:       g.agent.Name(),
+		Model:       g.model,
+		TaskCount:   taskCount,
+	}
+}
+
 // extractEpicContext extracts markdown content from <epic_context> tags.
 // If tags are not found, returns the original output trimmed.
 func extractEpicContext(output string) string {
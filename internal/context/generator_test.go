@@ -419,3 +419,180 @@ This is the content.`,
 		})
 	}
 }
+
+func TestGenerator_Generate_WithModel(t *testing.T) {
+	mock := &mockAgent{name: "claude"}
+	g, err := NewGenerator(mock, WithModel("haiku"))
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	if _, err := g.Generate(context.Background(), &ticks.Epic{ID: "e1", Title: "Epic"}, nil); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if mock.lastOpts.Model != "haiku" {
+		t.Errorf("RunOpts.Model = %q, want %q", mock.lastOpts.Model, "haiku")
+	}
+
+	meta := g.Metadata(t.TempDir(), 3)
+	if meta.Agent != "claude" || meta.Model != "haiku" || meta.TaskCount != 3 {
+		t.Errorf("Metadata() = %+v, want agent claude, model haiku, 3 tasks", meta)
+	}
+	if meta.GitCommit != "" {
+		t.Errorf("Metadata().GitCommit = %q, want empty outside a git repo", meta.GitCommit)
+	}
+	if time.Since(meta.GeneratedAt) > time.Minute {
+		t.Errorf("Metadata().GeneratedAt = %v, want now", meta.GeneratedAt)
+	}
+}
+
+func TestGenerator_Update(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []GeneratorOption
+		output    string
+		runErr    error
+		want      string
+		wantErr   bool
+		wantModel string
+	}{
+		{
+			name:      "extracts updated context",
+			output:    "Done.\n<epic_context>\n# Updated\n</epic_context>",
+			want:      "# Updated",
+			wantModel: "",
+		},
+		{
+			name:      "uses generation model by default",
+			opts:      []GeneratorOption{WithModel("sonnet")},
+			output:    "<epic_context># Updated</epic_context>",
+			want:      "# Updated",
+			wantModel: "sonnet",
+		},
+		{
+			name:      "update model overrides generation model",
+			opts:      []GeneratorOption{WithModel("sonnet"), WithUpdateModel("haiku")},
+			output:    "<epic_context># Updated</epic_context>",
+			want:      "# Updated",
+			wantModel: "haiku",
+		},
+		{
+			name:    "empty output is an error",
+			output:  "<epic_context></epic_context>",
+			wantErr: true,
+		},
+		{
+			name:    "agent error",
+			runErr:  errors.New("boom"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockAgent{
+				name: "test",
+				runFunc: func(ctx context.Context, prompt string, opts agent.RunOpts) (*agent.Result, error) {
+					if tt.runErr != nil {
+						return nil, tt.runErr
+					}
+					return &agent.Result{Output: tt.output, TokensIn: 100, TokensOut: 20, Cost: 0.01}, nil
+				},
+			}
+			opts := append(tt.opts, WithLogger(slog.New(slog.DiscardHandler)))
+			g, err := NewGenerator(mock, opts...)
+			if err != nil {
+				t.Fatalf("NewGenerator() error = %v", err)
+			}
+
+			got, usage, err := g.Update(context.Background(), &ticks.Epic{ID: "e1", Title: "Epic"}, "# Old", TaskChange{ID: "t1", Title: "Task", Diff: "+x"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// The agent's usage counts even when its output is unusable
+			wantUsage := Usage{TokensIn: 100, TokensOut: 20, Cost: 0.01}
+			if tt.runErr != nil {
+				wantUsage = Usage{}
+			}
+			if usage != wantUsage {
+				t.Errorf("Update() usage = %+v, want %+v", usage, wantUsage)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Update() = %q, want %q", got, tt.want)
+			}
+			if mock.lastOpts.Model != tt.wantModel {
+				t.Errorf("RunOpts.Model = %q, want %q", mock.lastOpts.Model, tt.wantModel)
+			}
+			if !strings.Contains(mock.lastPrompt, "# Old") || !strings.Contains(mock.lastPrompt, "[t1] Task") {
+				t.Errorf("Update() prompt missing current context or task: %q", mock.lastPrompt)
+			}
+		})
+	}
+}
+
+func TestGenerator_Update_NilEpic(t *testing.T) {
+	g, err := NewGenerator(&mockAgent{name: "test"})
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+	if _, _, err := g.Update(context.Background(), nil, "# Old", TaskChange{}); err == nil {
+		t.Error("Update() with nil epic should error")
+	}
+}
+
+func TestGenerator_GenerateRepoMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		runErr  error
+		want    string
+		wantErr bool
+	}{
+		{"extracts tagged map", "Explored.\n<repo_map>\n# Codebase Map\n</repo_map>", nil, "# Codebase Map", false},
+		{"untagged output used as is", "  # Codebase Map  ", nil, "# Codebase Map", false},
+		{"empty output is an error", "<repo_map></repo_map>", nil, "", true},
+		{"agent error", "", errors.New("boom"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockAgent{
+				name: "test",
+				runFunc: func(ctx context.Context, prompt string, opts agent.RunOpts) (*agent.Result, error) {
+					if tt.runErr != nil {
+						return nil, tt.runErr
+					}
+					return &agent.Result{Output: tt.output, TokensIn: 100, TokensOut: 20, Cost: 0.01}, nil
+				},
+			}
+			g, err := NewGenerator(mock, WithModel("sonnet"), WithLogger(slog.New(slog.DiscardHandler)))
+			if err != nil {
+				t.Fatalf("NewGenerator() error = %v", err)
+			}
+
+			got, usage, err := g.GenerateRepoMap(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateRepoMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			wantUsage := Usage{TokensIn: 100, TokensOut: 20, Cost: 0.01}
+			if tt.runErr != nil {
+				wantUsage = Usage{}
+			}
+			if usage != wantUsage {
+				t.Errorf("GenerateRepoMap() usage = %+v, want %+v", usage, wantUsage)
+			}
+			if got != tt.want {
+				t.Errorf("GenerateRepoMap() = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(mock.lastPrompt, "# Generate Codebase Map") {
+				t.Error("GenerateRepoMap() used the wrong prompt")
+			}
+			if mock.lastOpts.Model != "sonnet" {
+				t.Errorf("RunOpts.Model = %q, want %q", mock.lastOpts.Model, "sonnet")
+			}
+		})
+	}
+}
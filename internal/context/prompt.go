@@ -2,6 +2,11 @@ package context
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"text/template"
 
 	"github.com/pengelbrecht/ticker/internal/ticks"
@@ -9,8 +14,12 @@ import (
 
 // PromptBuilder builds prompts for generating epic context documents.
 type PromptBuilder struct {
-	tmpl      *template.Template
-	maxTokens int
+	tmpl        *template.Template
+	updateTmpl  *template.Template
+	repoMapTmpl *template.Template
+	maxTokens   int
+	repoMap     string // shared codebase map included in generation prompts
+	text        string // generation template ("" = built-in)
 }
 
 // promptData holds the data passed to the prompt template.
@@ -20,6 +29,7 @@ type promptData struct {
 	EpicDescription string
 	Tasks           []taskData
 	MaxTokens       int
+	RepoMap         string
 }
 
 // taskData holds task information for the template.
@@ -46,7 +56,15 @@ You are preparing context for an AI coding agent that will work on tasks in this
 
 {{.Description}}
 {{end}}
+{{if .RepoMap}}
+## Codebase Map
 
+The agent also receives this repository-wide map with every task. Don't repeat
+what it covers (module layout, build and test commands, general conventions);
+focus on what is specific to this epic.
+
+{{.RepoMap}}
+{{end}}
 ## Instructions
 
 Analyze the codebase and generate a context document that will help complete these tasks.
@@ -95,6 +113,91 @@ Wrap your context document in <epic_context> tags:
 </epic_context>
 `
 
+// updateData holds the data passed to the context update template.
+type updateData struct {
+	EpicID    string
+	EpicTitle string
+	Context   string
+	Task      TaskChange
+	MaxTokens int
+}
+
+// contextUpdateTemplate is the prompt template for updating an existing epic
+// context after a task completes. It asks for a minimal rewrite so the update
+// stays cheap and unaffected sections are kept verbatim.
+const contextUpdateTemplate = `# Update Epic Context
+
+An AI coding agent just completed a task in this epic. The context document
+below was written before that task. Update it so it matches the codebase now.
+
+## Epic
+**[{{.EpicID}}] {{.EpicTitle}}**
+
+## Completed Task
+**[{{.Task.ID}}] {{.Task.Title}}**
+{{if .Task.CloseReason}}
+Close reason: {{.Task.CloseReason}}
+{{end}}
+## Changes Made
+
+` + "```diff" + `
+{{.Task.Diff}}
+` + "```" + `
+
+## Current Context
+
+<epic_context>
+{{.Context}}
+</epic_context>
+
+## Instructions
+
+- Rewrite only the sections the changes affect (new or moved files, changed
+  types and functions, new patterns). Keep every other section word for word.
+- Do not explore the codebase beyond what the changes require.
+- Do not describe the completed task itself; the context is for the tasks still to come.
+- Keep the document under {{.MaxTokens}} tokens.
+
+## Output Format
+
+Return the complete updated document wrapped in <epic_context> tags.
+`
+
+// repoMapTemplate is the prompt template for generating the repository-wide
+// codebase map shared by all epics.
+const repoMapTemplate = `# Generate Codebase Map
+
+You are preparing an overview of this repository for AI coding agents. It is
+generated once and shared by every task in every epic, so keep it general:
+nothing specific to one feature or task.
+
+## Instructions
+
+Explore the repository and describe:
+
+1. **Layout** - Top-level modules, packages or directories and what each is for
+2. **Build & Test** - Exact commands to build, test, lint and format
+3. **Architecture** - Main entry points and how the major parts fit together
+4. **Conventions** - Error handling, logging, naming, test layout and other
+   patterns that apply across the codebase
+
+## Constraints
+
+- Keep the map under {{.MaxTokens}} tokens
+- Prefer file paths and short descriptions over prose
+- Summarize, don't copy entire files
+
+## Output Format
+
+Wrap your map in <repo_map> tags:
+
+<repo_map>
+# Codebase Map
+
+(your markdown content here)
+</repo_map>
+`
+
 // DefaultMaxTokens is the default max token limit for context documents.
 const DefaultMaxTokens = 4000
 
@@ -108,19 +211,79 @@ func PromptWithMaxTokens(tokens int) PromptBuilderOption {
 	}
 }
 
-// NewPromptBuilder creates a new PromptBuilder with the default template.
-func NewPromptBuilder(opts ...PromptBuilderOption) (*PromptBuilder, error) {
-	tmpl, err := template.New("context-generation").Parse(contextGenerationTemplate)
+// PromptWithTemplate replaces the built-in generation template (see
+// LoadPromptTemplate). Empty keeps the built-in one.
+func PromptWithTemplate(text string) PromptBuilderOption {
+	return func(pb *PromptBuilder) {
+		pb.text = text
+	}
+}
+
+// PromptTemplateFile is the user's context generation template, relative to
+// the repository root.
+const PromptTemplateFile = ".ticker/prompts/context.md.tmpl"
+
+// LoadPromptTemplate reads the user's context generation template from dir.
+// Returns "" if there is none. An invalid template (one that fails to parse
+// or to render sample data) returns "" with an error, so callers fall back
+// to the built-in template.
+func LoadPromptTemplate(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, PromptTemplateFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("reading prompt template: %w", err)
 	}
+
+	tmpl, err := template.New("context-generation").Parse(string(data))
+	if err == nil {
+		err = tmpl.Execute(io.Discard, samplePromptData)
+	}
+	if err != nil {
+		return "", fmt.Errorf("prompt template %s: %w", filepath.Base(PromptTemplateFile), err)
+	}
+	return string(data), nil
+}
+
+// samplePromptData exercises every field when validating user templates.
+var samplePromptData = promptData{
+	EpicID:          "abc",
+	EpicTitle:       "Sample epic",
+	EpicDescription: "Sample epic description.",
+	Tasks:           []taskData{{ID: "def", Title: "Sample task", Description: "Sample task description."}},
+	MaxTokens:       DefaultMaxTokens,
+	RepoMap:         "Sample codebase map",
+}
+
+// NewPromptBuilder creates a new PromptBuilder with the default template.
+func NewPromptBuilder(opts ...PromptBuilderOption) (*PromptBuilder, error) {
 	pb := &PromptBuilder{
-		tmpl:      tmpl,
 		maxTokens: DefaultMaxTokens,
+		text:      contextGenerationTemplate,
 	}
 	for _, opt := range opts {
 		opt(pb)
 	}
+	if pb.text == "" {
+		pb.text = contextGenerationTemplate
+	}
+
+	tmpl, err := template.New("context-generation").Parse(pb.text)
+	if err != nil {
+		return nil, err
+	}
+	updateTmpl, err := template.New("context-update").Parse(contextUpdateTemplate)
+	if err != nil {
+		return nil, err
+	}
+	repoMapTmpl, err := template.New("repo-map").Parse(repoMapTemplate)
+	if err != nil {
+		return nil, err
+	}
+	pb.tmpl = tmpl
+	pb.updateTmpl = updateTmpl
+	pb.repoMapTmpl = repoMapTmpl
 	return pb, nil
 }
 
@@ -132,6 +295,7 @@ func (p *PromptBuilder) Build(epic *ticks.Epic, tasks []ticks.Task) (string, err
 		EpicDescription: epic.Description,
 		Tasks:           make([]taskData, len(tasks)),
 		MaxTokens:       p.maxTokens,
+		RepoMap:         p.repoMap,
 	}
 
 	for i, t := range tasks {
@@ -149,3 +313,37 @@ func (p *PromptBuilder) Build(epic *ticks.Epic, tasks []ticks.Task) (string, err
 
 	return buf.String(), nil
 }
+
+// BuildUpdate generates the prompt for updating context (the current
+// document for epic) after change.
+func (p *PromptBuilder) BuildUpdate(epic *ticks.Epic, context string, change TaskChange) (string, error) {
+	data := updateData{
+		EpicID:    epic.ID,
+		EpicTitle: epic.Title,
+		Context:   context,
+		Task:      change,
+		MaxTokens: p.maxTokens,
+	}
+
+	var buf bytes.Buffer
+	if err := p.updateTmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// SetRepoMap sets the codebase map included in generation prompts, so epic
+// context can leave out what the map already covers. Empty means none.
+func (p *PromptBuilder) SetRepoMap(content string) {
+	p.repoMap = content
+}
+
+// BuildRepoMap generates the prompt for the repository-wide codebase map.
+func (p *PromptBuilder) BuildRepoMap() (string, error) {
+	var buf bytes.Buffer
+	if err := p.repoMapTmpl.Execute(&buf, struct{ MaxTokens int }{p.maxTokens}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
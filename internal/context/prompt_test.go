@@ -1,6 +1,8 @@
 package context
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -311,3 +313,149 @@ func TestPromptBuilder_Build_ManyTasks(t *testing.T) {
 		}
 	}
 }
+
+func TestPromptBuilder_BuildUpdate(t *testing.T) {
+	pb, err := NewPromptBuilder(PromptWithMaxTokens(2000))
+	if err != nil {
+		t.Fatalf("NewPromptBuilder() error = %v", err)
+	}
+
+	epic := &ticks.Epic{ID: "h8d", Title: "Parallel test execution"}
+	change := TaskChange{
+		ID:          "abc",
+		Title:       "Add worker pool",
+		CloseReason: "Added pool.go with a fixed-size pool",
+		Diff:        "+func NewPool(n int) *Pool {",
+	}
+
+	result, err := pb.BuildUpdate(epic, "# Epic Context: [h8d]\n\n## Relevant Code", change)
+	if err != nil {
+		t.Fatalf("BuildUpdate() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"# Update Epic Context",
+		"[h8d] Parallel test execution",
+		"[abc] Add worker pool",
+		"Close reason: Added pool.go with a fixed-size pool",
+		"```diff\n+func NewPool(n int) *Pool {\n```",
+		"<epic_context>\n# Epic Context: [h8d]\n\n## Relevant Code\n</epic_context>",
+		"under 2000 tokens",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("BuildUpdate() result missing %q", want)
+		}
+	}
+
+	// No close reason line without a reason
+	change.CloseReason = ""
+	result, err = pb.BuildUpdate(epic, "ctx", change)
+	if err != nil {
+		t.Fatalf("BuildUpdate() error = %v", err)
+	}
+	if strings.Contains(result, "Close reason:") {
+		t.Error("BuildUpdate() result has close reason line for empty reason")
+	}
+}
+
+func TestPromptBuilder_Build_RepoMap(t *testing.T) {
+	pb, err := NewPromptBuilder()
+	if err != nil {
+		t.Fatalf("NewPromptBuilder() error = %v", err)
+	}
+	epic := &ticks.Epic{ID: "h8d", Title: "Parallel test execution"}
+
+	result, err := pb.Build(epic, nil)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if strings.Contains(result, "## Codebase Map") {
+		t.Error("Build() result has codebase map section without a map")
+	}
+
+	pb.SetRepoMap("- internal/: packages\n- make test: run tests")
+	result, err = pb.Build(epic, nil)
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if !strings.Contains(result, "## Codebase Map") || !strings.Contains(result, "- make test: run tests") {
+		t.Error("Build() result missing codebase map")
+	}
+	if strings.Index(result, "## Codebase Map") > strings.Index(result, "## Instructions") {
+		t.Error("codebase map should come before instructions")
+	}
+}
+
+func TestPromptBuilder_BuildRepoMap(t *testing.T) {
+	pb, err := NewPromptBuilder(PromptWithMaxTokens(3000))
+	if err != nil {
+		t.Fatalf("NewPromptBuilder() error = %v", err)
+	}
+
+	result, err := pb.BuildRepoMap()
+	if err != nil {
+		t.Fatalf("BuildRepoMap() error = %v", err)
+	}
+	for _, want := range []string{
+		"# Generate Codebase Map",
+		"**Build & Test**",
+		"under 3000 tokens",
+		"<repo_map>",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("BuildRepoMap() result missing %q", want)
+		}
+	}
+}
+
+func TestLoadPromptTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // "" = no file
+		want    string
+		wantErr bool
+	}{
+		{"no template", "", "", false},
+		{"valid template", "Context for {{.EpicID}} ({{len .Tasks}} tasks)", "Context for {{.EpicID}} ({{len .Tasks}} tasks)", false},
+		{"parse error", "{{range .Tasks}}", "", true},
+		{"unknown field", "{{.Nope}}", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.content != "" {
+				path := filepath.Join(dir, PromptTemplateFile)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("MkdirAll() error = %v", err)
+				}
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+
+			got, err := LoadPromptTemplate(dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadPromptTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LoadPromptTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromptBuilder_Build_CustomTemplate(t *testing.T) {
+	pb, err := NewPromptBuilder(PromptWithTemplate("{{.EpicID}}:{{range .Tasks}} {{.ID}}{{end}} <{{.MaxTokens}}"), PromptWithMaxTokens(500))
+	if err != nil {
+		t.Fatalf("NewPromptBuilder() error = %v", err)
+	}
+
+	got, err := pb.Build(&ticks.Epic{ID: "h8d"}, []ticks.Task{{ID: "a"}, {ID: "b"}})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if got != "h8d: a b <500" {
+		t.Errorf("Build() = %q, want custom template output", got)
+	}
+}
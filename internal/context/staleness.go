@@ -0,0 +1,64 @@
+package context
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RefreshPolicy decides when a stored context is stale and should be
+// regenerated. Zero values disable the corresponding check.
+type RefreshPolicy struct {
+	// MaxAge is how old a context may get.
+	MaxAge time.Duration
+
+	// MaxCommits is how many commits may land after the context was generated.
+	MaxCommits int
+}
+
+// Enabled reports whether the policy checks anything.
+func (p RefreshPolicy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxCommits > 0
+}
+
+// StaleReason returns why a context with meta is stale under p, or "" if it
+// is fresh. dir is the repository used to count commits since meta.GitCommit;
+// the commit check is skipped if the commit is unknown or can't be found.
+func (p RefreshPolicy) StaleReason(meta *Metadata, dir string, now time.Time) string {
+	if meta == nil {
+		return ""
+	}
+	if p.MaxAge > 0 && !meta.GeneratedAt.IsZero() {
+		if age := now.Sub(meta.GeneratedAt); age > p.MaxAge {
+			return fmt.Sprintf("generated %s ago", formatAge(age))
+		}
+	}
+	if p.MaxCommits > 0 && meta.GitCommit != "" {
+		if n, err := CommitsSince(dir, meta.GitCommit); err == nil && n > p.MaxCommits {
+			return fmt.Sprintf("%d commits since generation", n)
+		}
+	}
+	return ""
+}
+
+// formatAge renders an age in days, or hours when under a day.
+func formatAge(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%dh", int(d/time.Hour))
+}
+
+// CommitsSince returns the number of commits reachable from HEAD but not from
+// commit, in the repository at dir.
+func CommitsSince(dir, commit string) (int, error) {
+	cmd := exec.Command("git", "rev-list", "--count", commit+"..HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("counting commits since %s: %w", commit, err)
+	}
+	return strconv.Atoi(strings.TrimSpace(string(out)))
+}
@@ -0,0 +1,97 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/gitutil"
+)
+
+func TestRefreshPolicy_StaleReason_Age(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		policy RefreshPolicy
+		meta   *Metadata
+		want   string
+	}{
+		{"no policy", RefreshPolicy{}, &Metadata{GeneratedAt: now.AddDate(0, -1, 0)}, ""},
+		{"no metadata", RefreshPolicy{MaxAge: 24 * time.Hour}, nil, ""},
+		{"fresh", RefreshPolicy{MaxAge: 7 * 24 * time.Hour}, &Metadata{GeneratedAt: now.AddDate(0, 0, -2)}, ""},
+		{"too old", RefreshPolicy{MaxAge: 7 * 24 * time.Hour}, &Metadata{GeneratedAt: now.AddDate(0, 0, -9)}, "generated 9d ago"},
+		{"unknown age", RefreshPolicy{MaxAge: 24 * time.Hour}, &Metadata{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.StaleReason(tt.meta, t.TempDir(), now); got != tt.want {
+				t.Errorf("StaleReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRefreshPolicy_StaleReason_Commits(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(n int) {
+		t.Helper()
+		name := fmt.Sprintf("file%d.txt", n)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", name)
+		git("commit", "-m", "commit "+name)
+	}
+
+	git("init")
+	git("config", "user.email", "test@test.com")
+	git("config", "user.name", "Test User")
+	commit(0)
+	base := gitutil.HeadCommit(dir)
+	if base == "" {
+		t.Fatal("HeadCommit() = empty in git repo")
+	}
+	for i := 1; i <= 3; i++ {
+		commit(i)
+	}
+
+	if n, err := CommitsSince(dir, base); err != nil || n != 3 {
+		t.Fatalf("CommitsSince() = %d, %v; want 3", n, err)
+	}
+
+	now := time.Now()
+	meta := &Metadata{GeneratedAt: now, GitCommit: base}
+	tests := []struct {
+		name   string
+		policy RefreshPolicy
+		meta   *Metadata
+		want   string
+	}{
+		{"under threshold", RefreshPolicy{MaxCommits: 3}, meta, ""},
+		{"over threshold", RefreshPolicy{MaxCommits: 2}, meta, "3 commits since generation"},
+		{"unknown commit", RefreshPolicy{MaxCommits: 1}, &Metadata{GeneratedAt: now, GitCommit: strings.Repeat("0", 40)}, ""},
+		{"no commit recorded", RefreshPolicy{MaxCommits: 1}, &Metadata{GeneratedAt: now}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.StaleReason(tt.meta, dir, now); got != tt.want {
+				t.Errorf("StaleReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
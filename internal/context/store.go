@@ -1,13 +1,18 @@
 package context
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 // Store manages reading and writing epic context documents.
 // Context documents are stored as markdown files in .ticker/context/<epic-id>.md
+// with generation metadata alongside in <epic-id>.meta.json. The shared
+// codebase map is stored the same way under RepoMapID.
 type Store struct {
 	// dir is the directory where context documents are stored.
 	dir string
@@ -43,23 +48,101 @@ func (s *Store) Save(epicID, content string) error {
 	}
 
 	filename := filepath.Join(s.dir, epicID+".md")
+	return writeAtomic(filename, []byte(content), "context file")
+}
 
+// writeAtomic writes data to a temp file, then renames it into place so
+// readers never see a partial file. what names the file in errors.
+func writeAtomic(filename string, data []byte, what string) error {
 	// Write to temp file first for atomic operation
 	tempFile := filename + ".tmp"
-	if err := os.WriteFile(tempFile, []byte(content), 0644); err != nil {
-		return fmt.Errorf("writing temp context file: %w", err)
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("writing temp %s: %w", what, err)
 	}
 
 	// Rename temp file to final location (atomic on most filesystems)
 	if err := os.Rename(tempFile, filename); err != nil {
 		// Clean up temp file on rename failure
 		os.Remove(tempFile)
-		return fmt.Errorf("renaming context file: %w", err)
+		return fmt.Errorf("renaming %s: %w", what, err)
 	}
 
 	return nil
 }
 
+// Metadata describes how and when a context document was generated.
+type Metadata struct {
+	// GeneratedAt is when the context was generated.
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// GitCommit is HEAD when the context was generated or last updated
+	// ("" if unknown).
+	GitCommit string `json:"git_commit,omitempty"`
+
+	// Agent and Model are what generated the context ("" model = agent default).
+	Agent string `json:"agent,omitempty"`
+	Model string `json:"model,omitempty"`
+
+	// TaskCount is the number of tasks in the epic at generation time.
+	TaskCount int `json:"task_count"`
+
+	// UpdatedAt is when the context was last updated incrementally after a
+	// task completed, and Updates how many times (zero if never).
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	Updates   int       `json:"updates,omitempty"`
+}
+
+// SaveMetadata writes the metadata for an epic's context document.
+func (s *Store) SaveMetadata(epicID string, meta Metadata) error {
+	if epicID == "" {
+		return fmt.Errorf("epic ID is required")
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("creating context directory: %w", err)
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding context metadata: %w", err)
+	}
+	return writeAtomic(s.metadataPath(epicID), data, "context metadata")
+}
+
+// LoadMetadata reads the metadata for an epic's context document.
+// Context saved without metadata (by older versions) gets its GeneratedAt
+// from the file's modification time. Returns nil (not error) if the context
+// doesn't exist.
+func (s *Store) LoadMetadata(epicID string) (*Metadata, error) {
+	if epicID == "" {
+		return nil, fmt.Errorf("epic ID is required")
+	}
+
+	data, err := os.ReadFile(s.metadataPath(epicID))
+	if err == nil {
+		var meta Metadata
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, fmt.Errorf("parsing context metadata: %w", err)
+		}
+		return &meta, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading context metadata: %w", err)
+	}
+
+	info, err := os.Stat(filepath.Join(s.dir, epicID+".md"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading context file: %w", err)
+	}
+	return &Metadata{GeneratedAt: info.ModTime()}, nil
+}
+
+// metadataPath returns the metadata file path for an epic.
+func (s *Store) metadataPath(epicID string) string {
+	return filepath.Join(s.dir, epicID+".meta.json")
+}
+
 // Load reads a context document for an epic.
 // Returns empty string (not error) if the context doesn't exist.
 func (s *Store) Load(epicID string) (string, error) {
@@ -105,5 +188,8 @@ func (s *Store) Delete(epicID string) error {
 		}
 		return fmt.Errorf("deleting context file: %w", err)
 	}
+	if err := os.Remove(s.metadataPath(epicID)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("deleting context metadata: %w", err)
+	}
 	return nil
 }
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewStore(t *testing.T) {
@@ -703,3 +704,81 @@ func TestStore_OverwriteContent(t *testing.T) {
 		t.Errorf("Load() = %q, want empty string", loaded)
 	}
 }
+
+func TestStore_Metadata(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStoreWithDir(dir)
+
+	// No context yet
+	meta, err := s.LoadMetadata("epic1")
+	if err != nil || meta != nil {
+		t.Fatalf("LoadMetadata() = %v, %v; want nil, nil", meta, err)
+	}
+
+	want := Metadata{
+		GeneratedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		GitCommit:   "abc123",
+		Agent:       "claude",
+		Model:       "sonnet",
+		TaskCount:   4,
+	}
+	if err := s.Save("epic1", "# Context"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := s.SaveMetadata("epic1", want); err != nil {
+		t.Fatalf("SaveMetadata() error = %v", err)
+	}
+
+	meta, err = s.LoadMetadata("epic1")
+	if err != nil {
+		t.Fatalf("LoadMetadata() error = %v", err)
+	}
+	if !meta.GeneratedAt.Equal(want.GeneratedAt) || meta.GitCommit != want.GitCommit ||
+		meta.Agent != want.Agent || meta.Model != want.Model || meta.TaskCount != want.TaskCount {
+		t.Errorf("LoadMetadata() = %+v, want %+v", meta, want)
+	}
+
+	// Delete removes metadata along with the context
+	if err := s.Delete("epic1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "epic1.meta.json")); err == nil {
+		t.Error("Delete() left the metadata file behind")
+	}
+}
+
+func TestStore_LoadMetadata_LegacyContext(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStoreWithDir(dir)
+
+	// Context saved without metadata falls back to the file's mtime
+	if err := s.Save("old", "# Context"); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	mtime := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(filepath.Join(dir, "old.md"), mtime, mtime); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	meta, err := s.LoadMetadata("old")
+	if err != nil {
+		t.Fatalf("LoadMetadata() error = %v", err)
+	}
+	if meta == nil || !meta.GeneratedAt.Equal(mtime) {
+		t.Errorf("LoadMetadata() = %+v, want GeneratedAt %v", meta, mtime)
+	}
+	if meta.GitCommit != "" {
+		t.Errorf("GitCommit = %q, want empty for legacy context", meta.GitCommit)
+	}
+}
+
+func TestStore_LoadMetadata_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStoreWithDir(dir)
+	if err := os.WriteFile(filepath.Join(dir, "bad.meta.json"), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LoadMetadata("bad"); err == nil {
+		t.Error("LoadMetadata() should error on corrupt metadata")
+	}
+}
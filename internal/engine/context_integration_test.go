@@ -2,6 +2,9 @@ package engine
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -11,6 +14,7 @@ import (
 	"github.com/pengelbrecht/ticker/internal/budget"
 	"github.com/pengelbrecht/ticker/internal/checkpoint"
 	epiccontext "github.com/pengelbrecht/ticker/internal/context"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
 	"github.com/pengelbrecht/ticker/internal/ticks"
 )
 
@@ -70,12 +74,14 @@ type mockTicksClientForContext struct {
 	notes         []string
 	addedNotes    []string
 	statusUpdates map[string]string
+	runRecords    map[string]*agent.RunRecord
 }
 
 func newMockTicksClientForContext() *mockTicksClientForContext {
 	return &mockTicksClientForContext{
 		closedTasks:   make(map[string]bool),
 		statusUpdates: make(map[string]string),
+		runRecords:    make(map[string]*agent.RunRecord),
 	}
 }
 
@@ -170,9 +176,14 @@ func (m *mockTicksClientForContext) SetAwaiting(taskID, awaiting, note string) e
 }
 
 func (m *mockTicksClientForContext) SetRunRecord(taskID string, record *agent.RunRecord) error {
+	m.runRecords[taskID] = record
 	return nil
 }
 
+func (m *mockTicksClientForContext) GetRunRecord(taskID string) (*agent.RunRecord, error) {
+	return m.runRecords[taskID], nil
+}
+
 // =============================================================================
 // Integration Tests for Engine Context Generation
 // =============================================================================
@@ -248,6 +259,15 @@ func TestEngine_ContextGeneration_ThreeTasks(t *testing.T) {
 		t.Errorf("context content = %q, should contain 'Generated Context'", content)
 	}
 
+	// Metadata is saved alongside the context
+	meta, err := store.LoadMetadata("epic-123")
+	if err != nil {
+		t.Fatalf("LoadMetadata() error = %v", err)
+	}
+	if meta == nil || meta.TaskCount != 3 || meta.Agent != "test" {
+		t.Errorf("LoadMetadata() = %+v, want 3 tasks generated by test agent", meta)
+	}
+
 	// Agent should have been called at least twice: once for context, once for task
 	if mockAg.runCallCount < 2 {
 		t.Errorf("agent.Run() called %d times, want at least 2", mockAg.runCallCount)
@@ -395,6 +415,234 @@ func TestEngine_ContextGeneration_AlreadyExists(t *testing.T) {
 	}
 }
 
+func TestEngine_ContextGeneration_Stale(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     epiccontext.RefreshPolicy
+		generated  time.Time
+		wantRegen  bool
+		wantCalls  int
+		wantPrefix string
+	}{
+		{"no policy keeps old context", epiccontext.RefreshPolicy{}, time.Now().AddDate(0, 0, -30), false, 1, "# Old"},
+		{"fresh context kept", epiccontext.RefreshPolicy{MaxAge: 7 * 24 * time.Hour}, time.Now().AddDate(0, 0, -1), false, 1, "# Old"},
+		{"stale context regenerated", epiccontext.RefreshPolicy{MaxAge: 7 * 24 * time.Hour}, time.Now().AddDate(0, 0, -10), true, 2, "# New"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store := epiccontext.NewStoreWithDir(filepath.Join(dir, "context"))
+			if err := store.Save("epic-stale", "# Old Context"); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if err := store.SaveMetadata("epic-stale", epiccontext.Metadata{GeneratedAt: tt.generated, TaskCount: 2}); err != nil {
+				t.Fatalf("SaveMetadata() error = %v", err)
+			}
+
+			mockTicks := newMockTicksClientForContext()
+			mockTicks.epic = &ticks.Epic{ID: "epic-stale", Title: "Stale Epic", Type: "epic"}
+			mockTicks.tasks = []*ticks.Task{
+				{ID: "task-1", Title: "Task 1", Status: "open"},
+				{ID: "task-2", Title: "Task 2", Status: "open"},
+			}
+			mockAg := &mockAgentForContext{
+				name:          "test",
+				available:     true,
+				contextOutput: "# New Context",
+				taskOutputs:   []string{"Task 1 done"},
+			}
+
+			engine := &Engine{
+				agent:      mockAg,
+				ticks:      mockTicks,
+				budget:     budget.NewTracker(budget.Limits{MaxIterations: 1}),
+				checkpoint: checkpoint.NewManagerWithDir(filepath.Join(dir, "checkpoints")),
+				prompt:     NewPromptBuilder(),
+			}
+			generator, err := epiccontext.NewGenerator(mockAg)
+			if err != nil {
+				t.Fatalf("NewGenerator() error = %v", err)
+			}
+			engine.SetContextComponents(store, generator)
+			engine.SetContextRefresh(tt.policy)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := engine.Run(ctx, RunConfig{EpicID: "epic-stale", MaxIterations: 1, AgentTimeout: time.Second}); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			content, _ := store.Load("epic-stale")
+			if !strings.HasPrefix(content, tt.wantPrefix) {
+				t.Errorf("context = %q, want prefix %q", content, tt.wantPrefix)
+			}
+			if mockAg.runCallCount != tt.wantCalls {
+				t.Errorf("agent.Run() called %d times, want %d", mockAg.runCallCount, tt.wantCalls)
+			}
+			meta, _ := store.LoadMetadata("epic-stale")
+			if regenerated := meta != nil && meta.GeneratedAt.After(tt.generated.Add(time.Minute)); regenerated != tt.wantRegen {
+				t.Errorf("metadata regenerated = %v, want %v", regenerated, tt.wantRegen)
+			}
+		})
+	}
+}
+
+func TestEngine_updateEpicContext(t *testing.T) {
+	tests := []struct {
+		name        string
+		enabled     bool
+		change      bool
+		wantContext string
+		wantUpdates int
+	}{
+		{"disabled", false, true, "# Old Context", 0},
+		{"no changes", true, false, "# Old Context", 0},
+		{"updated from diff", true, true, "# Updated Context", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := createTempGitRepo(t)
+			store := epiccontext.NewStoreWithDir(filepath.Join(t.TempDir(), "context"))
+			if err := store.Save("epic-1", "# Old Context"); err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if err := store.SaveMetadata("epic-1", epiccontext.Metadata{GeneratedAt: time.Now().Add(-time.Hour), GitCommit: "old"}); err != nil {
+				t.Fatalf("SaveMetadata() error = %v", err)
+			}
+
+			mockAg := &mockAgentForContext{name: "test", available: true, contextOutput: "<epic_context>\n# Updated Context\n</epic_context>"}
+			generator, err := epiccontext.NewGenerator(mockAg)
+			if err != nil {
+				t.Fatalf("NewGenerator() error = %v", err)
+			}
+			mockTicks := newMockTicksClientForContext()
+			mockTicks.tasks = []*ticks.Task{{ID: "task-1", Title: "Add pool", Status: "closed"}}
+
+			b := budget.NewTracker(budget.Limits{})
+			e := &Engine{ticks: mockTicks, budget: b}
+			e.SetContextComponents(store, generator)
+			if tt.enabled {
+				e.EnableContextUpdates()
+			}
+
+			state := &runState{
+				epic:        &ticks.Epic{ID: "epic-1", Title: "Epic"},
+				epicContext: "# Old Context",
+				workDir:     repo,
+				baseRefs:    map[string]string{"task-1": gitutil.HeadCommit(repo)},
+			}
+			if tt.change {
+				if err := os.WriteFile(filepath.Join(repo, "initial.txt"), []byte("changed"), 0644); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			}
+
+			e.updateEpicContext(context.Background(), state, mockTicks.tasks[0])
+
+			if state.epicContext != tt.wantContext {
+				t.Errorf("state.epicContext = %q, want %q", state.epicContext, tt.wantContext)
+			}
+			if content, _ := store.Load("epic-1"); content != tt.wantContext {
+				t.Errorf("stored context = %q, want %q", content, tt.wantContext)
+			}
+			meta, err := store.LoadMetadata("epic-1")
+			if err != nil || meta == nil {
+				t.Fatalf("LoadMetadata() = %v, %v", meta, err)
+			}
+			if meta.Updates != tt.wantUpdates {
+				t.Errorf("meta.Updates = %d, want %d", meta.Updates, tt.wantUpdates)
+			}
+			if tt.wantUpdates > 0 {
+				if !strings.Contains(mockAg.lastPrompt, "+changed") {
+					t.Errorf("update prompt missing task diff: %q", mockAg.lastPrompt)
+				}
+				if meta.GitCommit != state.baseRefs["task-1"] || meta.UpdatedAt.IsZero() {
+					t.Errorf("metadata = %+v, want HEAD commit and update time recorded", meta)
+				}
+			} else if mockAg.runCallCount != 0 {
+				t.Errorf("agent.Run() called %d times, want 0", mockAg.runCallCount)
+			}
+			// The update's usage counts toward the budget, not as an iteration
+			if usage := b.Usage(); usage.TokensIn != 100*mockAg.runCallCount || usage.Iterations != 0 {
+				t.Errorf("budget usage = %+v, want %d tokens in and no iterations", usage, 100*mockAg.runCallCount)
+			}
+		})
+	}
+}
+
+func TestEngine_ensureRepoMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		existing  string
+		policy    epiccontext.RefreshPolicy
+		commits   int // commits after the existing map was generated
+		want      string
+		wantCalls int
+	}{
+		{"disabled", false, "", epiccontext.RefreshPolicy{}, 0, "", 0},
+		{"generated when missing", true, "", epiccontext.RefreshPolicy{}, 0, "# New Map", 1},
+		{"existing map reused", true, "# Old Map", epiccontext.RefreshPolicy{MaxCommits: 2}, 2, "# Old Map", 0},
+		{"stale map regenerated", true, "# Old Map", epiccontext.RefreshPolicy{MaxCommits: 2}, 3, "# New Map", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := createTempGitRepo(t)
+			store := epiccontext.NewStoreWithDir(filepath.Join(t.TempDir(), "context"))
+			if tt.existing != "" {
+				if err := store.Save(epiccontext.RepoMapID, tt.existing); err != nil {
+					t.Fatalf("Save() error = %v", err)
+				}
+				meta := epiccontext.Metadata{GeneratedAt: time.Now(), GitCommit: gitutil.HeadCommit(repo)}
+				if err := store.SaveMetadata(epiccontext.RepoMapID, meta); err != nil {
+					t.Fatalf("SaveMetadata() error = %v", err)
+				}
+			}
+			for i := 0; i < tt.commits; i++ {
+				cmd := exec.Command("git", "commit", "--allow-empty", "-m", fmt.Sprintf("commit %d", i))
+				cmd.Dir = repo
+				if err := cmd.Run(); err != nil {
+					t.Fatalf("git commit error = %v", err)
+				}
+			}
+
+			mockAg := &mockAgentForContext{name: "test", available: true, contextOutput: "<repo_map># New Map</repo_map>"}
+			generator, err := epiccontext.NewGenerator(mockAg)
+			if err != nil {
+				t.Fatalf("NewGenerator() error = %v", err)
+			}
+			b := budget.NewTracker(budget.Limits{})
+			e := &Engine{budget: b}
+			e.SetContextComponents(store, generator)
+			if tt.enabled {
+				e.EnableRepoMap(tt.policy)
+			}
+
+			got := e.ensureRepoMap(context.Background(), &runState{workDir: repo})
+			if got != tt.want {
+				t.Errorf("ensureRepoMap() = %q, want %q", got, tt.want)
+			}
+			if mockAg.runCallCount != tt.wantCalls {
+				t.Errorf("agent.Run() called %d times, want %d", mockAg.runCallCount, tt.wantCalls)
+			}
+			if usage := b.Usage(); usage.TokensIn != 100*tt.wantCalls || usage.Iterations != 0 {
+				t.Errorf("budget usage = %+v, want %d tokens in and no iterations", usage, 100*tt.wantCalls)
+			}
+
+			// The map is handed to epic context generation
+			if _, err := generator.Generate(context.Background(), &ticks.Epic{ID: "e1"}, nil); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if hasMap := strings.Contains(mockAg.lastPrompt, "## Codebase Map"); hasMap != (tt.want != "") {
+				t.Errorf("context prompt has codebase map = %v, want %v", hasMap, tt.want != "")
+			}
+		})
+	}
+}
+
 func TestEngine_ContextGeneration_GeneratorFails(t *testing.T) {
 	// Test: Generation fails - run proceeds without context
 	dir := t.TempDir()
@@ -1,3 +1,6 @@
 // Package engine provides the core Ralph loop orchestration.
 // It manages iteration lifecycles, signal detection, and verification hooks.
+//
+// Progress is reported as typed events on an EventBus (see Engine.Subscribe).
+// The run log, HeadlessOutput and the TUI are all subscribers.
 package engine
@@ -0,0 +1,183 @@
+package engine
+
+import (
+	"fmt"
+	"os"
+
+	epiccontext "github.com/pengelbrecht/ticker/internal/context"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+// DryRunResult describes what a run would do, as worked out by DryRun.
+type DryRunResult struct {
+	EpicID string
+
+	// Context says what would happen to the epic context before the first
+	// iteration, e.g. "generate (5 tasks)" or "use existing".
+	Context string
+
+	// RepoMap says the same for the shared codebase map.
+	RepoMap string
+
+	// Iterations are the iterations the run would go through if every task
+	// were closed by its first attempt.
+	Iterations []DryRunIteration
+
+	// Remaining are the open tasks the run would not reach: blocked, awaiting
+	// a human, or past the iteration limit.
+	Remaining []ticks.Task
+}
+
+// DryRunIteration is one iteration of a dry run.
+type DryRunIteration struct {
+	Iteration int
+	Task      *ticks.Task
+	Agent     string
+	Prompt    string
+	Stats     PromptStats
+
+	// Verifiers are the names of the verifiers that would check the task,
+	// in order. Empty if verification is off.
+	Verifiers []string
+}
+
+// DryRun walks the iteration loop for config.EpicID without running an agent
+// or changing any ticks. Each task NextTask returns is assumed to be closed
+// by its iteration, which gives the order tasks would be worked in. Prompts
+// are built as Run would build them on a first attempt, using the stored
+// epic context and codebase map (which a real run might generate first).
+func (e *Engine) DryRun(config RunConfig) (*DryRunResult, error) {
+	if config.MaxIterations == 0 {
+		config.MaxIterations = DefaultMaxIterations
+	}
+
+	epic, err := e.ticks.GetEpic(config.EpicID)
+	if err != nil {
+		return nil, fmt.Errorf("getting epic: %w", err)
+	}
+	tasks, err := e.ticks.ListTasks(config.EpicID)
+	if err != nil {
+		return nil, fmt.Errorf("listing tasks: %w", err)
+	}
+
+	dir := config.WorkDir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	state := &runState{epicID: config.EpicID, epic: epic, workDir: config.WorkDir}
+	result := &DryRunResult{
+		EpicID:  config.EpicID,
+		Context: e.dryRunContext(epic.ID, dir, len(tasks)),
+		RepoMap: e.dryRunRepoMap(dir),
+	}
+	if e.contextStore != nil {
+		state.epicContext, _ = e.contextStore.Load(epic.ID)
+		if e.repoMap {
+			state.repoMap, _ = e.contextStore.Load(epiccontext.RepoMapID)
+		}
+	}
+	notes, _ := e.ticks.GetNotes(config.EpicID)
+	notes = notesSinceDigest(notes)
+	repo := repoInfo(state.workDir)
+
+	closed := make(map[string]bool)
+	for state.iteration < config.MaxIterations {
+		task, err := e.dryRunNextTask(config.EpicID, closed)
+		if err != nil {
+			return nil, fmt.Errorf("getting next task: %w", err)
+		}
+		if task == nil {
+			break
+		}
+		state.iteration++
+
+		humanNotes, _ := e.ticks.GetHumanNotes(task.ID)
+		iter := DryRunIteration{Iteration: state.iteration, Task: task}
+		iter.Prompt, iter.Stats = e.prompt.BuildWithStats(IterationContext{
+			Iteration:     state.iteration,
+			Epic:          epic,
+			Task:          task,
+			EpicNotes:     notes,
+			HumanFeedback: humanNotes,
+			EpicContext:   state.epicContext,
+			RepoMap:       state.repoMap,
+			Attempt:       1,
+			Repo:          repo,
+		})
+		if a, err := e.resolveAgent(epic, task); err == nil && a != nil {
+			iter.Agent = a.Name()
+		}
+		if e.verifyEnabled && !config.SkipVerify {
+			verifiers, _ := e.verifiers(state, task, dir)
+			for _, v := range verifiers {
+				iter.Verifiers = append(iter.Verifiers, v.Name())
+			}
+		}
+
+		result.Iterations = append(result.Iterations, iter)
+		closed[task.ID] = true
+	}
+
+	for _, t := range tasks {
+		if t.Status != "closed" && !closed[t.ID] {
+			result.Remaining = append(result.Remaining, t)
+		}
+	}
+	return result, nil
+}
+
+// dryRunNextTask returns the task NextTask would return once the tasks in
+// closed are closed.
+func (e *Engine) dryRunNextTask(epicID string, closed map[string]bool) (*ticks.Task, error) {
+	if len(closed) == 0 {
+		return e.ticks.NextTask(epicID)
+	}
+	tasks, err := e.ticks.ListTasks(epicID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tasks {
+		if closed[tasks[i].ID] {
+			tasks[i].Status = "closed"
+		}
+	}
+	return ticks.ReadyTask(tasks, func(id string) bool {
+		if closed[id] {
+			return true
+		}
+		blocker, err := e.ticks.GetTask(id)
+		return err != nil || blocker == nil || blocker.Status == "closed"
+	}), nil
+}
+
+// dryRunContext describes what ensureEpicContext would do for an epic with
+// taskCount tasks.
+func (e *Engine) dryRunContext(epicID, dir string, taskCount int) string {
+	if e.contextStore == nil || e.contextGenerator == nil {
+		return "disabled"
+	}
+	if e.contextStore.Exists(epicID) {
+		if reason := e.contextStaleReason(epicID, dir, e.contextRefresh); reason != "" {
+			return "regenerate (" + reason + ")"
+		}
+		return "use existing"
+	}
+	if taskCount <= 1 {
+		return "skip (single-task epic)"
+	}
+	return fmt.Sprintf("generate (%d tasks)", taskCount)
+}
+
+// dryRunRepoMap describes what ensureRepoMap would do.
+func (e *Engine) dryRunRepoMap(dir string) string {
+	if !e.repoMap || e.contextStore == nil || e.contextGenerator == nil {
+		return "disabled"
+	}
+	if !e.contextStore.Exists(epiccontext.RepoMapID) {
+		return "generate"
+	}
+	if reason := e.contextStaleReason(epiccontext.RepoMapID, dir, e.repoMapRefresh); reason != "" {
+		return "regenerate (" + reason + ")"
+	}
+	return "use existing"
+}
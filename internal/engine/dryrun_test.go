@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	epiccontext "github.com/pengelbrecht/ticker/internal/context"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+	"github.com/pengelbrecht/ticker/internal/verify"
+)
+
+func TestEngine_DryRun(t *testing.T) {
+	repo := createTempGitRepo(t)
+	awaiting := "approval"
+
+	mockTicks := newMockTicksClientForContext()
+	mockTicks.epic = &ticks.Epic{ID: "epic-1", Title: "Epic", Type: "epic"}
+	mockTicks.tasks = []*ticks.Task{
+		{ID: "task-1", Title: "First", Description: "Do first thing", Status: "open", Priority: 1},
+		{ID: "task-2", Title: "Second", Description: "Do second thing", Status: "open", Priority: 1, BlockedBy: []string{"task-3"}},
+		{ID: "task-3", Title: "Third", Description: "Do third thing", Status: "open", Priority: 2},
+		{ID: "task-4", Title: "Fourth", Description: "Needs sign-off", Status: "open", Priority: 1, Awaiting: &awaiting},
+		{ID: "task-5", Title: "Done", Status: "closed"},
+	}
+	mockTicks.notes = []string{"Use the v2 API"}
+	mockAg := &mockAgentForContext{name: "test", available: true}
+
+	store := epiccontext.NewStoreWithDir(filepath.Join(t.TempDir(), "context"))
+	generator, err := epiccontext.NewGenerator(mockAg)
+	if err != nil {
+		t.Fatalf("NewGenerator() error = %v", err)
+	}
+
+	e := NewEngine(mockAg, mockTicks, nil, nil)
+	e.SetContextComponents(store, generator)
+	e.EnableVerification()
+	e.SetVerificationConfig(&verify.Config{Commands: []*verify.CommandConfig{{Name: "test", Command: "go test ./..."}}})
+
+	result, err := e.DryRun(RunConfig{EpicID: "epic-1", WorkDir: repo})
+	if err != nil {
+		t.Fatalf("DryRun() error = %v", err)
+	}
+
+	var order []string
+	for _, it := range result.Iterations {
+		order = append(order, it.Task.ID)
+	}
+	if got := strings.Join(order, ","); got != "task-1,task-3,task-2" {
+		t.Errorf("task order = %s, want task-1,task-3,task-2", got)
+	}
+	if len(result.Remaining) != 1 || result.Remaining[0].ID != "task-4" {
+		t.Errorf("Remaining = %+v, want only task-4", result.Remaining)
+	}
+	if result.Context != "generate (5 tasks)" {
+		t.Errorf("Context = %q, want %q", result.Context, "generate (5 tasks)")
+	}
+	if result.RepoMap != "disabled" {
+		t.Errorf("RepoMap = %q, want %q", result.RepoMap, "disabled")
+	}
+
+	first := result.Iterations[0]
+	if !strings.Contains(first.Prompt, "Do first thing") || !strings.Contains(first.Prompt, "Use the v2 API") {
+		t.Error("prompt should include the task and epic notes")
+	}
+	if first.Agent != "test" {
+		t.Errorf("Agent = %q, want %q", first.Agent, "test")
+	}
+	if got := strings.Join(first.Verifiers, ","); got != "git,test" {
+		t.Errorf("Verifiers = %s, want git,test", got)
+	}
+
+	// Nothing ran and nothing changed
+	if mockAg.runCallCount != 0 {
+		t.Errorf("agent ran %d times, want 0", mockAg.runCallCount)
+	}
+	if len(mockTicks.addedNotes) != 0 || len(mockTicks.statusUpdates) != 0 || len(mockTicks.closedTasks) != 0 {
+		t.Errorf("ticks changed: notes %v, statuses %v, closed %v", mockTicks.addedNotes, mockTicks.statusUpdates, mockTicks.closedTasks)
+	}
+	if store.Exists("epic-1") {
+		t.Error("dry run should not generate context")
+	}
+}
+
+func TestEngine_DryRun_MaxIterations(t *testing.T) {
+	mockTicks := newMockTicksClientForContext()
+	mockTicks.epic = &ticks.Epic{ID: "epic-1", Title: "Epic", Type: "epic"}
+	mockTicks.tasks = []*ticks.Task{
+		{ID: "task-1", Title: "First", Status: "open"},
+		{ID: "task-2", Title: "Second", Status: "open"},
+	}
+	e := NewEngine(&mockAgentForContext{name: "test", available: true}, mockTicks, nil, nil)
+
+	result, err := e.DryRun(RunConfig{EpicID: "epic-1", MaxIterations: 1})
+	if err != nil {
+		t.Fatalf("DryRun() error = %v", err)
+	}
+	if len(result.Iterations) != 1 {
+		t.Errorf("len(Iterations) = %d, want 1", len(result.Iterations))
+	}
+	if len(result.Remaining) != 1 || result.Remaining[0].ID != "task-2" {
+		t.Errorf("Remaining = %+v, want only task-2", result.Remaining)
+	}
+	if result.Context != "disabled" || result.Iterations[0].Verifiers != nil {
+		t.Errorf("Context = %q, Verifiers = %v, want disabled and none", result.Context, result.Iterations[0].Verifiers)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/pengelbrecht/ticker/internal/budget"
 	"github.com/pengelbrecht/ticker/internal/checkpoint"
 	epiccontext "github.com/pengelbrecht/ticker/internal/context"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
 	"github.com/pengelbrecht/ticker/internal/runlog"
 	"github.com/pengelbrecht/ticker/internal/ticks"
 	"github.com/pengelbrecht/ticker/internal/verify"
@@ -35,11 +38,13 @@ type TicksClient interface {
 	SetStatus(issueID, status string) error
 	SetAwaiting(taskID, awaiting, note string) error
 	SetRunRecord(taskID string, record *agent.RunRecord) error
+	GetRunRecord(taskID string) (*agent.RunRecord, error)
 }
 
 // Engine orchestrates the Ralph iteration loop.
 type Engine struct {
 	agent      agent.Agent
+	agents     *agent.Registry // optional; resolves the agent per iteration
 	ticks      TicksClient
 	budget     *budget.Tracker
 	checkpoint *checkpoint.Manager
@@ -48,41 +53,38 @@ type Engine struct {
 	// Context generation components (optional)
 	contextStore     *epiccontext.Store
 	contextGenerator *epiccontext.Generator
+	contextRefresh   epiccontext.RefreshPolicy
+	contextUpdates   bool // update context after each verified task
+	repoMap          bool // use the shared codebase map
+	repoMapRefresh   epiccontext.RefreshPolicy
+
+	// Epic notes compaction (optional, set via EnableNotesCompaction)
+	compactNotesTokens int
+	compactionModel    string
 
 	// Verification enabled flag (set via EnableVerification)
 	verifyEnabled bool
 
+	// Verification config with command verifiers (optional, set via SetVerificationConfig)
+	verifyConfig *verify.Config
+
+	// Escalation ladder for retried tasks (optional, set via SetEscalation)
+	escalation *verify.EscalationConfig
+
+	// Resume the agent's session on retries (set via EnableSessionContinuation)
+	continueSessions bool
+
+	// Record each iteration's raw agent output (set via EnableTranscripts)
+	recordTranscripts bool
+
 	// Baseline of uncommitted files at engine start (for git verification)
 	gitBaseline map[string]bool
 
 	// Run logger for control flow events (optional)
 	runLog *runlog.Logger
 
-	// Callbacks for TUI integration (optional)
-	OnIterationStart func(ctx IterationContext)
-	OnIterationEnd   func(result *IterationResult)
-	OnOutput         func(chunk string)
-	OnSignal         func(signal Signal, reason string)
-
-	// Verification callbacks for TUI status display (optional)
-	OnVerificationStart func(taskID string)
-	OnVerificationEnd   func(taskID string, results *verify.Results)
-
-	// Context generation callbacks for TUI status display (optional)
-	OnContextGenerating func(epicID string, taskCount int)
-	OnContextGenerated  func(epicID string, tokenCount int)
-	OnContextLoaded     func(epicID string, content string) // content passed for preview/token display
-	OnContextSkipped    func(epicID string, reason string)
-	OnContextFailed     func(epicID string, errMsg string)
-	OnContextActive     func(epicID string) // called at start of each iteration when context is in use
-
-	// Watch mode callback - called when no tasks available and entering idle state.
-	OnIdle func()
-
-	// Rich streaming callback for real-time agent state updates.
-	// Called whenever agent state changes (text, thinking, tools, metrics).
-	// If set, this provides structured updates; OnOutput is still called for backward compat.
-	OnAgentState func(snap agent.AgentStateSnapshot)
+	// Run events for the TUI, headless output and other subscribers
+	events *EventBus
 }
 
 // RunConfig configures an engine run.
@@ -112,6 +114,15 @@ type RunConfig struct {
 	// Nil means no pause support.
 	PauseChan <-chan bool
 
+	// StopChan, when closed, ends the run once the current iteration is done
+	// (also while paused or idle). Nil means no stop support.
+	StopChan <-chan struct{}
+
+	// WakeChan makes an idle watch-mode engine check for tasks right away
+	// instead of at the next poll, e.g. after a human answered a handoff.
+	// Nil means the engine only polls and watches files.
+	WakeChan <-chan struct{}
+
 	// MaxTaskRetries is the maximum iterations on the same task before assuming stuck (0 = 3 default).
 	MaxTaskRetries int
 
@@ -171,6 +182,9 @@ const (
 
 	// ExitReasonWatchTimeout indicates watch mode timed out - preserve worktree.
 	ExitReasonWatchTimeout = "watch timeout"
+
+	// ExitReasonStopped indicates a stop was requested via StopChan - preserve worktree.
+	ExitReasonStopped = "stopped by request"
 )
 
 // ShouldCleanupWorktree determines if a worktree should be removed based on exit reason.
@@ -234,6 +248,21 @@ type IterationResult struct {
 	// TaskTitle is the title of the task.
 	TaskTitle string
 
+	// Agent is the name of the agent backend that ran the iteration.
+	Agent string
+
+	// SessionID is the agent session that ran the iteration (if reported).
+	SessionID string
+
+	// Resumed indicates the iteration continued an earlier agent session.
+	Resumed bool
+
+	// Model is the model that produced the output (if the agent reports it).
+	Model string
+
+	// FallbackFrom lists models that were tried first and were unavailable.
+	FallbackFrom []string
+
 	// Output is the agent's full output.
 	Output string
 
@@ -261,17 +290,42 @@ type IterationResult struct {
 	// IsTimeout indicates the iteration was terminated due to timeout.
 	// When true, Output may contain partial output captured before timeout.
 	IsTimeout bool
+
+	// BaseCommit and HeadCommit are the repository HEAD before and after the
+	// agent ran (empty outside a git repository).
+	BaseCommit string
+	HeadCommit string
+
+	// Commits are the commits made during the iteration, oldest first.
+	Commits []string
 }
 
 // NewEngine creates a new engine with the given dependencies.
 func NewEngine(a agent.Agent, t TicksClient, b *budget.Tracker, c *checkpoint.Manager) *Engine {
-	return &Engine{
+	e := &Engine{
 		agent:      a,
 		ticks:      t,
 		budget:     b,
 		checkpoint: c,
 		prompt:     NewPromptBuilder(),
+		events:     NewEventBus(),
 	}
+	e.events.Subscribe(e.logEvent)
+	return e
+}
+
+// Events returns the bus the engine publishes run events on.
+func (e *Engine) Events() *EventBus {
+	return e.events
+}
+
+// Subscribe registers fn to receive the engine's run events. Returns a
+// function that removes the subscription.
+func (e *Engine) Subscribe(fn func(Event)) (unsubscribe func()) {
+	if e.events == nil {
+		e.events = NewEventBus()
+	}
+	return e.events.Subscribe(fn)
 }
 
 // EnableVerification enables verification after task completion.
@@ -280,6 +334,100 @@ func (e *Engine) EnableVerification() {
 	e.verifyEnabled = true
 }
 
+// SetPromptBuilder replaces the built-in prompt templates, e.g. with ones
+// loaded from .ticker/prompts (see LoadPromptBuilder).
+func (e *Engine) SetPromptBuilder(pb *PromptBuilder) {
+	e.prompt = pb
+}
+
+// SetVerificationConfig sets the verification config. Its command verifiers
+// run after the git check whenever verification is enabled.
+func (e *Engine) SetVerificationConfig(cfg *verify.Config) {
+	e.verifyConfig = cfg
+}
+
+// SetAgentRegistry sets the registry used to pick an agent for each iteration.
+// The agent is resolved from the task, then its epic, then the registry default.
+// Without a registry, the agent passed to NewEngine runs every iteration.
+func (e *Engine) SetAgentRegistry(r *agent.Registry) {
+	e.agents = r
+}
+
+// resolveAgent returns the agent that should work on task within epic.
+func (e *Engine) resolveAgent(epic *ticks.Epic, task *ticks.Task) (agent.Agent, error) {
+	if e.agents == nil {
+		return e.agent, nil
+	}
+	return ResolveAgent(e.agents, epic, task)
+}
+
+// ResolveAgent picks the agent for task from r: the task's own selection wins,
+// then the epic's (epic may be nil), then the registry default.
+func ResolveAgent(r *agent.Registry, epic *ticks.Epic, task *ticks.Task) (agent.Agent, error) {
+	var epicAgent string
+	if epic != nil {
+		epicAgent = epic.AgentName()
+	}
+	return r.Resolve(task.AgentName(), epicAgent)
+}
+
+// SetEscalation sets the escalation ladder applied when a task is retried or
+// reopened by verification. With no tiers, stuck tasks exit as before.
+func (e *Engine) SetEscalation(cfg *verify.EscalationConfig) {
+	e.escalation = cfg
+}
+
+// EnableSessionContinuation makes retries of the same task resume the agent's
+// previous session with a short follow-up prompt instead of starting over.
+// Only used with agents that support resuming (see agent.CanResume).
+func (e *Engine) EnableSessionContinuation() {
+	e.continueSessions = true
+}
+
+// EnableTranscripts records the raw output stream of every iteration's
+// agent run next to the run log (.ticker/runs/<run-id>/iter-<n>.jsonl.gz),
+// for replay. Requires a run log; agents without a raw stream record nothing.
+func (e *Engine) EnableTranscripts() {
+	e.recordTranscripts = true
+}
+
+// closeTranscript flushes and closes an iteration's transcript and logs the result.
+func (e *Engine) closeTranscript(file io.Closer, w *agent.TranscriptWriter, taskID string, iteration int) {
+	err := w.Flush()
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+	e.runLog.LogTranscript(taskID, iteration, errMsg)
+}
+
+// SetContextRefresh sets when stored epic context is considered stale and
+// regenerated at the start of a run. The zero policy never refreshes.
+func (e *Engine) SetContextRefresh(p epiccontext.RefreshPolicy) {
+	e.contextRefresh = p
+}
+
+// EnableContextUpdates makes the engine revise the epic context after each
+// task that passes verification, from the task's close reason and diff, so
+// later tasks see the code as it is now. Requires context components and
+// verification to be enabled.
+func (e *Engine) EnableContextUpdates() {
+	e.contextUpdates = true
+}
+
+// EnableRepoMap makes the engine maintain a repository-wide codebase map
+// (module layout, build and test commands, conventions) shared by all epics,
+// regenerated when stale under p. The map is included in every iteration
+// prompt and in epic context generation, so epic context can stay specific to
+// the epic. Requires context components.
+func (e *Engine) EnableRepoMap(p epiccontext.RefreshPolicy) {
+	e.repoMap = true
+	e.repoMapRefresh = p
+}
+
 // SetContextComponents sets the context store and generator for epic context.
 // When both are set, the engine will generate context before the first iteration
 // of an epic (if the epic has >1 children and context doesn't already exist).
@@ -291,23 +439,32 @@ func (e *Engine) SetContextComponents(store *epiccontext.Store, generator *epicc
 // ensureEpicContext generates epic context if needed.
 // Context is generated when:
 //   - Context store and generator are configured
-//   - Context doesn't already exist for this epic
+//   - Context doesn't already exist for this epic, or is stale under the
+//     refresh policy (see SetContextRefresh)
 //   - Epic has >1 children (no benefit for single-task epics)
 //
+// dir is the repository used to record and compare git commits.
 // Errors are logged but do not abort the run (context is optional).
-func (e *Engine) ensureEpicContext(ctx context.Context, epic *ticks.Epic) {
+func (e *Engine) ensureEpicContext(ctx context.Context, epic *ticks.Epic, dir string) {
 	// Skip if context components are not configured
 	if e.contextStore == nil || e.contextGenerator == nil {
 		return
 	}
 
-	// Skip if context already exists - will be loaded in loadEpicContext
+	// Skip if fresh context already exists - will be loaded in loadEpicContext
 	if e.contextStore.Exists(epic.ID) {
+		reason := e.contextStaleReason(epic.ID, dir, e.contextRefresh)
+		if reason == "" {
+			if e.runLog != nil {
+				e.runLog.LogContextSkipped(epic.ID, "already exists", 0)
+			}
+			// Note: ContextLoadedEvent is published in loadEpicContext when the context is actually loaded
+			return
+		}
+		// Stale - regenerate. If that fails, the old context is still used.
 		if e.runLog != nil {
-			e.runLog.LogContextSkipped(epic.ID, "already exists", 0)
+			e.runLog.LogContextStale(epic.ID, reason)
 		}
-		// Note: OnContextLoaded is called in loadEpicContext when the context is actually loaded
-		return
 	}
 
 	// Get epic tasks to check count and for generation
@@ -324,9 +481,7 @@ func (e *Engine) ensureEpicContext(ctx context.Context, epic *ticks.Epic) {
 		if e.runLog != nil {
 			e.runLog.LogContextSkipped(epic.ID, "too few tasks", len(tasks))
 		}
-		if e.OnContextSkipped != nil {
-			e.OnContextSkipped(epic.ID, "single-task epic")
-		}
+		e.events.Publish(ContextSkippedEvent{EventInfo: eventInfo(epic.ID), Reason: "single-task epic"})
 		return
 	}
 
@@ -334,9 +489,7 @@ func (e *Engine) ensureEpicContext(ctx context.Context, epic *ticks.Epic) {
 	if e.runLog != nil {
 		e.runLog.LogContextGenerationStarted(epic.ID, len(tasks))
 	}
-	if e.OnContextGenerating != nil {
-		e.OnContextGenerating(epic.ID, len(tasks))
-	}
+	e.events.Publish(ContextGeneratingEvent{EventInfo: eventInfo(epic.ID), TaskCount: len(tasks)})
 
 	// Generate context using the AI agent
 	content, err := e.contextGenerator.Generate(ctx, epic, tasks)
@@ -345,9 +498,7 @@ func (e *Engine) ensureEpicContext(ctx context.Context, epic *ticks.Epic) {
 		if e.runLog != nil {
 			e.runLog.LogContextGenerationFailed(epic.ID, err.Error())
 		}
-		if e.OnContextFailed != nil {
-			e.OnContextFailed(epic.ID, err.Error())
-		}
+		e.events.Publish(ContextFailedEvent{EventInfo: eventInfo(epic.ID), Error: err.Error()})
 		return
 	}
 
@@ -356,19 +507,159 @@ func (e *Engine) ensureEpicContext(ctx context.Context, epic *ticks.Epic) {
 		if e.runLog != nil {
 			e.runLog.LogContextSaveFailed(epic.ID, err.Error())
 		}
-		if e.OnContextFailed != nil {
-			e.OnContextFailed(epic.ID, err.Error())
-		}
+		e.events.Publish(ContextFailedEvent{EventInfo: eventInfo(epic.ID), Error: err.Error()})
 		return
 	}
 
+	if err := e.contextStore.SaveMetadata(epic.ID, e.contextGenerator.Metadata(dir, len(tasks))); err != nil {
+		// Without metadata the context just can't be checked for staleness
+		if e.runLog != nil {
+			e.runLog.LogContextError(epic.ID, err.Error(), "save_metadata")
+		}
+	}
+
 	if e.runLog != nil {
 		e.runLog.LogContextGenerationCompleted(epic.ID, len(content))
 	}
-	if e.OnContextGenerated != nil {
-		// Approximate token count: content length / 4 (rough estimate)
-		tokenCount := len(content) / 4
-		e.OnContextGenerated(epic.ID, tokenCount)
+	// Approximate token count: content length / 4 (rough estimate)
+	e.events.Publish(ContextGeneratedEvent{EventInfo: eventInfo(epic.ID), Tokens: len(content) / 4})
+}
+
+// contextStaleReason returns why the stored context for epicID should be
+// regenerated under policy, or "" if it is fresh or policy never refreshes.
+func (e *Engine) contextStaleReason(epicID, dir string, policy epiccontext.RefreshPolicy) string {
+	if !policy.Enabled() {
+		return ""
+	}
+	meta, err := e.contextStore.LoadMetadata(epicID)
+	if err != nil {
+		if e.runLog != nil {
+			e.runLog.LogContextError(epicID, err.Error(), "load_metadata")
+		}
+		return ""
+	}
+	return policy.StaleReason(meta, dir, time.Now())
+}
+
+// ensureRepoMap returns the shared codebase map (see EnableRepoMap),
+// generating it first if it is missing or stale, and hands it to the context
+// generator. Returns "" if the map is disabled or unavailable. Errors are
+// logged but do not abort the run; a stale map is kept if regeneration fails.
+func (e *Engine) ensureRepoMap(ctx context.Context, state *runState) string {
+	if !e.repoMap || e.contextStore == nil || e.contextGenerator == nil {
+		return ""
+	}
+	id := epiccontext.RepoMapID
+	dir := state.workDir
+
+	regenerate := true
+	if e.contextStore.Exists(id) {
+		regenerate = false
+		if reason := e.contextStaleReason(id, dir, e.repoMapRefresh); reason != "" {
+			regenerate = true
+			if e.runLog != nil {
+				e.runLog.LogContextStale(id, reason)
+			}
+		}
+	}
+
+	if regenerate {
+		if e.runLog != nil {
+			e.runLog.LogContextGenerationStarted(id, 0)
+		}
+		content, usage, err := e.contextGenerator.GenerateRepoMap(ctx)
+		e.addUsage(state, usage.TokensIn, usage.TokensOut, usage.Cost)
+		if err == nil {
+			err = e.contextStore.Save(id, content)
+		}
+		if err != nil {
+			if e.runLog != nil {
+				e.runLog.LogContextGenerationFailed(id, err.Error())
+			}
+		} else {
+			if err := e.contextStore.SaveMetadata(id, e.contextGenerator.Metadata(dir, 0)); err != nil && e.runLog != nil {
+				e.runLog.LogContextError(id, err.Error(), "save_metadata")
+			}
+			if e.runLog != nil {
+				e.runLog.LogContextGenerationCompleted(id, len(content))
+			}
+		}
+	}
+
+	content, err := e.contextStore.Load(id)
+	if err != nil {
+		if e.runLog != nil {
+			e.runLog.LogContextLoadFailed(id, err.Error())
+		}
+		return ""
+	}
+	e.contextGenerator.SetRepoMap(content)
+	return content
+}
+
+// updateEpicContext revises the run's epic context after task passed
+// verification (see EnableContextUpdates). Does nothing if the epic has no
+// context or the task changed nothing. Errors are logged and the previous
+// context is kept.
+func (e *Engine) updateEpicContext(ctx context.Context, state *runState, task *ticks.Task) {
+	if !e.contextUpdates || e.contextStore == nil || e.contextGenerator == nil {
+		return
+	}
+	if state.epic == nil || state.epicContext == "" {
+		return
+	}
+	epicID := state.epic.ID
+
+	dir := state.workDir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	diff, err := verify.TaskDiff(ctx, dir, state.baseRefs[task.ID])
+	if err != nil {
+		if e.runLog != nil {
+			e.runLog.LogContextError(epicID, err.Error(), "task_diff")
+		}
+		return
+	}
+	if strings.TrimSpace(diff) == "" {
+		return
+	}
+
+	change := epiccontext.TaskChange{ID: task.ID, Title: task.Title, Diff: diff}
+	if closed, err := e.ticks.GetTask(task.ID); err == nil {
+		change.CloseReason = closed.ClosedReason
+	}
+
+	content, usage, err := e.contextGenerator.Update(ctx, state.epic, state.epicContext, change)
+	e.addUsage(state, usage.TokensIn, usage.TokensOut, usage.Cost)
+	if err != nil {
+		if e.runLog != nil {
+			e.runLog.LogContextError(epicID, err.Error(), "update")
+		}
+		return
+	}
+	if err := e.contextStore.Save(epicID, content); err != nil {
+		if e.runLog != nil {
+			e.runLog.LogContextSaveFailed(epicID, err.Error())
+		}
+		return
+	}
+	state.epicContext = content
+
+	// The context now reflects HEAD, so commit-based staleness restarts here
+	meta, err := e.contextStore.LoadMetadata(epicID)
+	if err == nil && meta != nil {
+		meta.GitCommit = gitutil.HeadCommit(dir)
+		meta.UpdatedAt = time.Now()
+		meta.Updates++
+		err = e.contextStore.SaveMetadata(epicID, *meta)
+	}
+	if err != nil && e.runLog != nil {
+		e.runLog.LogContextError(epicID, err.Error(), "save_metadata")
+	}
+
+	if e.runLog != nil {
+		e.runLog.LogContextUpdated(epicID, task.ID, len(content))
 	}
 }
 
@@ -388,9 +679,9 @@ func (e *Engine) loadEpicContext(epicID string) string {
 		return ""
 	}
 
-	// Notify TUI that context was loaded from cache (if content exists)
-	if content != "" && e.OnContextLoaded != nil {
-		e.OnContextLoaded(epicID, content)
+	// Notify subscribers that context was loaded from cache (if content exists)
+	if content != "" {
+		e.events.Publish(ContextLoadedEvent{EventInfo: eventInfo(epicID), Content: content})
 	}
 
 	return content
@@ -407,6 +698,101 @@ func (e *Engine) RunLog() *runlog.Logger {
 	return e.runLog
 }
 
+// logEvent writes run events to the run log, if one is set. Control flow
+// decisions that aren't events are logged where they are made.
+func (e *Engine) logEvent(ev Event) {
+	if e.runLog == nil {
+		return
+	}
+
+	switch ev := ev.(type) {
+	case IterationStartEvent:
+		e.runLog.LogIterationStart(ev.Context.Iteration, ev.Context.Task.ID, ev.Context.Task.Title)
+
+	case IterationEndEvent:
+		r := ev.Result
+		errStr := ""
+		if r.Error != nil {
+			errStr = r.Error.Error()
+		}
+		signalStr := ""
+		if r.Signal != SignalNone {
+			signalStr = r.Signal.String()
+		}
+		if len(r.FallbackFrom) > 0 {
+			e.runLog.LogModelFallback(r.TaskID, r.FallbackFrom, r.Model)
+		}
+		e.runLog.LogIterationEnd(runlog.IterationEndData{
+			Iteration: r.Iteration,
+			TaskID:    r.TaskID,
+			Agent:     r.Agent,
+			Model:     r.Model,
+			Resumed:   r.Resumed,
+			Duration:  r.Duration,
+			TokensIn:  r.TokensIn,
+			TokensOut: r.TokensOut,
+			Cost:      r.Cost,
+			Signal:    signalStr,
+			Error:     errStr,
+			IsTimeout: r.IsTimeout,
+			Commits:   r.Commits,
+		})
+
+	case SignalEvent:
+		e.runLog.LogSignalDetected(ev.Signal.String(), ev.Reason, ev.TaskID)
+
+	case VerificationStartEvent:
+		e.runLog.LogVerificationStarted(ev.TaskID)
+
+	case VerificationEndEvent:
+		if ev.Results == nil {
+			return
+		}
+		var verifiers, failed []string
+		for _, r := range ev.Results.Results {
+			errStr := ""
+			if r.Error != nil {
+				errStr = r.Error.Error()
+			}
+			e.runLog.LogVerifierResult(runlog.VerifierResultData{
+				TaskID:   ev.TaskID,
+				Verifier: r.Verifier,
+				Passed:   r.Passed,
+				Skipped:  r.Skipped,
+				Output:   r.Output,
+				Error:    errStr,
+				Duration: r.Duration,
+				WorkDir:  ev.WorkDir,
+			})
+			verifiers = append(verifiers, r.Verifier)
+			if !r.Passed {
+				failed = append(failed, r.Verifier)
+			}
+		}
+		e.runLog.LogVerificationCompleted(ev.TaskID, ev.Results.AllPassed, verifiers, failed)
+
+	case BudgetEvent:
+		if !ev.Exceeded {
+			return
+		}
+		e.runLog.LogBudgetCheck(runlog.BudgetCheckData{
+			LimitType:   "budget",
+			ShouldStop:  true,
+			StopReason:  ev.Reason,
+			Iteration:   ev.Iteration,
+			TotalTokens: ev.Usage.TotalTokens(),
+			TotalCost:   ev.Usage.Cost,
+		})
+
+	case PauseEvent:
+		if ev.Paused {
+			e.runLog.LogPauseEntered(ev.Iteration)
+		} else {
+			e.runLog.LogPauseExited(ev.Iteration)
+		}
+	}
+}
+
 // Run executes the engine loop until completion, signal, or budget exceeded.
 func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult, err error) {
 	// Apply defaults
@@ -576,9 +962,10 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 
 	state.epic = epic
 
-	// Ensure epic context is generated before first iteration
-	// This runs once at the start of the epic run
-	e.ensureEpicContext(ctx, epic)
+	// Ensure the shared codebase map and epic context are generated before
+	// the first iteration. This runs once at the start of the epic run
+	state.repoMap = e.ensureRepoMap(ctx, state)
+	e.ensureEpicContext(ctx, epic, state.workDir)
 
 	// Load epic context for use in iteration prompts
 	state.epicContext = e.loadEpicContext(epic.ID)
@@ -591,19 +978,23 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 			return state.toResult("context cancelled", e.budget.Usage()), ctx.Err()
 		}
 
+		// Check for a stop request
+		select {
+		case <-config.StopChan:
+			return state.toResult(ExitReasonStopped, e.budget.Usage()), nil
+		default:
+		}
+
 		// Check budget limits before starting iteration
 		if shouldStop, reason := e.budget.ShouldStop(); shouldStop {
-			if e.runLog != nil {
-				usage := e.budget.Usage()
-				e.runLog.LogBudgetCheck(runlog.BudgetCheckData{
-					LimitType:   "budget",
-					ShouldStop:  true,
-					StopReason:  reason,
-					Iteration:   state.iteration,
-					TotalTokens: usage.TotalTokens(),
-					TotalCost:   usage.Cost,
-				})
-			}
+			e.events.Publish(BudgetEvent{
+				EventInfo: eventInfo(state.epicID),
+				Iteration: state.iteration,
+				Usage:     e.budget.Usage(),
+				Limits:    e.budget.Limits(),
+				Exceeded:  true,
+				Reason:    reason,
+			})
 			return state.toResult(reason, e.budget.Usage()), nil
 		}
 
@@ -612,21 +1003,19 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 			select {
 			case paused := <-config.PauseChan:
 				if paused {
-					if e.runLog != nil {
-						e.runLog.LogPauseEntered(state.iteration)
-					}
+					e.events.Publish(PauseEvent{EventInfo: eventInfo(state.epicID), Iteration: state.iteration, Paused: true})
 					// Wait for unpause
 					for paused {
 						select {
 						case <-ctx.Done():
 							e.writeInterruptionNotes(state, config.EpicID)
 							return state.toResult("context cancelled while paused", e.budget.Usage()), ctx.Err()
+						case <-config.StopChan:
+							return state.toResult(ExitReasonStopped, e.budget.Usage()), nil
 						case paused = <-config.PauseChan:
 						}
 					}
-					if e.runLog != nil {
-						e.runLog.LogPauseExited(state.iteration)
-					}
+					e.events.Publish(PauseEvent{EventInfo: eventInfo(state.epicID), Iteration: state.iteration, Paused: false})
 				}
 			default:
 				// Not paused, continue
@@ -683,10 +1072,23 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 			return state.toResult(reason, e.budget.Usage()), nil
 		}
 
-		// Stuck loop detection - catch agent forgetting to close tasks
-		if task.ID == state.lastTaskID {
+		// Stuck loop detection - catch agent forgetting to close tasks.
+		// Retried or reopened tasks climb the escalation ladder first; the
+		// run only gives up once the top tier has failed.
+		retry := task.ID == state.lastTaskID
+		reopened := state.reopened[task.ID]
+		delete(state.reopened, task.ID)
+		escalated := false
+		if retry || reopened {
+			reason := "retry"
+			if reopened {
+				reason = "verification failed"
+			}
+			escalated = e.escalate(state, task.ID, reason)
+		}
+		if retry {
 			state.sameTaskCount++
-			if state.sameTaskCount > config.MaxTaskRetries {
+			if !escalated && state.sameTaskCount > config.MaxTaskRetries {
 				if e.runLog != nil {
 					e.runLog.LogStuckLoopExceeded(task.ID, state.sameTaskCount, config.MaxTaskRetries)
 				}
@@ -700,6 +1102,13 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 			state.sameTaskCount = 1
 		}
 
+		// Only consecutive retries and reopened tasks continue their session
+		if !retry && !reopened {
+			e.forgetSession(state, task.ID)
+		} else if state.followUps[task.ID] == "" {
+			e.setFollowUp(state, task.ID, followUpStillOpen)
+		}
+
 		// Log task selection
 		if e.runLog != nil {
 			e.runLog.LogTaskSelected(task.ID, task.Title, state.sameTaskCount)
@@ -708,49 +1117,42 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 		// Track current task for interruption notes
 		state.currentTaskID = task.ID
 		state.currentTaskTitle = task.Title
+		e.rememberBaseRef(state, task.ID)
 
-		// Run iteration
+		// Run iteration (escalated tasks may get a longer timeout)
+		timeout := config.AgentTimeout
+		if tier := e.escalationTier(state, task.ID); tier != nil && tier.GetTimeout() > 0 {
+			timeout = tier.GetTimeout()
+		}
 		state.iteration++
-		iterResult := e.runIteration(ctx, state, task, config.AgentTimeout)
-
-		// Update budget
-		e.budget.Add(iterResult.TokensIn, iterResult.TokensOut, iterResult.Cost)
+		iterResult := e.runIteration(ctx, state, task, timeout)
 
-		// Call callback
-		if e.OnIterationEnd != nil {
-			e.OnIterationEnd(iterResult)
+		// Update budget (resumed iterations are tracked separately too)
+		if iterResult.Resumed {
+			e.budget.AddResumed(iterResult.TokensIn, iterResult.TokensOut, iterResult.Cost)
+		} else {
+			e.budget.Add(iterResult.TokensIn, iterResult.TokensOut, iterResult.Cost)
 		}
-
-		// Log iteration end
-		if e.runLog != nil {
-			errStr := ""
-			if iterResult.Error != nil {
-				errStr = iterResult.Error.Error()
-			}
-			signalStr := ""
-			if iterResult.Signal != SignalNone {
-				signalStr = iterResult.Signal.String()
-			}
-			e.runLog.LogIterationEnd(runlog.IterationEndData{
-				Iteration: iterResult.Iteration,
-				TaskID:    iterResult.TaskID,
-				Duration:  iterResult.Duration,
-				TokensIn:  iterResult.TokensIn,
-				TokensOut: iterResult.TokensOut,
-				Cost:      iterResult.Cost,
-				Signal:    signalStr,
-				Error:     errStr,
-				IsTimeout: iterResult.IsTimeout,
-			})
+		if iterResult.SessionID != "" {
+			e.rememberSession(state, task.ID, iterResult.SessionID)
 		}
 
+		e.events.Publish(IterationEndEvent{EventInfo: eventInfo(state.epicID), Result: iterResult})
+		e.events.Publish(BudgetEvent{
+			EventInfo: eventInfo(state.epicID),
+			Iteration: state.iteration,
+			Usage:     e.budget.Usage(),
+			Limits:    e.budget.Limits(),
+		})
+
 		// Handle timeout specially - add detailed note for recovery
 		if iterResult.IsTimeout {
 			if e.runLog != nil {
-				e.runLog.LogAgentTimeout(iterResult.TaskID, config.AgentTimeout, len(iterResult.Output))
+				e.runLog.LogAgentTimeout(iterResult.TaskID, timeout, len(iterResult.Output))
 			}
-			note := buildTimeoutNote(state.iteration, iterResult.TaskID, config.AgentTimeout, iterResult.Output)
+			note := buildTimeoutNote(state.iteration, iterResult.TaskID, timeout, iterResult.Output)
 			_ = e.ticks.AddNote(config.EpicID, note)
+			e.setFollowUp(state, task.ID, fmt.Sprintf(followUpTimeout, timeout))
 			continue // Try next iteration
 		}
 
@@ -761,6 +1163,8 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 			}
 			// Add note about the error for next iteration
 			_ = e.ticks.AddNote(config.EpicID, fmt.Sprintf("Iteration %d error: %v", state.iteration, iterResult.Error))
+			// The session may be broken - start the next attempt fresh
+			e.forgetSession(state, task.ID)
 			continue // Try next iteration
 		}
 
@@ -771,44 +1175,10 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 				// Log but don't fail on status check error
 				_ = e.ticks.AddNote(config.EpicID, fmt.Sprintf("Warning: could not check task status: %v", err))
 			} else if taskClosed {
-				// Log verification start
-				if e.runLog != nil {
-					e.runLog.LogVerificationStarted(task.ID)
-				}
 				// Run verification in the correct working directory
-				verifyResult := e.runVerification(ctx, task.ID, iterResult.Output, config.EpicID, state.workDir)
-
-				// Log detailed results for each verifier
-				if e.runLog != nil && verifyResult != nil {
-					for _, r := range verifyResult.Results {
-						errStr := ""
-						if r.Error != nil {
-							errStr = r.Error.Error()
-						}
-						e.runLog.LogVerifierResult(runlog.VerifierResultData{
-							TaskID:   task.ID,
-							Verifier: r.Verifier,
-							Passed:   r.Passed,
-							Output:   r.Output,
-							Error:    errStr,
-							Duration: r.Duration,
-							WorkDir:  state.workDir,
-						})
-					}
-				}
+				verifyResult := e.runVerification(ctx, state, task, iterResult.Output)
 
 				if verifyResult != nil && !verifyResult.AllPassed {
-					// Log verification failure
-					if e.runLog != nil {
-						var verifiers, failed []string
-						for _, r := range verifyResult.Results {
-							verifiers = append(verifiers, r.Verifier)
-							if !r.Passed {
-								failed = append(failed, r.Verifier)
-							}
-						}
-						e.runLog.LogVerificationCompleted(task.ID, false, verifiers, failed)
-					}
 					// Verification failed - reopen task and add note
 					if err := e.ticks.ReopenTask(task.ID); err != nil {
 						_ = e.ticks.AddNote(config.EpicID, fmt.Sprintf("Warning: could not reopen task %s: %v", task.ID, err))
@@ -816,24 +1186,24 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 					if e.runLog != nil {
 						e.runLog.LogTaskReopened(task.ID, "verification failed")
 					}
+					// Next attempt on this task escalates, even if not consecutive
+					if state.reopened == nil {
+						state.reopened = make(map[string]bool)
+					}
+					state.reopened[task.ID] = true
 					// Add epic note with failure details
 					note := buildVerificationFailureNote(state.iteration, task.ID, verifyResult)
 					_ = e.ticks.AddNote(config.EpicID, note)
+					e.setFollowUp(state, task.ID, followUpVerificationFailed+note)
 					// Continue to next iteration - agent will see the failure in notes
 					continue
 				}
 				// Verification passed - track as completed
 				if e.runLog != nil {
-					var verifiers []string
-					if verifyResult != nil {
-						for _, r := range verifyResult.Results {
-							verifiers = append(verifiers, r.Verifier)
-						}
-					}
-					e.runLog.LogVerificationCompleted(task.ID, true, verifiers, nil)
 					e.runLog.LogTaskCompleted(task.ID, true)
 				}
 				state.completedTasks = append(state.completedTasks, task.ID)
+				e.updateEpicContext(ctx, state, task)
 			}
 		}
 
@@ -842,23 +1212,22 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 			state.signal = iterResult.Signal
 			state.signalReason = iterResult.SignalReason
 
-			// Log signal detection
-			if e.runLog != nil {
-				e.runLog.LogSignalDetected(iterResult.Signal.String(), iterResult.SignalReason, task.ID)
-			}
-
-			if e.OnSignal != nil {
-				e.OnSignal(iterResult.Signal, iterResult.SignalReason)
-			}
+			e.events.Publish(SignalEvent{
+				EventInfo: eventInfo(state.epicID),
+				TaskID:    task.ID,
+				Signal:    iterResult.Signal,
+				Reason:    iterResult.SignalReason,
+			})
 
 			// Special case: COMPLETE signal is ignored (ticker handles completion via tk next)
 			if iterResult.Signal == SignalComplete {
 				if e.runLog != nil {
 					e.runLog.LogSignalHandled(iterResult.Signal.String(), task.ID, "ignored (ticker handles completion automatically)", "")
 				}
-				if e.OnOutput != nil {
-					e.OnOutput("\n[Warning: Agent emitted COMPLETE signal - ignoring. Ticker handles completion automatically.]\n")
-				}
+				e.events.Publish(OutputEvent{
+					EventInfo: eventInfo(state.epicID),
+					Text:      "\n[Warning: Agent emitted COMPLETE signal - ignoring. Ticker handles completion automatically.]\n",
+				})
 				// Continue to next iteration - don't close epic
 			} else {
 				// All other signals (handoff signals) set the task to awaiting state
@@ -871,6 +1240,8 @@ func (e *Engine) Run(ctx context.Context, config RunConfig) (result *RunResult,
 				if e.runLog != nil {
 					e.runLog.LogSignalHandled(iterResult.Signal.String(), task.ID, "set task awaiting", awaitingState)
 				}
+				// Human feedback needs the full prompt when the task comes back
+				e.forgetSession(state, task.ID)
 				// Continue to next task - never block waiting for human response
 				// The task is now awaiting human, so tk next won't return it
 				continue
@@ -911,6 +1282,23 @@ type runState struct {
 	lastTaskID    string
 	sameTaskCount int
 
+	// Escalation: current tier per task (0 = base) and tasks reopened by
+	// verification since their last attempt
+	escalationLevel map[string]int
+	reopened        map[string]bool
+
+	// Session continuation: last agent session per task and why the next
+	// attempt is needed (see EnableSessionContinuation)
+	sessions  map[string]string
+	followUps map[string]string
+
+	// Iterations run per task, for the prompt's attempt number
+	attempts map[string]int
+
+	// Commit each task started from, for the acceptance-criteria review and
+	// context update diffs
+	baseRefs map[string]string
+
 	// Current task being worked on (for interruption notes)
 	currentTaskID    string
 	currentTaskTitle string
@@ -920,6 +1308,9 @@ type runState struct {
 
 	// Epic context (pre-computed context for the epic, loaded once at start)
 	epicContext string
+
+	// Shared codebase map (loaded once at start, see EnableRepoMap)
+	repoMap string
 }
 
 // toResult converts run state to a RunResult.
@@ -946,21 +1337,27 @@ func (e *Engine) runIteration(ctx context.Context, state *runState, task *ticks.
 	}
 
 	// Mark task as in_progress before starting (enables crash recovery)
-	if err := e.ticks.SetStatus(task.ID, "in_progress"); err != nil {
+	if err := e.ticks.SetStatus(task.ID, "in_progress"); err != nil && state.epicID != "" {
 		// Log but continue - status update is not critical
 		_ = e.ticks.AddNote(state.epicID, fmt.Sprintf("Warning: could not mark %s as in_progress: %v", task.ID, err))
 	}
 
-	// Refresh epic to get latest notes
-	epic, err := e.ticks.GetEpic(state.epicID)
-	if err != nil {
-		result.Error = fmt.Errorf("refreshing epic: %w", err)
-		return result
-	}
-	state.epic = epic
+	// Refresh epic to get latest notes (standalone tasks have none)
+	var epic *ticks.Epic
+	var notes []string
+	if state.epicID != "" {
+		var err error
+		epic, err = e.ticks.GetEpic(state.epicID)
+		if err != nil {
+			result.Error = fmt.Errorf("refreshing epic: %w", err)
+			return result
+		}
+		state.epic = epic
 
-	// Get epic notes (continue without notes on error)
-	notes, _ := e.ticks.GetNotes(state.epicID)
+		// Get epic notes (continue without notes on error), compacted if too long
+		notes, _ = e.ticks.GetNotes(state.epicID)
+		notes = e.compactNotes(ctx, state, task, notes)
+	}
 
 	// Get human feedback notes for this task (continue without on error)
 	humanNotes, _ := e.ticks.GetHumanNotes(task.ID)
@@ -973,27 +1370,54 @@ func (e *Engine) runIteration(ctx context.Context, state *runState, task *ticks.
 		EpicNotes:     notes,
 		HumanFeedback: humanNotes,
 		EpicContext:   state.epicContext,
+		RepoMap:       state.repoMap,
+		Attempt:       state.attempts[task.ID] + 1,
+		Repo:          repoInfo(state.workDir),
 	}
-
-	if e.OnIterationStart != nil {
-		e.OnIterationStart(iterCtx)
+	if state.attempts == nil {
+		state.attempts = make(map[string]int)
 	}
-
-	// Log iteration start
-	if e.runLog != nil {
-		e.runLog.LogIterationStart(state.iteration, task.ID, task.Title)
+	state.attempts[task.ID]++
+	tier := e.escalationTier(state, task.ID)
+	if tier != nil {
+		iterCtx.ExtraThinking = tier.GetExtraThinking()
 	}
 
+	e.events.Publish(IterationStartEvent{EventInfo: eventInfo(state.epicID), Context: iterCtx})
+
 	// Notify that context is active for this iteration (if context exists)
-	if state.epicContext != "" && e.OnContextActive != nil {
-		e.OnContextActive(state.epicID)
+	if state.epicContext != "" {
+		e.events.Publish(ContextActiveEvent{EventInfo: eventInfo(state.epicID)})
 	}
 
-	prompt := e.prompt.Build(iterCtx)
+	// Pick the agent for this task (task > epic > default)
+	runAgent, err := e.resolveAgent(epic, task)
+	if err != nil {
+		result.Error = fmt.Errorf("resolving agent: %w", err)
+		return result
+	}
+	result.Agent = runAgent.Name()
+
+	// Continue the previous session with a short follow-up if possible
+	resumeSession, followUp := e.takeFollowUp(state, task.ID, runAgent)
+	iterCtx.FollowUp = followUp
+	result.Resumed = resumeSession != ""
+
+	prompt, stats := e.prompt.BuildWithStats(iterCtx)
+	if e.runLog != nil {
+		e.runLog.LogPromptBuilt(runlog.PromptBuiltData{
+			TaskID:        task.ID,
+			Tokens:        stats.Tokens,
+			Budget:        e.prompt.maxTokens,
+			Sections:      stats.Sections,
+			NotesIncluded: stats.NotesIncluded,
+			NotesOmitted:  stats.NotesOmitted,
+		})
+	}
 
 	// Log agent started
 	if e.runLog != nil {
-		e.runLog.LogAgentStarted(task.ID, len(prompt), timeout, state.workDir)
+		e.runLog.LogAgentStarted(task.ID, runAgent.Name(), len(prompt), timeout, state.workDir)
 	}
 
 	// Create context with timeout
@@ -1004,38 +1428,67 @@ func (e *Engine) runIteration(ctx context.Context, state *runState, task *ticks.
 	startTime := time.Now()
 
 	opts := agent.RunOpts{
-		Timeout: timeout,
-		WorkDir: state.workDir,
-	}
+		Timeout:        timeout,
+		WorkDir:        state.workDir,
+		TaskID:         task.ID,
+		EpicID:         state.epicID,
+		PersistSession: e.continueSessions,
+		ResumeSession:  resumeSession,
+	}
+	if tier != nil {
+		opts.Model = tier.GetModel()
+	}
+
+	// Publish structured state updates and plain output deltas
+	opts.StateCallback = func(snap agent.AgentStateSnapshot) {
+		e.events.Publish(AgentStateEvent{EventInfo: eventInfo(state.epicID), Snapshot: snap})
+	}
+	streamChan := make(chan string, 100)
+	opts.Stream = streamChan
+	streamDone := make(chan struct{})
+	go func() {
+		defer close(streamDone)
+		for chunk := range streamChan {
+			e.events.Publish(OutputEvent{EventInfo: eventInfo(state.epicID), Text: chunk})
+		}
+	}()
 
-	// Set up rich streaming callback if configured (preferred)
-	if e.OnAgentState != nil {
-		opts.StateCallback = e.OnAgentState
+	// Record the raw output stream if configured
+	var transcript io.WriteCloser
+	var transcriptWriter *agent.TranscriptWriter
+	if e.recordTranscripts && e.runLog != nil {
+		transcript, err = e.runLog.CreateTranscript(state.iteration)
+		if err != nil {
+			e.runLog.LogTranscript(task.ID, state.iteration, err.Error())
+		} else {
+			transcriptWriter = agent.NewTranscriptWriter(transcript)
+			opts.Transcript = transcriptWriter
+		}
 	}
 
-	// Set up legacy streaming if callback is configured (backward compat)
-	var streamChan chan string
-	if e.OnOutput != nil {
-		streamChan = make(chan string, 100)
-		opts.Stream = streamChan
+	agentResult, err := runAgent.Run(iterCtx2, prompt, opts)
 
-		// Forward stream to callback
-		go func() {
-			for chunk := range streamChan {
-				e.OnOutput(chunk)
-			}
-		}()
+	if transcript != nil {
+		e.closeTranscript(transcript, transcriptWriter, task.ID, state.iteration)
 	}
 
-	agentResult, err := e.agent.Run(iterCtx2, prompt, opts)
-
-	// Close stream channel
-	if streamChan != nil {
-		close(streamChan)
-	}
+	// Close stream channel and wait for the remaining output to be published
+	close(streamChan)
+	<-streamDone
 
 	result.Duration = time.Since(startTime)
 
+	// Attribute the commits the agent made to the task, and persist the
+	// RunRecord (enables viewing historical run data)
+	result.BaseCommit = iterCtx.Repo.Commit
+	result.HeadCommit = gitutil.HeadCommit(iterCtx.Repo.Dir)
+	result.Commits = gitutil.CommitsBetween(iterCtx.Repo.Dir, result.BaseCommit, result.HeadCommit)
+	var record *agent.RunRecord
+	if agentResult != nil {
+		record = agentResult.Record
+	}
+	e.saveRunRecord(task.ID, record, result, startTime)
+
 	// Handle timeout specially - capture partial output
 	if errors.Is(err, agent.ErrTimeout) {
 		result.IsTimeout = true
@@ -1045,7 +1498,8 @@ func (e *Engine) runIteration(ctx context.Context, state *runState, task *ticks.
 			result.TokensOut = agentResult.TokensOut
 			result.Cost = agentResult.Cost
 			if agentResult.Record != nil {
-				_ = e.ticks.SetRunRecord(task.ID, agentResult.Record)
+				result.Model = agentResult.Record.Model
+				result.SessionID = agentResult.Record.SessionID
 			}
 		}
 		return result
@@ -1061,9 +1515,10 @@ func (e *Engine) runIteration(ctx context.Context, state *runState, task *ticks.
 	result.TokensOut = agentResult.TokensOut
 	result.Cost = agentResult.Cost
 
-	// Persist RunRecord to task (enables viewing historical run data)
 	if agentResult.Record != nil {
-		_ = e.ticks.SetRunRecord(task.ID, agentResult.Record)
+		result.Model = agentResult.Record.Model
+		result.FallbackFrom = agentResult.Record.FallbackFrom
+		result.SessionID = agentResult.Record.SessionID
 	}
 
 	// Parse signals
@@ -1072,6 +1527,34 @@ func (e *Engine) runIteration(ctx context.Context, state *runState, task *ticks.
 	return result
 }
 
+// saveRunRecord persists an iteration's RunRecord to its task, with the
+// commits of the task's earlier runs carried forward so the record lists
+// everything the task committed. While the task's runs follow on from each
+// other, the base of its first run is kept too, so BaseCommit..HeadCommit
+// spans the whole task. If the agent produced no record but the iteration
+// committed, a bare record keeps the commits attributed.
+func (e *Engine) saveRunRecord(taskID string, record *agent.RunRecord, result *IterationResult, started time.Time) {
+	if record == nil {
+		if len(result.Commits) == 0 {
+			return
+		}
+		record = &agent.RunRecord{StartedAt: started, EndedAt: started.Add(result.Duration)}
+	}
+	record.BaseCommit = result.BaseCommit
+	record.HeadCommit = result.HeadCommit
+	record.Commits = result.Commits
+	if prev, err := e.ticks.GetRunRecord(taskID); err == nil && prev != nil {
+		if len(prev.Commits) > 0 {
+			record.Commits = append(slices.Clone(prev.Commits), result.Commits...)
+		}
+		// Nothing was committed between the previous run and this one
+		if prev.BaseCommit != "" && prev.HeadCommit == result.BaseCommit {
+			record.BaseCommit = prev.BaseCommit
+		}
+	}
+	_ = e.ticks.SetRunRecord(taskID, record)
+}
+
 // buildTimeoutNote creates a detailed note about a timeout for recovery.
 // Includes iteration number, task ID, timeout duration, and partial output summary.
 func buildTimeoutNote(iteration int, taskID string, timeout time.Duration, partialOutput string) string {
@@ -1123,15 +1606,32 @@ func (e *Engine) wasTaskClosed(taskID string) (bool, error) {
 	return task.Status == "closed", nil
 }
 
-// runVerification executes verification for a completed task.
-// workDir specifies the directory to verify (worktree path or empty for cwd).
+// addUsage records the usage of an agent run outside an iteration (a review,
+// notes digest, epic context update or repo map) in the budget, without
+// counting an iteration.
+func (e *Engine) addUsage(state *runState, tokensIn, tokensOut int, cost float64) {
+	if e.budget == nil || (tokensIn == 0 && tokensOut == 0 && cost == 0) {
+		return
+	}
+	e.budget.AddUsage(tokensIn, tokensOut, cost)
+	e.events.Publish(BudgetEvent{
+		EventInfo: eventInfo(state.epicID),
+		Iteration: state.iteration,
+		Usage:     e.budget.Usage(),
+		Limits:    e.budget.Limits(),
+	})
+}
+
+// runVerification executes verification for a completed task in the run's
+// working directory (worktree path or cwd). The task's own verification rules
+// are merged with the repo config.
 // Returns nil if verification is not enabled or cannot run.
-func (e *Engine) runVerification(ctx context.Context, taskID string, agentOutput string, epicID string, workDir string) *verify.Results {
+func (e *Engine) runVerification(ctx context.Context, state *runState, task *ticks.Task, agentOutput string) *verify.Results {
 	if !e.verifyEnabled {
 		return nil
 	}
 
-	dir := workDir
+	dir := state.workDir
 	if dir == "" {
 		var err error
 		dir, err = os.Getwd()
@@ -1140,28 +1640,63 @@ func (e *Engine) runVerification(ctx context.Context, taskID string, agentOutput
 		}
 	}
 
-	gitVerifier := verify.NewGitVerifier(dir)
-	if gitVerifier == nil {
+	verifiers, err := e.verifiers(state, task, dir)
+	if err != nil {
+		_ = e.ticks.AddNote(task.ID, fmt.Sprintf("Ignoring task verification rules: %v", err))
+	}
+	if len(verifiers) == 0 {
 		return nil
 	}
 
-	// Set baseline so only NEW uncommitted changes are flagged
-	if e.gitBaseline != nil {
-		gitVerifier.SetBaseline(e.gitBaseline)
-	}
+	e.events.Publish(VerificationStartEvent{EventInfo: eventInfo(state.epicID), TaskID: task.ID})
 
-	if e.OnVerificationStart != nil {
-		e.OnVerificationStart(taskID)
-	}
+	runner := verify.NewRunner(dir, verifiers...)
+	runner.SetConcurrency(e.verifyConfig.GetMaxParallel())
+	results := runner.Run(ctx, task.ID, agentOutput)
+	tokensIn, tokensOut, cost := results.Usage()
+	e.addUsage(state, tokensIn, tokensOut, cost)
 
-	runner := verify.NewRunner(dir, gitVerifier)
-	results := runner.Run(ctx, taskID, agentOutput)
+	e.events.Publish(VerificationEndEvent{EventInfo: eventInfo(state.epicID), TaskID: task.ID, WorkDir: dir, Results: results})
 
-	if e.OnVerificationEnd != nil {
-		e.OnVerificationEnd(taskID, results)
+	return results
+}
+
+// verifiers returns the verifiers to run for task in dir: git, configured
+// commands and the reviewer, adjusted by the task's own verification rules.
+// If the rules are invalid they are ignored and the error returned with the
+// unadjusted verifiers.
+func (e *Engine) verifiers(state *runState, task *ticks.Task, dir string) ([]verify.Verifier, error) {
+	var verifiers []verify.Verifier
+	if gitVerifier := verify.NewGitVerifier(dir); gitVerifier != nil {
+		// Set baseline so only NEW uncommitted changes are flagged
+		if e.gitBaseline != nil {
+			gitVerifier.SetBaseline(e.gitBaseline)
+		}
+		verifiers = append(verifiers, gitVerifier)
+	}
+	verifiers = append(verifiers, e.verifyConfig.CommandVerifiers(dir)...)
+	reviewer := e.criteriaVerifier(state, task, dir)
+	if reviewer != nil {
+		verifiers = append(verifiers, reviewer)
 	}
 
-	return results
+	// Apply the task's own verification rules, if it declares any
+	rules, err := verify.ParseTaskRules(task.Description)
+	verifiers = rules.Apply(dir, verifiers)
+
+	if reviewer != nil {
+		// Only review work that passes the other required checks. Optional
+		// ones only warn, so they don't hold up the review.
+		var names []string
+		for _, v := range verifiers {
+			if o, ok := v.(verify.Optional); v == verify.Verifier(reviewer) || (ok && o.IsOptional()) {
+				continue
+			}
+			names = append(names, v.Name())
+		}
+		reviewer.RunAfter(names...)
+	}
+	return verifiers, err
 }
 
 // signalToAwaiting maps signals to their corresponding awaiting states.
@@ -1211,13 +1746,18 @@ func buildVerificationFailureNote(iteration int, taskID string, results *verify.
 
 	// Add details from failed verifiers
 	for _, r := range results.FailedResults() {
-		sb.WriteString(fmt.Sprintf(" [%s] ", r.Verifier))
+		if r.Optional {
+			sb.WriteString(fmt.Sprintf(" [%s (optional)] ", r.Verifier))
+		} else {
+			sb.WriteString(fmt.Sprintf(" [%s] ", r.Verifier))
+		}
 		if r.Output != "" {
-			// Truncate output if too long
+			// Truncate output if too long, keeping the end where
+			// test and build failures are reported
 			output := r.Output
 			const maxLen = 300
 			if len(output) > maxLen {
-				output = output[:maxLen] + "..."
+				output = "..." + output[len(output)-maxLen:]
 			}
 			// Clean up for single-line note
 			output = strings.ReplaceAll(output, "\n", " | ")
@@ -1274,10 +1814,8 @@ func (e *Engine) handleWatchIdle(ctx context.Context, config RunConfig, state *r
 		e.runLog.LogIdleEntered("tasks blocked or awaiting human", config.WatchPollInterval)
 	}
 
-	// Notify caller that we're entering idle state
-	if e.OnIdle != nil {
-		e.OnIdle()
-	}
+	// Notify subscribers that we're entering idle state
+	e.events.Publish(IdleEvent{EventInfo: eventInfo(state.epicID)})
 
 	// Try to create a file watcher for the .tick/issues directory
 	// This provides faster response than polling when available
@@ -1301,6 +1839,9 @@ func (e *Engine) handleWatchIdle(ctx context.Context, config RunConfig, state *r
 			e.writeInterruptionNotes(state, config.EpicID)
 			return state.toResult("context cancelled while idle", e.budget.Usage())
 
+		case <-config.StopChan:
+			return state.toResult(ExitReasonStopped, e.budget.Usage())
+
 		case <-fileChanges:
 			// File change detected - check for new tasks immediately
 			if e.runLog != nil {
@@ -1345,31 +1886,44 @@ func (e *Engine) handleWatchIdle(ctx context.Context, config RunConfig, state *r
 			}
 			// Still blocked/awaiting - continue watching
 
-		case <-time.After(config.WatchPollInterval):
-			// Periodic poll - check for new tasks
-			task, err := e.ticks.NextTask(config.EpicID)
-			if err == nil && task != nil {
-				if e.runLog != nil {
-					e.runLog.LogIdleTaskCheck(true, task.ID)
-				}
-				return nil // Tasks available - continue processing
-			}
-			if e.runLog != nil {
-				e.runLog.LogIdleTaskCheck(false, "")
+		case <-config.WakeChan:
+			// Woken up (e.g. a handoff was answered) - check right away
+			if result, done := e.pollIdle(config, state); done {
+				return result
 			}
 
-			// Check if epic is now complete
-			result := e.checkForEpicCompletion(config, state)
-			if result != nil {
+		case <-time.After(config.WatchPollInterval):
+			// Periodic poll - check for new tasks
+			if result, done := e.pollIdle(config, state); done {
 				return result
 			}
 
-			// Still blocked/awaiting - re-trigger OnIdle callback
-			if e.OnIdle != nil {
-				e.OnIdle()
-			}
+			// Still blocked/awaiting - publish idle again
+			e.events.Publish(IdleEvent{EventInfo: eventInfo(state.epicID)})
+		}
+	}
+}
+
+// pollIdle checks for a task while idle. done reports that idling is over:
+// result is nil if a task is available, or the run's result if the epic
+// completed.
+func (e *Engine) pollIdle(config RunConfig, state *runState) (result *RunResult, done bool) {
+	task, err := e.ticks.NextTask(config.EpicID)
+	if err == nil && task != nil {
+		if e.runLog != nil {
+			e.runLog.LogIdleTaskCheck(true, task.ID)
 		}
+		return nil, true // Tasks available - continue processing
+	}
+	if e.runLog != nil {
+		e.runLog.LogIdleTaskCheck(false, "")
+	}
+
+	// Check if epic is now complete
+	if result := e.checkForEpicCompletion(config, state); result != nil {
+		return result, true
 	}
+	return nil, false
 }
 
 // checkForEpicCompletion checks if all tasks are done and the epic should be closed.
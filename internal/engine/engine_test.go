@@ -3,6 +3,11 @@ package engine
 import (
 	"context"
 	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +15,8 @@ import (
 	"github.com/pengelbrecht/ticker/internal/agent"
 	"github.com/pengelbrecht/ticker/internal/budget"
 	"github.com/pengelbrecht/ticker/internal/checkpoint"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
+	"github.com/pengelbrecht/ticker/internal/runlog"
 	"github.com/pengelbrecht/ticker/internal/ticks"
 	"github.com/pengelbrecht/ticker/internal/verify"
 )
@@ -28,6 +35,15 @@ type mockResponse struct {
 	tokensOut int
 	cost      float64
 	err       error
+
+	// transcript is written to RunOpts.Transcript, if set
+	transcript string
+
+	// record is returned as the run's RunRecord
+	record *agent.RunRecord
+
+	// run is called with the run options before returning (e.g. to commit)
+	run func(opts agent.RunOpts)
 }
 
 func (m *mockAgent) Name() string    { return m.name }
@@ -45,12 +61,20 @@ func (m *mockAgent) Run(ctx context.Context, prompt string, opts agent.RunOpts)
 		return nil, resp.err
 	}
 
+	if opts.Transcript != nil && resp.transcript != "" {
+		io.WriteString(opts.Transcript, resp.transcript)
+	}
+	if resp.run != nil {
+		resp.run(opts)
+	}
+
 	return &agent.Result{
 		Output:    resp.output,
 		TokensIn:  resp.tokensIn,
 		TokensOut: resp.tokensOut,
 		Cost:      resp.cost,
 		Duration:  100 * time.Millisecond,
+		Record:    resp.record,
 	}, nil
 }
 
@@ -315,6 +339,10 @@ func (m *mockTicksClient) SetRunRecord(taskID string, record *agent.RunRecord) e
 	return nil
 }
 
+func (m *mockTicksClient) GetRunRecord(taskID string) (*agent.RunRecord, error) {
+	return nil, nil
+}
+
 func TestNewEngine(t *testing.T) {
 	a := &mockAgent{name: "test", available: true}
 	tc := ticks.NewClient()
@@ -405,6 +433,156 @@ func TestEngine_Run_SingleTask_Complete(t *testing.T) {
 	}
 }
 
+func TestEngine_Run_RecordsTranscripts(t *testing.T) {
+	dir := t.TempDir()
+	mockTicks := newMockTicksClientForContext()
+	mockTicks.epic = &ticks.Epic{ID: "epic-1", Title: "Epic", Type: "epic"}
+	mockTicks.tasks = []*ticks.Task{{ID: "task-1", Title: "First", Status: "open"}}
+
+	stream := `{"type":"system","subtype":"init","session_id":"s","model":"opus"}` + "\n"
+	mockAg := &mockAgent{name: "mock", available: true, responses: []mockResponse{{output: "done", transcript: stream}}}
+	e := NewEngine(mockAg, mockTicks, budget.NewTracker(budget.Limits{MaxIterations: 5}), checkpoint.NewManagerWithDir(t.TempDir()))
+
+	logger, err := runlog.NewWithWorkDir("epic-1", dir)
+	if err != nil {
+		t.Fatalf("NewWithWorkDir() error = %v", err)
+	}
+	defer logger.Close()
+	e.SetRunLog(logger)
+	e.EnableTranscripts()
+
+	if _, err := e.Run(context.Background(), RunConfig{EpicID: "epic-1", AgentTimeout: time.Minute, CheckpointEvery: 100}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	runsDir := filepath.Join(dir, ".ticker", "runs")
+	iterations, err := runlog.ListTranscripts(runsDir, logger.RunID())
+	if err != nil || len(iterations) != 1 {
+		t.Fatalf("ListTranscripts() = %v, %v, want one transcript", iterations, err)
+	}
+	r, err := runlog.OpenTranscript(runsDir, logger.RunID(), iterations[0])
+	if err != nil {
+		t.Fatalf("OpenTranscript() error = %v", err)
+	}
+	defer r.Close()
+	data, _ := io.ReadAll(r)
+	if !strings.Contains(string(data), stream) {
+		t.Errorf("transcript = %q, want it to contain the agent stream", data)
+	}
+}
+
+func TestEngine_Run_RecordsCommits(t *testing.T) {
+	repo := createTempGitRepo(t)
+	t.Chdir(repo)
+	base := gitutil.HeadCommit(repo)
+
+	mockTicks := newMockTicksClientForContext()
+	mockTicks.epic = &ticks.Epic{ID: "epic-1", Title: "Epic", Type: "epic"}
+	mockTicks.tasks = []*ticks.Task{{ID: "task-1", Title: "First", Status: "open"}}
+	mockTicks.runRecords["task-1"] = &agent.RunRecord{Commits: []string{"earlier"}} // from a previous run
+
+	commit := func(opts agent.RunOpts) {
+		if err := os.WriteFile(filepath.Join(repo, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+			t.Fatalf("writing file: %v", err)
+		}
+		for _, args := range [][]string{{"add", "feature.txt"}, {"commit", "-m", "Add feature"}} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = repo
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+	}
+	mockAg := &mockAgent{name: "mock", available: true, responses: []mockResponse{
+		{output: "done", record: &agent.RunRecord{Model: "opus"}, run: commit},
+	}}
+	e := NewEngine(mockAg, mockTicks, budget.NewTracker(budget.Limits{MaxIterations: 1}), checkpoint.NewManagerWithDir(t.TempDir()))
+
+	var results []*IterationResult
+	e.Subscribe(func(ev Event) {
+		if ev, ok := ev.(IterationEndEvent); ok {
+			results = append(results, ev.Result)
+		}
+	})
+
+	if _, err := e.Run(context.Background(), RunConfig{EpicID: "epic-1", MaxIterations: 1, AgentTimeout: time.Minute, CheckpointEvery: 100}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	head := gitutil.HeadCommit(repo)
+	if head == base {
+		t.Fatal("expected the agent's commit to move HEAD")
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d iteration results, want 1", len(results))
+	}
+	if results[0].BaseCommit != base || results[0].HeadCommit != head {
+		t.Errorf("iteration commits = %s..%s, want %s..%s", results[0].BaseCommit, results[0].HeadCommit, base, head)
+	}
+	if got := strings.Join(results[0].Commits, ","); got != head {
+		t.Errorf("iteration Commits = %v, want [%s]", results[0].Commits, head)
+	}
+	record := mockTicks.runRecords["task-1"]
+	if record == nil {
+		t.Fatal("expected a run record for task-1")
+	}
+	if record.BaseCommit != base || record.HeadCommit != head {
+		t.Errorf("run record commits = %s..%s, want %s..%s", record.BaseCommit, record.HeadCommit, base, head)
+	}
+	if got, want := strings.Join(record.Commits, ","), "earlier,"+head; got != want {
+		t.Errorf("run record Commits = %s, want %s (earlier runs carried forward)", got, want)
+	}
+}
+
+func TestEngine_Run_PassesTaskAndEpicIDs(t *testing.T) {
+	mockTicks := newMockTicksClientForContext()
+	mockTicks.epic = &ticks.Epic{ID: "epic-1", Title: "Epic", Type: "epic"}
+	mockTicks.tasks = []*ticks.Task{{ID: "task-1", Title: "First", Status: "open"}}
+
+	var got agent.RunOpts
+	mockAg := &mockAgent{name: "mock", available: true, responses: []mockResponse{
+		{output: "done", run: func(opts agent.RunOpts) { got = opts }},
+	}}
+	e := NewEngine(mockAg, mockTicks, budget.NewTracker(budget.Limits{MaxIterations: 1}), checkpoint.NewManagerWithDir(t.TempDir()))
+
+	if _, err := e.Run(context.Background(), RunConfig{EpicID: "epic-1", MaxIterations: 1, AgentTimeout: time.Minute, CheckpointEvery: 100}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got.TaskID != "task-1" || got.EpicID != "epic-1" {
+		t.Errorf("RunOpts TaskID, EpicID = %q, %q; want task-1, epic-1", got.TaskID, got.EpicID)
+	}
+}
+
+func TestEngine_saveRunRecord_TaskRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		prev     *agent.RunRecord
+		wantBase string
+	}{
+		{"first run", nil, "b"},
+		{"follows on from the previous run", &agent.RunRecord{BaseCommit: "a", HeadCommit: "b", Commits: []string{"b"}}, "a"},
+		{"commits in between", &agent.RunRecord{BaseCommit: "x", HeadCommit: "y", Commits: []string{"y"}}, "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockTicks := newMockTicksClientForContext()
+			if tt.prev != nil {
+				mockTicks.runRecords["task-1"] = tt.prev
+			}
+			e := NewEngine(&mockAgent{name: "mock", available: true}, mockTicks, nil, nil)
+
+			result := &IterationResult{BaseCommit: "b", HeadCommit: "c", Commits: []string{"c"}}
+			e.saveRunRecord("task-1", &agent.RunRecord{}, result, time.Now())
+
+			record := mockTicks.runRecords["task-1"]
+			if record.BaseCommit != tt.wantBase || record.HeadCommit != "c" {
+				t.Errorf("run record commits = %s..%s, want %s..c", record.BaseCommit, record.HeadCommit, tt.wantBase)
+			}
+		})
+	}
+}
+
 func TestEngine_Run_BudgetExceeded(t *testing.T) {
 	// Create tracker that's already at limit
 	b := budget.NewTracker(budget.Limits{MaxIterations: 1})
@@ -499,67 +677,45 @@ func TestRunState_ToResult(t *testing.T) {
 	}
 }
 
-func TestEngine_Callbacks(t *testing.T) {
+func TestEngine_Subscribe(t *testing.T) {
 	dir := t.TempDir()
 	b := budget.NewTracker(budget.Limits{MaxIterations: 10})
 	c := checkpoint.NewManagerWithDir(dir)
 	mockAg := &mockAgent{name: "test", available: true}
 
-	iterStartCalled := false
-	iterEndCalled := false
-	outputCalled := false
-	signalCalled := false
+	e := NewEngine(mockAg, newMockTicksClient(), b, c)
 
-	e := &Engine{
-		agent:      mockAg,
-		budget:     b,
-		checkpoint: c,
-		prompt:     NewPromptBuilder(),
-		OnIterationStart: func(ctx IterationContext) {
+	var iterStartCalled, iterEndCalled, outputCalled, signalCalled bool
+	e.Subscribe(func(ev Event) {
+		switch ev.(type) {
+		case IterationStartEvent:
 			iterStartCalled = true
-		},
-		OnIterationEnd: func(result *IterationResult) {
+		case IterationEndEvent:
 			iterEndCalled = true
-		},
-		OnOutput: func(chunk string) {
+		case OutputEvent:
 			outputCalled = true
-		},
-		OnSignal: func(signal Signal, reason string) {
+		case SignalEvent:
 			signalCalled = true
-		},
-	}
-
-	// Verify callbacks are set
-	if e.OnIterationStart == nil {
-		t.Error("OnIterationStart not set")
-	}
-	if e.OnIterationEnd == nil {
-		t.Error("OnIterationEnd not set")
-	}
-	if e.OnOutput == nil {
-		t.Error("OnOutput not set")
-	}
-	if e.OnSignal == nil {
-		t.Error("OnSignal not set")
-	}
+		}
+	})
 
-	// Call the callbacks directly to verify they work
-	e.OnIterationStart(IterationContext{})
-	e.OnIterationEnd(&IterationResult{})
-	e.OnOutput("test")
-	e.OnSignal(SignalComplete, "")
+	// Publish directly to verify the subscriber sees each event type
+	e.Events().Publish(IterationStartEvent{})
+	e.Events().Publish(IterationEndEvent{Result: &IterationResult{}})
+	e.Events().Publish(OutputEvent{Text: "test"})
+	e.Events().Publish(SignalEvent{Signal: SignalComplete})
 
 	if !iterStartCalled {
-		t.Error("OnIterationStart was not called")
+		t.Error("IterationStartEvent was not delivered")
 	}
 	if !iterEndCalled {
-		t.Error("OnIterationEnd was not called")
+		t.Error("IterationEndEvent was not delivered")
 	}
 	if !outputCalled {
-		t.Error("OnOutput was not called")
+		t.Error("OutputEvent was not delivered")
 	}
 	if !signalCalled {
-		t.Error("OnSignal was not called")
+		t.Error("SignalEvent was not delivered")
 	}
 }
 
@@ -701,63 +857,113 @@ func TestEnableVerification(t *testing.T) {
 	}
 }
 
-func TestEngine_VerificationCallbacks(t *testing.T) {
+func TestEngine_runVerification_CommandVerifiers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+
+	e := &Engine{}
+	e.EnableVerification()
+	e.SetVerificationConfig(&verify.Config{Commands: []*verify.CommandConfig{
+		{Name: "build", Command: "true"},
+		{Name: "test", Command: "echo 'FAIL: TestFoo'; exit 1"},
+	}})
+
+	// Not a git repo, so only the command verifiers run
+	results := e.runVerification(context.Background(), &runState{workDir: t.TempDir()}, &ticks.Task{ID: "task1"}, "")
+	if results == nil {
+		t.Fatal("runVerification() = nil, want command verifier results")
+	}
+	if len(results.Results) != 2 {
+		t.Fatalf("len(Results) = %d, want 2", len(results.Results))
+	}
+	if results.AllPassed {
+		t.Error("AllPassed = true, want false with failing test command")
+	}
+	note := buildVerificationFailureNote(1, "task1", results)
+	if !strings.Contains(note, "[test] FAIL: TestFoo") {
+		t.Errorf("failure note = %q, want test output", note)
+	}
+}
+
+func TestEngine_runVerification_TaskRules(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+
+	e := &Engine{}
+	e.EnableVerification()
+	e.SetVerificationConfig(&verify.Config{Commands: []*verify.CommandConfig{
+		{Name: "build", Command: "true"},
+		{Name: "test", Command: "exit 1"},
+	}})
+	task := &ticks.Task{
+		ID:          "task1",
+		Description: "Update docs.\n\n```verify\n{\"skip\": [\"test\"], \"commands\": [{\"name\": \"docs\", \"command\": \"true\"}]}\n```",
+	}
+
+	results := e.runVerification(context.Background(), &runState{workDir: t.TempDir()}, task, "")
+	if results == nil {
+		t.Fatal("runVerification() = nil, want results")
+	}
+	var names []string
+	for _, r := range results.Results {
+		names = append(names, r.Verifier)
+	}
+	if strings.Join(names, ",") != "build,docs" {
+		t.Errorf("verifiers = %v, want [build docs]", names)
+	}
+	if !results.AllPassed {
+		t.Errorf("AllPassed = false, want true with failing test skipped: %s", results.Summary())
+	}
+}
+
+func TestEngine_VerificationEvents(t *testing.T) {
 	dir := t.TempDir()
 	b := budget.NewTracker(budget.Limits{MaxIterations: 10})
 	c := checkpoint.NewManagerWithDir(dir)
 	mockAg := &mockAgent{name: "test", available: true}
 
+	e := NewEngine(mockAg, newMockTicksClient(), b, c)
+
 	verifyStartCalled := false
 	verifyEndCalled := false
 	var verifyStartTaskID string
 	var verifyEndTaskID string
 	var verifyEndResults *verify.Results
 
-	e := &Engine{
-		agent:      mockAg,
-		budget:     b,
-		checkpoint: c,
-		prompt:     NewPromptBuilder(),
-		OnVerificationStart: func(taskID string) {
+	e.Subscribe(func(ev Event) {
+		switch ev := ev.(type) {
+		case VerificationStartEvent:
 			verifyStartCalled = true
-			verifyStartTaskID = taskID
-		},
-		OnVerificationEnd: func(taskID string, results *verify.Results) {
+			verifyStartTaskID = ev.TaskID
+		case VerificationEndEvent:
 			verifyEndCalled = true
-			verifyEndTaskID = taskID
-			verifyEndResults = results
-		},
-	}
-
-	// Verify callbacks are set
-	if e.OnVerificationStart == nil {
-		t.Error("OnVerificationStart not set")
-	}
-	if e.OnVerificationEnd == nil {
-		t.Error("OnVerificationEnd not set")
-	}
+			verifyEndTaskID = ev.TaskID
+			verifyEndResults = ev.Results
+		}
+	})
 
-	// Call the callbacks directly to verify they work
-	e.OnVerificationStart("task-123")
+	e.Events().Publish(VerificationStartEvent{TaskID: "task-123"})
 	testResults := verify.NewResults([]*verify.Result{
 		{Verifier: "test", Passed: true},
 	})
-	e.OnVerificationEnd("task-123", testResults)
+	e.Events().Publish(VerificationEndEvent{TaskID: "task-123", Results: testResults})
 
 	if !verifyStartCalled {
-		t.Error("OnVerificationStart was not called")
+		t.Error("VerificationStartEvent was not delivered")
 	}
 	if verifyStartTaskID != "task-123" {
-		t.Errorf("OnVerificationStart taskID = %q, want %q", verifyStartTaskID, "task-123")
+		t.Errorf("VerificationStartEvent.TaskID = %q, want %q", verifyStartTaskID, "task-123")
 	}
 	if !verifyEndCalled {
-		t.Error("OnVerificationEnd was not called")
+		t.Error("VerificationEndEvent was not delivered")
 	}
 	if verifyEndTaskID != "task-123" {
-		t.Errorf("OnVerificationEnd taskID = %q, want %q", verifyEndTaskID, "task-123")
+		t.Errorf("VerificationEndEvent.TaskID = %q, want %q", verifyEndTaskID, "task-123")
 	}
 	if verifyEndResults == nil {
-		t.Error("OnVerificationEnd results should not be nil")
+		t.Error("VerificationEndEvent.Results should not be nil")
 	}
 }
 
@@ -832,6 +1038,37 @@ func TestBuildVerificationFailureNote(t *testing.T) {
 				"...", // truncation indicator
 			},
 		},
+		{
+			name:      "long output keeps the end",
+			iteration: 1,
+			taskID:    "xyz789",
+			results: verify.NewResults([]*verify.Result{
+				{
+					Verifier: "test",
+					Passed:   false,
+					Output:   strings.Repeat("ok  pkg/foo\n", 100) + "FAIL: TestLast",
+				},
+			}),
+			wantContains: []string{
+				"[test]",
+				"...",
+				"FAIL: TestLast",
+			},
+		},
+		{
+			name:      "optional failure marked",
+			iteration: 4,
+			taskID:    "task2",
+			results: verify.NewResults([]*verify.Result{
+				{Verifier: "test", Passed: false, Output: "FAIL"},
+				{Verifier: "lint", Passed: false, Optional: true, Output: "unused variable"},
+			}),
+			wantContains: []string{
+				"[test]",
+				"[lint (optional)]",
+				"unused variable",
+			},
+		},
 		{
 			name:      "no output",
 			iteration: 2,
@@ -1901,45 +2138,29 @@ func TestRunConfig_WatchDefaults(t *testing.T) {
 	}
 }
 
-func TestEngine_OnIdleCallback(t *testing.T) {
+func TestEngine_IdleEvent(t *testing.T) {
 	dir := t.TempDir()
 	b := budget.NewTracker(budget.Limits{MaxIterations: 10})
 	c := checkpoint.NewManagerWithDir(dir)
 	mockAg := &mockAgent{name: "test", available: true}
 
-	idleCalled := false
-	idleCallCount := 0
+	e := NewEngine(mockAg, newMockTicksClient(), b, c)
 
-	e := &Engine{
-		agent:      mockAg,
-		budget:     b,
-		checkpoint: c,
-		prompt:     NewPromptBuilder(),
-		OnIdle: func() {
-			idleCalled = true
+	idleCallCount := 0
+	e.Subscribe(func(ev Event) {
+		if _, ok := ev.(IdleEvent); ok {
 			idleCallCount++
-		},
-	}
-
-	// Verify callback is set
-	if e.OnIdle == nil {
-		t.Error("OnIdle not set")
-	}
-
-	// Call the callback directly to verify it works
-	e.OnIdle()
+		}
+	})
 
-	if !idleCalled {
-		t.Error("OnIdle was not called")
-	}
+	e.Events().Publish(IdleEvent{})
 	if idleCallCount != 1 {
-		t.Errorf("OnIdle call count = %d, want 1", idleCallCount)
+		t.Errorf("IdleEvent count = %d, want 1", idleCallCount)
 	}
 
-	// Call again to verify counter increments
-	e.OnIdle()
+	e.Events().Publish(IdleEvent{})
 	if idleCallCount != 2 {
-		t.Errorf("OnIdle call count = %d, want 2", idleCallCount)
+		t.Errorf("IdleEvent count = %d, want 2", idleCallCount)
 	}
 }
 
@@ -1991,7 +2212,7 @@ func (m *mockTicksClientForWatch) HasOpenTasks(epicID string) (bool, error) {
 	return true, nil
 }
 
-func TestHandleWatchIdle_CallsOnIdle(t *testing.T) {
+func TestHandleWatchIdle_PublishesIdle(t *testing.T) {
 	mock := newMockTicksClientForWatch()
 	mock.epic = &ticks.Epic{ID: "test-epic", Title: "Test Epic", Type: "epic"}
 	// Configure: first poll returns no task, second poll returns a task
@@ -2008,10 +2229,12 @@ func TestHandleWatchIdle_CallsOnIdle(t *testing.T) {
 		budget:     b,
 		checkpoint: c,
 		prompt:     NewPromptBuilder(),
-		OnIdle: func() {
-			idleCallCount++
-		},
 	}
+	engine.Subscribe(func(ev Event) {
+		if _, ok := ev.(IdleEvent); ok {
+			idleCallCount++
+		}
+	})
 
 	state := &runState{
 		epicID:    "test-epic",
@@ -2026,14 +2249,14 @@ func TestHandleWatchIdle_CallsOnIdle(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
 	defer cancel()
 
-	// handleWatchIdle should call OnIdle at least once and then return nil when tasks become available
+	// handleWatchIdle should publish IdleEvent at least once and then return nil when tasks become available
 	result := engine.handleWatchIdle(ctx, config, state, time.Time{})
 
 	if result != nil {
 		t.Errorf("handleWatchIdle returned result %+v, want nil (tasks became available)", result)
 	}
 	if idleCallCount < 1 {
-		t.Errorf("OnIdle called %d times, want at least 1", idleCallCount)
+		t.Errorf("IdleEvent published %d times, want at least 1", idleCallCount)
 	}
 }
 
@@ -2053,7 +2276,6 @@ func TestHandleWatchIdle_WatchTimeout(t *testing.T) {
 		budget:     b,
 		checkpoint: c,
 		prompt:     NewPromptBuilder(),
-		OnIdle:     func() {},
 	}
 
 	state := &runState{
@@ -2096,7 +2318,6 @@ func TestHandleWatchIdle_ContextCancelled(t *testing.T) {
 		budget:     b,
 		checkpoint: c,
 		prompt:     NewPromptBuilder(),
-		OnIdle:     func() {},
 	}
 
 	state := &runState{
@@ -2144,7 +2365,6 @@ func TestHandleWatchIdle_EpicCompletes(t *testing.T) {
 		budget:     b,
 		checkpoint: c,
 		prompt:     NewPromptBuilder(),
-		OnIdle:     func() {},
 	}
 
 	state := &runState{
@@ -2173,6 +2393,36 @@ func TestHandleWatchIdle_EpicCompletes(t *testing.T) {
 	}
 }
 
+func TestHandleWatchIdle_Wake(t *testing.T) {
+	mock := newMockTicksClientForWatch()
+	mock.epic = &ticks.Epic{ID: "test-epic", Title: "Test Epic", Type: "epic"}
+	mock.tasksAvailable = []bool{true}
+	mock.hasOpenReturns = []bool{true}
+
+	engine := &Engine{
+		ticks:      mock,
+		budget:     budget.NewTracker(budget.Limits{MaxIterations: 10}),
+		checkpoint: checkpoint.NewManagerWithDir(t.TempDir()),
+		prompt:     NewPromptBuilder(),
+	}
+
+	wake := make(chan struct{}, 1)
+	wake <- struct{}{}
+	config := RunConfig{
+		EpicID:            "test-epic",
+		Watch:             true,
+		WatchPollInterval: time.Hour, // only the wake-up can end idling
+		WakeChan:          wake,
+	}
+	state := &runState{epicID: "test-epic", startTime: time.Now()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if result := engine.handleWatchIdle(ctx, config, state, time.Time{}); result != nil {
+		t.Errorf("handleWatchIdle returned result %+v, want nil (woken up with a task available)", result)
+	}
+}
+
 // =============================================================================
 // Debounce Tests
 // =============================================================================
@@ -2459,3 +2709,53 @@ func TestGetNextTaskWithDebounce_NegativeDebounce(t *testing.T) {
 		t.Errorf("GetTask called %d times, want 0 (negative debounce = no debounce)", len(mock.getTaskCalls))
 	}
 }
+
+func TestResolveAgent_Precedence(t *testing.T) {
+	r := agent.NewRegistry(nil)
+	r.Register("cheap", func() agent.Agent { return &mockAgent{name: "cheap"} })
+	r.Register("smart", func() agent.Agent { return &mockAgent{name: "smart"} })
+	r.SetDefault("cheap")
+
+	tests := []struct {
+		name string
+		epic *ticks.Epic
+		task *ticks.Task
+		want string
+	}{
+		{"registry default", &ticks.Epic{ID: "e1"}, &ticks.Task{ID: "t1"}, "cheap"},
+		{"nil epic", nil, &ticks.Task{ID: "t1"}, "cheap"},
+		{"epic field", &ticks.Epic{Agent: "smart"}, &ticks.Task{ID: "t1"}, "smart"},
+		{"epic label", &ticks.Epic{Labels: []string{"backend", "agent:smart"}}, &ticks.Task{ID: "t1"}, "smart"},
+		{"task overrides epic", &ticks.Epic{Agent: "smart"}, &ticks.Task{Agent: "cheap"}, "cheap"},
+		{"task label overrides epic", &ticks.Epic{Agent: "cheap"}, &ticks.Task{Labels: []string{"agent:smart"}}, "smart"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveAgent(r, tt.epic, tt.task)
+			if err != nil {
+				t.Fatalf("ResolveAgent() error = %v", err)
+			}
+			if got.Name() != tt.want {
+				t.Errorf("ResolveAgent() = %q, want %q", got.Name(), tt.want)
+			}
+		})
+	}
+
+	if _, err := ResolveAgent(r, nil, &ticks.Task{Agent: "missing"}); err == nil {
+		t.Error("ResolveAgent() with unknown agent should return error")
+	}
+}
+
+func TestEngine_resolveAgent_NoRegistry(t *testing.T) {
+	mockAg := &mockAgent{name: "fixed"}
+	e := NewEngine(mockAg, newMockTicksClient(), nil, nil)
+
+	got, err := e.resolveAgent(nil, &ticks.Task{Agent: "codex"})
+	if err != nil {
+		t.Fatalf("resolveAgent() error = %v", err)
+	}
+	if got != mockAg {
+		t.Errorf("resolveAgent() = %v, want engine agent when no registry is set", got.Name())
+	}
+}
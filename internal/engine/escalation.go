@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pengelbrecht/ticker/internal/runlog"
+	"github.com/pengelbrecht/ticker/internal/verify"
+)
+
+// escalationTier returns the tier the task is currently escalated to,
+// or nil if it runs at the base level.
+func (e *Engine) escalationTier(state *runState, taskID string) *verify.EscalationTier {
+	level := state.escalationLevel[taskID]
+	tiers := e.escalation.GetTiers()
+	if level == 0 || level > len(tiers) {
+		return nil
+	}
+	return &tiers[level-1]
+}
+
+// escalate moves the task to the next escalation tier, logging the change and
+// leaving a note on the task. Returns false if the task is already at the top
+// tier (or no tiers are configured).
+func (e *Engine) escalate(state *runState, taskID, reason string) bool {
+	tiers := e.escalation.GetTiers()
+	level := state.escalationLevel[taskID]
+	if level >= len(tiers) {
+		return false
+	}
+
+	level++
+	if state.escalationLevel == nil {
+		state.escalationLevel = make(map[string]int)
+	}
+	state.escalationLevel[taskID] = level
+	tier := tiers[level-1]
+
+	if e.runLog != nil {
+		e.runLog.LogTaskEscalated(runlog.TaskEscalatedData{
+			TaskID:        taskID,
+			Tier:          level,
+			MaxTier:       len(tiers),
+			Reason:        reason,
+			Model:         tier.GetModel(),
+			Timeout:       tier.GetTimeout(),
+			ExtraThinking: tier.GetExtraThinking(),
+		})
+	}
+	_ = e.ticks.AddNote(taskID, buildEscalationNote(level, len(tiers), reason, tier))
+	return true
+}
+
+// buildEscalationNote describes an escalation for the task's notes.
+func buildEscalationNote(level, maxLevel int, reason string, tier verify.EscalationTier) string {
+	var changes []string
+	if m := tier.GetModel(); m != "" {
+		changes = append(changes, "model "+m)
+	}
+	if d := tier.GetTimeout(); d > 0 {
+		changes = append(changes, fmt.Sprintf("timeout %v", d))
+	}
+	if tier.GetExtraThinking() {
+		changes = append(changes, "extra thinking")
+	}
+
+	note := fmt.Sprintf("Escalated to tier %d/%d after %s.", level, maxLevel, reason)
+	if len(changes) > 0 {
+		note += " Next attempt uses " + strings.Join(changes, ", ") + "."
+	}
+	return note
+}
@@ -0,0 +1,164 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/checkpoint"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+	"github.com/pengelbrecht/ticker/internal/verify"
+)
+
+// mockAgentOpts records the options and prompt of every run.
+type mockAgentOpts struct {
+	opts    []agent.RunOpts
+	prompts []string
+}
+
+func (m *mockAgentOpts) Name() string    { return "test" }
+func (m *mockAgentOpts) Available() bool { return true }
+
+func (m *mockAgentOpts) Run(ctx context.Context, prompt string, opts agent.RunOpts) (*agent.Result, error) {
+	m.opts = append(m.opts, opts)
+	m.prompts = append(m.prompts, prompt)
+	return &agent.Result{Output: "still working", Duration: time.Millisecond}, nil
+}
+
+func TestEngine_Run_EscalatesBeforeStuck(t *testing.T) {
+	extra := true
+	escalation := &verify.EscalationConfig{Tiers: []verify.EscalationTier{
+		{Model: strPtr("sonnet")},
+		{Model: strPtr("opus"), Timeout: strPtr("45m"), ExtraThinking: &extra},
+	}}
+
+	tests := []struct {
+		name       string
+		escalation *verify.EscalationConfig
+		wantModels []string
+	}{
+		{"no tiers", nil, []string{"", ""}},
+		{"two tiers", escalation, []string{"", "sonnet", "opus"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The agent never closes the task, so it is selected every time
+			task := &ticks.Task{ID: "task1", Title: "Stubborn task"}
+			mockTicks := newMockTicksClient()
+			mockTicks.epic = &ticks.Epic{ID: "epic1", Title: "Epic", Type: "epic"}
+			for i := 0; i < 10; i++ {
+				mockTicks.tasks = append(mockTicks.tasks, task)
+			}
+
+			mockAg := &mockAgentOpts{}
+			e := NewEngine(mockAg, mockTicks, budget.NewTracker(budget.Limits{MaxIterations: 20}), checkpoint.NewManagerWithDir(t.TempDir()))
+			e.SetEscalation(tt.escalation)
+
+			result, err := e.Run(context.Background(), RunConfig{
+				EpicID:          "epic1",
+				MaxTaskRetries:  2,
+				AgentTimeout:    time.Minute,
+				CheckpointEvery: 100,
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if !strings.HasPrefix(result.ExitReason, "stuck on task task1") {
+				t.Errorf("ExitReason = %q, want stuck on task1", result.ExitReason)
+			}
+
+			var models []string
+			for _, o := range mockAg.opts {
+				models = append(models, o.Model)
+			}
+			if strings.Join(models, ",") != strings.Join(tt.wantModels, ",") {
+				t.Fatalf("models = %q, want %q", models, tt.wantModels)
+			}
+
+			if tt.escalation == nil {
+				return
+			}
+			last := len(mockAg.opts) - 1
+			if mockAg.opts[last].Timeout != 45*time.Minute {
+				t.Errorf("top tier Timeout = %v, want 45m", mockAg.opts[last].Timeout)
+			}
+			if mockAg.opts[1].Timeout != time.Minute {
+				t.Errorf("tier 1 Timeout = %v, want base 1m", mockAg.opts[1].Timeout)
+			}
+			if !strings.Contains(mockAg.prompts[last], "Previous Attempts Failed") {
+				t.Error("top tier prompt should contain the extra-thinking section")
+			}
+			if strings.Contains(mockAg.prompts[1], "Previous Attempts Failed") {
+				t.Error("tier 1 prompt should not contain the extra-thinking section")
+			}
+
+			var escalationNotes int
+			for _, n := range mockTicks.addedNotes {
+				if strings.HasPrefix(n, "Escalated to tier") {
+					escalationNotes++
+				}
+			}
+			if escalationNotes != 2 {
+				t.Errorf("escalation notes = %d, want 2 (notes: %q)", escalationNotes, mockTicks.addedNotes)
+			}
+		})
+	}
+}
+
+func TestEngine_escalate(t *testing.T) {
+	e := &Engine{
+		ticks: newMockTicksClient(),
+		escalation: &verify.EscalationConfig{Tiers: []verify.EscalationTier{
+			{Model: strPtr("opus")},
+		}},
+	}
+	state := &runState{}
+
+	if tier := e.escalationTier(state, "task1"); tier != nil {
+		t.Errorf("escalationTier() before escalation = %+v, want nil", tier)
+	}
+	if !e.escalate(state, "task1", "verification failed") {
+		t.Fatal("escalate() = false, want true for first tier")
+	}
+	if tier := e.escalationTier(state, "task1"); tier == nil || tier.GetModel() != "opus" {
+		t.Errorf("escalationTier() = %+v, want opus tier", tier)
+	}
+	if e.escalate(state, "task1", "retry") {
+		t.Error("escalate() = true at top tier, want false")
+	}
+	if tier := e.escalationTier(state, "task2"); tier != nil {
+		t.Errorf("escalationTier() for other task = %+v, want nil", tier)
+	}
+}
+
+func TestBuildEscalationNote(t *testing.T) {
+	extra := true
+	tests := []struct {
+		name string
+		tier verify.EscalationTier
+		want string
+	}{
+		{
+			name: "all overrides",
+			tier: verify.EscalationTier{Model: strPtr("opus"), Timeout: strPtr("1h"), ExtraThinking: &extra},
+			want: "Escalated to tier 2/3 after retry. Next attempt uses model opus, timeout 1h0m0s, extra thinking.",
+		},
+		{
+			name: "no overrides",
+			tier: verify.EscalationTier{},
+			want: "Escalated to tier 2/3 after retry.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildEscalationNote(2, 3, "retry", tt.tier); got != tt.want {
+				t.Errorf("buildEscalationNote() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/verify"
+)
+
+// Event is something that happened during a run. The engine publishes events
+// on its EventBus; subscribers (TUI, headless output, run log, ...) switch on
+// the concrete type:
+//
+//	eng.Subscribe(func(ev engine.Event) {
+//		switch ev := ev.(type) {
+//		case engine.IterationStartEvent:
+//			fmt.Println("starting", ev.Context.Task.ID)
+//		case engine.SignalEvent:
+//			fmt.Println("signal", ev.Signal)
+//		}
+//	})
+type Event interface {
+	// Info returns the epic and time of the event.
+	Info() EventInfo
+}
+
+// EventInfo is embedded in every event.
+type EventInfo struct {
+	// EpicID is the epic being run when the event happened.
+	EpicID string
+
+	// Time is when the event was published.
+	Time time.Time
+}
+
+// Info returns i, so that embedding EventInfo implements Event.
+func (i EventInfo) Info() EventInfo {
+	return i
+}
+
+// IterationStartEvent is published before the agent runs for a task.
+type IterationStartEvent struct {
+	EventInfo
+	Context IterationContext
+}
+
+// IterationEndEvent is published after each iteration, once its usage has
+// been added to the budget.
+type IterationEndEvent struct {
+	EventInfo
+	Result *IterationResult
+}
+
+// OutputEvent carries a chunk of agent output text, or an engine notice
+// shown inline with it.
+type OutputEvent struct {
+	EventInfo
+	Text string
+}
+
+// AgentStateEvent carries a snapshot of the running agent's state (output,
+// thinking, tools, metrics). Published whenever the state changes.
+type AgentStateEvent struct {
+	EventInfo
+	Snapshot agent.AgentStateSnapshot
+}
+
+// SignalEvent is published when the agent emits a signal.
+type SignalEvent struct {
+	EventInfo
+	TaskID string
+	Signal Signal
+	Reason string
+}
+
+// VerificationStartEvent is published before a closed task's verifiers run.
+type VerificationStartEvent struct {
+	EventInfo
+	TaskID string
+}
+
+// VerificationEndEvent carries the results of a task's verification.
+type VerificationEndEvent struct {
+	EventInfo
+	TaskID  string
+	WorkDir string
+	Results *verify.Results
+}
+
+// ContextGeneratingEvent is published when epic context generation starts.
+type ContextGeneratingEvent struct {
+	EventInfo
+	TaskCount int
+}
+
+// ContextGeneratedEvent is published when epic context has been generated.
+type ContextGeneratedEvent struct {
+	EventInfo
+	Tokens int // approximate
+}
+
+// ContextLoadedEvent is published when stored epic context is loaded.
+type ContextLoadedEvent struct {
+	EventInfo
+	Content string
+}
+
+// ContextSkippedEvent is published when epic context generation is skipped.
+type ContextSkippedEvent struct {
+	EventInfo
+	Reason string
+}
+
+// ContextFailedEvent is published when epic context generation fails.
+type ContextFailedEvent struct {
+	EventInfo
+	Error string
+}
+
+// ContextActiveEvent is published at the start of each iteration that has
+// epic context in its prompt.
+type ContextActiveEvent struct {
+	EventInfo
+}
+
+// PauseEvent is published when the run pauses (Paused set) and when it
+// resumes. Pause requests sent on RunConfig.PauseChan take effect between
+// iterations.
+type PauseEvent struct {
+	EventInfo
+	Iteration int // iterations run so far in this epic
+	Paused    bool
+}
+
+// IdleEvent is published when watch mode has no task to run and waits.
+type IdleEvent struct {
+	EventInfo
+}
+
+// BudgetEvent reports budget usage after each iteration, and when a limit
+// stops the run (Exceeded set, with Reason).
+type BudgetEvent struct {
+	EventInfo
+	Iteration int // iterations run so far in this epic
+	Usage     budget.Usage
+	Limits    budget.Limits
+	Exceeded  bool
+	Reason    string
+}
+
+// EventBus delivers events to any number of subscribers. Events are delivered
+// synchronously, in subscription order, on the goroutine that publishes them;
+// subscribers that do slow work should hand it off. AgentStateEvent and
+// OutputEvent are published from the agent's goroutine, the rest from the
+// run loop. It is safe for concurrent use.
+type EventBus struct {
+	mu     sync.RWMutex
+	subs   []subscription
+	nextID int
+}
+
+type subscription struct {
+	id int
+	fn func(Event)
+}
+
+// NewEventBus creates an event bus with no subscribers. A nil *EventBus
+// can be published to and has no subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe registers fn to receive every event published from now on.
+// Returns a function that removes the subscription.
+func (b *EventBus) Subscribe(fn func(Event)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subs = append(b.subs, subscription{id: id, fn: fn})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subs {
+			if s.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish delivers ev to all subscribers.
+func (b *EventBus) Publish(ev Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	subs := b.subs
+	b.mu.RUnlock()
+
+	for _, s := range subs {
+		s.fn(ev)
+	}
+}
+
+// eventInfo returns the EventInfo for an event about epicID, published now.
+func eventInfo(epicID string) EventInfo {
+	return EventInfo{EpicID: epicID, Time: time.Now()}
+}
@@ -0,0 +1,166 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/checkpoint"
+	"github.com/pengelbrecht/ticker/internal/runlog"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+func TestEventBus_Subscribe(t *testing.T) {
+	bus := NewEventBus()
+
+	var got []string
+	bus.Subscribe(func(ev Event) { got = append(got, "first") })
+	unsubscribe := bus.Subscribe(func(ev Event) { got = append(got, "second") })
+	bus.Subscribe(func(ev Event) { got = append(got, "third") })
+
+	bus.Publish(IdleEvent{})
+	want := []string{"first", "second", "third"}
+	if len(got) != len(want) {
+		t.Fatalf("delivered to %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("delivery %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	got = nil
+	unsubscribe()
+	unsubscribe() // second call is a no-op
+	bus.Publish(IdleEvent{})
+	if len(got) != 2 || got[0] != "first" || got[1] != "third" {
+		t.Errorf("after unsubscribe delivered to %v, want [first third]", got)
+	}
+}
+
+func TestEventBus_PublishNil(t *testing.T) {
+	var bus *EventBus
+	bus.Publish(IdleEvent{}) // must not panic
+}
+
+func TestEngine_Run_PublishesEvents(t *testing.T) {
+	mockTicks := newMockTicksClientForContext()
+	mockTicks.epic = &ticks.Epic{ID: "epic-1", Title: "Epic", Type: "epic"}
+	mockTicks.tasks = []*ticks.Task{{ID: "task-1", Title: "First", Status: "open"}}
+
+	mockAg := &mockAgent{name: "mock", available: true, responses: []mockResponse{{output: "done <promise>COMPLETE</promise>"}}}
+	e := NewEngine(mockAg, mockTicks, budget.NewTracker(budget.Limits{MaxIterations: 5}), checkpoint.NewManagerWithDir(t.TempDir()))
+
+	var events []Event
+	e.Subscribe(func(ev Event) { events = append(events, ev) })
+
+	if _, err := e.Run(context.Background(), RunConfig{EpicID: "epic-1", AgentTimeout: time.Minute, CheckpointEvery: 100}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	index := func(match func(Event) bool) int {
+		for i, ev := range events {
+			if match(ev) {
+				return i
+			}
+		}
+		return -1
+	}
+	start := index(func(ev Event) bool { _, ok := ev.(IterationStartEvent); return ok })
+	signal := index(func(ev Event) bool { _, ok := ev.(SignalEvent); return ok })
+	end := index(func(ev Event) bool { _, ok := ev.(IterationEndEvent); return ok })
+	budgetEv := index(func(ev Event) bool { _, ok := ev.(BudgetEvent); return ok })
+
+	if start < 0 || signal < 0 || end < 0 || budgetEv < 0 {
+		t.Fatalf("missing events: start=%d signal=%d end=%d budget=%d", start, signal, end, budgetEv)
+	}
+	// Signals are handled once the iteration is over
+	if !(start < end && end < budgetEv && budgetEv < signal) {
+		t.Errorf("event order start=%d end=%d budget=%d signal=%d, want increasing", start, end, budgetEv, signal)
+	}
+
+	for _, ev := range events {
+		if ev.Info().EpicID != "epic-1" {
+			t.Errorf("%T EpicID = %q, want %q", ev, ev.Info().EpicID, "epic-1")
+		}
+		if ev.Info().Time.IsZero() {
+			t.Errorf("%T Time is zero", ev)
+		}
+	}
+
+	if b := events[budgetEv].(BudgetEvent); b.Iteration != 1 || b.Exceeded {
+		t.Errorf("BudgetEvent = %+v, want iteration 1, not exceeded", b)
+	}
+}
+
+func TestEngine_LogEvent(t *testing.T) {
+	dir := t.TempDir()
+	logger, err := runlog.NewWithWorkDir("epic-1", dir)
+	if err != nil {
+		t.Fatalf("NewWithWorkDir() error = %v", err)
+	}
+	defer logger.Close()
+
+	e := NewEngine(&mockAgent{name: "mock", available: true}, newMockTicksClient(), budget.NewTracker(budget.Limits{}), checkpoint.NewManagerWithDir(t.TempDir()))
+	e.SetRunLog(logger)
+
+	task := &ticks.Task{ID: "task-1", Title: "First"}
+	e.Events().Publish(IterationStartEvent{EventInfo: eventInfo("epic-1"), Context: IterationContext{Iteration: 1, Task: task}})
+	e.Events().Publish(SignalEvent{EventInfo: eventInfo("epic-1"), TaskID: "task-1", Signal: SignalComplete})
+	e.Events().Publish(OutputEvent{EventInfo: eventInfo("epic-1"), Text: "not logged"})
+	e.Events().Publish(IterationEndEvent{EventInfo: eventInfo("epic-1"), Result: &IterationResult{Iteration: 1, TaskID: "task-1"}})
+
+	logged, err := runlog.ReadEvents(logger.FilePath())
+	if err != nil {
+		t.Fatalf("ReadEvents() error = %v", err)
+	}
+	var types []runlog.EventType
+	for _, ev := range logged {
+		types = append(types, ev.Type)
+	}
+	want := []runlog.EventType{runlog.EventIterationStart, runlog.EventSignalDetected, runlog.EventIterationEnd}
+	if len(types) != len(want) {
+		t.Fatalf("logged %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Errorf("logged[%d] = %q, want %q", i, types[i], want[i])
+		}
+	}
+}
+
+func TestEngine_Run_StopWhilePaused(t *testing.T) {
+	mockTicks := newMockTicksClientForContext()
+	mockTicks.epic = &ticks.Epic{ID: "epic-1", Title: "Epic", Type: "epic"}
+	mockTicks.tasks = []*ticks.Task{{ID: "task-1", Title: "First", Status: "open"}}
+
+	mockAg := &mockAgent{name: "mock", available: true, responses: []mockResponse{{output: "done"}}}
+	e := NewEngine(mockAg, mockTicks, budget.NewTracker(budget.Limits{MaxIterations: 5}), checkpoint.NewManagerWithDir(t.TempDir()))
+
+	pause := make(chan bool, 1)
+	stop := make(chan struct{})
+	pause <- true
+
+	var paused bool
+	e.Subscribe(func(ev Event) {
+		if p, ok := ev.(PauseEvent); ok && p.Paused {
+			paused = true
+			close(stop)
+		}
+	})
+
+	result, err := e.Run(context.Background(), RunConfig{EpicID: "epic-1", AgentTimeout: time.Minute, CheckpointEvery: 100, PauseChan: pause, StopChan: stop})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !paused {
+		t.Error("PauseEvent{Paused: true} was not published")
+	}
+	if result.ExitReason != ExitReasonStopped {
+		t.Errorf("ExitReason = %q, want %q", result.ExitReason, ExitReasonStopped)
+	}
+	if result.Iterations != 0 {
+		t.Errorf("Iterations = %d, want 0", result.Iterations)
+	}
+}
@@ -269,6 +269,19 @@ func (h *HeadlessOutput) Interrupted() {
 	}
 }
 
+// Idle outputs when watch mode is waiting for tasks.
+func (h *HeadlessOutput) Idle() {
+	h.flushOutput()
+	if h.jsonl {
+		h.writeJSON(map[string]interface{}{
+			"type":    "idle",
+			"message": "waiting for tasks",
+		})
+	} else {
+		fmt.Fprintf(h.writer, "%s[IDLE] No tasks available, waiting...\n", h.prefix())
+	}
+}
+
 // ContextGenerating outputs when context generation starts.
 func (h *HeadlessOutput) ContextGenerating(epicID string, taskCount int) {
 	if h.jsonl {
@@ -424,6 +437,46 @@ func contextPreviewForLoaded(text string, maxChars int) string {
 	return preview
 }
 
+// Handle writes an engine event. Subscribe it to an engine's events to get
+// headless output for its run. Verification results are followed by a
+// task_complete line.
+func (h *HeadlessOutput) Handle(ev Event) {
+	switch ev := ev.(type) {
+	case IterationStartEvent:
+		h.Task(ev.Context.Task, ev.Context.Iteration)
+		if ev.Context.EpicContext != "" {
+			h.ContextInjected(ev.Context.Task.ID, ev.Context.EpicContext)
+		}
+	case OutputEvent:
+		h.Output(ev.Text)
+	case SignalEvent:
+		h.Signal(ev.Signal, ev.Reason)
+	case VerificationStartEvent:
+		h.VerifyStart(ev.TaskID)
+	case VerificationEndEvent:
+		passed := true
+		if ev.Results != nil {
+			passed = ev.Results.AllPassed
+		}
+		h.VerifyEnd(ev.TaskID, ev.Results)
+		h.TaskComplete(ev.TaskID, passed)
+	case IdleEvent:
+		h.Idle()
+	case ContextGeneratingEvent:
+		h.ContextGenerating(ev.EpicID, ev.TaskCount)
+	case ContextGeneratedEvent:
+		h.ContextGenerated(ev.EpicID, ev.Tokens)
+	case ContextLoadedEvent:
+		h.ContextLoaded(ev.EpicID, ev.Content)
+	case ContextSkippedEvent:
+		h.ContextSkipped(ev.EpicID, ev.Reason)
+	case ContextFailedEvent:
+		h.ContextFailed(ev.EpicID, ev.Error)
+	case ContextActiveEvent:
+		h.ContextActive(ev.EpicID)
+	}
+}
+
 // writeJSON writes a JSON object as a single line.
 func (h *HeadlessOutput) writeJSON(data map[string]interface{}) {
 	if h.epicID != "" {
@@ -459,6 +459,42 @@ func TestHeadlessOutput_Interrupted(t *testing.T) {
 	})
 }
 
+func TestHeadlessOutput_Handle(t *testing.T) {
+	var buf bytes.Buffer
+	out := NewHeadlessOutput(true, "")
+	out.SetWriter(&buf)
+
+	task := &ticks.Task{ID: "task1", Title: "Test task"}
+	out.Handle(IterationStartEvent{Context: IterationContext{Iteration: 1, Task: task}})
+	out.Handle(OutputEvent{Text: "working\n"})
+	out.Handle(SignalEvent{TaskID: "task1", Signal: SignalComplete})
+	out.Handle(VerificationStartEvent{TaskID: "task1"})
+	out.Handle(VerificationEndEvent{TaskID: "task1", Results: &verify.Results{AllPassed: false}})
+	out.Handle(IdleEvent{})
+	out.Handle(BudgetEvent{Iteration: 1}) // not written
+
+	var types []string
+	var taskComplete map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &data); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", line, err)
+		}
+		types = append(types, data["type"].(string))
+		if data["type"] == "task_complete" {
+			taskComplete = data
+		}
+	}
+
+	want := []string{"task", "output", "signal", "verify_start", "verify_end", "task_complete", "idle"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("event types = %v, want %v", types, want)
+	}
+	if taskComplete == nil || taskComplete["verification_pass"] != false {
+		t.Errorf("task_complete = %v, want verification_pass=false", taskComplete)
+	}
+}
+
 func TestHeadlessOutput_ContextInjected(t *testing.T) {
 	context := "# Epic Context: [abc] Test Epic\n\n## Relevant Code\n\n- file1.go\n- file2.go"
 
@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+// NotesDigestPrefix starts the epic note that summarizes the notes before it.
+// Prompts include the latest digest and the notes after it, not the notes it
+// replaces.
+const NotesDigestPrefix = "Notes digest:"
+
+// notesDigestTimeout bounds the agent run that writes a notes digest.
+const notesDigestTimeout = 5 * time.Minute
+
+// EnableNotesCompaction makes the engine summarize the epic notes into a
+// digest once they grow past threshold estimated tokens. The digest is added
+// to the epic as a note, so later iterations and runs start from it. model
+// overrides the agent's model for the summary ("" = default).
+func (e *Engine) EnableNotesCompaction(threshold int, model string) {
+	e.compactNotesTokens = threshold
+	e.compactionModel = model
+}
+
+// notesSinceDigest returns the latest notes digest and the notes after it,
+// or all notes if there is no digest.
+func notesSinceDigest(notes []string) []string {
+	for i := len(notes) - 1; i >= 0; i-- {
+		if strings.Contains(notes[i], NotesDigestPrefix) {
+			return notes[i:]
+		}
+	}
+	return notes
+}
+
+// compactNotes returns the notes to include in the prompt for task. If they
+// are over the compaction threshold, an agent summarizes them into a digest
+// that is written back to the epic and returned in their place. On failure
+// the notes are returned as they are.
+func (e *Engine) compactNotes(ctx context.Context, state *runState, task *ticks.Task, notes []string) []string {
+	notes = notesSinceDigest(notes)
+	if e.compactNotesTokens <= 0 || len(notes) < 2 {
+		return notes
+	}
+	before := estimateTokens(strings.Join(notes, "\n"))
+	if before <= e.compactNotesTokens {
+		return notes
+	}
+
+	fail := func(err error) []string {
+		if e.runLog != nil {
+			e.runLog.LogContextError(state.epicID, err.Error(), "compact_notes")
+		}
+		return notes
+	}
+
+	a, err := e.resolveAgent(state.epic, task)
+	if err != nil {
+		return fail(fmt.Errorf("resolving agent: %w", err))
+	}
+	result, err := a.Run(ctx, buildNotesDigestPrompt(state.epic, notes), agent.RunOpts{
+		Timeout:  notesDigestTimeout,
+		WorkDir:  state.workDir,
+		Model:    e.compactionModel,
+		ReadOnly: true,
+	})
+	if result != nil {
+		e.addUsage(state, result.TokensIn, result.TokensOut, result.Cost)
+	}
+	if err != nil {
+		return fail(fmt.Errorf("summarizing notes: %w", err))
+	}
+	digest := parseNotesDigest(result.Output)
+	if digest == "" {
+		return fail(fmt.Errorf("summarizing notes: agent returned no digest"))
+	}
+
+	note := fmt.Sprintf("%s (%d notes) %s", NotesDigestPrefix, len(notes), digest)
+	if err := e.ticks.AddNote(state.epicID, note); err != nil {
+		return fail(fmt.Errorf("saving notes digest: %w", err))
+	}
+	if e.runLog != nil {
+		e.runLog.LogNotesCompacted(state.epicID, len(notes), before, estimateTokens(note))
+	}
+	return []string{note}
+}
+
+// buildNotesDigestPrompt asks the agent to summarize notes on epic.
+func buildNotesDigestPrompt(epic *ticks.Epic, notes []string) string {
+	var sb strings.Builder
+	sb.WriteString("# Compact Epic Notes\n\n")
+	if epic != nil {
+		fmt.Fprintf(&sb, "The notes below were left on epic [%s] %s by earlier iterations of an autonomous agent. ", epic.ID, epic.Title)
+	} else {
+		sb.WriteString("The notes below were left on an epic by earlier iterations of an autonomous agent. ")
+	}
+	sb.WriteString("They have grown too long to include in every prompt. ")
+	sb.WriteString("Summarize them into a digest that replaces them. Do not modify any files.\n\n")
+	sb.WriteString("Keep decisions, the current state of the work, known problems and their causes, ")
+	sb.WriteString("anything a human asked for, and warnings a later iteration needs. ")
+	sb.WriteString("Merge repeated notes (such as repeated timeouts or verification failures on the same task) into one line with a count. ")
+	sb.WriteString("Drop anything that is no longer relevant.\n\n")
+
+	sb.WriteString("## Notes\n\n")
+	for _, n := range notes {
+		sb.WriteString("- ")
+		sb.WriteString(n)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n## Output\n\n")
+	sb.WriteString("End your response with the digest as a short list, one fact per line:\n\n")
+	sb.WriteString("<digest>\n- ...\n</digest>\n")
+	return sb.String()
+}
+
+// parseNotesDigest extracts the digest from the last <digest>...</digest>
+// block in output and joins its lines into one, since epic notes are stored
+// one per line. Returns "" if there is no non-empty digest.
+func parseNotesDigest(output string) string {
+	end := strings.LastIndex(output, "</digest>")
+	if end < 0 {
+		return ""
+	}
+	begin := strings.LastIndex(output[:end], "<digest>")
+	if begin < 0 {
+		return ""
+	}
+
+	var items []string
+	for _, line := range strings.Split(output[begin+len("<digest>"):end], "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(strings.TrimLeft(line, "-*"))
+		if line != "" {
+			items = append(items, line)
+		}
+	}
+	return strings.Join(items, "; ")
+}
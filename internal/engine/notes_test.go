@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+func TestNotesSinceDigest(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes []string
+		want  int
+	}{
+		{"no notes", nil, 0},
+		{"no digest", []string{"a", "b", "c"}, 3},
+		{"digest first", []string{NotesDigestPrefix + " x", "b", "c"}, 3},
+		{"digest in middle", []string{"a", NotesDigestPrefix + " x", "c"}, 2},
+		{"latest digest wins", []string{NotesDigestPrefix + " x", "b", NotesDigestPrefix + " y", "d"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := notesSinceDigest(tt.notes)
+			if len(got) != tt.want {
+				t.Errorf("notesSinceDigest() = %v, want %d notes", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNotesDigest(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"no digest", "I summarized the notes.", ""},
+		{"empty digest", "<digest>\n\n</digest>", ""},
+		{"list", "Here it is:\n<digest>\n- Auth uses JWT\n- task-3 timed out 4 times\n</digest>", "Auth uses JWT; task-3 timed out 4 times"},
+		{"last block wins", "<digest>- old</digest>\n<digest>\n* new\n</digest>", "new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseNotesDigest(tt.output); got != tt.want {
+				t.Errorf("parseNotesDigest() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_compactNotes(t *testing.T) {
+	var notes []string
+	for range 30 {
+		notes = append(notes, "Iteration timed out on task-3 "+strings.Repeat("x", 100))
+	}
+	digestOutput := "<digest>\n- task-3 timed out 30 times\n</digest>"
+
+	tests := []struct {
+		name      string
+		threshold int
+		output    string
+		wantNotes int
+		wantAdded bool
+	}{
+		{"disabled", 0, digestOutput, 30, false},
+		{"under threshold", 5000, digestOutput, 30, false},
+		{"compacted", 500, digestOutput, 1, true},
+		{"no digest returned", 500, "done", 30, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockAg := &mockAgentForContext{name: "test", available: true, taskOutputs: []string{tt.output}}
+			mockTicks := newMockTicksClientForContext()
+			b := budget.NewTracker(budget.Limits{})
+			e := &Engine{agent: mockAg, ticks: mockTicks, budget: b}
+			e.EnableNotesCompaction(tt.threshold, "")
+
+			state := &runState{epicID: "epic-1", epic: &ticks.Epic{ID: "epic-1", Title: "Epic"}}
+			got := e.compactNotes(context.Background(), state, &ticks.Task{ID: "task-3"}, notes)
+
+			if len(got) != tt.wantNotes {
+				t.Errorf("compactNotes() returned %d notes, want %d", len(got), tt.wantNotes)
+			}
+			// The summary run counts toward the budget, but not as an iteration
+			wantTokens := 0
+			if mockAg.runCallCount > 0 {
+				wantTokens = 100
+			}
+			if usage := b.Usage(); usage.TokensIn != wantTokens || usage.Iterations != 0 {
+				t.Errorf("budget usage = %d tokens in %d iterations, want %d in 0", usage.TokensIn, usage.Iterations, wantTokens)
+			}
+			if (len(mockTicks.addedNotes) > 0) != tt.wantAdded {
+				t.Fatalf("added notes = %v, want digest added %v", mockTicks.addedNotes, tt.wantAdded)
+			}
+			if !tt.wantAdded {
+				return
+			}
+			digest := mockTicks.addedNotes[0]
+			if !strings.HasPrefix(digest, NotesDigestPrefix) || !strings.Contains(digest, "task-3 timed out 30 times") {
+				t.Errorf("digest note = %q, want digest of the notes", digest)
+			}
+			if got[0] != digest {
+				t.Errorf("compactNotes() = %v, want the digest note", got)
+			}
+			if !strings.Contains(mockAg.lastPrompt, "Iteration timed out on task-3") {
+				t.Error("summary prompt should include the notes")
+			}
+			// The digest replaces the notes before it from now on
+			if since := notesSinceDigest(append(notes, digest, "later")); len(since) != 2 {
+				t.Errorf("notesSinceDigest() after compaction = %d notes, want 2", len(since))
+			}
+		})
+	}
+}
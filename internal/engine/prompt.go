@@ -1,10 +1,16 @@
 package engine
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"text/template"
 
+	"github.com/pengelbrecht/ticker/internal/gitutil"
 	"github.com/pengelbrecht/ticker/internal/ticks"
 )
 
@@ -30,28 +36,265 @@ type IterationContext struct {
 	// This is the contents of .ticker/context/<epic-id>.md if it exists,
 	// or an empty string if no context has been generated.
 	EpicContext string
+
+	// RepoMap is the repository-wide codebase map shared by all epics
+	// (.ticker/context/_repo.md), or empty if not used.
+	RepoMap string
+
+	// ExtraThinking adds a section asking the agent to step back and think
+	// harder, used when the task has been escalated after failed attempts.
+	ExtraThinking bool
+
+	// FollowUp explains why a previous attempt on this task didn't finish it.
+	// When set, the agent is resuming its earlier session and gets a short
+	// follow-up prompt instead of the full iteration prompt.
+	FollowUp string
+
+	// Attempt is which attempt at this task the iteration is in this run
+	// (1 for the first). Zero if unknown.
+	Attempt int
+
+	// Repo describes the repository the agent works in.
+	Repo RepoInfo
 }
 
+// RepoInfo describes the repository an iteration runs in.
+type RepoInfo struct {
+	Dir    string // working directory (the worktree in worktree mode)
+	Branch string // current branch ("" if detached or unknown)
+	Commit string // HEAD commit ("" if unknown)
+}
+
+// PromptsDir is where user prompt templates are loaded from, relative to the
+// repository root (see LoadPromptBuilder).
+const PromptsDir = ".ticker/prompts"
+
+// templateExt is the file extension of user prompt templates.
+const templateExt = ".md.tmpl"
+
 // PromptBuilder constructs prompts for autonomous agent iterations.
 type PromptBuilder struct {
-	tmpl *template.Template
+	tmpl      *template.Template
+	followUp  *template.Template
+	vars      map[string]string
+	maxTokens int // 0 = no budget
+}
+
+// PromptStats describes the size of a built prompt in estimated tokens.
+type PromptStats struct {
+	// Tokens is the size of the whole prompt.
+	Tokens int
+
+	// Sections breaks Tokens down by the content passed to the template:
+	// task, epic, human_feedback, epic_notes, epic_context, codebase_map and
+	// follow_up. "other" is the rest (the template's own text).
+	Sections map[string]int
+
+	// NotesIncluded counts the epic notes in the prompt; NotesOmitted those
+	// left out to fit the token budget.
+	NotesIncluded int
+	NotesOmitted  int
 }
 
 // NewPromptBuilder creates a new PromptBuilder with the default template.
 func NewPromptBuilder() *PromptBuilder {
-	tmpl := template.Must(template.New("prompt").Parse(promptTemplate))
-	return &PromptBuilder{tmpl: tmpl}
+	partials := template.Must(template.New("partials").Parse(promptPartials))
+	tmpl := template.Must(template.Must(partials.Clone()).New("prompt").Parse(promptTemplate))
+	followUp := template.Must(template.Must(partials.Clone()).New("followup").Parse(followUpTemplate))
+	return &PromptBuilder{tmpl: tmpl, followUp: followUp}
+}
+
+// LoadPromptBuilder creates a PromptBuilder that uses the templates in
+// dir/.ticker/prompts where present:
+//
+//   - iteration.md.tmpl replaces the iteration prompt
+//   - followup.md.tmpl replaces the follow-up prompt for resumed sessions
+//   - partials/<name>.md.tmpl replaces one named section of the built-in
+//     prompt (e.g. rules, handoff_signals, requires_content), or adds a new
+//     one for custom templates to use
+//
+// Each template is validated on load by parsing it and rendering sample data.
+// Invalid templates are skipped in favor of the built-in ones and reported in
+// the returned error; the builder is usable even when an error is returned.
+func LoadPromptBuilder(dir string) (*PromptBuilder, error) {
+	promptsDir := filepath.Join(dir, PromptsDir)
+	var errs []error
+
+	partials := template.Must(template.New("partials").Parse(promptPartials))
+	files, _ := filepath.Glob(filepath.Join(promptsDir, "partials", "*"+templateExt))
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), templateExt)
+		data, err := os.ReadFile(file)
+		if err == nil {
+			err = checkTemplate(partials, name, string(data))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("prompt partial %s: %w", name, err))
+			continue
+		}
+		template.Must(partials.New(name).Parse(string(data)))
+	}
+
+	tmpl, err := loadTemplate(partials, promptsDir, "iteration", promptTemplate)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	followUp, err := loadTemplate(partials, promptsDir, "followup", followUpTemplate)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return &PromptBuilder{tmpl: tmpl, followUp: followUp}, errors.Join(errs...)
+}
+
+// loadTemplate parses promptsDir/<name>.md.tmpl with partials, falling back
+// to builtin if the file doesn't exist or is invalid.
+func loadTemplate(partials *template.Template, promptsDir, name, builtin string) (*template.Template, error) {
+	text := builtin
+	var loadErr error
+
+	data, err := os.ReadFile(filepath.Join(promptsDir, name+templateExt))
+	switch {
+	case err == nil:
+		if err := checkTemplate(partials, name, string(data)); err != nil {
+			loadErr = fmt.Errorf("prompt template %s: %w", name+templateExt, err)
+		} else {
+			text = string(data)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		loadErr = fmt.Errorf("reading prompt template %s: %w", name+templateExt, err)
+	}
+
+	return template.Must(template.Must(partials.Clone()).New(name).Parse(text)), loadErr
+}
+
+// checkTemplate parses text as template name alongside partials and renders
+// it with sample data, returning the first parse or execution error.
+func checkTemplate(partials *template.Template, name, text string) error {
+	t, err := partials.Clone()
+	if err != nil {
+		return err
+	}
+	if _, err := t.New(name).Parse(text); err != nil {
+		return err
+	}
+	return t.ExecuteTemplate(io.Discard, name, sampleTemplateData)
+}
+
+// sampleTemplateData exercises every field when validating user templates.
+var sampleTemplateData = templateData{
+	Iteration:          2,
+	EpicID:             "abc",
+	EpicTitle:          "Sample epic",
+	EpicDescription:    "Sample epic description.",
+	TaskID:             "def",
+	TaskTitle:          "Sample task",
+	TaskDescription:    "Sample task description.",
+	AcceptanceCriteria: "Acceptance Criteria:\n- it works",
+	Requires:           "content",
+	EpicNotes:          []string{"Sample note"},
+	HumanFeedback:      []ticks.Note{{Content: "Sample feedback", Author: "human"}},
+	EpicContext:        "Sample epic context",
+	RepoMap:            "Sample codebase map",
+	ExtraThinking:      true,
+	FollowUp:           "Sample follow-up",
+	Attempt:            2,
+	Repo:               RepoInfo{Dir: "/repo", Branch: "main", Commit: "0123456789abcdef"},
+	Vars:               map[string]string{},
+}
+
+// SetVars sets user-defined template variables, available to templates as
+// {{.Vars.name}} (from prompts.vars in .ticker/config.json).
+func (pb *PromptBuilder) SetVars(vars map[string]string) {
+	pb.vars = vars
+}
+
+// SetTokenBudget limits iteration prompts to about maxTokens estimated tokens
+// (0 = no limit). See BuildWithStats.
+func (pb *PromptBuilder) SetTokenBudget(maxTokens int) {
+	pb.maxTokens = maxTokens
 }
 
 // Build generates a prompt string from the given iteration context.
+// If ctx.FollowUp is set, a short follow-up prompt for a resumed session is
+// generated instead.
 func (pb *PromptBuilder) Build(ctx IterationContext) string {
-	var buf strings.Builder
+	prompt, _ := pb.BuildWithStats(ctx)
+	return prompt
+}
+
+// BuildWithStats generates the prompt like Build and reports its size.
+// If the prompt is over the token budget, epic notes are left out oldest
+// first and replaced by a line saying how many were omitted. The task, human
+// feedback and everything else are always kept, so a prompt can still exceed
+// the budget when those alone are too large.
+func (pb *PromptBuilder) BuildWithStats(ctx IterationContext) (string, PromptStats) {
+	data := pb.templateData(ctx)
+	tmpl := pb.tmpl
+	if ctx.FollowUp != "" && pb.followUp != nil {
+		tmpl = pb.followUp
+	}
+
+	prompt := render(tmpl, data)
+	notes := data.EpicNotes
+	omitted := 0
+	if pb.maxTokens > 0 && ctx.FollowUp == "" && len(notes) > 0 && estimateTokens(prompt) > pb.maxTokens {
+		// Measure with only the omitted-notes line, so the notes section's
+		// own text is counted
+		data.EpicNotes = []string{omittedNotesLine(len(notes))}
+		kept := fitNotes(notes, pb.maxTokens-estimateTokens(render(tmpl, data)))
+		omitted = len(notes) - len(kept)
+		data.EpicNotes = kept
+		if omitted > 0 {
+			data.EpicNotes = append([]string{omittedNotesLine(omitted)}, kept...)
+		}
+		prompt = render(tmpl, data)
+	}
+
+	stats := PromptStats{
+		Tokens:        estimateTokens(prompt),
+		NotesIncluded: len(notes) - omitted,
+		NotesOmitted:  omitted,
+	}
+	if ctx.FollowUp != "" {
+		stats.NotesIncluded = 0
+		stats.Sections = map[string]int{"follow_up": estimateTokens(data.FollowUp)}
+	} else {
+		feedback := 0
+		for _, n := range data.HumanFeedback {
+			feedback += estimateTokens(n.Content)
+		}
+		stats.Sections = map[string]int{
+			"task":           estimateTokens(data.TaskTitle + data.TaskDescription + data.AcceptanceCriteria),
+			"epic":           estimateTokens(data.EpicTitle + data.EpicDescription),
+			"human_feedback": feedback,
+			"epic_notes":     estimateTokens(strings.Join(data.EpicNotes, "\n")),
+			"epic_context":   estimateTokens(data.EpicContext),
+			"codebase_map":   estimateTokens(data.RepoMap),
+		}
+	}
+	other := stats.Tokens
+	for _, n := range stats.Sections {
+		other -= n
+	}
+	stats.Sections["other"] = max(other, 0)
 
+	return prompt, stats
+}
+
+// templateData converts an iteration context to template data.
+func (pb *PromptBuilder) templateData(ctx IterationContext) templateData {
 	data := templateData{
 		Iteration:     ctx.Iteration,
 		EpicNotes:     ctx.EpicNotes,
 		HumanFeedback: ctx.HumanFeedback,
 		EpicContext:   ctx.EpicContext,
+		RepoMap:       ctx.RepoMap,
+		ExtraThinking: ctx.ExtraThinking,
+		FollowUp:      ctx.FollowUp,
+		Attempt:       ctx.Attempt,
+		Repo:          ctx.Repo,
+		Vars:          pb.vars,
 	}
 
 	if ctx.Epic != nil {
@@ -70,14 +313,44 @@ func (pb *PromptBuilder) Build(ctx IterationContext) string {
 		}
 	}
 
-	if err := pb.tmpl.Execute(&buf, data); err != nil {
+	return data
+}
+
+// render executes tmpl with data.
+func render(tmpl *template.Template, data templateData) string {
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, data); err != nil {
 		// This should never happen with a valid template
 		return fmt.Sprintf("Error generating prompt: %v", err)
 	}
-
 	return buf.String()
 }
 
+// estimateTokens approximates the token count of s at ~4 characters per
+// token, rounding up.
+func estimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// fitNotes returns the newest notes that fit in about avail tokens.
+func fitNotes(notes []string, avail int) []string {
+	start := len(notes)
+	for start > 0 {
+		cost := estimateTokens("- " + notes[start-1] + "\n")
+		if cost > avail {
+			break
+		}
+		avail -= cost
+		start--
+	}
+	return notes[start:]
+}
+
+// omittedNotesLine stands in for n epic notes left out of the prompt.
+func omittedNotesLine(n int) string {
+	return fmt.Sprintf("(%d older notes omitted to fit the prompt budget)", n)
+}
+
 // templateData holds the data passed to the prompt template.
 type templateData struct {
 	Iteration          int
@@ -92,6 +365,30 @@ type templateData struct {
 	EpicNotes          []string
 	HumanFeedback      []ticks.Note
 	EpicContext        string
+	RepoMap            string
+	ExtraThinking      bool
+	FollowUp           string
+	Attempt            int
+	Repo               RepoInfo
+	Vars               map[string]string
+}
+
+// repoInfo describes the git repository at dir (the current directory if
+// empty). Fields that can't be determined are left empty.
+func repoInfo(dir string) RepoInfo {
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	info := RepoInfo{Dir: dir, Commit: gitutil.HeadCommit(dir)}
+
+	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil {
+		if branch := strings.TrimSpace(string(out)); branch != "HEAD" {
+			info.Branch = branch
+		}
+	}
+	return info
 }
 
 // extractAcceptanceCriteria parses acceptance criteria from a task description.
@@ -117,23 +414,12 @@ func extractAcceptanceCriteria(description string) string {
 	return ""
 }
 
-// promptTemplate is the Go template for generating iteration prompts.
+// promptTemplate is the Go template for generating iteration prompts. The
+// sections are partials (see promptPartials) so they can be overridden one
+// at a time from .ticker/prompts/partials.
 const promptTemplate = `# Iteration {{.Iteration}}
-{{if .EpicContext}}
-## Epic Context
-
-The following context was generated for this epic. Use it to understand the codebase.
-
-{{.EpicContext}}
-{{end}}
-{{if .EpicNotes}}
-## IMPORTANT: Review Epic Notes First
-
-These notes were left by previous iterations. Read them carefully before starting work.
-
-{{range .EpicNotes}}- {{.}}
-{{end}}
-{{end}}
+{{template "codebase_map" .}}{{template "epic_context" .}}
+{{template "epic_notes" .}}
 ## Epic: {{.EpicTitle}}
 {{if .EpicDescription}}
 {{.EpicDescription}}
@@ -148,7 +434,48 @@ These notes were left by previous iterations. Read them carefully before startin
 ### Acceptance Criteria
 {{.AcceptanceCriteria}}
 {{end}}
-{{if .HumanFeedback}}
+{{template "human_feedback" .}}
+{{template "previous_attempts" .}}
+
+{{template "instructions" .}}
+{{if eq .Requires "content"}}{{template "requires_content" .}}{{else if eq .Requires "review"}}{{template "requires_review" .}}{{else if eq .Requires "approval"}}{{template "requires_approval" .}}{{end}}
+
+{{template "handoff_signals" .}}
+
+## Reading Human Feedback
+
+If this task was previously handed off, check the "Human Feedback" section above for the human's response. Address their feedback before proceeding.
+
+{{template "rules" .}}
+
+Begin working on the task now.
+`
+
+// promptPartials defines the named sections of the iteration prompt. Each
+// can be replaced by .ticker/prompts/partials/<name>.md.tmpl.
+const promptPartials = `{{define "codebase_map"}}{{if .RepoMap}}
+## Codebase Map
+
+An overview of this repository, shared by all epics.
+
+{{.RepoMap}}
+{{end}}{{end}}
+{{define "epic_context"}}{{if .EpicContext}}
+## Epic Context
+
+The following context was generated for this epic. Use it to understand the codebase.
+
+{{.EpicContext}}
+{{end}}{{end}}
+{{define "epic_notes"}}{{if .EpicNotes}}
+## IMPORTANT: Review Epic Notes First
+
+These notes were left by previous iterations. Read them carefully before starting work.
+
+{{range .EpicNotes}}- {{.}}
+{{end}}
+{{end}}{{end}}
+{{define "human_feedback"}}{{if .HumanFeedback}}
 
 ## Human Feedback
 
@@ -157,9 +484,18 @@ This task was previously handed to a human. Their response:
 {{range .HumanFeedback}}- {{.Content}}
 {{end}}
 Address this feedback before proceeding.
-{{end}}
+{{end}}{{end}}
+{{define "previous_attempts"}}{{if .ExtraThinking}}
+
+## ⚠️ Previous Attempts Failed
+
+Earlier attempts at this task did not succeed - check the epic notes for timeouts, errors or verification failures. Ultrathink before changing any code:
 
-## Instructions
+1. Work out why the previous attempts failed. Do not repeat an approach that already failed.
+2. Re-read the task description and acceptance criteria, and check your assumptions against the code.
+3. Plan the change end to end before editing, then verify it with tests before closing the task.
+{{end}}{{end}}
+{{define "instructions"}}## Instructions
 
 1. **Review epic notes above** - Previous iterations may have left important context.
 2. **Complete the current task** - Implement the required functionality as specified.
@@ -167,8 +503,8 @@ Address this feedback before proceeding.
 4. **Close the task** - Run ` + "`tk close {{.TaskID}} --reason \"<solution summary>\"`" + ` when complete. The reason should summarize HOW you solved the task (approach taken, key changes made, files modified).
 5. **Simplify your code (optional)** - If you have access to the code-simplifier skill, consider running it on your modified files before committing to ensure clean, maintainable code.
 6. **Commit your changes** - Create a commit with the task ID in the message.
-7. **Add epic note** - Run ` + "`tk note {{.EpicID}} \"<message>\"`" + ` to leave context for future iterations. Include learnings, gotchas, architectural decisions, or anything the next iteration should know.
-{{if eq .Requires "content"}}
+7. **Add epic note** - Run ` + "`tk note {{.EpicID}} \"<message>\"`" + ` to leave context for future iterations. Include learnings, gotchas, architectural decisions, or anything the next iteration should know.{{end}}
+{{define "requires_content"}}
 
 ## ⚠️ Content Review Required
 
@@ -190,7 +526,8 @@ tk note {{.TaskID}} "Review: <specific files and locations to check, what to loo
 - "Done with the task" (not helpful - WHAT to review?)
 
 The human reviewer needs to know exactly what to look at and what to evaluate.
-{{else if eq .Requires "review"}}
+{{end}}
+{{define "requires_review"}}
 
 ## ⚠️ Code Review Required
 
@@ -203,7 +540,8 @@ This task requires **code review** before closing. When you signal COMPLETE, a h
 ` + "```bash" + `
 tk note {{.TaskID}} "PR: <url> - Key changes: <what to focus on during review>"
 ` + "```" + `
-{{else if eq .Requires "approval"}}
+{{end}}
+{{define "requires_approval"}}
 
 ## ⚠️ Approval Required
 
@@ -215,8 +553,7 @@ This task requires **human approval** before closing. When you signal COMPLETE,
 tk note {{.TaskID}} "Summary: <what changed, any risks or considerations for approval>"
 ` + "```" + `
 {{end}}
-
-## Handoff Signals
+{{define "handoff_signals"}}## Handoff Signals
 
 When you need human involvement, emit a signal and the system will hand off the task:
 
@@ -242,20 +579,34 @@ The human reviewer only sees the signal reason - they do NOT have access to your
 - ` + "`<promise>CONTENT_REVIEW: Need approval on error messages</promise>`" + ` (Which messages? Human can't see your output!)
 - ` + "`<promise>INPUT_NEEDED: Which option should I use?</promise>`" + ` (What options? Include them!)
 
-After emitting a handoff signal, the system moves to another task. When a human responds, you may be assigned this task again with their feedback in the notes.
-
-## Reading Human Feedback
-
-If this task was previously handed off, check the "Human Feedback" section above for the human's response. Address their feedback before proceeding.
-
-## Rules
+After emitting a handoff signal, the system moves to another task. When a human responds, you may be assigned this task again with their feedback in the notes.{{end}}
+{{define "rules"}}## Rules
 
 1. **One task per iteration** - Focus only on the current task. Do not work on other tasks.
 2. **No questions** - You are autonomous. Make reasonable decisions based on the context provided.
 3. **Always leave notes** - Before finishing, add a note summarizing what you did and any context for the next iteration.
 4. **Don't modify ticker internals** - Never modify .tick/, .ticker/, or .gitignore unless explicitly required by the task. These are managed by ticker.
 5. **Task completion** - Just close your task with ` + "`tk close`" + ` when done. Ticker automatically detects when all tasks in the epic are complete.
-6. **Never revert other tasks' work** - Code already in the repo was committed by previous tasks and is intentional. You may revert your own changes from this iteration, but NEVER revert commits from other tasks. If you think existing code is wrong, leave a note or escalate - do not "clean up" or revert code you didn't write.
+6. **Never revert other tasks' work** - Code already in the repo was committed by previous tasks and is intentional. You may revert your own changes from this iteration, but NEVER revert commits from other tasks. If you think existing code is wrong, leave a note or escalate - do not "clean up" or revert code you didn't write.{{end}}`
 
-Begin working on the task now.
+// followUpTemplate is the Go template for follow-up prompts sent to a resumed
+// session. The agent already has the task, epic and instructions in context,
+// so this only explains what went wrong.
+const followUpTemplate = `# Iteration {{.Iteration}} (continuing your previous session)
+
+Your previous attempt at task **[{{.TaskID}}] {{.TaskTitle}}** did not finish it.
+
+{{.FollowUp}}
+{{if .HumanFeedback}}
+
+## Human Feedback
+
+{{range .HumanFeedback}}- {{.Content}}
+{{end}}
+Address this feedback before proceeding.
+{{end}}
+{{if .ExtraThinking}}
+Ultrathink before changing more code: work out why the earlier attempts failed and do not repeat an approach that already failed.
+{{end}}
+Continue from where you left off. The same instructions and rules apply: run the tests, close the task with ` + "`tk close {{.TaskID}} --reason \"<solution summary>\"`" + `, commit your changes, and leave a note with ` + "`tk note {{.EpicID}} \"<message>\"`" + `. Emit a handoff signal if you need a human.
 `
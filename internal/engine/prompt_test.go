@@ -1,9 +1,16 @@
 package engine
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/checkpoint"
 	"github.com/pengelbrecht/ticker/internal/ticks"
 )
 
@@ -639,3 +646,361 @@ func TestPromptBuilder_Build_EpicContextBeforeEpicNotes(t *testing.T) {
 		t.Error("epic context section should appear before epic notes section")
 	}
 }
+
+func TestPromptBuilder_Build_RepoMap(t *testing.T) {
+	pb := NewPromptBuilder()
+
+	ctx := IterationContext{
+		Iteration:   1,
+		Epic:        &ticks.Epic{ID: "epic1", Title: "Test Epic"},
+		Task:        &ticks.Task{ID: "task1", Title: "Test task"},
+		EpicContext: "Epic-specific context",
+		RepoMap:     "# Codebase Map\n\n- cmd/: entry points",
+	}
+
+	prompt := pb.Build(ctx)
+
+	mapIdx := strings.Index(prompt, "## Codebase Map")
+	contextIdx := strings.Index(prompt, "## Epic Context")
+	if mapIdx == -1 || !strings.Contains(prompt, "- cmd/: entry points") {
+		t.Fatal("prompt missing codebase map section")
+	}
+	if contextIdx == -1 || mapIdx >= contextIdx {
+		t.Error("codebase map should appear before epic context")
+	}
+
+	ctx.RepoMap = ""
+	if strings.Contains(pb.Build(ctx), "## Codebase Map") {
+		t.Error("prompt should not have codebase map section when RepoMap is empty")
+	}
+}
+
+func TestPromptBuilder_Build_ExtraThinking(t *testing.T) {
+	pb := NewPromptBuilder()
+
+	ctx := IterationContext{
+		Iteration: 3,
+		Epic: &ticks.Epic{
+			ID:    "epic1",
+			Title: "Test Epic",
+		},
+		Task: &ticks.Task{
+			ID:          "task1",
+			Title:       "Test task",
+			Description: "Do something.",
+		},
+	}
+
+	if prompt := pb.Build(ctx); strings.Contains(prompt, "Previous Attempts Failed") {
+		t.Error("prompt should not contain extra-thinking section by default")
+	}
+
+	ctx.ExtraThinking = true
+	prompt := pb.Build(ctx)
+	if !strings.Contains(prompt, "## ⚠️ Previous Attempts Failed") {
+		t.Error("prompt missing extra-thinking section header")
+	}
+	if !strings.Contains(prompt, "Ultrathink") {
+		t.Error("prompt missing extra-thinking instruction")
+	}
+	// Section comes before the standard instructions
+	if strings.Index(prompt, "Previous Attempts Failed") > strings.Index(prompt, "## Instructions") {
+		t.Error("extra-thinking section should appear before instructions")
+	}
+}
+
+func TestPromptBuilder_Build_FollowUp(t *testing.T) {
+	pb := NewPromptBuilder()
+
+	ctx := IterationContext{
+		Iteration: 4,
+		Epic: &ticks.Epic{
+			ID:          "epic1",
+			Title:       "Test Epic",
+			Description: "A long epic description.",
+		},
+		Task: &ticks.Task{
+			ID:          "task1",
+			Title:       "Test task",
+			Description: "Do something.",
+		},
+		EpicNotes: []string{"an old note"},
+		FollowUp:  "Verification failed:\n\nFAIL: TestFoo",
+	}
+
+	prompt := pb.Build(ctx)
+
+	if !strings.Contains(prompt, "continuing your previous session") {
+		t.Error("follow-up prompt missing header")
+	}
+	if !strings.Contains(prompt, "FAIL: TestFoo") {
+		t.Error("follow-up prompt missing failure details")
+	}
+	if !strings.Contains(prompt, `tk close task1 --reason "<solution summary>"`) {
+		t.Error("follow-up prompt missing close command")
+	}
+	// The full prompt sections are not repeated
+	for _, section := range []string{"A long epic description.", "an old note", "## Handoff Signals"} {
+		if strings.Contains(prompt, section) {
+			t.Errorf("follow-up prompt should not contain %q", section)
+		}
+	}
+	if len(prompt) >= len(pb.Build(IterationContext{Iteration: 4, Epic: ctx.Epic, Task: ctx.Task})) {
+		t.Error("follow-up prompt should be shorter than the full prompt")
+	}
+}
+
+// writePromptFile writes a user prompt template under dir/.ticker/prompts.
+func writePromptFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, PromptsDir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestPromptBuilder_BuildWithStats_Budget(t *testing.T) {
+	var notes []string
+	for i := range 40 {
+		notes = append(notes, fmt.Sprintf("note-%02d %s", i, strings.Repeat("x", 200)))
+	}
+	ctx := IterationContext{
+		Iteration:     3,
+		Epic:          &ticks.Epic{ID: "epic1", Title: "Test Epic"},
+		Task:          &ticks.Task{ID: "task1", Title: "Task", Description: "Do something."},
+		HumanFeedback: []ticks.Note{{Content: "Use the v2 API", Author: "human"}},
+		EpicNotes:     notes,
+	}
+
+	pb := NewPromptBuilder()
+	_, unlimited := pb.BuildWithStats(ctx)
+	noNotes := ctx
+	noNotes.EpicNotes = nil
+	_, base := pb.BuildWithStats(noNotes)
+
+	tests := []struct {
+		name        string
+		budget      int
+		wantOmitted bool
+	}{
+		{"no budget", 0, false},
+		{"under budget", unlimited.Tokens + 100, false},
+		{"over budget", base.Tokens + 400, true},
+		{"task alone over budget", base.Tokens - 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pb := NewPromptBuilder()
+			pb.SetTokenBudget(tt.budget)
+			prompt, stats := pb.BuildWithStats(ctx)
+
+			if (stats.NotesOmitted > 0) != tt.wantOmitted {
+				t.Fatalf("NotesOmitted = %d, want omitted %v", stats.NotesOmitted, tt.wantOmitted)
+			}
+			if stats.NotesIncluded+stats.NotesOmitted != len(notes) {
+				t.Errorf("NotesIncluded + NotesOmitted = %d, want %d", stats.NotesIncluded+stats.NotesOmitted, len(notes))
+			}
+			if !strings.Contains(prompt, "Use the v2 API") || !strings.Contains(prompt, "Do something.") {
+				t.Error("prompt should always include the task and human feedback")
+			}
+			if !tt.wantOmitted {
+				if !strings.Contains(prompt, "note-00") {
+					t.Error("prompt should include all notes")
+				}
+				return
+			}
+
+			if strings.Contains(prompt, "note-00") {
+				t.Error("prompt should leave out the oldest note")
+			}
+			if !strings.Contains(prompt, fmt.Sprintf("(%d older notes omitted", stats.NotesOmitted)) {
+				t.Error("prompt should say how many notes were omitted")
+			}
+			if stats.NotesIncluded > 0 {
+				if !strings.Contains(prompt, "note-39") {
+					t.Error("prompt should keep the newest notes")
+				}
+				if stats.Tokens > tt.budget {
+					t.Errorf("Tokens = %d, want at most budget %d", stats.Tokens, tt.budget)
+				}
+			}
+		})
+	}
+}
+
+func TestPromptBuilder_BuildWithStats_Sections(t *testing.T) {
+	pb := NewPromptBuilder()
+	prompt, stats := pb.BuildWithStats(IterationContext{
+		Iteration:   1,
+		Epic:        &ticks.Epic{ID: "epic1", Title: "Test Epic"},
+		Task:        &ticks.Task{ID: "task1", Title: "Task", Description: strings.Repeat("d", 400)},
+		EpicNotes:   []string{strings.Repeat("n", 800)},
+		EpicContext: strings.Repeat("c", 1200),
+	})
+
+	if stats.Tokens != estimateTokens(prompt) {
+		t.Errorf("Tokens = %d, want %d", stats.Tokens, estimateTokens(prompt))
+	}
+	if stats.Sections["epic_notes"] != 200 {
+		t.Errorf("Sections[epic_notes] = %d, want 200", stats.Sections["epic_notes"])
+	}
+	if stats.Sections["epic_context"] != 300 {
+		t.Errorf("Sections[epic_context] = %d, want 300", stats.Sections["epic_context"])
+	}
+	if stats.Sections["task"] < 100 {
+		t.Errorf("Sections[task] = %d, want at least 100", stats.Sections["task"])
+	}
+	sum := 0
+	for _, n := range stats.Sections {
+		sum += n
+	}
+	if sum != stats.Tokens {
+		t.Errorf("sections sum to %d, want %d", sum, stats.Tokens)
+	}
+	if stats.NotesIncluded != 1 {
+		t.Errorf("NotesIncluded = %d, want 1", stats.NotesIncluded)
+	}
+}
+
+func TestLoadPromptBuilder(t *testing.T) {
+	iterCtx := IterationContext{
+		Iteration: 1,
+		Epic:      &ticks.Epic{ID: "epic1", Title: "Test Epic"},
+		Task:      &ticks.Task{ID: "task1", Title: "Test task"},
+		Attempt:   2,
+		Repo:      RepoInfo{Dir: "/src/app", Branch: "main", Commit: "abc123"},
+	}
+	builtin := NewPromptBuilder().Build(iterCtx)
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		wantErr  string
+		contains []string
+		excludes []string
+		same     bool // prompt identical to the built-in one
+	}{
+		{
+			name: "no templates uses built-in",
+			same: true,
+		},
+		{
+			name:     "partial override replaces one section",
+			files:    map[string]string{"partials/rules.md.tmpl": "## Rules\n\nOur rules for {{.TaskID}}."},
+			contains: []string{"## Rules\n\nOur rules for task1.", "## Handoff Signals", "## Instructions"},
+			excludes: []string{"One task per iteration"},
+		},
+		{
+			name: "iteration template with partials and new data",
+			files: map[string]string{
+				"iteration.md.tmpl":      "{{.TaskID}} attempt {{.Attempt}} on {{.Repo.Branch}}@{{.Repo.Commit}} for {{.Vars.team}}\n{{template \"extra\" .}}\n{{template \"handoff_signals\" .}}",
+				"partials/extra.md.tmpl": "Extra section",
+			},
+			contains: []string{"task1 attempt 2 on main@abc123 for platform", "Extra section", "## Handoff Signals"},
+			excludes: []string{"## Rules"},
+		},
+		{
+			name:    "parse error falls back to built-in",
+			files:   map[string]string{"iteration.md.tmpl": "{{if .TaskID}}unterminated"},
+			wantErr: "iteration.md.tmpl",
+			same:    true,
+		},
+		{
+			name:    "unknown field falls back to built-in",
+			files:   map[string]string{"iteration.md.tmpl": "{{.NoSuchField}}"},
+			wantErr: "iteration.md.tmpl",
+			same:    true,
+		},
+		{
+			name:    "missing partial falls back to built-in",
+			files:   map[string]string{"iteration.md.tmpl": "{{template \"nope\" .}}"},
+			wantErr: "iteration.md.tmpl",
+			same:    true,
+		},
+		{
+			name:    "invalid partial keeps built-in section",
+			files:   map[string]string{"partials/rules.md.tmpl": "{{.Bogus}}"},
+			wantErr: "prompt partial rules",
+			same:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writePromptFile(t, dir, name, content)
+			}
+
+			pb, err := LoadPromptBuilder(dir)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("LoadPromptBuilder() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("LoadPromptBuilder() error = %v, want containing %q", err, tt.wantErr)
+			}
+			pb.SetVars(map[string]string{"team": "platform"})
+
+			prompt := pb.Build(iterCtx)
+			if tt.same && prompt != builtin {
+				t.Errorf("Build() differs from the built-in prompt:\n%s", prompt)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(prompt, want) {
+					t.Errorf("Build() missing %q:\n%s", want, prompt)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(prompt, unwanted) {
+					t.Errorf("Build() should not contain %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadPromptBuilder_FollowUp(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "followup.md.tmpl", "Retry {{.TaskID}}: {{.FollowUp}}")
+
+	pb, err := LoadPromptBuilder(dir)
+	if err != nil {
+		t.Fatalf("LoadPromptBuilder() error = %v", err)
+	}
+	ctx := IterationContext{Task: &ticks.Task{ID: "task1"}, FollowUp: "tests failed"}
+	if got := pb.Build(ctx); got != "Retry task1: tests failed" {
+		t.Errorf("Build() = %q, want custom follow-up", got)
+	}
+}
+
+func TestEngine_Run_PromptAttempt(t *testing.T) {
+	dir := t.TempDir()
+	writePromptFile(t, dir, "iteration.md.tmpl", "attempt {{.Attempt}} of {{.TaskID}}")
+	pb, err := LoadPromptBuilder(dir)
+	if err != nil {
+		t.Fatalf("LoadPromptBuilder() error = %v", err)
+	}
+
+	// The agent never closes the task, so it is selected every time
+	task := &ticks.Task{ID: "task1", Title: "Stubborn task"}
+	mockTicks := newMockTicksClient()
+	mockTicks.epic = &ticks.Epic{ID: "epic1", Title: "Epic", Type: "epic"}
+	mockTicks.tasks = []*ticks.Task{task, task, task}
+
+	mockAg := &mockAgentOpts{}
+	e := NewEngine(mockAg, mockTicks, budget.NewTracker(budget.Limits{MaxIterations: 3}), checkpoint.NewManagerWithDir(t.TempDir()))
+	e.SetPromptBuilder(pb)
+
+	if _, err := e.Run(context.Background(), RunConfig{EpicID: "epic1", MaxTaskRetries: 5, AgentTimeout: time.Minute, CheckpointEvery: 100}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	want := []string{"attempt 1 of task1", "attempt 2 of task1", "attempt 3 of task1"}
+	if strings.Join(mockAg.prompts, ",") != strings.Join(want, ",") {
+		t.Errorf("prompts = %q, want %q", mockAg.prompts, want)
+	}
+}
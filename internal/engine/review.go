@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"os"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+	"github.com/pengelbrecht/ticker/internal/verify"
+)
+
+// rememberBaseRef records the commit a task starts from, so the reviewer and
+// context updates can diff everything done for it across retries. Only the
+// first attempt counts.
+func (e *Engine) rememberBaseRef(state *runState, taskID string) {
+	if !e.verifyEnabled || !(e.verifyConfig.GetReview().IsEnabled() || e.contextUpdates) {
+		return
+	}
+	if _, ok := state.baseRefs[taskID]; ok {
+		return
+	}
+	dir := state.workDir
+	if dir == "" {
+		dir, _ = os.Getwd()
+	}
+	if state.baseRefs == nil {
+		state.baseRefs = make(map[string]string)
+	}
+	state.baseRefs[taskID] = gitutil.HeadCommit(dir)
+}
+
+// criteriaVerifier returns the acceptance-criteria reviewer for task, or nil
+// if review is disabled, the task has no acceptance criteria, or no
+// reviewing agent is available. A task agent that can't run read-only (a
+// command agent) hands the review to a built-in agent.
+func (e *Engine) criteriaVerifier(state *runState, task *ticks.Task, dir string) *verify.CriteriaVerifier {
+	cfg := e.verifyConfig.GetReview()
+	if !cfg.IsEnabled() {
+		return nil
+	}
+	criteria := extractAcceptanceCriteria(task.Description)
+	if criteria == "" {
+		return nil
+	}
+
+	reviewer, err := e.resolveAgent(state.epic, task)
+	if name := cfg.GetAgent(); name != "" && e.agents != nil {
+		reviewer, err = e.agents.Get(name)
+	}
+	if err != nil || reviewer == nil {
+		return nil
+	}
+	if !agent.CanRunReadOnly(reviewer) {
+		fallback := e.readOnlyFallback()
+		if e.runLog != nil {
+			to := ""
+			if fallback != nil {
+				to = fallback.Name()
+			}
+			e.runLog.LogReviewAgentFallback(task.ID, reviewer.Name(), to)
+		}
+		if fallback == nil {
+			return nil
+		}
+		reviewer = fallback
+	}
+
+	return verify.NewCriteriaVerifier(dir, reviewer, cfg, verify.ReviewTask{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		Criteria:    criteria,
+		BaseRef:     state.baseRefs[task.ID],
+	})
+}
+
+// readOnlyFallback returns the first available built-in agent that can run
+// read-only, or nil if there is none.
+func (e *Engine) readOnlyFallback() agent.Agent {
+	if e.agents == nil {
+		return nil
+	}
+	for _, name := range agent.BuiltinAgents {
+		a, err := e.agents.Get(name)
+		if err == nil && agent.CanRunReadOnly(a) && a.Available() {
+			return a
+		}
+	}
+	return nil
+}
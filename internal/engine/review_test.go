@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+	"github.com/pengelbrecht/ticker/internal/verify"
+)
+
+func reviewEnabledConfig(commands ...*verify.CommandConfig) *verify.Config {
+	enabled := true
+	return &verify.Config{Commands: commands, Review: &verify.ReviewConfig{Enabled: &enabled}}
+}
+
+func TestEngine_criteriaVerifier(t *testing.T) {
+	withCriteria := &ticks.Task{ID: "t1", Description: "Add a flag.\n\nAcceptance Criteria:\n- flag exists"}
+	noCriteria := &ticks.Task{ID: "t2", Description: "Add a flag."}
+
+	tests := []struct {
+		name   string
+		config *verify.Config
+		task   *ticks.Task
+		want   bool
+	}{
+		{"review disabled", &verify.Config{}, withCriteria, false},
+		{"no acceptance criteria", reviewEnabledConfig(), noCriteria, false},
+		{"review enabled", reviewEnabledConfig(), withCriteria, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &Engine{agent: &mockAgent{name: "test", available: true}}
+			e.EnableVerification()
+			e.SetVerificationConfig(tt.config)
+
+			got := e.criteriaVerifier(&runState{}, tt.task, t.TempDir())
+			if (got != nil) != tt.want {
+				t.Errorf("criteriaVerifier() = %v, want verifier: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEngine_criteriaVerifier_CommandAgentFallback(t *testing.T) {
+	task := &ticks.Task{ID: "t1", Description: "Add a flag.\n\nAcceptance Criteria:\n- flag exists"}
+	verdict := `<verdict>{"criteria": [{"criterion": "flag exists", "passed": true}]}</verdict>`
+
+	tests := []struct {
+		name       string
+		claude     *mockAgent
+		wantReview bool
+	}{
+		{"built-in agent reviews", &mockAgent{name: "claude", available: true, responses: []mockResponse{{output: verdict}}}, true},
+		{"no built-in agent available", &mockAgent{name: "claude"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents := agent.NewRegistry(&agent.Config{Commands: map[string]*agent.CommandConfig{"aider": {Command: "aider"}}})
+			agents.Register("claude", func() agent.Agent { return tt.claude })
+			agents.Register("codex", func() agent.Agent { return &mockAgent{name: "codex"} })
+			agents.SetDefault("aider")
+
+			e := NewEngine(nil, nil, nil, nil)
+			e.SetAgentRegistry(agents)
+			e.EnableVerification()
+			e.SetVerificationConfig(reviewEnabledConfig())
+
+			dir := createTempGitRepo(t)
+			got := e.criteriaVerifier(&runState{}, task, dir)
+			if (got != nil) != tt.wantReview {
+				t.Fatalf("criteriaVerifier() = %v, want verifier: %v", got, tt.wantReview)
+			}
+			if got == nil {
+				return
+			}
+			if result := got.Verify(context.Background(), task.ID, ""); !result.Passed {
+				t.Errorf("Verify() = %+v, want the built-in agent's passing review", result)
+			}
+			if tt.claude.callCount != 1 {
+				t.Errorf("claude callCount = %d, want 1", tt.claude.callCount)
+			}
+		})
+	}
+}
+
+func TestEngine_rememberBaseRef(t *testing.T) {
+	e := &Engine{}
+	e.EnableVerification()
+	state := &runState{workDir: t.TempDir()}
+
+	// Review disabled - nothing recorded
+	e.rememberBaseRef(state, "t1")
+	if _, ok := state.baseRefs["t1"]; ok {
+		t.Error("rememberBaseRef() recorded a ref with review disabled")
+	}
+
+	e.SetVerificationConfig(reviewEnabledConfig())
+	state.baseRefs = map[string]string{"t1": "abc123"}
+	e.rememberBaseRef(state, "t1")
+	if state.baseRefs["t1"] != "abc123" {
+		t.Errorf("baseRefs[t1] = %q, want the first attempt's ref kept", state.baseRefs["t1"])
+	}
+
+	e.rememberBaseRef(state, "t2")
+	if _, ok := state.baseRefs["t2"]; !ok {
+		t.Error("rememberBaseRef() should record a ref for a new task")
+	}
+}
+
+func TestEngine_verifiers_ReviewPrerequisites(t *testing.T) {
+	optional := false
+	e := &Engine{agent: &mockAgent{name: "test", available: true}}
+	e.EnableVerification()
+	e.SetVerificationConfig(reviewEnabledConfig(
+		&verify.CommandConfig{Name: "test", Command: "go test ./..."},
+		&verify.CommandConfig{Name: "lint", Command: "golangci-lint run", Required: &optional},
+		&verify.CommandConfig{Name: "build", Command: "go build ./..."},
+	))
+	task := &ticks.Task{
+		ID:          "t1",
+		Description: "Add a flag.\n\nAcceptance Criteria:\n- flag exists\n\n```verify\n{\"skip\": [\"build\"]}\n```",
+	}
+
+	verifiers, err := e.verifiers(&runState{}, task, t.TempDir())
+	if err != nil {
+		t.Fatalf("verifiers() error = %v", err)
+	}
+	reviewer, ok := verifiers[len(verifiers)-1].(*verify.CriteriaVerifier)
+	if !ok {
+		t.Fatalf("last verifier = %T, want *verify.CriteriaVerifier", verifiers[len(verifiers)-1])
+	}
+
+	// Optional lint and skipped build don't gate the review
+	got := strings.Join(reviewer.DependsOn(), ",")
+	if got != "test" {
+		t.Errorf("reviewer DependsOn() = %q, want %q", got, "test")
+	}
+}
+
+func TestEngine_runVerification_ReviewUsage(t *testing.T) {
+	reviewer := &mockAgent{name: "test", available: true, responses: []mockResponse{{
+		output:    `<verdict>{"criteria": [{"criterion": "flag exists", "passed": true}]}</verdict>`,
+		tokensIn:  2000,
+		tokensOut: 400,
+		cost:      0.05,
+	}}}
+	b := budget.NewTracker(budget.Limits{})
+	e := NewEngine(reviewer, nil, b, nil)
+	e.EnableVerification()
+	e.SetVerificationConfig(reviewEnabledConfig())
+	task := &ticks.Task{ID: "t1", Description: "Add a flag.\n\nAcceptance Criteria:\n- flag exists"}
+
+	results := e.runVerification(context.Background(), &runState{workDir: createTempGitRepo(t)}, task, "")
+	if results == nil || !results.AllPassed {
+		t.Fatalf("runVerification() = %v, want passing review", results)
+	}
+
+	// The review counts toward the budget, but not as an iteration
+	usage := b.Usage()
+	if usage.TokensIn != 2000 || usage.TokensOut != 400 || usage.Cost != 0.05 {
+		t.Errorf("budget usage = %d/%d/%f, want the review's 2000/400/0.05", usage.TokensIn, usage.TokensOut, usage.Cost)
+	}
+	if usage.Iterations != 0 {
+		t.Errorf("budget Iterations = %d, want 0", usage.Iterations)
+	}
+}
+
+func TestEngine_runVerification_ReviewSkippedOnFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+
+	reviewer := &mockAgent{name: "test", available: true}
+	e := &Engine{agent: reviewer}
+	e.EnableVerification()
+	e.SetVerificationConfig(reviewEnabledConfig(&verify.CommandConfig{Name: "test", Command: "exit 1"}))
+	task := &ticks.Task{ID: "t1", Description: "Add a flag.\n\nAcceptance Criteria:\n- flag exists"}
+
+	results := e.runVerification(context.Background(), &runState{workDir: t.TempDir()}, task, "")
+	if results == nil || len(results.Results) != 2 {
+		t.Fatalf("runVerification() = %v, want test and criteria results", results)
+	}
+	review := results.Results[1]
+	if review.Verifier != verify.CriteriaVerifierName || !review.Skipped {
+		t.Errorf("Results[1] = %s, want skipped criteria review", review)
+	}
+	if reviewer.callCount != 0 {
+		t.Errorf("reviewer ran %d times, want 0 when other checks fail", reviewer.callCount)
+	}
+}
@@ -0,0 +1,57 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/checkpoint"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+// TestEngine_Run_ScriptedAgent runs the engine end to end with a scripted
+// agent: one iteration per task, the second handing off for input.
+func TestEngine_Run_ScriptedAgent(t *testing.T) {
+	mockTicks := newMockTicksClientForContext()
+	mockTicks.epic = &ticks.Epic{ID: "epic-1", Title: "Epic", Type: "epic"}
+	mockTicks.tasks = []*ticks.Task{
+		{ID: "task-1", Title: "First", Status: "open"},
+		{ID: "task-2", Title: "Second", Status: "open"},
+	}
+
+	scripted := agent.NewScriptedAgent(&agent.Scenario{Steps: []agent.ScriptStep{
+		{Output: "Done.", Signal: "COMPLETE", Tools: []agent.ScriptTool{{Name: "Edit", Input: "main.go"}}, TokensIn: 100, TokensOut: 20},
+		{Output: "Need a decision.", Signal: "INPUT_NEEDED: Postgres or SQLite?"},
+	}})
+	e := NewEngine(scripted, mockTicks, budget.NewTracker(budget.Limits{MaxIterations: 5}), checkpoint.NewManagerWithDir(t.TempDir()))
+
+	var sawTool bool
+	var signals []Signal
+	e.Subscribe(func(ev Event) {
+		switch ev := ev.(type) {
+		case AgentStateEvent:
+			if ev.Snapshot.ActiveTool != nil && ev.Snapshot.ActiveTool.Name == "Edit" {
+				sawTool = true
+			}
+		case SignalEvent:
+			signals = append(signals, ev.Signal)
+		}
+	})
+
+	result, err := e.Run(context.Background(), RunConfig{EpicID: "epic-1", AgentTimeout: time.Minute, CheckpointEvery: 100})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if len(signals) != 2 || signals[0] != SignalComplete || signals[1] != SignalInputNeeded {
+		t.Errorf("signals = %v, want COMPLETE then INPUT_NEEDED", signals)
+	}
+	if !sawTool {
+		t.Error("AgentStateEvent should carry the scripted tool call")
+	}
+	if result.Iterations != 2 || result.TotalTokens != 120 {
+		t.Errorf("Iterations = %d, TotalTokens = %d, want 2 and 120", result.Iterations, result.TotalTokens)
+	}
+}
@@ -0,0 +1,52 @@
+package engine
+
+import "github.com/pengelbrecht/ticker/internal/agent"
+
+// Follow-up messages sent when resuming a session (see EnableSessionContinuation).
+const (
+	followUpStillOpen          = "The task is still open. Finish the remaining work and close it, or emit a handoff signal if you are stuck."
+	followUpTimeout            = "The previous attempt timed out after %v. Check what you had already finished, avoid long-running commands, and complete the remaining work."
+	followUpVerificationFailed = "You closed the task but verification failed, so it has been reopened. Fix the problems below:\n\n"
+)
+
+// rememberSession records the agent session that last worked on a task.
+func (e *Engine) rememberSession(state *runState, taskID, sessionID string) {
+	if !e.continueSessions {
+		return
+	}
+	if state.sessions == nil {
+		state.sessions = make(map[string]string)
+	}
+	state.sessions[taskID] = sessionID
+}
+
+// forgetSession drops a task's session so its next attempt starts fresh.
+func (e *Engine) forgetSession(state *runState, taskID string) {
+	delete(state.sessions, taskID)
+	delete(state.followUps, taskID)
+}
+
+// setFollowUp records why the next attempt at a task is needed.
+func (e *Engine) setFollowUp(state *runState, taskID, reason string) {
+	if !e.continueSessions {
+		return
+	}
+	if state.followUps == nil {
+		state.followUps = make(map[string]string)
+	}
+	state.followUps[taskID] = reason
+}
+
+// takeFollowUp returns the session to resume and the follow-up message for
+// the next attempt at a task, or empty strings if the attempt should start
+// fresh. The follow-up is consumed either way.
+func (e *Engine) takeFollowUp(state *runState, taskID string, a agent.Agent) (sessionID, followUp string) {
+	followUp = state.followUps[taskID]
+	delete(state.followUps, taskID)
+
+	sessionID = state.sessions[taskID]
+	if !e.continueSessions || sessionID == "" || followUp == "" || !agent.CanResume(a) {
+		return "", ""
+	}
+	return sessionID, followUp
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/checkpoint"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+// mockResumableAgent records run options and reports a session per run.
+type mockResumableAgent struct {
+	mockAgentOpts
+	resumable bool
+}
+
+func (m *mockResumableAgent) CanResume() bool { return m.resumable }
+
+func (m *mockResumableAgent) Run(ctx context.Context, prompt string, opts agent.RunOpts) (*agent.Result, error) {
+	result, _ := m.mockAgentOpts.Run(ctx, prompt, opts)
+	result.TokensIn = 1000
+	if opts.ResumeSession != "" {
+		result.TokensIn = 100
+	}
+	result.Record = &agent.RunRecord{SessionID: "sess-1", Success: true}
+	return result, nil
+}
+
+func TestEngine_Run_SessionContinuation(t *testing.T) {
+	tests := []struct {
+		name        string
+		resumable   bool
+		enabled     bool
+		wantResumed bool
+	}{
+		{"enabled with resumable agent", true, true, true},
+		{"disabled", true, false, false},
+		{"agent cannot resume", false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := &ticks.Task{ID: "task1", Title: "Stubborn task"}
+			mockTicks := newMockTicksClient()
+			mockTicks.epic = &ticks.Epic{ID: "epic1", Title: "Epic", Type: "epic"}
+			mockTicks.tasks = []*ticks.Task{task, task, task}
+
+			mockAg := &mockResumableAgent{resumable: tt.resumable}
+			tracker := budget.NewTracker(budget.Limits{MaxIterations: 10})
+			e := NewEngine(mockAg, mockTicks, tracker, checkpoint.NewManagerWithDir(t.TempDir()))
+			if tt.enabled {
+				e.EnableSessionContinuation()
+			}
+
+			_, err := e.Run(context.Background(), RunConfig{
+				EpicID:          "epic1",
+				MaxTaskRetries:  2,
+				AgentTimeout:    time.Minute,
+				CheckpointEvery: 100,
+			})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(mockAg.opts) != 2 {
+				t.Fatalf("agent runs = %d, want 2", len(mockAg.opts))
+			}
+
+			first, retry := mockAg.opts[0], mockAg.opts[1]
+			if first.ResumeSession != "" {
+				t.Errorf("first run ResumeSession = %q, want fresh run", first.ResumeSession)
+			}
+			if first.PersistSession != tt.enabled {
+				t.Errorf("first run PersistSession = %v, want %v", first.PersistSession, tt.enabled)
+			}
+
+			resumed := retry.ResumeSession != ""
+			if resumed != tt.wantResumed {
+				t.Fatalf("retry ResumeSession = %q, want resumed %v", retry.ResumeSession, tt.wantResumed)
+			}
+			followUp := strings.Contains(mockAg.prompts[1], "continuing your previous session")
+			if followUp != tt.wantResumed {
+				t.Errorf("retry prompt follow-up = %v, want %v", followUp, tt.wantResumed)
+			}
+
+			usage := tracker.Usage()
+			if tt.wantResumed {
+				if retry.ResumeSession != "sess-1" {
+					t.Errorf("retry ResumeSession = %q, want %q", retry.ResumeSession, "sess-1")
+				}
+				if !strings.Contains(mockAg.prompts[1], followUpStillOpen) {
+					t.Error("retry prompt should explain why the task is retried")
+				}
+				if usage.Resumed.Iterations != 1 || usage.Resumed.TokensIn != 100 {
+					t.Errorf("Resumed usage = %+v, want 1 iteration with 100 tokens in", usage.Resumed)
+				}
+			} else if usage.Resumed.Iterations != 0 {
+				t.Errorf("Resumed.Iterations = %d, want 0", usage.Resumed.Iterations)
+			}
+			if usage.Iterations != 2 {
+				t.Errorf("Iterations = %d, want 2", usage.Iterations)
+			}
+		})
+	}
+}
+
+func TestEngine_takeFollowUp(t *testing.T) {
+	e := &Engine{continueSessions: true}
+	state := &runState{}
+	resumable := &mockResumableAgent{resumable: true}
+
+	// No session yet
+	e.setFollowUp(state, "task1", "timed out")
+	if id, msg := e.takeFollowUp(state, "task1", resumable); id != "" || msg != "" {
+		t.Errorf("takeFollowUp() without session = %q, %q; want empty", id, msg)
+	}
+
+	e.rememberSession(state, "task1", "sess-1")
+	e.setFollowUp(state, "task1", "timed out")
+	id, msg := e.takeFollowUp(state, "task1", resumable)
+	if id != "sess-1" || msg != "timed out" {
+		t.Errorf("takeFollowUp() = %q, %q; want sess-1, timed out", id, msg)
+	}
+
+	// Follow-up is consumed
+	if id, _ := e.takeFollowUp(state, "task1", resumable); id != "" {
+		t.Errorf("takeFollowUp() second call = %q, want empty", id)
+	}
+
+	// Forgotten sessions start fresh
+	e.setFollowUp(state, "task1", "still open")
+	e.forgetSession(state, "task1")
+	if id, _ := e.takeFollowUp(state, "task1", resumable); id != "" {
+		t.Errorf("takeFollowUp() after forgetSession = %q, want empty", id)
+	}
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+// RunTask runs one iteration on a task outside an epic run: a standalone
+// task, or an orphan whose epic is closed (its notes are still included).
+// Like an iteration of Run, it publishes the iteration's events, records its
+// transcript, attributes its commits to the task's run record, counts its
+// usage in the budget and moves the task to awaiting on a handoff signal.
+// Verification and picking the next task are left to the caller.
+func (e *Engine) RunTask(ctx context.Context, task *ticks.Task, iteration int, timeout time.Duration) *IterationResult {
+	state := &runState{iteration: iteration, startTime: time.Now()}
+	if task.Parent != "" {
+		if _, err := e.ticks.GetEpic(task.Parent); err == nil {
+			state.epicID = task.Parent
+		}
+	}
+
+	result := e.runIteration(ctx, state, task, timeout)
+
+	if e.budget != nil {
+		e.budget.Add(result.TokensIn, result.TokensOut, result.Cost)
+	}
+	e.events.Publish(IterationEndEvent{EventInfo: eventInfo(state.epicID), Result: result})
+	if e.budget != nil {
+		e.events.Publish(BudgetEvent{
+			EventInfo: eventInfo(state.epicID),
+			Iteration: iteration,
+			Usage:     e.budget.Usage(),
+			Limits:    e.budget.Limits(),
+		})
+	}
+
+	if result.Error == nil && result.Signal != SignalNone {
+		e.events.Publish(SignalEvent{
+			EventInfo: eventInfo(state.epicID),
+			TaskID:    task.ID,
+			Signal:    result.Signal,
+			Reason:    result.SignalReason,
+		})
+		// Ticker closes tasks itself; COMPLETE is ignored as in Run
+		if result.Signal != SignalComplete {
+			_ = e.handleSignal(task, result.Signal, result.SignalReason)
+			if e.runLog != nil {
+				e.runLog.LogSignalHandled(result.Signal.String(), task.ID, "set task awaiting", signalToAwaiting[result.Signal])
+			}
+		}
+	}
+	return result
+}
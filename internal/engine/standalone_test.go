@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+func TestEngine_RunTask_RecordsCommits(t *testing.T) {
+	repo := createTempGitRepo(t)
+	t.Chdir(repo)
+
+	mockTicks := newMockTicksClientForContext()
+	task := &ticks.Task{ID: "task-1", Title: "Standalone", Status: "open"}
+	mockTicks.tasks = []*ticks.Task{task}
+
+	commit := func(name string) func(agent.RunOpts) {
+		return func(agent.RunOpts) {
+			if err := os.WriteFile(filepath.Join(repo, name), []byte(name), 0644); err != nil {
+				t.Fatalf("writing file: %v", err)
+			}
+			for _, args := range [][]string{{"add", name}, {"commit", "-m", "Add " + name}} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = repo
+				if out, err := cmd.CombinedOutput(); err != nil {
+					t.Fatalf("git %v: %v\n%s", args, err, out)
+				}
+			}
+		}
+	}
+	mockAg := &mockAgent{name: "mock", available: true, responses: []mockResponse{
+		{output: "first", record: &agent.RunRecord{Model: "opus"}, run: commit("a.txt")},
+		{output: "second", record: &agent.RunRecord{Model: "opus"}, run: commit("b.txt")},
+	}}
+	b := budget.NewTracker(budget.Limits{})
+	e := NewEngine(mockAg, mockTicks, b, nil)
+
+	base := gitutil.HeadCommit(repo)
+	var outputs []string
+	e.Subscribe(func(ev Event) {
+		if ev, ok := ev.(IterationEndEvent); ok {
+			outputs = append(outputs, ev.Result.Output)
+		}
+	})
+	for i := 1; i <= 2; i++ {
+		if result := e.RunTask(context.Background(), task, i, time.Minute); result.Error != nil {
+			t.Fatalf("RunTask(%d) error = %v", i, result.Error)
+		}
+	}
+
+	// Both runs' commits, and the range of the whole task, are on the record
+	head := gitutil.HeadCommit(repo)
+	record := mockTicks.runRecords["task-1"]
+	if record == nil {
+		t.Fatal("expected a run record for task-1")
+	}
+	if got, want := strings.Join(record.Commits, ","), strings.Join(gitutil.CommitsBetween(repo, base, head), ","); got != want || len(record.Commits) != 2 {
+		t.Errorf("run record Commits = %v, want both runs' commits %s", record.Commits, want)
+	}
+	if record.BaseCommit != base || record.HeadCommit != head {
+		t.Errorf("run record range = %s..%s, want %s..%s", record.BaseCommit, record.HeadCommit, base, head)
+	}
+	if strings.Join(outputs, ",") != "first,second" {
+		t.Errorf("IterationEndEvent outputs = %v, want [first second]", outputs)
+	}
+	if usage := b.Usage(); usage.Iterations != 2 {
+		t.Errorf("budget Iterations = %d, want 2", usage.Iterations)
+	}
+}
+
+func TestEngine_RunTask_Signal(t *testing.T) {
+	mockTicks := newMockTicksClient()
+	task := &ticks.Task{ID: "task-1", Title: "Standalone", Status: "open"}
+	mockTicks.tasks = []*ticks.Task{task}
+	mockAg := &mockAgent{name: "mock", available: true, responses: []mockResponse{
+		{output: "<promise>INPUT_NEEDED: Postgres or SQLite?</promise>"},
+	}}
+	e := NewEngine(mockAg, mockTicks, budget.NewTracker(budget.Limits{}), nil)
+
+	var signals []SignalEvent
+	e.Subscribe(func(ev Event) {
+		if ev, ok := ev.(SignalEvent); ok {
+			signals = append(signals, ev)
+		}
+	})
+
+	result := e.RunTask(context.Background(), task, 1, time.Minute)
+	if result.Error != nil {
+		t.Fatalf("RunTask() error = %v", result.Error)
+	}
+	if len(signals) != 1 || signals[0].Signal != SignalInputNeeded || signals[0].TaskID != "task-1" {
+		t.Errorf("SignalEvents = %+v, want INPUT_NEEDED for task-1", signals)
+	}
+	if got := mockTicks.GetAwaiting("task-1"); got != "input" {
+		t.Errorf("awaiting = %q, want %q", got, "input")
+	}
+}
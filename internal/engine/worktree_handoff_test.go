@@ -408,10 +408,12 @@ func TestWorktree_CleanupOnEpicComplete(t *testing.T) {
 	engine := NewEngine(agentMock, mock, b, c)
 
 	// Hook into engine to close task after first iteration (simulating agent calling tk close)
-	engine.OnIterationEnd = func(result *IterationResult) {
-		// Simulate agent closing the task via tk close
-		mock.CloseTask("task1", "Completed")
-	}
+	engine.Subscribe(func(ev Event) {
+		if _, ok := ev.(IterationEndEvent); ok {
+			// Simulate agent closing the task via tk close
+			mock.CloseTask("task1", "Completed")
+		}
+	})
 
 	// Run engine - should complete epic and cleanup worktree
 	ctx := context.Background()
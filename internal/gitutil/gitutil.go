@@ -0,0 +1,88 @@
+// Package gitutil holds the git queries ticker uses to track what a task
+// changed: the current commit, the commits made since another, and diffs
+// that leave out ticker's own metadata.
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// MetadataPaths are ticker's own metadata paths. They change during
+// execution and are left out of diffs.
+var MetadataPaths = []string{
+	".tick/",
+	".ticker/",
+}
+
+// Run runs a git command in dir and returns its output. Errors carry git's
+// error message.
+func Run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// ShortSHA abbreviates a commit SHA for display.
+func ShortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// HeadCommit returns the commit SHA of HEAD in dir, or "" if unavailable.
+func HeadCommit(dir string) string {
+	cmd := exec.Command("git", "rev-parse", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// CommitsBetween returns the commits in dir after from up to and including
+// to, oldest first, or nil if there are none or they can't be determined.
+func CommitsBetween(dir, from, to string) []string {
+	if from == "" || to == "" || from == to {
+		return nil
+	}
+	cmd := exec.Command("git", "rev-list", "--reverse", from+".."+to)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(out))
+}
+
+// Diff runs git diff on refs in dir, excluding ticker's own metadata. With
+// no refs it returns the uncommitted changes; with two, the changes between
+// those commits.
+func Diff(ctx context.Context, dir string, refs ...string) (string, error) {
+	args := append([]string{"diff", "--no-color"}, refs...)
+	args = append(args, "--", ".")
+	for _, p := range MetadataPaths {
+		args = append(args, ":(exclude)"+strings.TrimSuffix(p, "/"))
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,165 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createTempGitRepo creates a temporary git repo with one initial commit.
+func createTempGitRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init"},
+		{"config", "user.email", "test@test.com"},
+		{"config", "user.name", "Test User"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "initial.txt"), []byte("initial\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "initial.txt"}, {"commit", "-m", "Initial commit"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestHeadCommit(t *testing.T) {
+	if got := HeadCommit(createTempGitRepo(t)); len(got) != 40 {
+		t.Errorf("HeadCommit() = %q, want a commit SHA", got)
+	}
+	if got := HeadCommit(t.TempDir()); got != "" {
+		t.Errorf("HeadCommit() outside a repo = %q, want empty", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := createTempGitRepo(t)
+	base := HeadCommit(dir)
+
+	for name, content := range map[string]string{"feature.go": "package feature\n", ".tick/issues/abc.json": "{}\n"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{{"add", "-A"}, {"commit", "-m", "Add feature"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	head := HeadCommit(dir)
+
+	// Uncommitted changes are not part of the range
+	if err := os.WriteFile(filepath.Join(dir, "initial.txt"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := Diff(context.Background(), dir, base, head)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if !strings.Contains(diff, "+package feature") {
+		t.Errorf("Diff() = %q, want the committed change", diff)
+	}
+	for _, unwanted := range []string{".tick/", "+changed"} {
+		if strings.Contains(diff, unwanted) {
+			t.Errorf("Diff() contains %q, want it excluded", unwanted)
+		}
+	}
+
+	if _, err := Diff(context.Background(), dir, base, "0000000"); err == nil {
+		t.Error("Diff() with unknown commit: expected error")
+	}
+}
+
+func TestCommitsBetween(t *testing.T) {
+	dir := createTempGitRepo(t)
+	base := HeadCommit(dir)
+
+	var commits []string
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{{"add", name}, {"commit", "-m", "Add " + name}} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+		commits = append(commits, HeadCommit(dir))
+	}
+	head := HeadCommit(dir)
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string
+	}{
+		{"two commits oldest first", base, head, commits},
+		{"one commit", commits[0], head, commits[1:]},
+		{"no commits", head, head, nil},
+		{"no base", "", head, nil},
+		{"unknown commit", "0000000", head, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CommitsBetween(dir, tt.from, tt.to)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("CommitsBetween() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := createTempGitRepo(t)
+
+	out, err := Run(dir, "rev-parse", "HEAD")
+	if err != nil || strings.TrimSpace(out) != HeadCommit(dir) {
+		t.Errorf("Run(rev-parse HEAD) = %q, %v; want %s", out, err, HeadCommit(dir))
+	}
+
+	// Errors carry git's message
+	if _, err := Run(dir, "rev-parse", "--verify", "no-such-ref"); err == nil || !strings.Contains(err.Error(), "git rev-parse:") || !strings.Contains(err.Error(), "fatal") {
+		t.Errorf("Run(rev-parse no-such-ref) error = %v, want git's message", err)
+	}
+}
+
+func TestShortSHA(t *testing.T) {
+	tests := []struct {
+		sha  string
+		want string
+	}{
+		{"0123456789abcdef", "0123456"},
+		{"0123456", "0123456"},
+		{"abc", "abc"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ShortSHA(tt.sha); got != tt.want {
+			t.Errorf("ShortSHA(%q) = %q, want %q", tt.sha, got, tt.want)
+		}
+	}
+}
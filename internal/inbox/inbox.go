@@ -0,0 +1,207 @@
+// Package inbox collects the tasks awaiting a human across epics and
+// applies human responses to them.
+//
+// A task is in the inbox while its awaiting field is set (see ticks.Task).
+// Responses set a verdict the way tk does and then process it, so a task
+// goes back to the agent or closes according to ticks.Task.ProcessVerdict.
+package inbox
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+// Types lists the awaiting types in the order the inbox shows them:
+// questions blocking the agent first, work a human took over last.
+var Types = []string{"input", "escalation", "approval", "review", "content", "checkpoint", "work"}
+
+// verdictTypes are the awaiting types answered with approve or reject.
+var verdictTypes = []string{"work", "approval", "review", "content", "checkpoint"}
+
+// answerTypes are the awaiting types answered with Answer.
+var answerTypes = []string{"input", "escalation"}
+
+// Ticks is the subset of the ticks client used by the inbox.
+type Ticks interface {
+	ListAllTasks() ([]ticks.Task, error)
+	GetTask(taskID string) (*ticks.Task, error)
+	GetAgentNotes(issueID string) ([]ticks.Note, error)
+	Approve(taskID string) error
+	Reject(taskID, feedback string) error
+	SetVerdict(taskID, verdict, feedback string) error
+	ProcessVerdict(taskID string) (ticks.VerdictResult, error)
+	SetAwaiting(taskID, awaiting, note string) error
+	AddHumanNote(issueID, message string) error
+}
+
+// Item is a task awaiting a human.
+type Item struct {
+	Task ticks.Task
+
+	// Reason is the agent's latest note, which for a handoff is the
+	// reason it gave with the signal (e.g. the question for input).
+	Reason string
+
+	// LastNote is the agent's note before Reason, usually its progress
+	// up to the handoff. Empty if there is none.
+	LastNote string
+}
+
+// Group is the items awaiting one type of human action, oldest first.
+type Group struct {
+	Awaiting string
+	Items    []Item
+}
+
+// List returns the open tasks awaiting a human, grouped by awaiting type in
+// Types order. Types not in Types come last. Empty groups are omitted.
+func List(t Ticks) ([]Group, error) {
+	tasks, err := t.ListAllTasks()
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string][]Item)
+	for _, task := range tasks {
+		if !task.IsOpen() || !task.IsAwaitingHuman() {
+			continue
+		}
+		item := Item{Task: task}
+		notes, err := t.GetAgentNotes(task.ID)
+		if err != nil {
+			return nil, fmt.Errorf("reading notes of %s: %w", task.ID, err)
+		}
+		if n := len(notes); n > 0 {
+			item.Reason = notes[n-1].Content
+			if n > 1 {
+				item.LastNote = notes[n-2].Content
+			}
+		}
+		awaiting := task.GetAwaitingType()
+		byType[awaiting] = append(byType[awaiting], item)
+	}
+
+	order := slices.Clone(Types)
+	var other []string
+	for awaiting := range byType {
+		if !slices.Contains(Types, awaiting) {
+			other = append(other, awaiting)
+		}
+	}
+	sort.Strings(other)
+	order = append(order, other...)
+
+	var groups []Group
+	for _, awaiting := range order {
+		items := byType[awaiting]
+		if len(items) == 0 {
+			continue
+		}
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Task.UpdatedAt.Before(items[j].Task.UpdatedAt)
+		})
+		groups = append(groups, Group{Awaiting: awaiting, Items: items})
+	}
+	return groups, nil
+}
+
+// Approve accepts the work on a task awaiting work, approval, review,
+// content or checkpoint. All but checkpoint close the task.
+func Approve(t Ticks, taskID string) (ticks.VerdictResult, error) {
+	task, err := awaitingTask(t, taskID, verdictTypes, "answer")
+	if err != nil {
+		return ticks.VerdictResult{}, err
+	}
+	if err := t.Approve(taskID); err != nil {
+		return ticks.VerdictResult{}, err
+	}
+	return processVerdict(t, task, "approved")
+}
+
+// Reject sends a task awaiting work, approval, review, content or
+// checkpoint back to the agent with feedback, which is required.
+func Reject(t Ticks, taskID, feedback string) (ticks.VerdictResult, error) {
+	if feedback == "" {
+		return ticks.VerdictResult{}, fmt.Errorf("feedback is required to reject a task")
+	}
+	task, err := awaitingTask(t, taskID, verdictTypes, "answer")
+	if err != nil {
+		return ticks.VerdictResult{}, err
+	}
+	if err := t.Reject(taskID, feedback); err != nil {
+		return ticks.VerdictResult{}, err
+	}
+	return processVerdict(t, task, "rejected")
+}
+
+// Answer replies to a task awaiting input or escalation and hands it back
+// to the agent, which sees the answer as a human note.
+func Answer(t Ticks, taskID, answer string) (ticks.VerdictResult, error) {
+	if answer == "" {
+		return ticks.VerdictResult{}, fmt.Errorf("an answer is required")
+	}
+	task, err := awaitingTask(t, taskID, answerTypes, "approve or reject")
+	if err != nil {
+		return ticks.VerdictResult{}, err
+	}
+	if err := t.SetVerdict(taskID, "approved", answer); err != nil {
+		return ticks.VerdictResult{}, err
+	}
+	return processVerdict(t, task, "approved")
+}
+
+// TakeOver hands an open task to a human as work, so no agent picks it up.
+// The optional note is added as a human note.
+func TakeOver(t Ticks, taskID, note string) error {
+	task, err := t.GetTask(taskID)
+	if err != nil {
+		return err
+	}
+	if !task.IsOpen() {
+		return fmt.Errorf("task %s is %s", taskID, task.Status)
+	}
+	if task.GetAwaitingType() == "work" {
+		return fmt.Errorf("task %s is already awaiting work", taskID)
+	}
+	if err := t.SetAwaiting(taskID, "work", ""); err != nil {
+		return err
+	}
+	if note != "" {
+		return t.AddHumanNote(taskID, note)
+	}
+	return nil
+}
+
+// awaitingTask loads taskID and checks it awaits one of types. hint names
+// the responses that fit the other awaiting types.
+func awaitingTask(t Ticks, taskID string, types []string, hint string) (*ticks.Task, error) {
+	task, err := t.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	awaiting := task.GetAwaitingType()
+	if awaiting == "" {
+		return nil, fmt.Errorf("task %s is not awaiting a human", taskID)
+	}
+	if !slices.Contains(types, awaiting) {
+		return nil, fmt.Errorf("task %s is awaiting %s; use %s", taskID, awaiting, hint)
+	}
+	return task, nil
+}
+
+// processVerdict processes the verdict just set on task and returns its
+// outcome. tk may already have processed it, in which case processing
+// again is a no-op and the outcome is worked out from the awaiting type.
+func processVerdict(t Ticks, task *ticks.Task, verdict string) (ticks.VerdictResult, error) {
+	if _, err := t.ProcessVerdict(task.ID); err != nil {
+		return ticks.VerdictResult{}, err
+	}
+	awaiting := task.GetAwaitingType() // also set for legacy Manual tasks
+	outcome := *task
+	outcome.Awaiting = &awaiting
+	outcome.Verdict = &verdict
+	return outcome.ProcessVerdict(), nil
+}
@@ -0,0 +1,235 @@
+package inbox
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+type fakeTicks struct {
+	tasks      map[string]*ticks.Task
+	order      []string
+	notes      map[string][]ticks.Note
+	verdicts   map[string]string
+	processed  []string
+	humanNotes map[string][]string
+}
+
+func newFakeTicks(tasks ...ticks.Task) *fakeTicks {
+	f := &fakeTicks{
+		tasks:      make(map[string]*ticks.Task),
+		notes:      make(map[string][]ticks.Note),
+		verdicts:   make(map[string]string),
+		humanNotes: make(map[string][]string),
+	}
+	for _, t := range tasks {
+		task := t
+		f.tasks[t.ID] = &task
+		f.order = append(f.order, t.ID)
+	}
+	return f
+}
+
+func (f *fakeTicks) ListAllTasks() ([]ticks.Task, error) {
+	var tasks []ticks.Task
+	for _, id := range f.order {
+		tasks = append(tasks, *f.tasks[id])
+	}
+	return tasks, nil
+}
+
+func (f *fakeTicks) GetTask(taskID string) (*ticks.Task, error) {
+	task, ok := f.tasks[taskID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return task, nil
+}
+
+func (f *fakeTicks) GetAgentNotes(issueID string) ([]ticks.Note, error) {
+	return f.notes[issueID], nil
+}
+
+func (f *fakeTicks) Approve(taskID string) error {
+	return f.SetVerdict(taskID, "approved", "")
+}
+
+func (f *fakeTicks) Reject(taskID, feedback string) error {
+	return f.SetVerdict(taskID, "rejected", feedback)
+}
+
+func (f *fakeTicks) SetVerdict(taskID, verdict, feedback string) error {
+	if feedback != "" {
+		f.humanNotes[taskID] = append(f.humanNotes[taskID], feedback)
+	}
+	f.verdicts[taskID] = verdict
+	return nil
+}
+
+func (f *fakeTicks) ProcessVerdict(taskID string) (ticks.VerdictResult, error) {
+	f.processed = append(f.processed, taskID)
+	return ticks.VerdictResult{}, nil
+}
+
+func (f *fakeTicks) SetAwaiting(taskID, awaiting, note string) error {
+	f.tasks[taskID].SetAwaiting(awaiting)
+	return nil
+}
+
+func (f *fakeTicks) AddHumanNote(issueID, message string) error {
+	f.humanNotes[issueID] = append(f.humanNotes[issueID], message)
+	return nil
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func newAwaitingTask(id, awaiting string, updated time.Time) ticks.Task {
+	return ticks.Task{ID: id, Status: "open", Awaiting: strPtr(awaiting), UpdatedAt: updated}
+}
+
+func TestList(t *testing.T) {
+	now := time.Now()
+	f := newFakeTicks(
+		newAwaitingTask("a1", "approval", now.Add(-time.Hour)),
+		ticks.Task{ID: "open", Status: "open"},
+		newAwaitingTask("i1", "input", now.Add(-time.Minute)),
+		newAwaitingTask("a2", "approval", now.Add(-2*time.Hour)),
+		ticks.Task{ID: "m1", Status: "open", Manual: true, UpdatedAt: now},
+		newAwaitingTask("x1", "custom", now),
+	)
+	f.notes["i1"] = []ticks.Note{{Content: "Scaffolded the service"}, {Content: "Which database?"}}
+	f.notes["a1"] = []ticks.Note{{Content: "Work complete, requires approval"}}
+
+	groups, err := List(f)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	var got []string
+	for _, g := range groups {
+		var ids []string
+		for _, item := range g.Items {
+			ids = append(ids, item.Task.ID)
+		}
+		got = append(got, g.Awaiting+":"+strings.Join(ids, ","))
+	}
+	want := "input:i1 approval:a2,a1 work:m1 custom:x1"
+	if strings.Join(got, " ") != want {
+		t.Errorf("List() groups = %v, want %s", got, want)
+	}
+
+	input := groups[0].Items[0]
+	if input.Reason != "Which database?" || input.LastNote != "Scaffolded the service" {
+		t.Errorf("input item Reason = %q, LastNote = %q, want the question and the note before it", input.Reason, input.LastNote)
+	}
+	approval := groups[1].Items[1]
+	if approval.Reason != "Work complete, requires approval" || approval.LastNote != "" {
+		t.Errorf("approval item Reason = %q, LastNote = %q, want only the reason", approval.Reason, approval.LastNote)
+	}
+}
+
+func TestApprove(t *testing.T) {
+	f := newFakeTicks(newAwaitingTask("t1", "approval", time.Now()), newAwaitingTask("t2", "checkpoint", time.Now()))
+
+	result, err := Approve(f, "t1")
+	if err != nil {
+		t.Fatalf("Approve() error = %v", err)
+	}
+	if !result.ShouldClose {
+		t.Error("Approve() of an approval should close the task")
+	}
+	if f.verdicts["t1"] != "approved" || len(f.processed) != 1 {
+		t.Errorf("verdict = %q, processed = %v, want approved and processed", f.verdicts["t1"], f.processed)
+	}
+
+	result, err = Approve(f, "t2")
+	if err != nil || result.ShouldClose {
+		t.Errorf("Approve() of a checkpoint = %+v, %v, want back to the agent", result, err)
+	}
+}
+
+func TestReject(t *testing.T) {
+	f := newFakeTicks(newAwaitingTask("t1", "review", time.Now()))
+
+	if _, err := Reject(f, "t1", ""); err == nil {
+		t.Error("Reject() without feedback should fail")
+	}
+
+	result, err := Reject(f, "t1", "Add tests")
+	if err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+	if result.ShouldClose {
+		t.Error("Reject() should send the task back to the agent")
+	}
+	if f.verdicts["t1"] != "rejected" || len(f.humanNotes["t1"]) != 1 || f.humanNotes["t1"][0] != "Add tests" {
+		t.Errorf("verdict = %q, notes = %v, want rejected with the feedback", f.verdicts["t1"], f.humanNotes["t1"])
+	}
+}
+
+func TestAnswer(t *testing.T) {
+	f := newFakeTicks(newAwaitingTask("t1", "input", time.Now()), newAwaitingTask("t2", "approval", time.Now()))
+
+	result, err := Answer(f, "t1", "Use Postgres")
+	if err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+	if result.ShouldClose {
+		t.Error("Answer() should send the task back to the agent")
+	}
+	if f.verdicts["t1"] != "approved" || f.humanNotes["t1"][0] != "Use Postgres" {
+		t.Errorf("verdict = %q, notes = %v, want approved with the answer", f.verdicts["t1"], f.humanNotes["t1"])
+	}
+
+	// Answer and approve/reject don't mix
+	if _, err := Answer(f, "t2", "yes"); err == nil || !strings.Contains(err.Error(), "approve or reject") {
+		t.Errorf("Answer() of an approval error = %v, want a hint to approve or reject", err)
+	}
+	if _, err := Reject(f, "t1", "no"); err == nil || !strings.Contains(err.Error(), "use answer") {
+		t.Errorf("Reject() of an input error = %v, want a hint to answer", err)
+	}
+}
+
+func TestResponses_NotAwaiting(t *testing.T) {
+	f := newFakeTicks(ticks.Task{ID: "t1", Status: "open"})
+
+	if _, err := Approve(f, "t1"); err == nil || !strings.Contains(err.Error(), "not awaiting") {
+		t.Errorf("Approve() error = %v, want not awaiting", err)
+	}
+	if _, err := Answer(f, "missing", "x"); err == nil {
+		t.Error("Answer() of a missing task should fail")
+	}
+	if len(f.verdicts) != 0 {
+		t.Errorf("verdicts = %v, want none set", f.verdicts)
+	}
+}
+
+func TestTakeOver(t *testing.T) {
+	f := newFakeTicks(
+		newAwaitingTask("t1", "input", time.Now()),
+		newAwaitingTask("t2", "work", time.Now()),
+		ticks.Task{ID: "t3", Status: "closed"},
+	)
+
+	if err := TakeOver(f, "t1", "I'll do this one"); err != nil {
+		t.Fatalf("TakeOver() error = %v", err)
+	}
+	if got := f.tasks["t1"].GetAwaitingType(); got != "work" {
+		t.Errorf("awaiting = %q, want work", got)
+	}
+	if len(f.humanNotes["t1"]) != 1 {
+		t.Errorf("human notes = %v, want the note", f.humanNotes["t1"])
+	}
+
+	if err := TakeOver(f, "t2", ""); err == nil {
+		t.Error("TakeOver() of a task already awaiting work should fail")
+	}
+	if err := TakeOver(f, "t3", ""); err == nil {
+		t.Error("TakeOver() of a closed task should fail")
+	}
+}
@@ -34,6 +34,10 @@ type RunnerConfig struct {
 	// EngineConfig is the base configuration for each engine run.
 	// EpicID will be set per-epic.
 	EngineConfig engine.RunConfig
+
+	// WakeChans holds each epic's engine wake channel (see
+	// engine.RunConfig.WakeChan), keyed by epic ID. Optional.
+	WakeChans map[string]<-chan struct{}
 }
 
 // EngineFactory creates Engine instances for parallel runs.
@@ -204,6 +208,7 @@ func (r *Runner) runEpic(ctx context.Context, epicID string) {
 		// Configure engine for this epic
 		cfg := r.config.EngineConfig
 		cfg.EpicID = epicID
+		cfg.WakeChan = r.config.WakeChans[epicID]
 		if wt != nil {
 			cfg.UseWorktree = false // We already created the worktree
 			cfg.WorkDir = wt.Path   // Pass the worktree path to the engine
@@ -0,0 +1,264 @@
+// Package revert undoes the commits an agent made for a task.
+//
+// The engine records every commit made during a task's iterations in the
+// task's RunRecord (see agent.RunRecord.Commits). Reverting a task reverts
+// exactly those commits in one revert commit, reopens the task and leaves a
+// human note explaining why, so the agent redoes it rather than restoring
+// the reverted work.
+//
+// Later commits that touch the same files may depend on the reverted work.
+// NewPlan reports them; it is up to the caller whether to go ahead.
+package revert
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+// ErrNoCommits is returned when a task has no recorded commits to revert.
+var ErrNoCommits = errors.New("no commits recorded")
+
+// Ticks is the subset of the ticks client used to revert tasks.
+type Ticks interface {
+	GetTask(taskID string) (*ticks.Task, error)
+	ListTasks(epicID string) ([]ticks.Task, error)
+	GetRunRecord(taskID string) (*agent.RunRecord, error)
+	SetRunRecord(taskID string, record *agent.RunRecord) error
+	ReopenTask(taskID string) error
+	AddHumanNote(issueID, message string) error
+}
+
+// Dependent is a later commit touching files the reverted commits changed.
+type Dependent struct {
+	Commit  string
+	Subject string
+
+	// TaskID is the task that made the commit, if ticker recorded it.
+	TaskID string
+
+	// Files are the files it shares with the reverted commits.
+	Files []string
+}
+
+// Plan is what reverting a task will do.
+type Plan struct {
+	Task *ticks.Task
+
+	// Dir is the repository (or epic worktree) the commits are reverted in.
+	Dir string
+
+	// Commits are the task's commits, oldest first.
+	Commits []string
+
+	// Files are the files the commits changed.
+	Files []string
+
+	// Dependents are later commits that may depend on the reverted ones.
+	Dependents []Dependent
+}
+
+// NewPlan works out how to revert taskID in dir. All of the task's commits
+// must be on dir's current branch.
+func NewPlan(t Ticks, dir, taskID string) (*Plan, error) {
+	task, err := t.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	record, err := t.GetRunRecord(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil || len(record.Commits) == 0 {
+		return nil, fmt.Errorf("task %s: %w", taskID, ErrNoCommits)
+	}
+
+	plan := &Plan{Task: task, Dir: dir, Commits: record.Commits}
+	for _, commit := range plan.Commits {
+		if _, err := gitutil.Run(dir, "merge-base", "--is-ancestor", commit, "HEAD"); err != nil {
+			return nil, fmt.Errorf("commit %s of task %s is not on the current branch in %s", gitutil.ShortSHA(commit), taskID, dir)
+		}
+		files, err := changedFiles(dir, commit)
+		if err != nil {
+			return nil, err
+		}
+		plan.Files = append(plan.Files, files...)
+	}
+	slices.Sort(plan.Files)
+	plan.Files = slices.Compact(plan.Files)
+
+	plan.Dependents, err = dependents(t, plan)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// dependents finds the commits after the task's first one that touch its
+// files, attributed to the epic's tasks where ticker recorded them.
+func dependents(t Ticks, plan *Plan) ([]Dependent, error) {
+	out, err := gitutil.Run(plan.Dir, "rev-list", "--reverse", plan.Commits[0]+"..HEAD")
+	if err != nil {
+		return nil, err
+	}
+	later := slices.DeleteFunc(strings.Fields(out), func(c string) bool {
+		return slices.Contains(plan.Commits, c)
+	})
+	if len(later) == 0 {
+		return nil, nil
+	}
+
+	owners := commitOwners(t, plan.Task)
+	var deps []Dependent
+	for _, commit := range later {
+		files, err := changedFiles(plan.Dir, commit)
+		if err != nil {
+			return nil, err
+		}
+		var shared []string
+		for _, f := range files {
+			if _, found := slices.BinarySearch(plan.Files, f); found {
+				shared = append(shared, f)
+			}
+		}
+		if len(shared) == 0 {
+			continue
+		}
+		subject, _ := gitutil.Run(plan.Dir, "log", "-1", "--format=%s", commit)
+		deps = append(deps, Dependent{
+			Commit:  commit,
+			Subject: strings.TrimSpace(subject),
+			TaskID:  owners[commit],
+			Files:   shared,
+		})
+	}
+	return deps, nil
+}
+
+// commitOwners maps the recorded commits of task's sibling tasks to their
+// task IDs. Best effort: tasks that can't be read are skipped.
+func commitOwners(t Ticks, task *ticks.Task) map[string]string {
+	owners := make(map[string]string)
+	if task.Parent == "" {
+		return owners
+	}
+	siblings, err := t.ListTasks(task.Parent)
+	if err != nil {
+		return owners
+	}
+	for _, s := range siblings {
+		if s.ID == task.ID {
+			continue
+		}
+		record, err := t.GetRunRecord(s.ID)
+		if err != nil || record == nil {
+			continue
+		}
+		for _, c := range record.Commits {
+			owners[c] = s.ID
+		}
+	}
+	return owners
+}
+
+// Revert reverts the plan's commits in one commit, reopens the task and adds
+// a note explaining the revert. Returns the revert commit. The working tree
+// must be clean apart from ticker's own metadata; if the revert conflicts it
+// is aborted and nothing changes.
+func Revert(t Ticks, plan *Plan) (string, error) {
+	if dirty := dirtyFiles(plan.Dir); len(dirty) > 0 {
+		return "", fmt.Errorf("uncommitted changes in %s: %s", plan.Dir, strings.Join(dirty, ", "))
+	}
+
+	// Newest first, so each revert applies on top of what came after it
+	args := []string{"revert", "--no-commit"}
+	for i := len(plan.Commits) - 1; i >= 0; i-- {
+		args = append(args, plan.Commits[i])
+	}
+	if _, err := gitutil.Run(plan.Dir, args...); err != nil {
+		_, _ = gitutil.Run(plan.Dir, "revert", "--abort")
+		return "", fmt.Errorf("reverting %s: %w", plan.Task.ID, err)
+	}
+	if _, err := gitutil.Run(plan.Dir, "commit", "-m", commitMessage(plan)); err != nil {
+		_, _ = gitutil.Run(plan.Dir, "revert", "--abort")
+		return "", fmt.Errorf("committing revert of %s: %w", plan.Task.ID, err)
+	}
+	revertCommit, err := gitutil.Run(plan.Dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	revertCommit = strings.TrimSpace(revertCommit)
+
+	// The commits are gone; don't revert them again
+	if record, err := t.GetRunRecord(plan.Task.ID); err == nil && record != nil {
+		record.Commits = nil
+		if err := t.SetRunRecord(plan.Task.ID, record); err != nil {
+			return revertCommit, err
+		}
+	}
+	if plan.Task.Status == "closed" {
+		if err := t.ReopenTask(plan.Task.ID); err != nil {
+			return revertCommit, err
+		}
+	}
+	return revertCommit, t.AddHumanNote(plan.Task.ID, revertNote(plan, revertCommit))
+}
+
+// commitMessage is the message of the revert commit.
+func commitMessage(plan *Plan) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Revert %s: %s\n\nReverts the commits made for task %s:\n\n", plan.Task.ID, plan.Task.Title, plan.Task.ID)
+	for _, c := range plan.Commits {
+		fmt.Fprintf(&sb, "  %s\n", c)
+	}
+	return sb.String()
+}
+
+// revertNote is the note left on the task for the agent that picks it up.
+func revertNote(plan *Plan, revertCommit string) string {
+	commits := make([]string, len(plan.Commits))
+	for i, c := range plan.Commits {
+		commits[i] = gitutil.ShortSHA(c)
+	}
+	return fmt.Sprintf("The work on this task was reverted by a human in %s (reverted commits: %s). "+
+		"Redo the task from the current code; do not restore the reverted commits.",
+		gitutil.ShortSHA(revertCommit), strings.Join(commits, ", "))
+}
+
+// changedFiles returns the files commit changed. Paths are NUL-separated
+// (-z) so names with spaces or quotes come through as they are.
+func changedFiles(dir, commit string) ([]string, error) {
+	out, err := gitutil.Run(dir, "diff-tree", "-z", "--no-commit-id", "--name-only", "-r", "--root", commit)
+	if err != nil {
+		return nil, fmt.Errorf("reading commit %s: %w", gitutil.ShortSHA(commit), err)
+	}
+	files := strings.FieldsFunc(out, func(r rune) bool { return r == 0 })
+	sort.Strings(files)
+	return files, nil
+}
+
+// dirtyFiles returns the uncommitted changes in dir outside ticker's
+// metadata, which may be dirty during a revert.
+func dirtyFiles(dir string) []string {
+	out, err := gitutil.Run(dir, "status", "--porcelain")
+	if err != nil {
+		return nil
+	}
+	var dirty []string
+	for _, line := range strings.Split(out, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		path := line[3:]
+		if !slices.ContainsFunc(gitutil.MetadataPaths, func(p string) bool { return strings.HasPrefix(path, p) }) {
+			dirty = append(dirty, path)
+		}
+	}
+	return dirty
+}
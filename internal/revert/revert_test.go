@@ -0,0 +1,335 @@
+package revert
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+type fakeTicks struct {
+	tasks      map[string]*ticks.Task
+	records    map[string]*agent.RunRecord
+	reopened   []string
+	humanNotes map[string][]string
+}
+
+func newFakeTicks(tasks ...ticks.Task) *fakeTicks {
+	f := &fakeTicks{
+		tasks:      make(map[string]*ticks.Task),
+		records:    make(map[string]*agent.RunRecord),
+		humanNotes: make(map[string][]string),
+	}
+	for _, t := range tasks {
+		task := t
+		f.tasks[t.ID] = &task
+	}
+	return f
+}
+
+func (f *fakeTicks) GetTask(taskID string) (*ticks.Task, error) {
+	task, ok := f.tasks[taskID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return task, nil
+}
+
+func (f *fakeTicks) ListTasks(epicID string) ([]ticks.Task, error) {
+	var tasks []ticks.Task
+	for _, t := range f.tasks {
+		if t.Parent == epicID {
+			tasks = append(tasks, *t)
+		}
+	}
+	return tasks, nil
+}
+
+func (f *fakeTicks) GetRunRecord(taskID string) (*agent.RunRecord, error) {
+	return f.records[taskID], nil
+}
+
+func (f *fakeTicks) SetRunRecord(taskID string, record *agent.RunRecord) error {
+	f.records[taskID] = record
+	return nil
+}
+
+func (f *fakeTicks) ReopenTask(taskID string) error {
+	f.reopened = append(f.reopened, taskID)
+	f.tasks[taskID].Status = "open"
+	return nil
+}
+
+func (f *fakeTicks) AddHumanNote(issueID, message string) error {
+	f.humanNotes[issueID] = append(f.humanNotes[issueID], message)
+	return nil
+}
+
+// testRepo is a git repository for revert tests.
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init")
+	r.git("config", "user.email", "test@test.com")
+	r.git("config", "user.name", "Test User")
+	r.commit("initial.txt", "initial\n")
+	return r
+}
+
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commit writes content to name and commits it, returning the commit.
+func (r *testRepo) commit(name, content string) string {
+	r.t.Helper()
+	path := filepath.Join(r.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+	r.git("add", name)
+	r.git("commit", "-m", "Change "+name)
+	return r.git("rev-parse", "HEAD")
+}
+
+func (r *testRepo) read(name string) string {
+	r.t.Helper()
+	data, err := os.ReadFile(filepath.Join(r.dir, name))
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func TestNewPlan(t *testing.T) {
+	repo := newTestRepo(t)
+	a1 := repo.commit("a.txt", "a\n")
+	b1 := repo.commit("b.txt", "b\n")
+	a2 := repo.commit("a.txt", "a\na2\n")
+	repo.commit("c.txt", "c\n")
+	b2 := repo.commit("b.txt", "b\nb2\n")
+
+	f := newFakeTicks(
+		ticks.Task{ID: "t1", Title: "Add a", Status: "closed", Parent: "epic"},
+		ticks.Task{ID: "t2", Title: "Add b", Status: "closed", Parent: "epic"},
+	)
+	f.records["t1"] = &agent.RunRecord{Commits: []string{a1, a2}}
+	f.records["t2"] = &agent.RunRecord{Commits: []string{b1, b2}}
+
+	plan, err := NewPlan(f, repo.dir, "t1")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if got := strings.Join(plan.Files, ","); got != "a.txt" {
+		t.Errorf("Files = %q, want %q", got, "a.txt")
+	}
+	if len(plan.Dependents) != 0 {
+		t.Errorf("Dependents = %+v, want none (later commits touch other files)", plan.Dependents)
+	}
+
+	// Interleaved commits of other tasks only count if they share files
+	plan, err = NewPlan(f, repo.dir, "t2")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if got := strings.Join(plan.Commits, ","); got != b1+","+b2 {
+		t.Errorf("Commits = %s, want %s,%s", got, b1, b2)
+	}
+	if len(plan.Dependents) != 0 {
+		t.Errorf("Dependents = %+v, want none", plan.Dependents)
+	}
+}
+
+func TestNewPlan_PathsWithSpaces(t *testing.T) {
+	repo := newTestRepo(t)
+	a1 := repo.commit("docs/release notes.md", "v1\n")
+	repo.commit("other.txt", "other\n")
+	b1 := repo.commit("docs/release notes.md", "v1\nv2\n")
+
+	f := newFakeTicks(
+		ticks.Task{ID: "t1", Title: "Write notes", Status: "closed", Parent: "epic"},
+		ticks.Task{ID: "t2", Title: "Update notes", Status: "closed", Parent: "epic"},
+	)
+	f.records["t1"] = &agent.RunRecord{Commits: []string{a1}}
+	f.records["t2"] = &agent.RunRecord{Commits: []string{b1}}
+
+	plan, err := NewPlan(f, repo.dir, "t1")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if got := strings.Join(plan.Files, ","); got != "docs/release notes.md" {
+		t.Errorf("Files = %q, want %q", got, "docs/release notes.md")
+	}
+	if len(plan.Dependents) != 1 || plan.Dependents[0].Commit != b1 || plan.Dependents[0].TaskID != "t2" {
+		t.Errorf("Dependents = %+v, want %s by t2", plan.Dependents, b1)
+	}
+}
+
+func TestNewPlan_Dependents(t *testing.T) {
+	repo := newTestRepo(t)
+	a1 := repo.commit("a.txt", "a\n")
+	later := repo.commit("a.txt", "a\nmore\n")
+	repo.commit("other.txt", "other\n")
+
+	f := newFakeTicks(
+		ticks.Task{ID: "t1", Title: "Add a", Status: "closed", Parent: "epic"},
+		ticks.Task{ID: "t2", Title: "Extend a", Status: "closed", Parent: "epic"},
+	)
+	f.records["t1"] = &agent.RunRecord{Commits: []string{a1}}
+	f.records["t2"] = &agent.RunRecord{Commits: []string{later}}
+
+	plan, err := NewPlan(f, repo.dir, "t1")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if len(plan.Dependents) != 1 {
+		t.Fatalf("Dependents = %+v, want one", plan.Dependents)
+	}
+	dep := plan.Dependents[0]
+	if dep.Commit != later || dep.TaskID != "t2" || strings.Join(dep.Files, ",") != "a.txt" || dep.Subject != "Change a.txt" {
+		t.Errorf("Dependent = %+v, want %s by t2 on a.txt", dep, later)
+	}
+}
+
+func TestNewPlan_Errors(t *testing.T) {
+	repo := newTestRepo(t)
+	f := newFakeTicks(
+		ticks.Task{ID: "none", Title: "No commits", Status: "closed"},
+		ticks.Task{ID: "gone", Title: "Elsewhere", Status: "closed"},
+	)
+	f.records["gone"] = &agent.RunRecord{Commits: []string{"0123456789abcdef0123456789abcdef01234567"}}
+
+	if _, err := NewPlan(f, repo.dir, "none"); !errors.Is(err, ErrNoCommits) {
+		t.Errorf("NewPlan(no commits) error = %v, want ErrNoCommits", err)
+	}
+	if _, err := NewPlan(f, repo.dir, "gone"); err == nil || !strings.Contains(err.Error(), "not on the current branch") {
+		t.Errorf("NewPlan(unknown commit) error = %v, want not on the current branch", err)
+	}
+	if _, err := NewPlan(f, repo.dir, "missing"); err == nil {
+		t.Error("NewPlan(missing task) expected error")
+	}
+}
+
+func TestRevert(t *testing.T) {
+	repo := newTestRepo(t)
+	a1 := repo.commit("a.txt", "a\n")
+	b1 := repo.commit("b.txt", "b\n")
+	a2 := repo.commit("a.txt", "a\na2\n")
+
+	f := newFakeTicks(ticks.Task{ID: "t1", Title: "Add a", Status: "closed"})
+	f.records["t1"] = &agent.RunRecord{Model: "opus", Commits: []string{a1, a2}}
+
+	// ticker's own metadata may be dirty
+	if err := os.MkdirAll(filepath.Join(repo.dir, ".tick"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo.dir, ".tick", "t1.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := NewPlan(f, repo.dir, "t1")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	revertCommit, err := Revert(f, plan)
+	if err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+
+	if got := repo.git("rev-parse", "HEAD"); got != revertCommit {
+		t.Errorf("Revert() = %s, want HEAD %s", revertCommit, got)
+	}
+	if repo.read("a.txt") != "" {
+		t.Error("expected a.txt to be gone after the revert")
+	}
+	if repo.read("b.txt") != "b\n" {
+		t.Error("expected b.txt (another task's work) to be untouched")
+	}
+	if msg := repo.git("log", "-1", "--format=%B"); !strings.HasPrefix(msg, "Revert t1: Add a") || !strings.Contains(msg, a1) || !strings.Contains(msg, a2) || strings.Contains(msg, b1) {
+		t.Errorf("revert commit message = %q", msg)
+	}
+
+	if len(f.reopened) != 1 || f.reopened[0] != "t1" {
+		t.Errorf("reopened = %v, want [t1]", f.reopened)
+	}
+	notes := f.humanNotes["t1"]
+	if len(notes) != 1 || !strings.Contains(notes[0], gitutil.ShortSHA(revertCommit)) || !strings.Contains(notes[0], gitutil.ShortSHA(a1)) {
+		t.Errorf("notes = %v, want a note naming the revert and reverted commits", notes)
+	}
+	if record := f.records["t1"]; len(record.Commits) != 0 || record.Model != "opus" {
+		t.Errorf("run record = %+v, want commits cleared and the rest kept", record)
+	}
+}
+
+func TestRevert_DirtyTree(t *testing.T) {
+	repo := newTestRepo(t)
+	a1 := repo.commit("a.txt", "a\n")
+	f := newFakeTicks(ticks.Task{ID: "t1", Title: "Add a", Status: "closed"})
+	f.records["t1"] = &agent.RunRecord{Commits: []string{a1}}
+
+	plan, err := NewPlan(f, repo.dir, "t1")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo.dir, "initial.txt"), []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Revert(f, plan); err == nil || !strings.Contains(err.Error(), "initial.txt") {
+		t.Errorf("Revert() error = %v, want uncommitted changes error", err)
+	}
+	if got := repo.git("rev-parse", "HEAD"); got != a1 {
+		t.Errorf("HEAD = %s, want unchanged %s", got, a1)
+	}
+	if len(f.reopened) != 0 {
+		t.Errorf("reopened = %v, want none", f.reopened)
+	}
+}
+
+func TestRevert_ConflictAborts(t *testing.T) {
+	repo := newTestRepo(t)
+	a1 := repo.commit("a.txt", "a\n")
+	repo.commit("a.txt", "rewritten\n")
+	f := newFakeTicks(ticks.Task{ID: "t1", Title: "Add a", Status: "closed"})
+	f.records["t1"] = &agent.RunRecord{Commits: []string{a1}}
+
+	plan, err := NewPlan(f, repo.dir, "t1")
+	if err != nil {
+		t.Fatalf("NewPlan() error = %v", err)
+	}
+	head := repo.git("rev-parse", "HEAD")
+
+	if _, err := Revert(f, plan); err == nil {
+		t.Fatal("Revert() expected a conflict error")
+	}
+	if got := repo.git("rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD = %s, want unchanged %s", got, head)
+	}
+	if status := repo.git("status", "--porcelain"); status != "" {
+		t.Errorf("working tree not clean after abort: %q", status)
+	}
+	if len(f.records["t1"].Commits) != 1 || len(f.reopened) != 0 {
+		t.Error("expected the task untouched after a failed revert")
+	}
+}
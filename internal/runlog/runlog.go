@@ -1,13 +1,18 @@
 // Package runlog provides structured logging for ticker runs.
 // Each run creates a JSONL file in .ticker/runs/<run-id>.jsonl that documents
-// every decision and action in the control flow loop.
+// every decision and action in the control flow loop. Optional per-iteration
+// agent transcripts are stored next to it in .ticker/runs/<run-id>/.
 package runlog
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -46,15 +51,22 @@ const (
 	EventPauseEntered EventType = "pause_entered"
 	EventPauseExited  EventType = "pause_exited"
 
-	// Stuck loop detection
+	// Stuck loop detection and escalation
 	EventStuckLoopWarning  EventType = "stuck_loop_warning"
 	EventStuckLoopExceeded EventType = "stuck_loop_exceeded"
+	EventTaskEscalated     EventType = "task_escalated"
+
+	// Prompt events
+	EventPromptBuilt    EventType = "prompt_built"
+	EventNotesCompacted EventType = "notes_compacted"
 
 	// Agent events
 	EventAgentStarted   EventType = "agent_started"
 	EventAgentCompleted EventType = "agent_completed"
 	EventAgentTimeout   EventType = "agent_timeout"
 	EventAgentError     EventType = "agent_error"
+	EventModelFallback  EventType = "model_fallback"
+	EventTranscript     EventType = "transcript_saved"
 
 	// Signal events
 	EventSignalDetected EventType = "signal_detected"
@@ -65,6 +77,7 @@ const (
 	EventVerifierResult        EventType = "verifier_result"
 	EventVerificationCompleted EventType = "verification_completed"
 	EventTaskReopened          EventType = "task_reopened"
+	EventReviewAgentFallback   EventType = "review_agent_fallback"
 	EventTaskCompleted         EventType = "task_completed"
 
 	// Watch mode events
@@ -97,52 +110,40 @@ type Logger struct {
 // New creates a new run logger.
 // Creates .ticker/runs/ directory if needed and opens the log file.
 func New(epicID string) (*Logger, error) {
-	runID := generateRunID()
-
-	// Create .ticker/runs directory
-	dir := filepath.Join(".ticker", "runs")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("creating run log directory: %w", err)
-	}
-
-	// Open log file
-	filePath := filepath.Join(dir, runID+".jsonl")
-	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("opening run log file: %w", err)
-	}
-
-	return &Logger{
-		runID:    runID,
-		epicID:   epicID,
-		file:     file,
-		filePath: filePath,
-	}, nil
+	return NewWithWorkDir(epicID, "")
 }
 
 // NewWithWorkDir creates a new run logger in a specific working directory.
+// Runs started in the same second (parallel epics) get distinct run IDs.
 func NewWithWorkDir(epicID, workDir string) (*Logger, error) {
-	runID := generateRunID()
-
 	// Create .ticker/runs directory in workDir
 	dir := filepath.Join(workDir, ".ticker", "runs")
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, fmt.Errorf("creating run log directory: %w", err)
 	}
 
-	// Open log file
-	filePath := filepath.Join(dir, runID+".jsonl")
-	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	if err != nil {
-		return nil, fmt.Errorf("opening run log file: %w", err)
+	// Open a log file no other run is using
+	base := generateRunID()
+	for n := 1; ; n++ {
+		runID := base
+		if n > 1 {
+			runID = fmt.Sprintf("%s-%d", base, n)
+		}
+		filePath := filepath.Join(dir, runID+".jsonl")
+		file, err := os.OpenFile(filePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("opening run log file: %w", err)
+		}
+		return &Logger{
+			runID:    runID,
+			epicID:   epicID,
+			file:     file,
+			filePath: filePath,
+		}, nil
 	}
-
-	return &Logger{
-		runID:    runID,
-		epicID:   epicID,
-		file:     file,
-		filePath: filePath,
-	}, nil
 }
 
 // RunID returns the unique identifier for this run.
@@ -155,6 +156,40 @@ func (l *Logger) FilePath() string {
 	return l.filePath
 }
 
+// SocketPath returns where the control socket of runID is while it runs:
+// <runsDir>/<run-id>.sock. Headless runs listen there for `ticker attach`.
+func SocketPath(runsDir, runID string) string {
+	return filepath.Join(runsDir, runID+".sock")
+}
+
+// SocketPath returns the control socket path for this run.
+func (l *Logger) SocketPath() string {
+	return SocketPath(filepath.Dir(l.filePath), l.runID)
+}
+
+// ListSockets returns the IDs of runs in runsDir that have a control
+// socket, newest first. A socket left behind by a run that crashed is
+// listed too; connecting to it fails.
+func ListSockets(runsDir string) ([]string, error) {
+	entries, err := os.ReadDir(runsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var runIDs []string
+	for _, e := range entries {
+		if e.Type()&os.ModeSocket == 0 || !strings.HasSuffix(e.Name(), ".sock") {
+			continue
+		}
+		runIDs = append(runIDs, strings.TrimSuffix(e.Name(), ".sock"))
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(runIDs)))
+	return runIDs, nil
+}
+
 // Close closes the log file.
 func (l *Logger) Close() error {
 	l.mu.Lock()
@@ -356,6 +391,9 @@ func (l *Logger) LogIterationStart(iteration int, taskID, taskTitle string) {
 type IterationEndData struct {
 	Iteration int           `json:"iteration"`
 	TaskID    string        `json:"task_id"`
+	Agent     string        `json:"agent,omitempty"`
+	Model     string        `json:"model,omitempty"`
+	Resumed   bool          `json:"resumed,omitempty"`
 	Duration  time.Duration `json:"duration"`
 	TokensIn  int           `json:"tokens_in"`
 	TokensOut int           `json:"tokens_out"`
@@ -363,11 +401,15 @@ type IterationEndData struct {
 	Signal    string        `json:"signal,omitempty"`
 	Error     string        `json:"error,omitempty"`
 	IsTimeout bool          `json:"is_timeout,omitempty"`
+	Commits   []string      `json:"commits,omitempty"`
 }
 
 // LogIterationEnd logs the end of an iteration.
 func (l *Logger) LogIterationEnd(data IterationEndData) {
 	msg := fmt.Sprintf("Iteration %d: completed in %v", data.Iteration, data.Duration)
+	if n := len(data.Commits); n > 0 {
+		msg += fmt.Sprintf(" (%d commits)", n)
+	}
 	if data.Error != "" {
 		msg = fmt.Sprintf("Iteration %d: error - %s", data.Iteration, data.Error)
 	} else if data.IsTimeout {
@@ -500,20 +542,78 @@ func (l *Logger) LogStuckLoopExceeded(taskID string, retryCount, maxRetries int)
 	})
 }
 
+// TaskEscalatedData contains escalation event data.
+type TaskEscalatedData struct {
+	TaskID        string        `json:"task_id"`
+	Tier          int           `json:"tier"`
+	MaxTier       int           `json:"max_tier"`
+	Reason        string        `json:"reason"`
+	Model         string        `json:"model,omitempty"`
+	Timeout       time.Duration `json:"timeout,omitempty"`
+	ExtraThinking bool          `json:"extra_thinking,omitempty"`
+}
+
+// LogTaskEscalated logs a task moving up the escalation ladder after a failed attempt.
+func (l *Logger) LogTaskEscalated(data TaskEscalatedData) {
+	l.log(EventTaskEscalated, fmt.Sprintf("Escalating task %s to tier %d/%d (%s)", data.TaskID, data.Tier, data.MaxTier, data.Reason), data)
+}
+
+// --- Prompt Events ---
+
+// PromptBuiltData contains the size of an iteration prompt by section.
+// Sizes are estimated tokens.
+type PromptBuiltData struct {
+	TaskID        string         `json:"task_id"`
+	Tokens        int            `json:"tokens"`
+	Budget        int            `json:"budget,omitempty"`
+	Sections      map[string]int `json:"sections"`
+	NotesIncluded int            `json:"notes_included"`
+	NotesOmitted  int            `json:"notes_omitted,omitempty"`
+}
+
+// LogPromptBuilt logs the size of the prompt built for a task.
+func (l *Logger) LogPromptBuilt(data PromptBuiltData) {
+	msg := fmt.Sprintf("Prompt for task %s: ~%d tokens", data.TaskID, data.Tokens)
+	if data.NotesOmitted > 0 {
+		msg += fmt.Sprintf(" (%d older notes omitted)", data.NotesOmitted)
+	}
+	l.log(EventPromptBuilt, msg, data)
+}
+
+// NotesCompactedData contains notes compaction event data.
+type NotesCompactedData struct {
+	EpicID       string `json:"epic_id"`
+	Notes        int    `json:"notes"`
+	TokensBefore int    `json:"tokens_before"`
+	TokensAfter  int    `json:"tokens_after"`
+}
+
+// LogNotesCompacted logs when epic notes are summarized into a digest.
+func (l *Logger) LogNotesCompacted(epicID string, notes, tokensBefore, tokensAfter int) {
+	l.log(EventNotesCompacted, fmt.Sprintf("Compacted %d notes for epic %s (~%d -> ~%d tokens)", notes, epicID, tokensBefore, tokensAfter), NotesCompactedData{
+		EpicID:       epicID,
+		Notes:        notes,
+		TokensBefore: tokensBefore,
+		TokensAfter:  tokensAfter,
+	})
+}
+
 // --- Agent Events ---
 
 // AgentStartedData contains agent start event data.
 type AgentStartedData struct {
 	TaskID       string        `json:"task_id"`
+	Agent        string        `json:"agent,omitempty"`
 	PromptLength int           `json:"prompt_length"`
 	Timeout      time.Duration `json:"timeout"`
 	WorkDir      string        `json:"work_dir,omitempty"`
 }
 
 // LogAgentStarted logs agent invocation start.
-func (l *Logger) LogAgentStarted(taskID string, promptLength int, timeout time.Duration, workDir string) {
-	l.log(EventAgentStarted, fmt.Sprintf("Starting agent for task %s", taskID), AgentStartedData{
+func (l *Logger) LogAgentStarted(taskID, agentName string, promptLength int, timeout time.Duration, workDir string) {
+	l.log(EventAgentStarted, fmt.Sprintf("Starting agent %s for task %s", agentName, taskID), AgentStartedData{
 		TaskID:       taskID,
+		Agent:        agentName,
 		PromptLength: promptLength,
 		Timeout:      timeout,
 		WorkDir:      workDir,
@@ -565,6 +665,46 @@ func (l *Logger) LogAgentError(taskID, errMsg string) {
 	})
 }
 
+// ModelFallbackData contains model fallback event data.
+type ModelFallbackData struct {
+	TaskID string   `json:"task_id"`
+	From   []string `json:"from"`
+	To     string   `json:"to"`
+}
+
+// LogModelFallback logs that unavailable models were skipped in favor of a fallback.
+func (l *Logger) LogModelFallback(taskID string, from []string, to string) {
+	l.log(EventModelFallback, fmt.Sprintf("Task %s fell back from %v to %s", taskID, from, to), ModelFallbackData{
+		TaskID: taskID,
+		From:   from,
+		To:     to,
+	})
+}
+
+// TranscriptData contains transcript event data.
+type TranscriptData struct {
+	TaskID    string `json:"task_id"`
+	Iteration int    `json:"iteration"`
+	Path      string `json:"path"`
+	Error     string `json:"error,omitempty"`
+}
+
+// LogTranscript logs that an iteration's transcript was saved, or why it
+// could not be.
+func (l *Logger) LogTranscript(taskID string, iteration int, errMsg string) {
+	path := TranscriptPath(filepath.Dir(l.filePath), l.runID, iteration)
+	msg := fmt.Sprintf("Saved transcript of iteration %d to %s", iteration, path)
+	if errMsg != "" {
+		msg = fmt.Sprintf("Could not save transcript of iteration %d: %s", iteration, errMsg)
+	}
+	l.log(EventTranscript, msg, TranscriptData{
+		TaskID:    taskID,
+		Iteration: iteration,
+		Path:      path,
+		Error:     errMsg,
+	})
+}
+
 // --- Signal Events ---
 
 // SignalDetectedData contains signal detection event data.
@@ -624,6 +764,7 @@ type VerifierResultData struct {
 	TaskID   string        `json:"task_id"`
 	Verifier string        `json:"verifier"`
 	Passed   bool          `json:"passed"`
+	Skipped  bool          `json:"skipped,omitempty"`
 	Output   string        `json:"output"`
 	Error    string        `json:"error,omitempty"`
 	Duration time.Duration `json:"duration"`
@@ -633,7 +774,9 @@ type VerifierResultData struct {
 // LogVerifierResult logs detailed results from a single verifier.
 func (l *Logger) LogVerifierResult(data VerifierResultData) {
 	status := "passed"
-	if !data.Passed {
+	if data.Skipped {
+		status = "skipped"
+	} else if !data.Passed {
 		status = "failed"
 	}
 	msg := fmt.Sprintf("Verifier %s %s for task %s", data.Verifier, status, data.TaskID)
@@ -679,6 +822,27 @@ func (l *Logger) LogTaskReopened(taskID, reason string) {
 	})
 }
 
+// ReviewAgentFallbackData contains review agent fallback event data.
+type ReviewAgentFallbackData struct {
+	TaskID string `json:"task_id"`
+	From   string `json:"from"`
+	To     string `json:"to,omitempty"` // empty when the review was skipped
+}
+
+// LogReviewAgentFallback logs when a task's agent can't review read-only and
+// another agent reviews instead, or (to empty) the review is skipped.
+func (l *Logger) LogReviewAgentFallback(taskID, from, to string) {
+	msg := fmt.Sprintf("Agent %s cannot review task %s read-only, reviewing with %s", from, taskID, to)
+	if to == "" {
+		msg = fmt.Sprintf("Agent %s cannot review task %s read-only and no built-in agent is available, skipping review", from, taskID)
+	}
+	l.log(EventReviewAgentFallback, msg, ReviewAgentFallbackData{
+		TaskID: taskID,
+		From:   from,
+		To:     to,
+	})
+}
+
 // TaskCompletedData contains task completed event data.
 type TaskCompletedData struct {
 	TaskID           string `json:"task_id"`
@@ -761,6 +925,8 @@ const (
 	EventContextSaveFailed          EventType = "context_save_failed"
 	EventContextGenerationCompleted EventType = "context_generation_completed"
 	EventContextLoadFailed          EventType = "context_load_failed"
+	EventContextStale               EventType = "context_stale"
+	EventContextUpdated             EventType = "context_updated"
 )
 
 // ContextSkippedData contains context skipped event data.
@@ -779,6 +945,36 @@ func (l *Logger) LogContextSkipped(epicID, reason string, taskCount int) {
 	})
 }
 
+// ContextStaleData contains context staleness event data.
+type ContextStaleData struct {
+	EpicID string `json:"epic_id"`
+	Reason string `json:"reason"`
+}
+
+// LogContextStale logs when existing context is regenerated because it is stale.
+func (l *Logger) LogContextStale(epicID, reason string) {
+	l.log(EventContextStale, fmt.Sprintf("Context for epic %s is stale (%s), regenerating", epicID, reason), ContextStaleData{
+		EpicID: epicID,
+		Reason: reason,
+	})
+}
+
+// ContextUpdatedData contains context update event data.
+type ContextUpdatedData struct {
+	EpicID        string `json:"epic_id"`
+	TaskID        string `json:"task_id"`
+	ContentLength int    `json:"content_length"`
+}
+
+// LogContextUpdated logs when context is updated after a task completes.
+func (l *Logger) LogContextUpdated(epicID, taskID string, contentLength int) {
+	l.log(EventContextUpdated, fmt.Sprintf("Context for epic %s updated after task %s (%d bytes)", epicID, taskID, contentLength), ContextUpdatedData{
+		EpicID:        epicID,
+		TaskID:        taskID,
+		ContentLength: contentLength,
+	})
+}
+
 // ContextErrorData contains context error event data.
 type ContextErrorData struct {
 	EpicID string `json:"epic_id"`
@@ -3,6 +3,7 @@ package runlog
 import (
 	"bufio"
 	"encoding/json"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -66,6 +67,71 @@ func TestNewWithWorkDir(t *testing.T) {
 	}
 }
 
+func TestNewWithWorkDir_DistinctRunIDs(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Parallel epics start their runs in the same second
+	seen := make(map[string]bool)
+	for range 3 {
+		logger, err := NewWithWorkDir("test-epic", tmpDir)
+		if err != nil {
+			t.Fatalf("NewWithWorkDir() failed: %v", err)
+		}
+		defer logger.Close()
+		if seen[logger.RunID()] {
+			t.Errorf("RunID() = %s, already used by another run", logger.RunID())
+		}
+		seen[logger.RunID()] = true
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	logger, err := NewWithWorkDir("test-epic", tmpDir)
+	if err != nil {
+		t.Fatalf("NewWithWorkDir() failed: %v", err)
+	}
+	defer logger.Close()
+
+	runsDir := filepath.Join(tmpDir, ".ticker", "runs")
+	want := filepath.Join(runsDir, logger.RunID()+".sock")
+	if got := logger.SocketPath(); got != want {
+		t.Errorf("SocketPath() = %s, want %s", got, want)
+	}
+	if got := SocketPath(runsDir, logger.RunID()); got != want {
+		t.Errorf("SocketPath(runsDir, runID) = %s, want %s", got, want)
+	}
+}
+
+func TestListSockets(t *testing.T) {
+	runsDir := t.TempDir()
+
+	if ids, err := ListSockets(filepath.Join(runsDir, "missing")); err != nil || len(ids) != 0 {
+		t.Errorf("ListSockets(missing dir) = %v, %v, want none", ids, err)
+	}
+
+	for _, id := range []string{"20250114-093012", "20250115-101500"} {
+		l, err := net.Listen("unix", SocketPath(runsDir, id))
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		defer l.Close()
+	}
+	// Run logs and other files aren't sockets
+	os.WriteFile(filepath.Join(runsDir, "20250116-080000.jsonl"), nil, 0644)
+	os.WriteFile(filepath.Join(runsDir, "20250116-080000.sock"), nil, 0644)
+
+	ids, err := ListSockets(runsDir)
+	if err != nil {
+		t.Fatalf("ListSockets() error = %v", err)
+	}
+	want := []string{"20250115-101500", "20250114-093012"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("ListSockets() = %v, want %v", ids, want)
+	}
+}
+
 func TestLogRunStart(t *testing.T) {
 	tmpDir := t.TempDir()
 	logger, err := NewWithWorkDir("test-epic", tmpDir)
@@ -146,6 +212,7 @@ func TestLogIterationEvents(t *testing.T) {
 		TokensIn:  1000,
 		TokensOut: 500,
 		Cost:      0.05,
+		Commits:   []string{"abc123"},
 	})
 	logger.Close()
 
@@ -160,6 +227,107 @@ func TestLogIterationEvents(t *testing.T) {
 	if events[1].Type != EventIterationEnd {
 		t.Errorf("second event Type = %s, want %s", events[1].Type, EventIterationEnd)
 	}
+	if !strings.Contains(events[1].Message, "(1 commits)") {
+		t.Errorf("second event Message = %q, want commit count", events[1].Message)
+	}
+}
+
+func TestLogModelFallback(t *testing.T) {
+	tmpDir := t.TempDir()
+	logger, err := NewWithWorkDir("test-epic", tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	logger.LogModelFallback("task-1", []string{"opus"}, "sonnet")
+	logger.Close()
+
+	events := readLogFile(t, logger.FilePath())
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	if events[0].Type != EventModelFallback {
+		t.Errorf("Type = %s, want %s", events[0].Type, EventModelFallback)
+	}
+	if !strings.Contains(events[0].Message, "opus") || !strings.Contains(events[0].Message, "sonnet") {
+		t.Errorf("Message = %q, want both models mentioned", events[0].Message)
+	}
+}
+
+func TestLogTaskEscalated(t *testing.T) {
+	tmpDir := t.TempDir()
+	logger, err := NewWithWorkDir("test-epic", tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	logger.LogTaskEscalated(TaskEscalatedData{
+		TaskID:  "task-1",
+		Tier:    1,
+		MaxTier: 2,
+		Reason:  "verification failed",
+		Model:   "opus",
+		Timeout: time.Hour,
+	})
+	logger.Close()
+
+	events := readLogFile(t, logger.FilePath())
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	if events[0].Type != EventTaskEscalated {
+		t.Errorf("Type = %s, want %s", events[0].Type, EventTaskEscalated)
+	}
+
+	var data TaskEscalatedData
+	if err := json.Unmarshal(events[0].Data, &data); err != nil {
+		t.Fatalf("failed to unmarshal data: %v", err)
+	}
+	if data.Tier != 1 || data.Model != "opus" || data.Reason != "verification failed" {
+		t.Errorf("data = %+v, want tier 1 opus after verification failed", data)
+	}
+}
+
+func TestLogPromptEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+	logger, err := NewWithWorkDir("test-epic", tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	logger.LogPromptBuilt(PromptBuiltData{
+		TaskID:        "task-1",
+		Tokens:        1200,
+		Budget:        1500,
+		Sections:      map[string]int{"task": 200, "epic_notes": 400},
+		NotesIncluded: 10,
+		NotesOmitted:  3,
+	})
+	logger.LogNotesCompacted("test-epic", 40, 5000, 300)
+	logger.Close()
+
+	events := readLogFile(t, logger.FilePath())
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != EventPromptBuilt {
+		t.Errorf("first event Type = %s, want %s", events[0].Type, EventPromptBuilt)
+	}
+	if !strings.Contains(events[0].Message, "3 older notes omitted") {
+		t.Errorf("Message = %q, want omitted notes mentioned", events[0].Message)
+	}
+
+	var data PromptBuiltData
+	if err := json.Unmarshal(events[0].Data, &data); err != nil {
+		t.Fatalf("failed to unmarshal data: %v", err)
+	}
+	if data.Sections["epic_notes"] != 400 || data.Tokens != 1200 {
+		t.Errorf("data = %+v, want 1200 tokens with 400 in epic_notes", data)
+	}
+
+	if events[1].Type != EventNotesCompacted {
+		t.Errorf("second event Type = %s, want %s", events[1].Type, EventNotesCompacted)
+	}
 }
 
 func TestLogSignalEvents(t *testing.T) {
@@ -0,0 +1,127 @@
+package runlog
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// transcriptExt is the file extension of recorded iteration transcripts.
+const transcriptExt = ".jsonl.gz"
+
+// TranscriptPath returns where the transcript of iteration is stored for
+// runID: <runsDir>/<run-id>/iter-<n>.jsonl.gz.
+func TranscriptPath(runsDir, runID string, iteration int) string {
+	return filepath.Join(runsDir, runID, fmt.Sprintf("iter-%d%s", iteration, transcriptExt))
+}
+
+// CreateTranscript creates the gzip-compressed transcript file for an
+// iteration of this run. The caller must close it.
+func (l *Logger) CreateTranscript(iteration int) (io.WriteCloser, error) {
+	path := TranscriptPath(filepath.Dir(l.filePath), l.runID, iteration)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("creating transcript directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating transcript: %w", err)
+	}
+	return &gzipFile{Writer: gzip.NewWriter(file), file: file}, nil
+}
+
+// gzipFile closes both the gzip stream and the underlying file.
+type gzipFile struct {
+	*gzip.Writer
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	err := g.Writer.Close()
+	if cerr := g.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// OpenTranscript opens the transcript of an iteration of runID, decompressed.
+func OpenTranscript(runsDir, runID string, iteration int) (io.ReadCloser, error) {
+	file, err := os.Open(TranscriptPath(runsDir, runID, iteration))
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("reading transcript: %w", err)
+	}
+	return &gunzipFile{Reader: zr, file: file}, nil
+}
+
+// gunzipFile closes both the gzip reader and the underlying file.
+type gunzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g *gunzipFile) Close() error {
+	err := g.Reader.Close()
+	if cerr := g.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// ListTranscripts returns the iterations of runID that have a transcript,
+// in order. Returns nil if none were recorded.
+func ListTranscripts(runsDir, runID string) ([]int, error) {
+	entries, err := os.ReadDir(filepath.Join(runsDir, runID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var iterations []int
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, "iter-") || !strings.HasSuffix(name, transcriptExt) {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "iter-"), transcriptExt))
+		if err != nil {
+			continue
+		}
+		iterations = append(iterations, n)
+	}
+	sort.Ints(iterations)
+	return iterations, nil
+}
+
+// ReadEvents reads all events from a run log file.
+func ReadEvents(path string) ([]Event, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
@@ -0,0 +1,91 @@
+package runlog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTranscript_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	logger, err := NewWithWorkDir("test-epic", tmpDir)
+	if err != nil {
+		t.Fatalf("NewWithWorkDir() failed: %v", err)
+	}
+	defer logger.Close()
+	runsDir := filepath.Join(tmpDir, ".ticker", "runs")
+
+	for _, n := range []int{10, 2} {
+		w, err := logger.CreateTranscript(n)
+		if err != nil {
+			t.Fatalf("CreateTranscript(%d) error = %v", n, err)
+		}
+		if _, err := w.Write([]byte(`{"type":"result"}` + "\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	path := TranscriptPath(runsDir, logger.RunID(), 2)
+	if filepath.Base(path) != "iter-2.jsonl.gz" {
+		t.Errorf("TranscriptPath() = %s, want iter-2.jsonl.gz", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("transcript file missing: %v", err)
+	}
+
+	iterations, err := ListTranscripts(runsDir, logger.RunID())
+	if err != nil {
+		t.Fatalf("ListTranscripts() error = %v", err)
+	}
+	if !reflect.DeepEqual(iterations, []int{2, 10}) {
+		t.Errorf("ListTranscripts() = %v, want [2 10]", iterations)
+	}
+
+	r, err := OpenTranscript(runsDir, logger.RunID(), 10)
+	if err != nil {
+		t.Fatalf("OpenTranscript() error = %v", err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("reading transcript: %v", err)
+	}
+	if string(data) != `{"type":"result"}`+"\n" {
+		t.Errorf("transcript = %q, want the written line", data)
+	}
+}
+
+func TestListTranscripts_NoneRecorded(t *testing.T) {
+	iterations, err := ListTranscripts(t.TempDir(), "20250101-000000")
+	if err != nil || iterations != nil {
+		t.Errorf("ListTranscripts() = %v, %v, want nil, nil", iterations, err)
+	}
+}
+
+func TestLogTranscript(t *testing.T) {
+	tmpDir := t.TempDir()
+	logger, err := NewWithWorkDir("test-epic", tmpDir)
+	if err != nil {
+		t.Fatalf("NewWithWorkDir() failed: %v", err)
+	}
+
+	logger.LogIterationStart(3, "task-1", "First task")
+	logger.LogTranscript("task-1", 3, "")
+	logger.Close()
+
+	events, err := ReadEvents(logger.FilePath())
+	if err != nil {
+		t.Fatalf("ReadEvents() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("ReadEvents() returned %d events, want 2", len(events))
+	}
+	if events[0].Type != EventIterationStart || events[1].Type != EventTranscript {
+		t.Errorf("event types = %s, %s, want %s, %s", events[0].Type, events[1].Type, EventIterationStart, EventTranscript)
+	}
+}
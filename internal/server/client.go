@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+// Client calls a Server's API.
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a client for the server at addr, in the forms Listen
+// accepts. A TCP address without a host connects to localhost.
+func NewClient(addr string) *Client {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		}
+		return &Client{baseURL: "http://ticker", http: &http.Client{Transport: transport}}
+	}
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
+	}
+	return &Client{baseURL: "http://" + addr, http: &http.Client{}}
+}
+
+// Status returns the run status.
+func (c *Client) Status(ctx context.Context) (Status, error) {
+	var st Status
+	err := c.do(ctx, "GET", "/status", &st)
+	return st, err
+}
+
+// Tasks returns the tasks of the run's epic.
+func (c *Client) Tasks(ctx context.Context) ([]ticks.Task, error) {
+	var tasks []ticks.Task
+	err := c.do(ctx, "GET", "/tasks", &tasks)
+	return tasks, err
+}
+
+// SetPaused pauses or resumes the run.
+func (c *Client) SetPaused(ctx context.Context, paused bool) error {
+	path := "/resume"
+	if paused {
+		path = "/pause"
+	}
+	return c.do(ctx, "POST", path, nil)
+}
+
+// Stop asks the run to end once the current iteration is done.
+func (c *Client) Stop(ctx context.Context) error {
+	return c.do(ctx, "POST", "/stop", nil)
+}
+
+// Events reads the event stream, calling fn for each message, starting
+// with the "snapshot". Returns nil when the server ends the stream, or
+// ctx's error once it is cancelled.
+func (c *Client) Events(ctx context.Context, fn func(Message)) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/events", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+
+	// Lines can be long (a snapshot holds the iteration's output), so read
+	// them whole rather than with a size-limited Scanner
+	r := bufio.NewReader(resp.Body)
+	for {
+		line, err := r.ReadString('\n')
+		if data, ok := strings.CutPrefix(strings.TrimRight(line, "\n"), "data: "); ok {
+			var msg Message
+			if jsonErr := json.Unmarshal([]byte(data), &msg); jsonErr == nil {
+				fn(msg)
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// do sends a request and decodes the JSON response into v (if not nil).
+func (c *Client) do(ctx context.Context, method, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return responseError(resp)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// responseError returns the error reported in an error response.
+func responseError(resp *http.Response) error {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if json.NewDecoder(resp.Body).Decode(&body) == nil && body.Error != "" {
+		return errors.New(body.Error)
+	}
+	return fmt.Errorf("unexpected response: %s", resp.Status)
+}
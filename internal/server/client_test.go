@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pengelbrecht/ticker/internal/checkpoint"
+)
+
+func TestClient(t *testing.T) {
+	s, _, _, config, _ := newTestServer(t)
+	defer s.Close()
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	c := NewClient(strings.TrimPrefix(ts.URL, "http://"))
+	ctx := context.Background()
+
+	st, err := c.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if st.RunID != "run-1" || st.State != StateRunning {
+		t.Errorf("Status() = %+v, want run-1 running", st)
+	}
+
+	if err := c.SetPaused(ctx, true); err != nil {
+		t.Fatalf("SetPaused(true) error = %v", err)
+	}
+	if paused := <-config.PauseChan; !paused {
+		t.Error("PauseChan = false after SetPaused(true)")
+	}
+	if err := c.SetPaused(ctx, false); err != nil {
+		t.Fatalf("SetPaused(false) error = %v", err)
+	}
+	if paused := <-config.PauseChan; paused {
+		t.Error("PauseChan = true after SetPaused(false)")
+	}
+
+	if err := c.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	select {
+	case <-config.StopChan:
+	default:
+		t.Error("StopChan not closed after Stop()")
+	}
+}
+
+func TestClient_Error(t *testing.T) {
+	s := New(newFakeTicks(), checkpoint.NewManagerWithDir(t.TempDir()))
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	c := NewClient(strings.TrimPrefix(ts.URL, "http://"))
+
+	// No run attached: the server's error message is returned
+	err := c.Stop(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no run") {
+		t.Errorf("Stop() error = %v, want the server's no-run error", err)
+	}
+}
+
+func TestClient_UnixSocket(t *testing.T) {
+	s, _, _, _, _ := newTestServer(t)
+	defer s.Close()
+
+	path := filepath.Join(t.TempDir(), "run.sock")
+	l, err := Listen("unix:"+path, false)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	go s.Serve(l)
+	defer l.Close()
+
+	st, err := NewClient("unix:" + path).Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() over unix socket error = %v", err)
+	}
+	if st.EpicID != "epic-1" {
+		t.Errorf("Status().EpicID = %q, want %q", st.EpicID, "epic-1")
+	}
+}
+
+func TestNewClient_Addr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{addr: ":8080", want: "http://localhost:8080"},
+		{addr: "10.0.0.5:8080", want: "http://10.0.0.5:8080"},
+		{addr: "unix:/tmp/ticker.sock", want: "http://ticker"},
+	}
+	for _, tt := range tests {
+		if got := NewClient(tt.addr).baseURL; got != tt.want {
+			t.Errorf("NewClient(%q).baseURL = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/engine"
+)
+
+// Message is one event on the event stream. Data holds the payload type
+// named in its doc comment for each Type.
+type Message struct {
+	Type   string          `json:"type"`
+	EpicID string          `json:"epic_id,omitempty"`
+	Time   time.Time       `json:"time"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// RunData is the payload of "run_start" and "run_end".
+type RunData struct {
+	RunID      string `json:"run_id,omitempty"`
+	ExitReason string `json:"exit_reason,omitempty"`
+}
+
+// IterationStartData is the payload of "iteration_start".
+type IterationStartData struct {
+	Iteration int    `json:"iteration"`
+	TaskID    string `json:"task_id"`
+	TaskTitle string `json:"task_title"`
+}
+
+// IterationEndData is the payload of "iteration_end".
+type IterationEndData struct {
+	Iteration  int     `json:"iteration"`
+	TaskID     string  `json:"task_id"`
+	Model      string  `json:"model,omitempty"`
+	TokensIn   int     `json:"tokens_in"`
+	TokensOut  int     `json:"tokens_out"`
+	Cost       float64 `json:"cost"`
+	DurationMS int64   `json:"duration_ms"`
+	Signal     string  `json:"signal,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// OutputData is the payload of "output": a chunk of agent output text.
+type OutputData struct {
+	Text string `json:"text"`
+}
+
+// AgentStateData is the payload of "agent_state": what changed in the
+// agent's state since the previous agent_state event of the iteration.
+// Output text is sent separately as "output" events.
+type AgentStateData struct {
+	Model     string      `json:"model,omitempty"`
+	Status    string      `json:"status,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Thinking  string      `json:"thinking,omitempty"` // new thinking text
+	ToolStart *ToolData   `json:"tool_start,omitempty"`
+	ToolEnd   *ToolData   `json:"tool_end,omitempty"`
+	NumTurns  int         `json:"num_turns"`
+	Metrics   MetricsData `json:"metrics"`
+}
+
+// ToolData describes a tool invocation. Duration and IsError are only set
+// once the tool has finished.
+type ToolData struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Input      string `json:"input,omitempty"`
+	DurationMS int64  `json:"duration_ms,omitempty"`
+	IsError    bool   `json:"is_error,omitempty"`
+}
+
+// MetricsData is the agent's token usage and cost so far this iteration.
+type MetricsData struct {
+	InputTokens         int     `json:"input_tokens"`
+	OutputTokens        int     `json:"output_tokens"`
+	CacheReadTokens     int     `json:"cache_read_tokens"`
+	CacheCreationTokens int     `json:"cache_creation_tokens"`
+	CostUSD             float64 `json:"cost_usd"`
+}
+
+// SnapshotData is the payload of "snapshot", the first event on every
+// stream: the run status plus the current iteration so far, so a client
+// joining mid-iteration can catch up.
+type SnapshotData struct {
+	Status Status `json:"status"`
+
+	// Output is the agent output of the current iteration so far.
+	Output string `json:"output,omitempty"`
+
+	// Agent is the agent's state so far, as a change from nothing:
+	// Thinking holds all thinking text and ToolStart the active tool.
+	Agent *AgentStateData `json:"agent,omitempty"`
+}
+
+// SignalData is the payload of "signal".
+type SignalData struct {
+	TaskID string `json:"task_id"`
+	Signal string `json:"signal"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// VerificationData is the payload of "verification_start" and
+// "verification_end" (which also sets Passed and Summary).
+type VerificationData struct {
+	TaskID  string `json:"task_id"`
+	Passed  bool   `json:"passed,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// ContextData is the payload of the "context_*" events. Only the fields
+// relevant to the event type are set.
+type ContextData struct {
+	TaskCount int    `json:"task_count,omitempty"`
+	Tokens    int    `json:"tokens,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// PauseData is the payload of "pause".
+type PauseData struct {
+	Paused bool `json:"paused"`
+}
+
+// BudgetData is the payload of "budget".
+type BudgetData struct {
+	Iteration int     `json:"iteration"`
+	TokensIn  int     `json:"tokens_in"`
+	TokensOut int     `json:"tokens_out"`
+	Cost      float64 `json:"cost"`
+	Exceeded  bool    `json:"exceeded,omitempty"`
+	Reason    string  `json:"reason,omitempty"`
+}
+
+// EncodeEvent converts an engine event into a stream Message.
+// Returns false for event types that aren't streamed. AgentStateEvent is
+// streamed as a change from the previous one, so Server encodes it with
+// DiffAgentState instead.
+func EncodeEvent(ev engine.Event) (Message, bool) {
+	info := ev.Info()
+	var typ string
+	var data any
+
+	switch ev := ev.(type) {
+	case engine.IterationStartEvent:
+		typ = "iteration_start"
+		d := IterationStartData{Iteration: ev.Context.Iteration}
+		if ev.Context.Task != nil {
+			d.TaskID = ev.Context.Task.ID
+			d.TaskTitle = ev.Context.Task.Title
+		}
+		data = d
+	case engine.IterationEndEvent:
+		typ = "iteration_end"
+		r := ev.Result
+		d := IterationEndData{
+			Iteration:  r.Iteration,
+			TaskID:     r.TaskID,
+			Model:      r.Model,
+			TokensIn:   r.TokensIn,
+			TokensOut:  r.TokensOut,
+			Cost:       r.Cost,
+			DurationMS: r.Duration.Milliseconds(),
+		}
+		if r.Signal != engine.SignalNone {
+			d.Signal = r.Signal.String()
+		}
+		if r.Error != nil {
+			d.Error = r.Error.Error()
+		}
+		data = d
+	case engine.OutputEvent:
+		typ = "output"
+		data = OutputData{Text: ev.Text}
+	case engine.SignalEvent:
+		typ = "signal"
+		data = SignalData{TaskID: ev.TaskID, Signal: ev.Signal.String(), Reason: ev.Reason}
+	case engine.VerificationStartEvent:
+		typ = "verification_start"
+		data = VerificationData{TaskID: ev.TaskID}
+	case engine.VerificationEndEvent:
+		typ = "verification_end"
+		d := VerificationData{TaskID: ev.TaskID, Passed: true}
+		if ev.Results != nil {
+			d.Passed = ev.Results.AllPassed
+			d.Summary = ev.Results.Summary()
+		}
+		data = d
+	case engine.ContextGeneratingEvent:
+		typ = "context_generating"
+		data = ContextData{TaskCount: ev.TaskCount}
+	case engine.ContextGeneratedEvent:
+		typ = "context_generated"
+		data = ContextData{Tokens: ev.Tokens}
+	case engine.ContextLoadedEvent:
+		typ = "context_loaded"
+	case engine.ContextSkippedEvent:
+		typ = "context_skipped"
+		data = ContextData{Reason: ev.Reason}
+	case engine.ContextFailedEvent:
+		typ = "context_failed"
+		data = ContextData{Error: ev.Error}
+	case engine.ContextActiveEvent:
+		typ = "context_active"
+	case engine.IdleEvent:
+		typ = "idle"
+	case engine.PauseEvent:
+		typ = "pause"
+		data = PauseData{Paused: ev.Paused}
+	case engine.BudgetEvent:
+		typ = "budget"
+		data = BudgetData{
+			Iteration: ev.Iteration,
+			TokensIn:  ev.Usage.TokensIn,
+			TokensOut: ev.Usage.TokensOut,
+			Cost:      ev.Usage.Cost,
+			Exceeded:  ev.Exceeded,
+			Reason:    ev.Reason,
+		}
+	default:
+		return Message{}, false
+	}
+
+	return message(typ, info.EpicID, info.Time, data), true
+}
+
+// DiffAgentState returns what changed from prev to snap, two snapshots of
+// the same agent run (prev is the zero value before the first).
+func DiffAgentState(prev, snap agent.AgentStateSnapshot) AgentStateData {
+	d := AgentStateData{
+		Model:    snap.Model,
+		Status:   string(snap.Status),
+		Error:    snap.ErrorMsg,
+		NumTurns: snap.NumTurns,
+		Metrics: MetricsData{
+			InputTokens:         snap.Metrics.InputTokens,
+			OutputTokens:        snap.Metrics.OutputTokens,
+			CacheReadTokens:     snap.Metrics.CacheReadTokens,
+			CacheCreationTokens: snap.Metrics.CacheCreationTokens,
+			CostUSD:             snap.Metrics.CostUSD,
+		},
+	}
+	if strings.HasPrefix(snap.Thinking, prev.Thinking) {
+		d.Thinking = snap.Thinking[len(prev.Thinking):]
+	} else {
+		d.Thinking = snap.Thinking
+	}
+
+	prevID, curID := toolID(prev.ActiveTool), toolID(snap.ActiveTool)
+	if prevID != "" && prevID != curID {
+		// Find the finished tool in history for its duration and result
+		d.ToolEnd = &ToolData{ID: prevID, Name: prev.ActiveTool.Name}
+		for _, tool := range snap.ToolHistory {
+			if tool.ID == prevID {
+				d.ToolEnd = &ToolData{
+					ID:         tool.ID,
+					Name:       tool.Name,
+					Input:      tool.Input,
+					DurationMS: tool.Duration.Milliseconds(),
+					IsError:    tool.IsError,
+				}
+				break
+			}
+		}
+	}
+	if curID != "" && curID != prevID {
+		d.ToolStart = &ToolData{ID: curID, Name: snap.ActiveTool.Name, Input: snap.ActiveTool.Input}
+	}
+	return d
+}
+
+// toolID returns the ID of tool, or "" for none.
+func toolID(tool *agent.ToolActivity) string {
+	if tool == nil {
+		return ""
+	}
+	return tool.ID
+}
+
+// message builds a Message with data encoded as JSON (nil for none).
+func message(typ, epicID string, t time.Time, data any) Message {
+	msg := Message{Type: typ, EpicID: epicID, Time: t}
+	if data != nil {
+		msg.Data, _ = json.Marshal(data)
+	}
+	return msg
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/engine"
+	"github.com/pengelbrecht/ticker/internal/verify"
+)
+
+func TestEncodeEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    engine.Event
+		wantType string
+		wantData string
+	}{
+		{
+			name:     "iteration end",
+			event:    engine.IterationEndEvent{Result: &engine.IterationResult{Iteration: 2, TaskID: "t1", TokensIn: 10, Signal: engine.SignalComplete, Error: errors.New("boom")}},
+			wantType: "iteration_end",
+			wantData: `{"iteration":2,"task_id":"t1","tokens_in":10,"tokens_out":0,"cost":0,"duration_ms":0,"signal":"COMPLETE","error":"boom"}`,
+		},
+		{
+			name:     "signal",
+			event:    engine.SignalEvent{TaskID: "t1", Signal: engine.SignalInputNeeded, Reason: "which db?"},
+			wantType: "signal",
+			wantData: `{"task_id":"t1","signal":"INPUT_NEEDED","reason":"which db?"}`,
+		},
+		{
+			name:     "verification end",
+			event:    engine.VerificationEndEvent{TaskID: "t1", Results: &verify.Results{AllPassed: true}},
+			wantType: "verification_end",
+			wantData: `{"task_id":"t1","passed":true,"summary":"No verifications run"}`,
+		},
+		{
+			name:     "idle",
+			event:    engine.IdleEvent{},
+			wantType: "idle",
+		},
+		{
+			name:     "pause",
+			event:    engine.PauseEvent{Paused: false},
+			wantType: "pause",
+			wantData: `{"paused":false}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, ok := EncodeEvent(tt.event)
+			if !ok {
+				t.Fatal("EncodeEvent() ok = false")
+			}
+			if msg.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", msg.Type, tt.wantType)
+			}
+			if string(msg.Data) != tt.wantData {
+				t.Errorf("Data = %s, want %s", msg.Data, tt.wantData)
+			}
+			if _, err := json.Marshal(msg); err != nil {
+				t.Errorf("marshal Message: %v", err)
+			}
+		})
+	}
+
+	// Agent state is streamed as a diff by Server
+	if _, ok := EncodeEvent(engine.AgentStateEvent{}); ok {
+		t.Error("EncodeEvent(AgentStateEvent) ok = true, want false")
+	}
+}
+
+func TestDiffAgentState(t *testing.T) {
+	edit := &agent.ToolActivity{ID: "tool-1", Name: "Edit", Input: "main.go"}
+	tests := []struct {
+		name     string
+		prev     agent.AgentStateSnapshot
+		snap     agent.AgentStateSnapshot
+		wantData string
+	}{
+		{
+			name:     "thinking delta",
+			prev:     agent.AgentStateSnapshot{Thinking: "Let me"},
+			snap:     agent.AgentStateSnapshot{Status: agent.StatusThinking, Thinking: "Let me look"},
+			wantData: `{"status":"thinking","thinking":" look","num_turns":0,"metrics":{"input_tokens":0,"output_tokens":0,"cache_read_tokens":0,"cache_creation_tokens":0,"cost_usd":0}}`,
+		},
+		{
+			name:     "tool start",
+			prev:     agent.AgentStateSnapshot{},
+			snap:     agent.AgentStateSnapshot{Status: agent.StatusToolUse, ActiveTool: edit},
+			wantData: `{"status":"tool_use","tool_start":{"id":"tool-1","name":"Edit","input":"main.go"},"num_turns":0,"metrics":{"input_tokens":0,"output_tokens":0,"cache_read_tokens":0,"cache_creation_tokens":0,"cost_usd":0}}`,
+		},
+		{
+			name: "tool end",
+			prev: agent.AgentStateSnapshot{ActiveTool: edit},
+			snap: agent.AgentStateSnapshot{
+				Status:      agent.StatusWriting,
+				ToolHistory: []agent.ToolActivity{{ID: "tool-1", Name: "Edit", Input: "main.go", Duration: 1500 * time.Millisecond, IsError: true}},
+				Metrics:     agent.Metrics{InputTokens: 10, CostUSD: 0.5},
+			},
+			wantData: `{"status":"writing","tool_end":{"id":"tool-1","name":"Edit","input":"main.go","duration_ms":1500,"is_error":true},"num_turns":0,"metrics":{"input_tokens":10,"output_tokens":0,"cache_read_tokens":0,"cache_creation_tokens":0,"cost_usd":0.5}}`,
+		},
+		{
+			name:     "unchanged tool",
+			prev:     agent.AgentStateSnapshot{ActiveTool: edit},
+			snap:     agent.AgentStateSnapshot{Status: agent.StatusToolUse, ActiveTool: edit},
+			wantData: `{"status":"tool_use","num_turns":0,"metrics":{"input_tokens":0,"output_tokens":0,"cache_read_tokens":0,"cache_creation_tokens":0,"cost_usd":0}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(DiffAgentState(tt.prev, tt.snap))
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+			if string(data) != tt.wantData {
+				t.Errorf("DiffAgentState() = %s, want %s", data, tt.wantData)
+			}
+		})
+	}
+}
@@ -0,0 +1,613 @@
+// Package server exposes a running ticker over a local HTTP control API.
+//
+// The API reports run status, budget usage, the epic's tasks and its
+// checkpoints, accepts pause, resume, stop, skip-task and add-note commands,
+// and streams engine events as Server-Sent Events. It listens on a TCP
+// address or a unix socket (see Listen) and has no authentication, so by
+// default it only listens on and answers to loopback addresses, and refuses
+// requests from web pages on other origins. Client is the matching client,
+// used by `ticker attach`.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/checkpoint"
+	"github.com/pengelbrecht/ticker/internal/engine"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+// Run states reported in Status.State.
+const (
+	StateRunning  = "running"
+	StatePaused   = "paused"
+	StateIdle     = "idle"
+	StateFinished = "finished"
+)
+
+// skipNote is the note added to a task skipped through the API.
+const skipNote = "Skipped via the ticker control API"
+
+// clientBuffer is how many events an event stream client may fall behind
+// before it is disconnected.
+const clientBuffer = 256
+
+// Ticks is the subset of the ticks client used by the server.
+type Ticks interface {
+	ListTasks(epicID string) ([]ticks.Task, error)
+	SetAwaiting(taskID, awaiting, note string) error
+	AddHumanNote(issueID, message string) error
+}
+
+// Run describes the engine run a Server reports on.
+type Run struct {
+	RunID     string
+	EpicID    string
+	EpicTitle string
+	Engine    *engine.Engine
+	Budget    *budget.Tracker
+}
+
+// Status is the run status returned by GET /status.
+type Status struct {
+	RunID          string    `json:"run_id,omitempty"`
+	EpicID         string    `json:"epic_id,omitempty"`
+	EpicTitle      string    `json:"epic_title,omitempty"`
+	State          string    `json:"state"`
+	Iteration      int       `json:"iteration"`
+	CurrentTask    *TaskRef  `json:"current_task,omitempty"`
+	PauseRequested bool      `json:"pause_requested"`
+	StopRequested  bool      `json:"stop_requested"`
+	StartedAt      time.Time `json:"started_at,omitempty"`
+	ExitReason     string    `json:"exit_reason,omitempty"`
+	Budget         *Budget   `json:"budget,omitempty"`
+}
+
+// TaskRef identifies a task.
+type TaskRef struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Budget is budget usage and limits (0 = unlimited).
+type Budget struct {
+	Iterations    int     `json:"iterations"`
+	TokensIn      int     `json:"tokens_in"`
+	TokensOut     int     `json:"tokens_out"`
+	Cost          float64 `json:"cost"`
+	ElapsedMS     int64   `json:"elapsed_ms"`
+	MaxIterations int     `json:"max_iterations"`
+	MaxTokens     int     `json:"max_tokens"`
+	MaxCost       float64 `json:"max_cost"`
+	MaxDurationMS int64   `json:"max_duration_ms"`
+}
+
+// Server serves the control API for one run at a time. Runs are attached as
+// they start, so a single server can follow consecutive epics (--auto).
+type Server struct {
+	ticks       Ticks
+	checkpoints *checkpoint.Manager
+
+	mu      sync.Mutex
+	status  Status
+	budget  *budget.Tracker
+	pause   chan bool     // current run's RunConfig.PauseChan
+	stop    chan struct{} // current run's RunConfig.StopChan
+	clients map[chan Message]struct{}
+
+	// The current iteration so far, for snapshots
+	output     strings.Builder
+	agentState agent.AgentStateSnapshot
+
+	// allowRemote accepts requests addressed to non-loopback hosts
+	allowRemote bool
+
+	http *http.Server
+}
+
+// New creates a server that reads tasks and checkpoints from t and cp.
+func New(t Ticks, cp *checkpoint.Manager) *Server {
+	s := &Server{
+		ticks:       t,
+		checkpoints: cp,
+		clients:     make(map[chan Message]struct{}),
+	}
+	s.http = &http.Server{Handler: s.Handler()}
+	return s
+}
+
+// AllowRemote makes the server answer requests addressed to any host, for
+// use with a listener on a non-loopback address. Requests from web pages on
+// other origins are still refused.
+func (s *Server) AllowRemote() {
+	s.allowRemote = true
+}
+
+// Listen opens the listener for addr: a TCP address such as ":8080" or
+// "localhost:8080", or a unix socket path prefixed with "unix:". A stale
+// socket file left by an earlier run is replaced. A bare ":port" listens on
+// 127.0.0.1 only; other non-loopback hosts are refused unless allowRemote
+// is set.
+func Listen(addr string, allowRemote bool) (net.Listener, error) {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		if path == "" {
+			return nil, fmt.Errorf("missing socket path in %q", addr)
+		}
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			os.Remove(path)
+		}
+		return net.Listen("unix", path)
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if !allowRemote && !isLoopback(host) {
+		return nil, fmt.Errorf("%s: %w", host, ErrNotLoopback)
+	}
+	return net.Listen("tcp", net.JoinHostPort(host, port))
+}
+
+// isLoopback reports whether host (without port) is localhost or a loopback IP.
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// hostOnly strips the port, if any, from a Host header value.
+func hostOnly(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return hostport
+}
+
+// checkOrigin guards against other web pages and DNS rebinding: TCP
+// requests must be addressed to a loopback host (unless AllowRemote), and
+// browser requests must come from a loopback origin or the API's own.
+// Unix socket connections can't come from a browser and are always allowed.
+func (s *Server) checkOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(http.LocalAddrContextKey).(*net.UnixAddr); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !s.allowRemote && !isLoopback(hostOnly(r.Host)) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %q is not allowed", r.Host))
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			u, err := url.Parse(origin)
+			if err != nil || (u.Host != r.Host && !isLoopback(u.Hostname())) {
+				writeError(w, http.StatusForbidden, fmt.Errorf("origin %q is not allowed", origin))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Serve accepts connections on l until Close is called or l is closed.
+// A server can serve several listeners at once.
+func (s *Server) Serve(l net.Listener) error {
+	err := s.http.Serve(l)
+	if errors.Is(err, http.ErrServerClosed) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close stops the server, disconnecting event stream clients.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	for ch := range s.clients {
+		delete(s.clients, ch)
+		close(ch)
+	}
+	s.mu.Unlock()
+	return s.http.Close()
+}
+
+// Attach makes the server report on run and wires the control endpoints to
+// it by setting config.PauseChan and config.StopChan. Call the returned
+// function with the run's exit reason once Run returns.
+func (s *Server) Attach(run Run, config *engine.RunConfig) (detach func(exitReason string)) {
+	pause := make(chan bool, 1)
+	stop := make(chan struct{})
+	config.PauseChan = pause
+	config.StopChan = stop
+
+	s.mu.Lock()
+	s.status = Status{
+		RunID:     run.RunID,
+		EpicID:    run.EpicID,
+		EpicTitle: run.EpicTitle,
+		State:     StateRunning,
+		StartedAt: time.Now(),
+	}
+	s.resetIteration()
+	s.budget = run.Budget
+	s.pause = pause
+	s.stop = stop
+	s.broadcastLocked(message("run_start", run.EpicID, time.Now(), RunData{RunID: run.RunID}))
+	s.mu.Unlock()
+
+	unsubscribe := run.Engine.Subscribe(s.Handle)
+
+	return func(exitReason string) {
+		unsubscribe()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.status.State = StateFinished
+		s.status.CurrentTask = nil
+		s.status.ExitReason = exitReason
+		s.pause = nil
+		s.stop = nil
+		s.broadcastLocked(message("run_end", run.EpicID, time.Now(), RunData{RunID: run.RunID, ExitReason: exitReason}))
+	}
+}
+
+// Handle updates the run status from an engine event and sends the event
+// to event stream clients. Both happen under one lock, so a client's
+// snapshot and its live events never overlap or leave a gap.
+func (s *Server) Handle(ev engine.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch ev := ev.(type) {
+	case engine.AgentStateEvent:
+		data := DiffAgentState(s.agentState, ev.Snapshot)
+		s.agentState = ev.Snapshot
+		info := ev.Info()
+		s.broadcastLocked(message("agent_state", info.EpicID, info.Time, data))
+		return
+	case engine.OutputEvent:
+		s.output.WriteString(ev.Text)
+	case engine.IterationStartEvent:
+		s.resetIteration()
+		s.status.State = StateRunning
+		s.status.Iteration = ev.Context.Iteration
+		if ev.Context.Task != nil {
+			s.status.CurrentTask = &TaskRef{ID: ev.Context.Task.ID, Title: ev.Context.Task.Title}
+		}
+	case engine.IterationEndEvent:
+		s.status.CurrentTask = nil
+	case engine.PauseEvent:
+		if ev.Paused {
+			s.status.State = StatePaused
+		} else {
+			s.status.State = StateRunning
+		}
+	case engine.IdleEvent:
+		s.status.State = StateIdle
+	}
+
+	if msg, ok := EncodeEvent(ev); ok {
+		s.broadcastLocked(msg)
+	}
+}
+
+// Status returns the current run status.
+func (s *Server) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.statusLocked()
+}
+
+// resetIteration clears the iteration kept for snapshots. Callers hold s.mu.
+func (s *Server) resetIteration() {
+	s.output.Reset()
+	s.agentState = agent.AgentStateSnapshot{}
+}
+
+// snapshotLocked returns the current snapshot. Callers hold s.mu.
+func (s *Server) snapshotLocked() SnapshotData {
+	snap := SnapshotData{
+		Status: s.statusLocked(),
+		Output: s.output.String(),
+	}
+	if s.agentState.Status != "" {
+		agentState := DiffAgentState(agent.AgentStateSnapshot{}, s.agentState)
+		snap.Agent = &agentState
+	}
+	return snap
+}
+
+func (s *Server) statusLocked() Status {
+	st := s.status
+	if st.CurrentTask != nil {
+		task := *st.CurrentTask
+		st.CurrentTask = &task
+	}
+	if s.budget != nil {
+		usage := s.budget.Usage()
+		limits := s.budget.Limits()
+		st.Budget = &Budget{
+			Iterations:    usage.Iterations,
+			TokensIn:      usage.TokensIn,
+			TokensOut:     usage.TokensOut,
+			Cost:          usage.Cost,
+			ElapsedMS:     usage.Duration().Milliseconds(),
+			MaxIterations: limits.MaxIterations,
+			MaxTokens:     limits.MaxTokens,
+			MaxCost:       limits.MaxCost,
+			MaxDurationMS: limits.MaxDuration.Milliseconds(),
+		}
+	}
+	return st
+}
+
+// SetPaused asks the run to pause or resume. Pausing takes effect between
+// iterations. Returns an error if no run is in progress.
+func (s *Server) SetPaused(paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pause == nil {
+		return errNoRun
+	}
+	// Replace any request the engine hasn't picked up yet. Only the server
+	// sends on the channel, so the send can't block.
+	select {
+	case <-s.pause:
+	default:
+	}
+	s.pause <- paused
+	s.status.PauseRequested = paused
+	return nil
+}
+
+// Stop asks the run to end once the current iteration is done.
+// Returns an error if no run is in progress.
+func (s *Server) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop == nil {
+		return errNoRun
+	}
+	if !s.status.StopRequested {
+		close(s.stop)
+		s.status.StopRequested = true
+	}
+	return nil
+}
+
+var errNoRun = errors.New("no run in progress")
+
+// ErrNotLoopback is returned by Listen for a non-loopback address when
+// remote access isn't allowed.
+var ErrNotLoopback = errors.New("not a loopback address")
+
+// Handler returns the HTTP handler for the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.handleStatus)
+	mux.HandleFunc("GET /tasks", s.handleTasks)
+	mux.HandleFunc("GET /checkpoints", s.handleCheckpoints)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("POST /pause", s.handlePause(true))
+	mux.HandleFunc("POST /resume", s.handlePause(false))
+	mux.HandleFunc("POST /stop", s.handleStop)
+	mux.HandleFunc("POST /tasks/{id}/skip", s.handleSkip)
+	mux.HandleFunc("POST /tasks/{id}/notes", s.handleNote)
+	return s.checkOrigin(mux)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.Status())
+}
+
+func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
+	epicID := s.Status().EpicID
+	if epicID == "" {
+		writeError(w, http.StatusConflict, errNoRun)
+		return
+	}
+	tasks, err := s.ticks.ListTasks(epicID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+func (s *Server) handleCheckpoints(w http.ResponseWriter, r *http.Request) {
+	epicID := s.Status().EpicID
+	if epicID == "" {
+		writeError(w, http.StatusConflict, errNoRun)
+		return
+	}
+	cps, err := s.checkpoints.ListForEpic(epicID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if cps == nil {
+		cps = []checkpoint.Checkpoint{}
+	}
+	writeJSON(w, http.StatusOK, cps)
+}
+
+func (s *Server) handlePause(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.SetPaused(paused); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, s.Status())
+	}
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	if err := s.Stop(); err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, s.Status())
+}
+
+// handleSkip hands the task to a human (awaiting work) so the engine stops
+// picking it. An iteration already working on it runs to the end.
+func (s *Server) handleSkip(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if !readJSON(w, r, &req, false) {
+		return
+	}
+	note := skipNote
+	if req.Reason != "" {
+		note += ": " + req.Reason
+	}
+	taskID := r.PathValue("id")
+	if err := s.ticks.SetAwaiting(taskID, "work", note); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"task_id": taskID, "awaiting": "work"})
+}
+
+// handleNote adds a human note to the task. The agent sees it the next time
+// it works on the task.
+func (s *Server) handleNote(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Message string `json:"message"`
+	}
+	if !readJSON(w, r, &req, true) {
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		writeError(w, http.StatusBadRequest, errors.New("message is required"))
+		return
+	}
+	taskID := r.PathValue("id")
+	if err := s.ticks.AddHumanNote(taskID, req.Message); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"task_id": taskID})
+}
+
+// handleEvents streams events as Server-Sent Events. The stream opens with
+// a "snapshot" event; no event is missed between it and the live events.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming not supported"))
+		return
+	}
+
+	ch := make(chan Message, clientBuffer)
+	s.mu.Lock()
+	snap := s.snapshotLocked()
+	s.clients[ch] = struct{}{}
+	s.mu.Unlock()
+	defer s.removeClient(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeSSE(w, message("snapshot", snap.Status.EpicID, time.Now(), snap)); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	keepalive := time.NewTicker(30 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case msg, ok := <-ch:
+			if !ok {
+				// Disconnected for falling behind, or the server closed
+				return
+			}
+			if err := writeSSE(w, msg); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// broadcastLocked sends msg to every event stream client. Clients that
+// have fallen clientBuffer events behind are disconnected rather than
+// slowing down the engine; they can reconnect for a fresh snapshot.
+// Callers hold s.mu.
+func (s *Server) broadcastLocked(msg Message) {
+	for ch := range s.clients {
+		select {
+		case ch <- msg:
+		default:
+			delete(s.clients, ch)
+			close(ch)
+		}
+	}
+}
+
+func (s *Server) removeClient(ch chan Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[ch]; ok {
+		delete(s.clients, ch)
+		close(ch)
+	}
+}
+
+// writeSSE writes msg as a single Server-Sent Event.
+func writeSSE(w http.ResponseWriter, msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Type, data)
+	return err
+}
+
+// readJSON decodes the request body into v. An empty body is allowed unless
+// required. Writes a 400 response and returns false on failure.
+func readJSON(w http.ResponseWriter, r *http.Request, v any, required bool) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil || (!required && errors.Is(err, io.EOF)) {
+		return true
+	}
+	if errors.Is(err, io.EOF) {
+		err = errors.New("request body is required")
+	}
+	writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
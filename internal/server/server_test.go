@@ -0,0 +1,407 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/budget"
+	"github.com/pengelbrecht/ticker/internal/checkpoint"
+	"github.com/pengelbrecht/ticker/internal/engine"
+	"github.com/pengelbrecht/ticker/internal/ticks"
+)
+
+type fakeTicks struct {
+	tasks    []ticks.Task
+	awaiting map[string]string
+	notes    map[string][]string
+}
+
+func newFakeTicks() *fakeTicks {
+	return &fakeTicks{awaiting: make(map[string]string), notes: make(map[string][]string)}
+}
+
+func (f *fakeTicks) ListTasks(epicID string) ([]ticks.Task, error) {
+	return f.tasks, nil
+}
+
+func (f *fakeTicks) SetAwaiting(taskID, awaiting, note string) error {
+	f.awaiting[taskID] = awaiting
+	f.notes[taskID] = append(f.notes[taskID], note)
+	return nil
+}
+
+func (f *fakeTicks) AddHumanNote(issueID, message string) error {
+	f.notes[issueID] = append(f.notes[issueID], message)
+	return nil
+}
+
+// newTestServer returns a server attached to a fresh engine run for epic-1.
+func newTestServer(t *testing.T) (*Server, *fakeTicks, *engine.Engine, *engine.RunConfig, func(string)) {
+	t.Helper()
+	ft := newFakeTicks()
+	s := New(ft, checkpoint.NewManagerWithDir(t.TempDir()))
+	eng := engine.NewEngine(nil, nil, budget.NewTracker(budget.Limits{}), checkpoint.NewManagerWithDir(t.TempDir()))
+	config := &engine.RunConfig{EpicID: "epic-1"}
+	detach := s.Attach(Run{RunID: "run-1", EpicID: "epic-1", Engine: eng, Budget: budget.NewTracker(budget.Limits{MaxIterations: 10})}, config)
+	return s, ft, eng, config, detach
+}
+
+func do(t *testing.T, h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = "localhost:7777"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer_NoRun(t *testing.T) {
+	s := New(newFakeTicks(), checkpoint.NewManagerWithDir(t.TempDir()))
+	h := s.Handler()
+
+	for _, path := range []string{"/pause", "/resume", "/stop"} {
+		if rec := do(t, h, "POST", path, ""); rec.Code != http.StatusConflict {
+			t.Errorf("POST %s status = %d, want %d", path, rec.Code, http.StatusConflict)
+		}
+	}
+	if rec := do(t, h, "GET", "/tasks", ""); rec.Code != http.StatusConflict {
+		t.Errorf("GET /tasks status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestServer_Status(t *testing.T) {
+	s, _, eng, _, detach := newTestServer(t)
+	h := s.Handler()
+
+	eng.Events().Publish(engine.IterationStartEvent{Context: engine.IterationContext{
+		Iteration: 3,
+		Task:      &ticks.Task{ID: "task-1", Title: "First"},
+	}})
+
+	var st Status
+	rec := do(t, h, "GET", "/status", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &st); err != nil {
+		t.Fatalf("decoding status: %v", err)
+	}
+	if st.RunID != "run-1" || st.EpicID != "epic-1" || st.State != StateRunning || st.Iteration != 3 {
+		t.Errorf("status = %+v, want run-1/epic-1 running at iteration 3", st)
+	}
+	if st.CurrentTask == nil || st.CurrentTask.ID != "task-1" {
+		t.Errorf("CurrentTask = %+v, want task-1", st.CurrentTask)
+	}
+	if st.Budget == nil || st.Budget.MaxIterations != 10 {
+		t.Errorf("Budget = %+v, want MaxIterations 10", st.Budget)
+	}
+
+	eng.Events().Publish(engine.PauseEvent{Paused: true})
+	if got := s.Status().State; got != StatePaused {
+		t.Errorf("State after PauseEvent = %q, want %q", got, StatePaused)
+	}
+	eng.Events().Publish(engine.IdleEvent{})
+	if got := s.Status().State; got != StateIdle {
+		t.Errorf("State after IdleEvent = %q, want %q", got, StateIdle)
+	}
+
+	detach("all tasks completed")
+	st = s.Status()
+	if st.State != StateFinished || st.ExitReason != "all tasks completed" || st.CurrentTask != nil {
+		t.Errorf("status after detach = %+v, want finished", st)
+	}
+	if rec := do(t, h, "POST", "/pause", ""); rec.Code != http.StatusConflict {
+		t.Errorf("POST /pause after detach status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+}
+
+func TestServer_PauseResume(t *testing.T) {
+	s, _, _, config, _ := newTestServer(t)
+	h := s.Handler()
+
+	if rec := do(t, h, "POST", "/pause", ""); rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /pause status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	if !s.Status().PauseRequested {
+		t.Error("PauseRequested = false after /pause")
+	}
+
+	// Resuming before the engine picked up the pause replaces the request
+	do(t, h, "POST", "/resume", "")
+	select {
+	case paused := <-config.PauseChan:
+		if paused {
+			t.Error("PauseChan = true, want the pending pause replaced by resume")
+		}
+	default:
+		t.Fatal("nothing sent on PauseChan")
+	}
+	select {
+	case v := <-config.PauseChan:
+		t.Errorf("unexpected extra value on PauseChan: %v", v)
+	default:
+	}
+}
+
+func TestServer_Stop(t *testing.T) {
+	s, _, _, config, _ := newTestServer(t)
+	h := s.Handler()
+
+	for i := 0; i < 2; i++ {
+		if rec := do(t, h, "POST", "/stop", ""); rec.Code != http.StatusAccepted {
+			t.Fatalf("POST /stop status = %d, want %d", rec.Code, http.StatusAccepted)
+		}
+	}
+	select {
+	case <-config.StopChan:
+	default:
+		t.Error("StopChan not closed after /stop")
+	}
+	if !s.Status().StopRequested {
+		t.Error("StopRequested = false after /stop")
+	}
+}
+
+func TestServer_TaskCommands(t *testing.T) {
+	s, ft, _, _, _ := newTestServer(t)
+	h := s.Handler()
+
+	rec := do(t, h, "POST", "/tasks/task-1/skip", `{"reason":"flaky"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("skip status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	if ft.awaiting["task-1"] != "work" {
+		t.Errorf("awaiting = %q, want %q", ft.awaiting["task-1"], "work")
+	}
+	if notes := ft.notes["task-1"]; len(notes) != 1 || !strings.Contains(notes[0], "flaky") {
+		t.Errorf("skip notes = %v, want one mentioning the reason", notes)
+	}
+
+	// Skip without a body
+	if rec := do(t, h, "POST", "/tasks/task-2/skip", ""); rec.Code != http.StatusOK {
+		t.Errorf("skip without body status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	rec = do(t, h, "POST", "/tasks/task-1/notes", `{"message":"use sqlite"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("notes status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	if notes := ft.notes["task-1"]; notes[len(notes)-1] != "use sqlite" {
+		t.Errorf("last note = %q, want %q", notes[len(notes)-1], "use sqlite")
+	}
+
+	for _, body := range []string{"", `{"message":"  "}`, `not json`} {
+		if rec := do(t, h, "POST", "/tasks/task-1/notes", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("notes with body %q status = %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestServer_TasksAndCheckpoints(t *testing.T) {
+	s, ft, _, _, _ := newTestServer(t)
+	ft.tasks = []ticks.Task{{ID: "task-1", Title: "First", Status: "open"}}
+	h := s.Handler()
+
+	var tasks []ticks.Task
+	rec := do(t, h, "GET", "/tasks", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &tasks); err != nil || len(tasks) != 1 || tasks[0].ID != "task-1" {
+		t.Errorf("GET /tasks = %s (%v), want task-1", rec.Body, err)
+	}
+
+	rec = do(t, h, "GET", "/checkpoints", "")
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("GET /checkpoints = %d %s, want 200 []", rec.Code, rec.Body)
+	}
+}
+
+func TestServer_Events(t *testing.T) {
+	s, _, eng, _, _ := newTestServer(t)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	defer s.Close()
+
+	resp, err := http.Get(ts.URL + "/events")
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	events := make(chan Message, 10)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+				var msg Message
+				if json.Unmarshal([]byte(data), &msg) == nil {
+					events <- msg
+				}
+			}
+		}
+		close(events)
+	}()
+
+	next := func() Message {
+		t.Helper()
+		select {
+		case msg := <-events:
+			return msg
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return Message{}
+		}
+	}
+
+	if msg := next(); msg.Type != "snapshot" {
+		t.Fatalf("first event = %q, want snapshot", msg.Type)
+	}
+	eng.Events().Publish(engine.OutputEvent{EventInfo: engine.EventInfo{EpicID: "epic-1", Time: time.Now()}, Text: "hello"})
+	msg := next()
+	var out OutputData
+	if msg.Type != "output" || json.Unmarshal(msg.Data, &out) != nil || out.Text != "hello" {
+		t.Errorf("event = %+v, want output with text hello", msg)
+	}
+	if msg.EpicID != "epic-1" {
+		t.Errorf("EpicID = %q, want %q", msg.EpicID, "epic-1")
+	}
+}
+
+func TestServer_Snapshot(t *testing.T) {
+	s, _, eng, _, _ := newTestServer(t)
+	defer s.Close()
+
+	info := engine.EventInfo{EpicID: "epic-1", Time: time.Now()}
+	eng.Events().Publish(engine.IterationStartEvent{EventInfo: info, Context: engine.IterationContext{Iteration: 3, Task: &ticks.Task{ID: "t1", Title: "Task one"}}})
+	eng.Events().Publish(engine.OutputEvent{EventInfo: info, Text: "hello "})
+	eng.Events().Publish(engine.OutputEvent{EventInfo: info, Text: "world"})
+	eng.Events().Publish(engine.AgentStateEvent{EventInfo: info, Snapshot: agent.AgentStateSnapshot{
+		Status:     agent.StatusToolUse,
+		Thinking:   "hmm",
+		ActiveTool: &agent.ToolActivity{ID: "tool-1", Name: "Bash"},
+	}})
+
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	var snap SnapshotData
+	err := NewClient(strings.TrimPrefix(ts.URL, "http://")).Events(context.Background(), func(msg Message) {
+		if msg.Type == "snapshot" {
+			json.Unmarshal(msg.Data, &snap)
+			s.Close() // end the stream
+		}
+	})
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+
+	if snap.Status.Iteration != 3 || snap.Status.CurrentTask == nil || snap.Status.CurrentTask.ID != "t1" {
+		t.Errorf("snapshot status = %+v, want iteration 3 on t1", snap.Status)
+	}
+	if snap.Output != "hello world" {
+		t.Errorf("snapshot Output = %q, want %q", snap.Output, "hello world")
+	}
+	if snap.Agent == nil || snap.Agent.Thinking != "hmm" || snap.Agent.ToolStart == nil || snap.Agent.ToolStart.Name != "Bash" {
+		t.Errorf("snapshot Agent = %+v, want thinking and the active tool", snap.Agent)
+	}
+
+	// A new iteration starts from scratch
+	eng.Events().Publish(engine.IterationStartEvent{EventInfo: info, Context: engine.IterationContext{Iteration: 4}})
+	s.mu.Lock()
+	snap = s.snapshotLocked()
+	s.mu.Unlock()
+	if snap.Output != "" || snap.Agent != nil {
+		t.Errorf("snapshot after new iteration = %+v, want no output or agent state", snap)
+	}
+}
+
+func TestListen_UnixSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ticker.sock")
+	l, err := Listen("unix:"+path, false)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	l.(interface{ SetUnlinkOnClose(bool) }).SetUnlinkOnClose(false)
+	l.Close()
+
+	// A stale socket file is replaced
+	l, err = Listen("unix:"+path, false)
+	if err != nil {
+		t.Fatalf("Listen() over stale socket error = %v", err)
+	}
+	l.Close()
+
+	if _, err := Listen("unix:", false); err == nil {
+		t.Error("Listen(\"unix:\") should fail without a path")
+	}
+}
+
+func TestListen_TCP(t *testing.T) {
+	// A bare port listens on loopback only
+	l, err := Listen(":0", false)
+	if err != nil {
+		t.Fatalf("Listen(\":0\") error = %v", err)
+	}
+	if host, _, _ := net.SplitHostPort(l.Addr().String()); host != "127.0.0.1" {
+		t.Errorf("Listen(\":0\") address = %s, want 127.0.0.1", l.Addr())
+	}
+	l.Close()
+
+	if _, err := Listen("0.0.0.0:0", false); !errors.Is(err, ErrNotLoopback) {
+		t.Errorf("Listen(\"0.0.0.0:0\") error = %v, want ErrNotLoopback", err)
+	}
+	l, err = Listen("0.0.0.0:0", true)
+	if err != nil {
+		t.Fatalf("Listen(\"0.0.0.0:0\", true) error = %v", err)
+	}
+	l.Close()
+}
+
+func TestServer_CheckOrigin(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        string
+		origin      string
+		allowRemote bool
+		allowed     bool
+	}{
+		{name: "localhost", host: "localhost:7777", allowed: true},
+		{name: "loopback IP", host: "127.0.0.1:7777", allowed: true},
+		{name: "IPv6 loopback", host: "[::1]:7777", allowed: true},
+		{name: "loopback origin", host: "localhost:7777", origin: "http://localhost:3000", allowed: true},
+		{name: "other host (DNS rebinding)", host: "evil.example:7777"},
+		{name: "other origin (CSRF)", host: "localhost:7777", origin: "https://evil.example"},
+		{name: "remote host allowed", host: "10.0.0.5:7777", allowRemote: true, allowed: true},
+		{name: "remote same origin", host: "10.0.0.5:7777", origin: "http://10.0.0.5:7777", allowRemote: true, allowed: true},
+		{name: "remote other origin", host: "10.0.0.5:7777", origin: "https://evil.example", allowRemote: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _, _, _ := newTestServer(t)
+			if tt.allowRemote {
+				s.AllowRemote()
+			}
+			req := httptest.NewRequest("POST", "/pause", nil)
+			req.Host = tt.host
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			rec := httptest.NewRecorder()
+			s.Handler().ServeHTTP(rec, req)
+
+			if allowed := rec.Code != http.StatusForbidden; allowed != tt.allowed {
+				t.Errorf("POST /pause = %d, want allowed %v", rec.Code, tt.allowed)
+			}
+			if s.Status().PauseRequested != tt.allowed {
+				t.Errorf("PauseRequested = %v, want %v", s.Status().PauseRequested, tt.allowed)
+			}
+		})
+	}
+}
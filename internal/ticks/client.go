@@ -245,8 +245,19 @@ func (c *Client) nextOrphanedTask() (*Task, error) {
 // 3. Not awaiting human action (awaiting=nil AND manual=false)
 // Tasks are sorted by priority (lowest number = highest priority) before selection.
 func (c *Client) findReadyTaskFromList(tasks []Task) (*Task, error) {
+	return ReadyTask(tasks, func(id string) bool {
+		// Blockers outside the list are looked up; unknown ones don't block
+		blocker, err := c.GetTask(id)
+		return err != nil || blocker == nil || blocker.Status == "closed"
+	}), nil
+}
+
+// ReadyTask returns the first task in tasks that is ready for agent work (see
+// findReadyTaskFromList), or nil if none is. blockerClosed reports whether a
+// blocker that is not in tasks is closed. tasks is sorted in place.
+func ReadyTask(tasks []Task, blockerClosed func(id string) bool) *Task {
 	if len(tasks) == 0 {
-		return nil, nil
+		return nil
 	}
 
 	// Sort by priority (lowest number = highest priority)
@@ -271,13 +282,10 @@ func (c *Client) findReadyTaskFromList(tasks []Task) (*Task, error) {
 				blockedIDs[t.ID] = true
 				break
 			}
-			// For blockers not in our list, we need to fetch them
-			if !exists {
-				blockerTask, err := c.GetTask(blockerID)
-				if err == nil && blockerTask != nil && blockerTask.Status != "closed" {
-					blockedIDs[t.ID] = true
-					break
-				}
+			// For blockers not in our list, we need to look them up
+			if !exists && !blockerClosed(blockerID) {
+				blockedIDs[t.ID] = true
+				break
 			}
 		}
 	}
@@ -295,10 +303,10 @@ func (c *Client) findReadyTaskFromList(tasks []Task) (*Task, error) {
 		}
 		// Found a ready task
 		taskCopy := t
-		return &taskCopy, nil
+		return &taskCopy
 	}
 
-	return nil, nil
+	return nil
 }
 
 // ListAwaitingTasks returns all tasks awaiting human attention under the given epic.
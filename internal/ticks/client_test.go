@@ -59,6 +59,43 @@ func TestEpicIsClosed(t *testing.T) {
 	}
 }
 
+func TestAgentName(t *testing.T) {
+	tests := []struct {
+		name string
+		task Task
+		want string
+	}{
+		{"none", Task{}, ""},
+		{"field", Task{Agent: "codex"}, "codex"},
+		{"label", Task{Labels: []string{"backend", "agent:codex"}}, "codex"},
+		{"field wins over label", Task{Agent: "claude", Labels: []string{"agent:codex"}}, "claude"},
+		{"empty label ignored", Task{Labels: []string{"agent:"}}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.task.AgentName(); got != tt.want {
+				t.Errorf("Task.AgentName() = %q, want %q", got, tt.want)
+			}
+			epic := Epic{Agent: tt.task.Agent, Labels: tt.task.Labels}
+			if got := epic.AgentName(); got != tt.want {
+				t.Errorf("Epic.AgentName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskAgentFromJSON(t *testing.T) {
+	data := `{"id":"abc","title":"t","status":"open","labels":["agent:codex"]}`
+	var task Task
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := task.AgentName(); got != "codex" {
+		t.Errorf("AgentName() = %q, want %q", got, "codex")
+	}
+}
+
 func TestTaskIsAwaitingHuman(t *testing.T) {
 	// Test nil Awaiting and Manual=false - agent's turn
 	task := &Task{Status: "open"}
@@ -2134,6 +2171,39 @@ func TestFindReadyTaskFromListPriorityOrdering(t *testing.T) {
 	}
 }
 
+// TestReadyTask_ExternalBlockers tests that blockers outside the list are
+// checked with blockerClosed
+func TestReadyTask_ExternalBlockers(t *testing.T) {
+	tests := []struct {
+		name   string
+		closed bool
+		want   string
+	}{
+		{"external blocker open", false, "unblocked"},
+		{"external blocker closed", true, "blocked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := []Task{
+				{ID: "blocked", Status: "open", Priority: 1, BlockedBy: []string{"other-epic-task"}},
+				{ID: "unblocked", Status: "open", Priority: 2},
+			}
+			var asked []string
+			task := ReadyTask(tasks, func(id string) bool {
+				asked = append(asked, id)
+				return tt.closed
+			})
+			if task == nil || task.ID != tt.want {
+				t.Errorf("ReadyTask() = %+v, want %q", task, tt.want)
+			}
+			if len(asked) != 1 || asked[0] != "other-epic-task" {
+				t.Errorf("blockerClosed called with %v, want [other-epic-task]", asked)
+			}
+		})
+	}
+}
+
 // TestFindReadyTaskFromListFiltersAwaiting tests that awaiting tasks are excluded
 func TestFindReadyTaskFromListFiltersAwaiting(t *testing.T) {
 	approval := "approval"
@@ -1,6 +1,7 @@
 package ticks
 
 import (
+	"strings"
 	"time"
 
 	"github.com/pengelbrecht/ticker/internal/agent"
@@ -18,6 +19,12 @@ type Task struct {
 	BlockedBy   []string `json:"blocked_by,omitempty"`
 	Parent      string   `json:"parent,omitempty"`
 	Manual      bool     `json:"manual,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+
+	// Agent selects the agent backend for this task (e.g. "codex").
+	// Takes precedence over the epic's agent and the configured default.
+	// An "agent:<name>" label has the same effect.
+	Agent string `json:"agent,omitempty"`
 
 	// Requires declares a gate that must be passed before closing.
 	// Set at creation time, persists through the tick lifecycle.
@@ -39,6 +46,9 @@ type Task struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	ClosedAt  time.Time `json:"closed_at,omitempty"`
 
+	// ClosedReason is the reason given when the task was closed.
+	ClosedReason string `json:"closed_reason,omitempty"`
+
 	// Run contains the agent run result for completed tasks.
 	Run *agent.RunRecord `json:"run,omitempty"`
 }
@@ -54,6 +64,8 @@ type Epic struct {
 	Type        string    `json:"type"`
 	Owner       string    `json:"owner"`
 	Children    []string  `json:"children,omitempty"`
+	Labels      []string  `json:"labels,omitempty"`
+	Agent       string    `json:"agent,omitempty"` // Agent backend for the epic's tasks (or "agent:<name>" label)
 	CreatedBy   string    `json:"created_by"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
@@ -79,6 +91,37 @@ func (n *Note) IsFromAgent() bool {
 	return n.Author == "" || n.Author == "agent"
 }
 
+// agentLabelPrefix marks a label that selects the agent backend.
+const agentLabelPrefix = "agent:"
+
+// agentFromLabels returns the agent named by an "agent:<name>" label, if any.
+func agentFromLabels(labels []string) string {
+	for _, label := range labels {
+		if name, ok := strings.CutPrefix(label, agentLabelPrefix); ok && name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// AgentName returns the agent backend selected for this task via the agent
+// field or an "agent:<name>" label. Returns "" if the task doesn't select one.
+func (t *Task) AgentName() string {
+	if t.Agent != "" {
+		return t.Agent
+	}
+	return agentFromLabels(t.Labels)
+}
+
+// AgentName returns the agent backend selected for this epic via the agent
+// field or an "agent:<name>" label. Returns "" if the epic doesn't select one.
+func (e *Epic) AgentName() string {
+	if e.Agent != "" {
+		return e.Agent
+	}
+	return agentFromLabels(e.Labels)
+}
+
 // IsOpen returns true if the task status is "open".
 func (t *Task) IsOpen() bool {
 	return t.Status == "open"
@@ -0,0 +1,282 @@
+package tui
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/pengelbrecht/ticker/internal/gitutil"
+)
+
+// -----------------------------------------------------------------------------
+// Diff Viewer - What a task's run changed in the code
+// -----------------------------------------------------------------------------
+
+// DiffLoader returns the git diff between commits from and to.
+// Called from a tea.Cmd, off the UI goroutine.
+type DiffLoader func(from, to string) (string, error)
+
+// maxDiffLines caps how much of a diff the viewer highlights and shows.
+const maxDiffLines = 5000
+
+// diffLoadedMsg carries the diff loaded for the diff viewer.
+type diffLoadedMsg struct {
+	taskID string
+	diff   string
+	err    error
+}
+
+// diffFile is a file in the diff and the line its section starts at.
+type diffFile struct {
+	name string
+	line int
+}
+
+// diffState is the diff viewer's state. The zero value is closed.
+type diffState struct {
+	taskID   string
+	from, to string
+	loading  bool
+	err      string
+	lines    []string // highlighted, one per diff line
+	files    []diffFile
+}
+
+// canViewDiff reports whether 'd' opens the diff of the viewed task's run.
+func (m Model) canViewDiff() bool {
+	if m.diffLoader == nil || m.viewingTask == "" || !m.viewingRunRecord {
+		return false
+	}
+	record := m.taskRunRecords[m.viewingTask]
+	return record != nil && record.BaseCommit != "" && record.HeadCommit != ""
+}
+
+// openDiff shows the diff of the viewed task's last run in the output pane.
+func (m Model) openDiff() (Model, tea.Cmd) {
+	if !m.canViewDiff() {
+		return m, nil
+	}
+	record := m.taskRunRecords[m.viewingTask]
+	m.diffView = &diffState{taskID: m.viewingTask, from: record.BaseCommit, to: record.HeadCommit}
+	if record.BaseCommit == record.HeadCommit {
+		m.setDiffContent()
+		return m, nil
+	}
+
+	m.diffView.loading = true
+	m.setDiffContent()
+	load, d := m.diffLoader, m.diffView
+	return m, func() tea.Msg {
+		diff, err := load(d.from, d.to)
+		return diffLoadedMsg{taskID: d.taskID, diff: diff, err: err}
+	}
+}
+
+// closeDiff returns the output pane to the viewed task's run details.
+func (m *Model) closeDiff() {
+	m.diffView = nil
+	m.refreshViewportContent()
+	m.viewport.GotoTop()
+}
+
+// handleDiffLoaded shows a loaded diff.
+func (m Model) handleDiffLoaded(msg diffLoadedMsg) Model {
+	d := m.diffView
+	if d == nil || d.taskID != msg.taskID {
+		return m // closed or moved on meanwhile
+	}
+	d.loading = false
+	if msg.err != nil {
+		d.err = msg.err.Error()
+	} else {
+		d.lines, d.files = highlightDiff(msg.diff)
+	}
+	m.setDiffContent()
+	m.viewport.GotoTop()
+	return m
+}
+
+// setDiffContent puts the diff (or its loading/empty state) in the viewport.
+// Lines are cut to the viewport width rather than wrapped, so file offsets
+// stay valid.
+func (m *Model) setDiffContent() {
+	d := m.diffView
+	switch {
+	case d.loading:
+		m.viewport.SetContent(dimStyle.Render("Loading diff..."))
+	case d.err != "":
+		m.viewport.SetContent(lipgloss.NewStyle().Foreground(colorRed).Render("Could not load diff: " + d.err))
+	case len(d.lines) == 0:
+		m.viewport.SetContent(dimStyle.Render(fmt.Sprintf("No changes committed in this run (%s)", gitutil.ShortSHA(d.to))))
+	default:
+		lines := make([]string, len(d.lines))
+		for i, line := range d.lines {
+			lines[i] = ansi.Truncate(line, m.viewport.Width, "…")
+		}
+		m.viewport.SetContent(strings.Join(lines, "\n"))
+	}
+}
+
+// currentDiffFile returns the index of the file at the top of the viewport,
+// or -1 if there are no files.
+func (m Model) currentDiffFile() int {
+	current := -1
+	for i, f := range m.diffView.files {
+		if f.line > m.viewport.YOffset {
+			break
+		}
+		current = i
+	}
+	return current
+}
+
+// jumpDiffFile scrolls to the next (delta 1) or previous (delta -1) file.
+func (m *Model) jumpDiffFile(delta int) {
+	files := m.diffView.files
+	if len(files) == 0 {
+		return
+	}
+	current := m.currentDiffFile()
+	next := current + delta
+	if delta < 0 && current >= 0 && m.viewport.YOffset > files[current].line {
+		next = current // back to the start of the current file first
+	}
+	if next < 0 || next >= len(files) {
+		return
+	}
+	m.viewport.SetYOffset(files[next].line)
+}
+
+// diffHeaderTitle is the output pane title while the diff viewer is open.
+func (m Model) diffHeaderTitle() string {
+	d := m.diffView
+	title := fmt.Sprintf("Diff [%s] %s..%s", d.taskID, gitutil.ShortSHA(d.from), gitutil.ShortSHA(d.to))
+	if n := len(d.files); n > 0 {
+		current := m.currentDiffFile()
+		if current < 0 {
+			current = 0
+		}
+		title += fmt.Sprintf(" · %s (%d/%d)", d.files[current].name, current+1, n)
+	}
+	return title
+}
+
+// Diff line styles (Catppuccin Mocha). Added and removed lines keep their
+// syntax colors on a tinted background.
+var (
+	diffFileStyle    = lipgloss.NewStyle().Foreground(colorPink).Bold(true)
+	diffMetaStyle    = lipgloss.NewStyle().Foreground(colorGray)
+	diffHunkStyle    = lipgloss.NewStyle().Foreground(colorPurple)
+	diffAddedBg      = lipgloss.Color("#2E3C35") // Green over Base
+	diffRemovedBg    = lipgloss.Color("#3F2A35") // Red over Base
+	diffAddedStyle   = lipgloss.NewStyle().Foreground(colorGreen).Background(diffAddedBg)
+	diffRemovedStyle = lipgloss.NewStyle().Foreground(colorRed).Background(diffRemovedBg)
+)
+
+// highlightDiff renders a unified git diff for the terminal, returning one
+// line per diff line and the files it touches. Changed and context lines
+// are syntax-highlighted with chroma using the lexer for their file.
+func highlightDiff(diff string) ([]string, []diffFile) {
+	diff = strings.TrimRight(diff, "\n")
+	if diff == "" {
+		return nil, nil
+	}
+	raw := strings.Split(diff, "\n")
+	truncated := len(raw) > maxDiffLines
+	if truncated {
+		raw = raw[:maxDiffLines]
+	}
+
+	style := styles.Get("catppuccin-mocha")
+	var lexer chroma.Lexer
+
+	lines := make([]string, 0, len(raw)+1)
+	var files []diffFile
+	inHunk := false
+	for _, line := range raw {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			name := diffFileName(line)
+			files = append(files, diffFile{name: name, line: len(lines)})
+			lexer = lexers.Match(path.Base(name))
+			if lexer == nil {
+				lexer = lexers.Fallback
+			}
+			lexer = chroma.Coalesce(lexer)
+			inHunk = false
+			lines = append(lines, diffFileStyle.Render(line))
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+			lines = append(lines, diffHunkStyle.Render(line))
+		case !inHunk || line == "":
+			lines = append(lines, diffMetaStyle.Render(line))
+		case line[0] == '+':
+			lines = append(lines, diffAddedStyle.Render("+")+highlightCode(lexer, style, line[1:], diffAddedBg))
+		case line[0] == '-':
+			lines = append(lines, diffRemovedStyle.Render("-")+highlightCode(lexer, style, line[1:], diffRemovedBg))
+		case line[0] == ' ':
+			lines = append(lines, " "+highlightCode(lexer, style, line[1:], nil))
+		default:
+			// e.g. "\ No newline at end of file"
+			lines = append(lines, diffMetaStyle.Render(line))
+		}
+	}
+	if truncated {
+		lines = append(lines, dimStyle.Render(fmt.Sprintf("... diff truncated at %d lines", maxDiffLines)))
+	}
+	return lines, files
+}
+
+// highlightCode highlights a single line of code on background bg (nil for
+// none). Falls back to the plain line if it can't be tokenised.
+func highlightCode(lexer chroma.Lexer, style *chroma.Style, code string, bg lipgloss.TerminalColor) string {
+	plain := lipgloss.NewStyle()
+	if bg != nil {
+		plain = plain.Background(bg)
+	}
+	if lexer == nil || code == "" {
+		return plain.Render(code)
+	}
+	tokens, err := chroma.Tokenise(lexer, nil, code)
+	if err != nil {
+		return plain.Render(code)
+	}
+
+	var sb strings.Builder
+	for _, token := range tokens {
+		value := strings.TrimRight(token.Value, "\n") // lexers end input with a newline
+		if value == "" {
+			continue
+		}
+		entry := style.Get(token.Type)
+		s := plain
+		if entry.Colour.IsSet() {
+			s = s.Foreground(lipgloss.Color(entry.Colour.String()))
+		}
+		if entry.Bold == chroma.Yes {
+			s = s.Bold(true)
+		}
+		if entry.Italic == chroma.Yes {
+			s = s.Italic(true)
+		}
+		sb.WriteString(s.Render(value))
+	}
+	return sb.String()
+}
+
+// diffFileName returns the file a "diff --git a/x b/x" line is about.
+func diffFileName(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+len(" b/"):]
+	}
+	return rest
+}
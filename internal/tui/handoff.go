@@ -0,0 +1,341 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+)
+
+// -----------------------------------------------------------------------------
+// Handoff Review Panel - Respond to tasks awaiting a human without leaving
+// -----------------------------------------------------------------------------
+
+// HandoffAction is a human response to a task awaiting a human.
+type HandoffAction string
+
+const (
+	HandoffApprove HandoffAction = "approve" // accept the work (closes the task)
+	HandoffReject  HandoffAction = "reject"  // back to the agent with feedback
+	HandoffAnswer  HandoffAction = "answer"  // answer the agent's question
+)
+
+// Awaiting types the review panel handles, by the actions they take.
+var (
+	handoffVerdictTypes = []string{"approval", "review", "content"}
+	handoffAnswerTypes  = []string{"input", "escalation"}
+)
+
+// handoffNotesShown is how many earlier agent notes the panel shows.
+const handoffNotesShown = 3
+
+// Handoff is what the review panel shows about a task awaiting a human.
+type Handoff struct {
+	TaskID   string
+	Title    string
+	Awaiting string
+
+	// Reason is why the agent handed off (the note it left with the signal).
+	Reason string
+
+	// Notes are the agent's earlier notes on the task, oldest first.
+	Notes []string
+
+	// RunRecord is the agent's last run on the task (nil if not recorded).
+	RunRecord *agent.RunRecord
+}
+
+// HandoffClient loads and answers handoffs for the review panel.
+// Both are called from tea.Cmds, off the UI goroutine.
+type HandoffClient interface {
+	LoadHandoff(taskID string) (*Handoff, error)
+	RespondHandoff(taskID string, action HandoffAction, text string) error
+}
+
+// handoffLoadedMsg carries the handoff loaded for the review panel.
+type handoffLoadedMsg struct {
+	taskID  string
+	handoff *Handoff
+	err     error
+}
+
+// handoffRespondedMsg reports the outcome of a response.
+type handoffRespondedMsg struct {
+	taskID string
+	action HandoffAction
+	err    error
+}
+
+// handoffState is the review panel's state. The zero value is closed.
+type handoffState struct {
+	taskID  string
+	handoff *Handoff // nil while loading
+	action  HandoffAction
+	input   textinput.Model // feedback or answer, while action needs text
+	busy    bool            // a response is being sent
+	err     string
+}
+
+// canReviewHandoff reports whether the review panel handles tasks awaiting
+// awaiting.
+func canReviewHandoff(awaiting string) bool {
+	return slices.Contains(handoffVerdictTypes, awaiting) || slices.Contains(handoffAnswerTypes, awaiting)
+}
+
+// canRespondToSelected reports whether 'r' opens the review panel on the
+// selected task.
+func (m Model) canRespondToSelected() bool {
+	if m.handoffClient == nil || m.selectedTask < 0 || m.selectedTask >= len(m.tasks) {
+		return false
+	}
+	return canReviewHandoff(m.tasks[m.selectedTask].Awaiting)
+}
+
+// openHandoff opens the review panel on the selected task, if it awaits a
+// response the panel handles.
+func (m Model) openHandoff() (Model, tea.Cmd) {
+	if !m.canRespondToSelected() {
+		return m, nil
+	}
+	task := m.tasks[m.selectedTask]
+
+	m.handoffPanel = &handoffState{taskID: task.ID}
+	client := m.handoffClient
+	return m, func() tea.Msg {
+		h, err := client.LoadHandoff(task.ID)
+		return handoffLoadedMsg{taskID: task.ID, handoff: h, err: err}
+	}
+}
+
+// startHandoffInput asks for the text action needs.
+func (p *handoffState) startHandoffInput(action HandoffAction, width int) {
+	p.action = action
+	p.input = textinput.New()
+	p.input.Cursor.SetMode(cursor.CursorStatic)
+	p.input.Width = width
+	p.input.Prompt = "> "
+	if action == HandoffAnswer {
+		p.input.Placeholder = "Your answer"
+	} else {
+		p.input.Placeholder = "Feedback for the agent"
+	}
+	p.input.Focus()
+}
+
+// handleHandoffLoaded shows a loaded handoff. Questions go straight to the
+// answer input.
+func (m Model) handleHandoffLoaded(msg handoffLoadedMsg) Model {
+	p := m.handoffPanel
+	if p == nil || p.taskID != msg.taskID {
+		return m // closed or moved on meanwhile
+	}
+	if msg.err != nil {
+		p.err = msg.err.Error()
+		return m
+	}
+	p.handoff = msg.handoff
+	if slices.Contains(handoffAnswerTypes, msg.handoff.Awaiting) {
+		p.startHandoffInput(HandoffAnswer, handoffWidth-10)
+	}
+	return m
+}
+
+// handleHandoffResponded closes the panel once a response went through.
+func (m Model) handleHandoffResponded(msg handoffRespondedMsg) Model {
+	p := m.handoffPanel
+	if p == nil || p.taskID != msg.taskID {
+		return m
+	}
+	p.busy = false
+	if msg.err != nil {
+		p.err = msg.err.Error()
+		return m
+	}
+
+	// Not awaiting anymore; the next task list refresh has the new status
+	for i := range m.tasks {
+		if m.tasks[i].ID == msg.taskID {
+			m.tasks[i].Awaiting = ""
+		}
+	}
+	m.handoffPanel = nil
+	return m
+}
+
+// handleHandoffKey handles a key while the review panel is open.
+func (m Model) handleHandoffKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	p := m.handoffPanel
+	if msg.String() == "ctrl+c" {
+		m.quitting = true
+		return m, tea.Quit
+	}
+	if p.busy {
+		return m, nil
+	}
+
+	// Typing feedback or an answer
+	if p.action != "" {
+		switch msg.String() {
+		case "esc":
+			if p.action == HandoffAnswer {
+				m.handoffPanel = nil
+			} else {
+				p.action = ""
+				p.err = ""
+			}
+			return m, nil
+		case "enter":
+			text := strings.TrimSpace(p.input.Value())
+			if text == "" {
+				return m, nil
+			}
+			return m, m.respondHandoff(p.action, text)
+		}
+		var cmd tea.Cmd
+		p.input, cmd = p.input.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "esc", "q":
+		m.handoffPanel = nil
+	case "a":
+		if p.handoff != nil && slices.Contains(handoffVerdictTypes, p.handoff.Awaiting) {
+			return m, m.respondHandoff(HandoffApprove, "")
+		}
+	case "x":
+		if p.handoff != nil && slices.Contains(handoffVerdictTypes, p.handoff.Awaiting) {
+			p.startHandoffInput(HandoffReject, handoffWidth-10)
+		}
+	}
+	return m, nil
+}
+
+// respondHandoff sends a response for the panel's task.
+func (m Model) respondHandoff(action HandoffAction, text string) tea.Cmd {
+	p := m.handoffPanel
+	p.busy = true
+	p.err = ""
+	client, taskID := m.handoffClient, p.taskID
+	return func() tea.Msg {
+		err := client.RespondHandoff(taskID, action, text)
+		return handoffRespondedMsg{taskID: taskID, action: action, err: err}
+	}
+}
+
+// handoffWidth is the width of the review panel.
+const handoffWidth = 72
+
+// renderHandoffOverlay renders the review panel over the base view.
+// Layout:
+// ┌─ Awaiting approval ───────────────────────────┐
+// │  [abc] Add login page                          │
+// │                                                │
+// │  Work complete, requires approval              │
+// │                                                │
+// │  Agent notes                                   │
+// │  • Implemented the form and validation         │
+// │                                                │
+// │  Last run   sonnet · 12 turns · 1:42 · $0.31   │
+// │                                                │
+// │  a:approve  x:reject  esc:close                │
+// └────────────────────────────────────────────────┘
+func (m Model) renderHandoffOverlay() string {
+	p := m.handoffPanel
+	textWidth := handoffWidth - 6 // border and padding
+	wrap := lipgloss.NewStyle().Width(textWidth)
+	lblStyle := dimStyle.Width(11)
+	valStyle := lipgloss.NewStyle().Foreground(colorBlue)
+	keyStyle := footerStyle.Bold(true)
+
+	title := "Handoff"
+	var lines []string
+	switch {
+	case p.handoff == nil && p.err != "":
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorRed).Render(wrap.Render(p.err)))
+		lines = append(lines, "", keyStyle.Render("esc")+footerStyle.Render(":close"))
+	case p.handoff == nil:
+		lines = append(lines, dimStyle.Render("Loading "+p.taskID+"..."))
+	default:
+		h := p.handoff
+		title = "Awaiting " + h.Awaiting
+		lines = append(lines, valStyle.Bold(true).Render(wrap.Render(fmt.Sprintf("[%s] %s", h.TaskID, h.Title))))
+		lines = append(lines, "")
+		if h.Reason != "" {
+			lines = append(lines, wrap.Render(h.Reason), "")
+		}
+
+		if len(h.Notes) > 0 {
+			lines = append(lines, headerStyle.Render("Agent notes"))
+			notes := h.Notes
+			if len(notes) > handoffNotesShown {
+				notes = notes[len(notes)-handoffNotesShown:]
+			}
+			for _, note := range notes {
+				lines = append(lines, dimStyle.Render(wrap.Render("• "+note)))
+			}
+			lines = append(lines, "")
+		}
+
+		if r := h.RunRecord; r != nil {
+			run := []string{}
+			if r.Model != "" {
+				run = append(run, r.Model)
+			}
+			run = append(run, fmt.Sprintf("%d turns", r.NumTurns))
+			if !r.StartedAt.IsZero() && !r.EndedAt.IsZero() {
+				run = append(run, formatDuration(r.EndedAt.Sub(r.StartedAt)))
+			} else if r.Metrics.DurationMS > 0 {
+				run = append(run, formatDuration(time.Duration(r.Metrics.DurationMS)*time.Millisecond))
+			}
+			run = append(run, formatTokens(r.Metrics.InputTokens+r.Metrics.OutputTokens)+" tokens")
+			run = append(run, fmt.Sprintf("$%.2f", r.Metrics.CostUSD))
+			lines = append(lines, lblStyle.Render("Last run")+valStyle.Render(strings.Join(run, " · ")))
+			if len(r.Tools) > 0 {
+				lines = append(lines, lblStyle.Render("Tools")+valStyle.Render(fmt.Sprintf("%d calls", len(r.Tools))))
+			}
+			lines = append(lines, "")
+		}
+
+		if p.action != "" {
+			label := "Feedback"
+			if p.action == HandoffAnswer {
+				label = "Answer"
+			}
+			lines = append(lines, headerStyle.Render(label), p.input.View(), "")
+		}
+		if p.err != "" {
+			lines = append(lines, lipgloss.NewStyle().Foreground(colorRed).Render(wrap.Render(p.err)), "")
+		}
+
+		var hints []string
+		switch {
+		case p.busy:
+			hints = append(hints, dimStyle.Render("Sending..."))
+		case p.action == HandoffAnswer:
+			hints = append(hints, keyStyle.Render("enter")+footerStyle.Render(":answer"), keyStyle.Render("esc")+footerStyle.Render(":close"))
+		case p.action == HandoffReject:
+			hints = append(hints, keyStyle.Render("enter")+footerStyle.Render(":reject"), keyStyle.Render("esc")+footerStyle.Render(":back"))
+		default:
+			hints = append(hints, keyStyle.Render("a")+footerStyle.Render(":approve"), keyStyle.Render("x")+footerStyle.Render(":reject"), keyStyle.Render("esc")+footerStyle.Render(":close"))
+		}
+		lines = append(lines, strings.Join(hints, "  "))
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colorPeach).
+		Background(colorSurface).
+		Padding(1, 2).
+		Width(handoffWidth)
+
+	content := lipgloss.JoinVertical(lipgloss.Left, headerStyle.Render(title), "", strings.Join(lines, "\n"))
+	return placeOverlay(boxStyle.Render(content), m.renderBaseView(), m.width, m.height)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/charmbracelet/x/ansi"
 
 	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
 )
 
 func init() {
@@ -188,6 +189,12 @@ type VerifyResultMsg struct {
 // IdleMsg indicates the engine has entered idle state (watch mode).
 type IdleMsg struct{}
 
+// PausedMsg sets the pause state without sending it to the engine, for when
+// the run was paused or resumed elsewhere (e.g. by another attached client).
+type PausedMsg struct {
+	Paused bool
+}
+
 // -----------------------------------------------------------------------------
 // Context Generation Messages - Epic context generation status updates
 // -----------------------------------------------------------------------------
@@ -706,6 +713,10 @@ type Model struct {
 	conflictBranch string
 	conflictPath   string
 
+	// Handoff review panel (nil when closed)
+	handoffPanel  *handoffState
+	handoffClient HandoffClient
+
 	// Components
 	viewport         viewport.Model
 	tasks            []TaskInfo
@@ -720,6 +731,8 @@ type Model struct {
 	taskRunRecords   map[string]*agent.RunRecord // per-task RunRecord for completed tasks
 	viewingTask      string                      // task ID being viewed (empty = live output)
 	viewingRunRecord bool                        // true when viewing a RunRecord detail view
+	diffView         *diffState                  // diff of the viewed task's run (nil when closed)
+	diffLoader       DiffLoader
 
 	// Tool activity tracking
 	activeTool   *ToolActivityInfo  // currently active tool (nil if none)
@@ -1119,6 +1132,14 @@ type Config struct {
 	MaxCost      float64
 	MaxIteration int
 	PauseChan    chan<- bool
+
+	// Handoff loads and answers tasks awaiting a human from the review
+	// panel ('r'). Nil disables the panel.
+	Handoff HandoffClient
+
+	// Diff loads the diff of a task's run for the diff viewer ('d').
+	// Nil disables the viewer.
+	Diff DiffLoader
 }
 
 // New creates a new TUI model with the given configuration.
@@ -1155,7 +1176,9 @@ func New(cfg Config) Model {
 		taskRunRecords: make(map[string]*agent.RunRecord),
 
 		// Communication
-		pauseChan: cfg.PauseChan,
+		pauseChan:     cfg.PauseChan,
+		handoffClient: cfg.Handoff,
+		diffLoader:    cfg.Diff,
 
 		// Internal
 		keys:       defaultKeyMap,
@@ -1375,6 +1398,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.iteration = msg.Iterations
 		}
 
+	case PausedMsg:
+		m.paused = msg.Paused
+
 	case IdleMsg:
 		// Watch mode: engine is idling, waiting for tasks
 		// Update status to show idle state
@@ -1591,6 +1617,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.updateOutputViewport()
 		}
 
+	case handoffLoadedMsg:
+		m = m.handleHandoffLoaded(msg)
+
+	case handoffRespondedMsg:
+		m = m.handleHandoffResponded(msg)
+
+	case diffLoadedMsg:
+		m = m.handleDiffLoaded(msg)
+
 	case tea.KeyMsg:
 		// Priority 0: If conflict overlay is showing, only allow quit (no dismiss)
 		if m.showConflict {
@@ -1629,7 +1664,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
-		// Priority 3 & 4: Global keys and pane-specific navigation
+		// Priority 3: Handoff review panel takes all keys (it may be typing)
+		if m.handoffPanel != nil {
+			return m.handleHandoffKey(msg)
+		}
+
+		// Priority 4 & 5: Global keys and pane-specific navigation
 		switch msg.String() {
 		case "q", "ctrl+c":
 			m.quitting = true
@@ -1728,6 +1768,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "?":
 			m.showHelp = !m.showHelp
+		case "d":
+			// Toggle the diff of the viewed task's run
+			if m.diffView != nil {
+				m.closeDiff()
+			} else {
+				return m.openDiff()
+			}
+		case "n", "N":
+			// Next/previous file in the diff viewer
+			if m.diffView != nil {
+				if msg.String() == "n" {
+					m.jumpDiffFile(1)
+				} else {
+					m.jumpDiffFile(-1)
+				}
+			}
+		case "r":
+			// Respond to the selected task's handoff
+			if m.focusedPane == PaneTasks {
+				return m.openHandoff()
+			}
 		case "p":
 			m.paused = !m.paused
 			// Send pause state to engine if channel is available
@@ -1750,6 +1811,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// View selected task's details (RunRecord for closed tasks, output for others)
 			if m.focusedPane == PaneTasks && len(m.tasks) > 0 && m.selectedTask < len(m.tasks) {
 				task := m.tasks[m.selectedTask]
+				m.diffView = nil
 				// Prefer RunRecord view for closed tasks
 				if runRecord, ok := m.taskRunRecords[task.ID]; ok && runRecord != nil {
 					m.viewingTask = task.ID
@@ -1766,8 +1828,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		case "esc":
-			// Return to live output
-			if m.viewingTask != "" {
+			// Close the diff viewer, or return to live output
+			if m.diffView != nil {
+				m.closeDiff()
+			} else if m.viewingTask != "" {
 				m.viewingTask = ""
 				m.viewingRunRecord = false
 				m.updateOutputViewport()
@@ -2225,7 +2289,10 @@ func (m *Model) refreshViewportContent() {
 	// Save current scroll position
 	yOffset := m.viewport.YOffset
 
-	if m.viewingTask == "" {
+	if m.diffView != nil {
+		// Viewing a run's diff
+		m.setDiffContent()
+	} else if m.viewingTask == "" {
 		// Viewing live output
 		content := m.buildOutputContent(m.viewport.Width)
 		m.viewport.SetContent(content)
@@ -2256,6 +2323,7 @@ func (m *Model) updateSelectedTaskView() {
 	}
 
 	task := m.tasks[m.selectedTask]
+	m.diffView = nil
 
 	// If this is the current (in-progress) task, show live output
 	if task.IsCurrent {
@@ -2372,6 +2440,15 @@ func (m *Model) buildRunRecordContent(record *agent.RunRecord, width int) string
 		sections = append(sections, lblStyle.Render("Model:")+"  "+valStyle.Render(modelStr))
 	}
 
+	// Commits the run moved HEAD across (diff viewer with 'd')
+	if record.BaseCommit != "" && record.HeadCommit != "" {
+		commitStr := "no commits"
+		if record.BaseCommit != record.HeadCommit {
+			commitStr = gitutil.ShortSHA(record.BaseCommit) + ".." + gitutil.ShortSHA(record.HeadCommit)
+		}
+		sections = append(sections, lblStyle.Render("Commits:")+"  "+valStyle.Render(commitStr))
+	}
+
 	// Result status
 	var resultStr string
 	if record.Success {
@@ -2737,6 +2814,11 @@ func (m Model) View() string {
 		return m.renderConflictOverlay()
 	}
 
+	// If showing handoff review panel, render it on top
+	if m.handoffPanel != nil {
+		return m.renderHandoffOverlay()
+	}
+
 	// Build main layout
 	statusBar := m.renderStatusBar()
 	footer := m.renderFooter()
@@ -3082,7 +3164,9 @@ func (m Model) renderOutputPane(height int) string {
 
 	// Determine header title based on viewing mode
 	var headerTitle string
-	if m.viewingTask != "" {
+	if m.diffView != nil {
+		headerTitle = m.diffHeaderTitle()
+	} else if m.viewingTask != "" {
 		if m.viewingRunRecord {
 			headerTitle = fmt.Sprintf("Run Details [%s]", m.viewingTask)
 		} else {
@@ -3124,7 +3208,10 @@ func (m Model) renderOutputPane(height int) string {
 	contentLines = append(contentLines, lipgloss.NewStyle().Foreground(colorGray).Render(strings.Repeat("─", innerWidth)))
 
 	// Determine what to show
-	if m.paused && len(m.tasks) > 0 && m.selectedTask >= 0 && m.selectedTask < len(m.tasks) {
+	if m.diffView != nil {
+		// Diff viewer: scrollable diff of the viewed task's run
+		contentLines = append(contentLines, m.viewport.View())
+	} else if m.paused && len(m.tasks) > 0 && m.selectedTask >= 0 && m.selectedTask < len(m.tasks) {
 		// Detail mode: show selected task details
 		task := m.tasks[m.selectedTask]
 		detailContent := m.renderTaskDetail(task, innerWidth, innerHeight)
@@ -3258,6 +3345,9 @@ func (m Model) renderFooter() string {
 	} else if m.showHelp {
 		// Help overlay: only close hint
 		hints = append(hints, keyStyle.Render("?")+descStyle.Render(":close"))
+	} else if m.handoffPanel != nil {
+		// Handoff panel: hints are in the panel itself
+		hints = append(hints, keyStyle.Render("esc")+descStyle.Render(":close"))
 	} else {
 		// Normal mode: full hint set
 		hints = append(hints, keyStyle.Render("q")+descStyle.Render(":quit"))
@@ -3270,8 +3360,19 @@ func (m Model) renderFooter() string {
 		}
 
 		hints = append(hints, keyStyle.Render("j/k")+descStyle.Render(":nav"))
+		// Show r:respond when the selected task is waiting on us
+		if m.canRespondToSelected() {
+			hints = append(hints, keyStyle.Render("r")+descStyle.Render(":respond"))
+		}
+		// Show diff hints when a run's diff is open or available
+		if m.diffView != nil {
+			hints = append(hints, keyStyle.Render("n/N")+descStyle.Render(":file"))
+			hints = append(hints, keyStyle.Render("d")+descStyle.Render(":close diff"))
+		} else if m.canViewDiff() {
+			hints = append(hints, keyStyle.Render("d")+descStyle.Render(":diff"))
+		}
 		// Show esc:live hint when viewing historical task output
-		if m.viewingTask != "" {
+		if m.viewingTask != "" && m.diffView == nil {
 			hints = append(hints, keyStyle.Render("esc")+descStyle.Render(":live"))
 		}
 		hints = append(hints, keyStyle.Render("tab")+descStyle.Render(":pane"))
@@ -3521,6 +3622,9 @@ func (m Model) renderHelpOverlay() string {
 	// Actions section
 	lines = append(lines, sectionStyle.Render("Actions"))
 	lines = append(lines, keyStyle.Render("p")+descStyle.Render("Pause/Resume"))
+	lines = append(lines, keyStyle.Render("r")+descStyle.Render("Respond to handoff"))
+	lines = append(lines, keyStyle.Render("d")+descStyle.Render("Diff of task's run"))
+	lines = append(lines, keyStyle.Render("n/N")+descStyle.Render("Next/prev diff file"))
 	lines = append(lines, keyStyle.Render("?")+descStyle.Render("Toggle help"))
 	lines = append(lines, keyStyle.Render("q")+descStyle.Render("Quit"))
 	lines = append(lines, "")
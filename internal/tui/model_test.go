@@ -247,6 +247,30 @@ func TestUpdate_KeyPause(t *testing.T) {
 	}
 }
 
+func TestUpdate_PausedMsg(t *testing.T) {
+	pauseChan := make(chan bool, 1)
+	m := New(Config{PauseChan: pauseChan})
+
+	newModel, _ := m.Update(PausedMsg{Paused: true})
+	m = newModel.(Model)
+	if !m.paused {
+		t.Error("expected paused to be true after PausedMsg")
+	}
+
+	// The state came from elsewhere, so nothing is sent back
+	select {
+	case <-pauseChan:
+		t.Error("expected PausedMsg not to send on the pause channel")
+	default:
+	}
+
+	newModel, _ = m.Update(PausedMsg{Paused: false})
+	m = newModel.(Model)
+	if m.paused {
+		t.Error("expected paused to be false after PausedMsg{Paused: false}")
+	}
+}
+
 func TestUpdate_KeyNavigation(t *testing.T) {
 	m := New(Config{})
 	m.width = 100
@@ -4366,3 +4390,401 @@ func TestContextFailedMsg(t *testing.T) {
 		t.Error("expected output to contain error message")
 	}
 }
+
+// -----------------------------------------------------------------------------
+// Handoff review panel tests
+// -----------------------------------------------------------------------------
+
+// fakeHandoffs is a HandoffClient recording responses.
+type fakeHandoffs struct {
+	handoff    *Handoff
+	respondErr error
+	responses  []string // "action:taskID:text"
+}
+
+func (f *fakeHandoffs) LoadHandoff(taskID string) (*Handoff, error) {
+	if f.handoff == nil {
+		return nil, errors.New("not found")
+	}
+	return f.handoff, nil
+}
+
+func (f *fakeHandoffs) RespondHandoff(taskID string, action HandoffAction, text string) error {
+	f.responses = append(f.responses, fmt.Sprintf("%s:%s:%s", action, taskID, text))
+	return f.respondErr
+}
+
+// handoffModel returns a model with task t1 awaiting awaiting, its review
+// panel opened and loaded.
+func handoffModel(t *testing.T, client *fakeHandoffs, awaiting string) Model {
+	t.Helper()
+	m := New(Config{Handoff: client})
+	m.width, m.height, m.realWidth, m.realHeight = 120, 40, 120, 40
+	m.tasks = []TaskInfo{{ID: "t1", Title: "Add login page", Status: TaskStatusOpen, Awaiting: awaiting}}
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m = newModel.(Model)
+	if m.handoffPanel == nil || cmd == nil {
+		t.Fatalf("expected 'r' to open the handoff panel for a task awaiting %s", awaiting)
+	}
+	newModel, _ = m.Update(cmd())
+	return newModel.(Model)
+}
+
+// typeText sends text to the model one rune at a time.
+func typeText(m Model, text string) Model {
+	for _, r := range text {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = newModel.(Model)
+	}
+	return m
+}
+
+// pressKey sends a key and runs the resulting command, if any, back through
+// the model.
+func pressKey(m Model, key tea.KeyMsg) Model {
+	newModel, cmd := m.Update(key)
+	m = newModel.(Model)
+	if cmd != nil {
+		if msg := cmd(); msg != nil {
+			newModel, _ = m.Update(msg)
+			m = newModel.(Model)
+		}
+	}
+	return m
+}
+
+func TestHandoff_OpenOnlyForAwaitingTasks(t *testing.T) {
+	tests := []struct {
+		awaiting string
+		client   bool
+		want     bool
+	}{
+		{"approval", true, true},
+		{"review", true, true},
+		{"content", true, true},
+		{"input", true, true},
+		{"escalation", true, true},
+		{"work", true, false},
+		{"checkpoint", true, false},
+		{"", true, false},
+		{"approval", false, false},
+	}
+
+	for _, tt := range tests {
+		cfg := Config{}
+		if tt.client {
+			cfg.Handoff = &fakeHandoffs{}
+		}
+		m := New(cfg)
+		m.tasks = []TaskInfo{{ID: "t1", Awaiting: tt.awaiting}}
+
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+		m = newModel.(Model)
+		if got := m.handoffPanel != nil; got != tt.want {
+			t.Errorf("awaiting %q (client %v): panel open = %v, want %v", tt.awaiting, tt.client, got, tt.want)
+		}
+	}
+}
+
+func TestHandoff_Approve(t *testing.T) {
+	client := &fakeHandoffs{handoff: &Handoff{
+		TaskID:   "t1",
+		Title:    "Add login page",
+		Awaiting: "approval",
+		Reason:   "Work complete, requires approval",
+		Notes:    []string{"Implemented the form"},
+		RunRecord: &agent.RunRecord{
+			Model:    "sonnet",
+			NumTurns: 12,
+			Metrics:  agent.MetricsRecord{InputTokens: 1000, OutputTokens: 500, CostUSD: 0.31},
+		},
+	}}
+	m := handoffModel(t, client, "approval")
+
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"Awaiting approval", "[t1] Add login page", "Work complete, requires approval", "Implemented the form", "12 turns", "$0.31", "a:approve"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("handoff panel missing %q", want)
+		}
+	}
+
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if len(client.responses) != 1 || client.responses[0] != "approve:t1:" {
+		t.Errorf("responses = %v, want [approve:t1:]", client.responses)
+	}
+	if m.handoffPanel != nil {
+		t.Error("expected panel to close after approving")
+	}
+	if m.tasks[0].Awaiting != "" {
+		t.Errorf("task awaiting = %q, want cleared", m.tasks[0].Awaiting)
+	}
+}
+
+func TestHandoff_RejectWithFeedback(t *testing.T) {
+	client := &fakeHandoffs{handoff: &Handoff{TaskID: "t1", Title: "Add login page", Awaiting: "review"}}
+	m := handoffModel(t, client, "review")
+
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	if m.handoffPanel.action != HandoffReject {
+		t.Fatalf("action = %q, want %q", m.handoffPanel.action, HandoffReject)
+	}
+
+	// Empty feedback is not sent
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(client.responses) != 0 {
+		t.Errorf("expected no response for empty feedback, got %v", client.responses)
+	}
+
+	// Keys go to the input while typing, not to the panel or global keys
+	m = typeText(m, "add a test")
+	if m.quitting || m.paused {
+		t.Error("expected typed keys not to trigger global keys")
+	}
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(client.responses) != 1 || client.responses[0] != "reject:t1:add a test" {
+		t.Errorf("responses = %v, want [reject:t1:add a test]", client.responses)
+	}
+	if m.handoffPanel != nil {
+		t.Error("expected panel to close after rejecting")
+	}
+}
+
+func TestHandoff_EscBacksOutOfReject(t *testing.T) {
+	client := &fakeHandoffs{handoff: &Handoff{TaskID: "t1", Awaiting: "content"}}
+	m := handoffModel(t, client, "content")
+
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.handoffPanel == nil || m.handoffPanel.action != "" {
+		t.Fatal("expected esc to leave the feedback input but keep the panel open")
+	}
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.handoffPanel != nil {
+		t.Error("expected second esc to close the panel")
+	}
+	if len(client.responses) != 0 {
+		t.Errorf("expected no responses, got %v", client.responses)
+	}
+}
+
+func TestHandoff_Answer(t *testing.T) {
+	client := &fakeHandoffs{handoff: &Handoff{TaskID: "t1", Title: "Pick a database", Awaiting: "input", Reason: "Postgres or SQLite?"}}
+	m := handoffModel(t, client, "input")
+
+	// Questions open straight into the answer input
+	if m.handoffPanel.action != HandoffAnswer {
+		t.Fatalf("action = %q, want %q", m.handoffPanel.action, HandoffAnswer)
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "Postgres or SQLite?") {
+		t.Error("expected panel to show the agent's question")
+	}
+
+	m = typeText(m, "SQLite")
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if len(client.responses) != 1 || client.responses[0] != "answer:t1:SQLite" {
+		t.Errorf("responses = %v, want [answer:t1:SQLite]", client.responses)
+	}
+	if m.handoffPanel != nil {
+		t.Error("expected panel to close after answering")
+	}
+}
+
+func TestHandoff_RespondError(t *testing.T) {
+	client := &fakeHandoffs{
+		handoff:    &Handoff{TaskID: "t1", Awaiting: "approval"},
+		respondErr: errors.New("task is not awaiting"),
+	}
+	m := handoffModel(t, client, "approval")
+
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if m.handoffPanel == nil {
+		t.Fatal("expected panel to stay open after a failed response")
+	}
+	if m.handoffPanel.err != "task is not awaiting" {
+		t.Errorf("err = %q, want %q", m.handoffPanel.err, "task is not awaiting")
+	}
+	if m.tasks[0].Awaiting != "approval" {
+		t.Errorf("task awaiting = %q, want unchanged", m.tasks[0].Awaiting)
+	}
+}
+
+func TestHandoff_LoadError(t *testing.T) {
+	m := handoffModel(t, &fakeHandoffs{}, "approval")
+	if m.handoffPanel.err != "not found" {
+		t.Errorf("err = %q, want %q", m.handoffPanel.err, "not found")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "not found") {
+		t.Error("expected panel to show the load error")
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Diff viewer tests
+// -----------------------------------------------------------------------------
+
+const testDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-func old() {}
++func renamed() {}
+diff --git a/README.md b/README.md
+index 3333333..4444444 100644
+--- a/README.md
++++ b/README.md
+@@ -1 +1,2 @@
+ # Title
++More docs
+`
+
+func TestDiffFileName(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"diff --git a/main.go b/main.go", "main.go"},
+		{"diff --git a/internal/tui/diff.go b/internal/tui/diff.go", "internal/tui/diff.go"},
+		{"diff --git a/old.go b/new.go", "new.go"},
+	}
+	for _, tt := range tests {
+		if got := diffFileName(tt.line); got != tt.want {
+			t.Errorf("diffFileName(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightDiff(t *testing.T) {
+	lines, files := highlightDiff(testDiff)
+
+	if len(lines) != 15 {
+		t.Fatalf("highlightDiff() returned %d lines, want 15", len(lines))
+	}
+	if len(files) != 2 || files[0] != (diffFile{name: "main.go", line: 0}) || files[1] != (diffFile{name: "README.md", line: 8}) {
+		t.Errorf("files = %+v, want main.go at 0 and README.md at 8", files)
+	}
+
+	// Highlighting keeps the text of every line
+	for i, want := range strings.Split(strings.TrimRight(testDiff, "\n"), "\n") {
+		if got := ansi.Strip(lines[i]); got != want {
+			t.Errorf("line %d = %q, want %q", i, got, want)
+		}
+	}
+
+	if lines, files := highlightDiff(""); lines != nil || files != nil {
+		t.Errorf("highlightDiff(\"\") = %v, %v, want nil", lines, files)
+	}
+}
+
+// diffModel returns a model viewing task t1's run record, which moved HEAD
+// from base to head. The viewport is short enough for the test diff to
+// scroll.
+func diffModel(load DiffLoader, base, head string) Model {
+	m := New(Config{Diff: load})
+	m.width, m.height, m.realWidth, m.realHeight = 120, 40, 120, 40
+	m.updateViewportSize()
+	m.viewport.Height = 5
+	m.tasks = []TaskInfo{{ID: "t1", Title: "Rename", Status: TaskStatusClosed}}
+	m.taskRunRecords["t1"] = &agent.RunRecord{BaseCommit: base, HeadCommit: head}
+	m.updateSelectedTaskView()
+	return m
+}
+
+func TestDiffViewer(t *testing.T) {
+	var gotFrom, gotTo string
+	load := func(from, to string) (string, error) {
+		gotFrom, gotTo = from, to
+		return testDiff, nil
+	}
+	m := diffModel(load, "aaaaaaaaaa", "bbbbbbbbbb")
+
+	if !strings.Contains(ansi.Strip(m.buildRunRecordContent(m.taskRunRecords["t1"], 100)), "aaaaaaa..bbbbbbb") {
+		t.Error("expected run details to show the commit range")
+	}
+
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if m.diffView == nil {
+		t.Fatal("expected 'd' to open the diff viewer")
+	}
+	if gotFrom != "aaaaaaaaaa" || gotTo != "bbbbbbbbbb" {
+		t.Errorf("loaded diff %s..%s, want aaaaaaaaaa..bbbbbbbbbb", gotFrom, gotTo)
+	}
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"Diff [t1] aaaaaaa..bbbbbbb", "main.go (1/2)", "diff --git a/main.go b/main.go"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("diff view missing %q", want)
+		}
+	}
+
+	// n/N move between files
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if m.viewport.YOffset != 8 {
+		t.Errorf("after n: YOffset = %d, want 8", m.viewport.YOffset)
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "README.md (2/2)") || !strings.Contains(view, "diff --git a/README.md") {
+		t.Error("expected the second file after n")
+	}
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	if m.viewport.YOffset != 0 {
+		t.Errorf("after N: YOffset = %d, want 0", m.viewport.YOffset)
+	}
+
+	// esc goes back to the run details, not live output
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.diffView != nil {
+		t.Error("expected esc to close the diff viewer")
+	}
+	if m.viewingTask != "t1" || !m.viewingRunRecord {
+		t.Errorf("after esc: viewing %q (run record %v), want t1 run record", m.viewingTask, m.viewingRunRecord)
+	}
+}
+
+func TestDiffViewer_NoCommits(t *testing.T) {
+	loaded := false
+	load := func(from, to string) (string, error) {
+		loaded = true
+		return "", nil
+	}
+	m := diffModel(load, "aaaaaaa", "aaaaaaa")
+
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if loaded {
+		t.Error("expected no diff load when the run made no commits")
+	}
+	if !strings.Contains(ansi.Strip(m.View()), "No changes committed in this run") {
+		t.Error("expected the no-commits message")
+	}
+}
+
+func TestDiffViewer_LoadError(t *testing.T) {
+	load := func(from, to string) (string, error) {
+		return "", errors.New("bad object")
+	}
+	m := diffModel(load, "aaaaaaa", "bbbbbbb")
+
+	m = pressKey(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if !strings.Contains(ansi.Strip(m.View()), "Could not load diff: bad object") {
+		t.Error("expected the load error in the diff viewer")
+	}
+}
+
+func TestDiffViewer_Unavailable(t *testing.T) {
+	load := func(from, to string) (string, error) { return testDiff, nil }
+	tests := []struct {
+		name string
+		m    Model
+	}{
+		{"no loader", diffModel(nil, "aaaaaaa", "bbbbbbb")},
+		{"no commits recorded", diffModel(load, "", "")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := pressKey(tt.m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+			if m.diffView != nil {
+				t.Error("expected 'd' not to open the diff viewer")
+			}
+		})
+	}
+}
@@ -60,7 +60,7 @@ func TestStreamingPipeline_BasicTextFlow(t *testing.T) {
 	state := &agent.AgentState{}
 	mockProgram := &mockTUIProgram{}
 
-	// Track deltas for message conversion (mimics main.go OnAgentState)
+	// Track deltas for message conversion (mimics main.go agent state forwarding)
 	var prevOutput string
 
 	// Create parser with callback that converts to TUI messages
@@ -759,7 +759,7 @@ func TestStreamingPipeline_FullEndToEnd(t *testing.T) {
 
 	var prevOutput, prevThinking, prevToolID string
 
-	// Full callback that mimics main.go OnAgentState
+	// Full callback that mimics main.go agent state forwarding
 	parser := agent.NewStreamParser(state, func() {
 		snap := state.Snapshot()
 
@@ -953,7 +953,7 @@ func TestStreamingPipeline_NewlinePreservation(t *testing.T) {
 	parser := agent.NewStreamParser(state, func() {
 		snap := state.Snapshot()
 
-		// Convert output delta (mimics main.go OnAgentState)
+		// Convert output delta (mimics main.go agent state forwarding)
 		if snap.Output != prevOutput {
 			delta := snap.Output[len(prevOutput):]
 			if delta != "" {
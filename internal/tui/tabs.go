@@ -191,6 +191,7 @@ func (m *Model) syncFromActiveTab() {
 	m.taskRunRecords = tab.TaskRunRecords
 	m.viewingTask = tab.ViewingTask
 	m.viewingRunRecord = tab.ViewingRunRecord
+	m.diffView = nil
 
 	// Sync iteration state
 	m.iteration = tab.Iteration
@@ -0,0 +1,143 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// maxCommandOutput is how much command output is kept in a Result.
+// The tail is kept since test and build failures are reported last.
+const maxCommandOutput = 8 * 1024
+
+// commandWaitDelay bounds how long to wait for output after the command is
+// killed on timeout.
+const commandWaitDelay = time.Second
+
+// CommandVerifier runs a shell command (tests, lint, build) and passes if it
+// exits with status zero. Configured via verification.commands.
+type CommandVerifier struct {
+	name     string
+	command  string
+	dir      string
+	timeout  time.Duration
+	env      map[string]string
+	required bool
+	deps     []string
+}
+
+// NewCommandVerifier creates a command verifier from cfg.
+// A relative cfg.WorkDir is resolved against dir.
+func NewCommandVerifier(dir string, cfg *CommandConfig) *CommandVerifier {
+	workDir := dir
+	if wd := cfg.GetWorkDir(); wd != "" {
+		if filepath.IsAbs(wd) {
+			workDir = wd
+		} else {
+			workDir = filepath.Join(dir, wd)
+		}
+	}
+	return &CommandVerifier{
+		name:     cfg.Name,
+		command:  cfg.Command,
+		dir:      workDir,
+		timeout:  cfg.GetTimeout(),
+		env:      cfg.Env,
+		required: cfg.IsRequired(),
+		deps:     cfg.DependsOn,
+	}
+}
+
+// Name returns the configured verifier name.
+func (v *CommandVerifier) Name() string {
+	return v.name
+}
+
+// DependsOn returns the verifiers that must pass before this one runs.
+func (v *CommandVerifier) DependsOn() []string {
+	return v.deps
+}
+
+// IsOptional reports whether the command was configured with required: false.
+func (v *CommandVerifier) IsOptional() bool {
+	return !v.required
+}
+
+// Verify runs the command and captures its combined output.
+// Fails on a non-zero exit, a timeout, or if the command can't be started.
+// The task ID is exported to the command as TICKER_TASK_ID.
+func (v *CommandVerifier) Verify(ctx context.Context, taskID string, agentOutput string) *Result {
+	start := time.Now()
+
+	result := &Result{
+		Verifier: v.Name(),
+		Optional: !v.required,
+	}
+
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	cmd := shellCommand(ctx, v.command)
+	cmd.Dir = v.dir
+	// Don't wait for orphaned children holding the output pipe after a timeout
+	cmd.WaitDelay = commandWaitDelay
+	cmd.Env = append(os.Environ(), "TICKER_TASK_ID="+taskID)
+	keys := make([]string, 0, len(v.env))
+	for k := range v.env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		cmd.Env = append(cmd.Env, k+"="+v.env[k])
+	}
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	result.Duration = time.Since(start)
+	result.Output = tail(output.String(), maxCommandOutput)
+
+	if err == nil {
+		result.Passed = true
+		return result
+	}
+
+	result.Passed = false
+	result.Error = err
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		result.Error = fmt.Errorf("timed out after %v", v.timeout)
+		result.Output = result.Error.Error() + "\n" + result.Output
+	}
+	if result.Output == "" {
+		result.Output = result.Error.Error()
+	}
+	return result
+}
+
+// shellCommand runs command with the platform shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// tail returns the last max bytes of s, marking the cut.
+func tail(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return "...(truncated)\n" + s[len(s)-max:]
+}
@@ -0,0 +1,130 @@
+package verify
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func skipWithoutShell(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("command verifier tests use POSIX shell syntax")
+	}
+}
+
+func TestCommandVerifier_Verify(t *testing.T) {
+	skipWithoutShell(t)
+
+	tests := []struct {
+		name       string
+		cfg        *CommandConfig
+		wantPassed bool
+		wantOutput string
+	}{
+		{
+			name:       "passing command",
+			cfg:        &CommandConfig{Name: "test", Command: "echo all good"},
+			wantPassed: true,
+			wantOutput: "all good",
+		},
+		{
+			name:       "failing command captures stderr",
+			cfg:        &CommandConfig{Name: "test", Command: "echo 'FAIL: TestFoo' >&2; exit 1"},
+			wantPassed: false,
+			wantOutput: "FAIL: TestFoo",
+		},
+		{
+			name:       "env and task id",
+			cfg:        &CommandConfig{Name: "env", Command: `echo "$GREETING $TICKER_TASK_ID"`, Env: map[string]string{"GREETING": "hello"}},
+			wantPassed: true,
+			wantOutput: "hello task-1",
+		},
+		{
+			name:       "timeout",
+			cfg:        &CommandConfig{Name: "slow", Command: "sleep 5", Timeout: strPtr("100ms")},
+			wantPassed: false,
+			wantOutput: "timed out after 100ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewCommandVerifier(t.TempDir(), tt.cfg)
+			result := v.Verify(context.Background(), "task-1", "")
+
+			if result.Verifier != tt.cfg.Name {
+				t.Errorf("Verifier = %q, want %q", result.Verifier, tt.cfg.Name)
+			}
+			if result.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v (output: %q)", result.Passed, tt.wantPassed, result.Output)
+			}
+			if !strings.Contains(result.Output, tt.wantOutput) {
+				t.Errorf("Output = %q, want to contain %q", result.Output, tt.wantOutput)
+			}
+			if !tt.wantPassed && result.Error == nil {
+				t.Error("Error = nil, want error for failed command")
+			}
+		})
+	}
+}
+
+func TestCommandVerifier_WorkDir(t *testing.T) {
+	skipWithoutShell(t)
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "marker"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := NewCommandVerifier(dir, &CommandConfig{Name: "ls", Command: "test -f marker", WorkDir: strPtr("sub")})
+	if result := v.Verify(context.Background(), "", ""); !result.Passed {
+		t.Errorf("Verify() in workdir failed: %q", result.Output)
+	}
+}
+
+func TestCommandVerifier_Optional(t *testing.T) {
+	skipWithoutShell(t)
+
+	required := false
+	v := NewCommandVerifier(t.TempDir(), &CommandConfig{Name: "lint", Command: "exit 1", Required: &required})
+	result := v.Verify(context.Background(), "", "")
+	if result.Passed || !result.Optional {
+		t.Errorf("Verify() = Passed %v, Optional %v; want failed optional result", result.Passed, result.Optional)
+	}
+	if results := NewResults([]*Result{result}); !results.AllPassed {
+		t.Error("optional failure should not fail AllPassed")
+	}
+	if !v.IsOptional() {
+		t.Error("IsOptional() = false, want true for required: false")
+	}
+}
+
+func TestCommandVerifier_DependsOn(t *testing.T) {
+	var v Verifier = NewCommandVerifier(t.TempDir(), &CommandConfig{Name: "test", Command: "make test", DependsOn: []string{"build"}})
+	d, ok := v.(Dependent)
+	if !ok {
+		t.Fatal("CommandVerifier should implement Dependent")
+	}
+	if got := d.DependsOn(); len(got) != 1 || got[0] != "build" {
+		t.Errorf("DependsOn() = %v, want [build]", got)
+	}
+}
+
+func TestTail(t *testing.T) {
+	if got := tail("short", 10); got != "short" {
+		t.Errorf("tail() = %q, want unchanged", got)
+	}
+	got := tail("0123456789", 4)
+	if !strings.HasSuffix(got, "6789") || !strings.HasPrefix(got, "...(truncated)") {
+		t.Errorf("tail() = %q, want truncated marker and last 4 bytes", got)
+	}
+}
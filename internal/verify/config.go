@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
 )
 
 // Config holds verification configuration loaded from .ticker/config.json.
@@ -14,6 +18,336 @@ type Config struct {
 	// Enabled controls whether verification runs (default true).
 	// Set to false to completely skip verification.
 	Enabled *bool `json:"enabled,omitempty"`
+
+	// Commands are extra verifiers (tests, lint, build) run after the git
+	// check, in order unless they declare dependencies.
+	Commands []*CommandConfig `json:"commands,omitempty"`
+
+	// MaxParallel is how many independent verifiers may run at once
+	// (default 1 = sequential).
+	MaxParallel *int `json:"max_parallel,omitempty"`
+
+	// Review configures the acceptance-criteria reviewer (off by default).
+	Review *ReviewConfig `json:"review,omitempty"`
+}
+
+// ReviewConfig configures the CriteriaVerifier, which asks a read-only agent
+// whether the task's acceptance criteria are met.
+//
+// Example:
+//
+//	{
+//	  "verification": {
+//	    "review": {"enabled": true, "agent": "claude", "model": "sonnet", "timeout": "5m"}
+//	  }
+//	}
+type ReviewConfig struct {
+	// Enabled turns on the reviewer for tasks with acceptance criteria
+	// (default false).
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Agent is the agent that reviews (default "" = the task's agent).
+	Agent *string `json:"agent,omitempty"`
+
+	// Model overrides the reviewing agent's model (default "" = its default).
+	Model *string `json:"model,omitempty"`
+
+	// Timeout is the max review duration as a string (default "10m").
+	Timeout *string `json:"timeout,omitempty"`
+
+	// Required controls whether unmet criteria fail verification (default true).
+	Required *bool `json:"required,omitempty"`
+}
+
+// DefaultReviewTimeout is the default timeout for the reviewer agent.
+const DefaultReviewTimeout = 10 * time.Minute
+
+// IsEnabled returns whether the reviewer runs (default false).
+func (c *ReviewConfig) IsEnabled() bool {
+	return c != nil && c.Enabled != nil && *c.Enabled
+}
+
+// GetAgent returns the reviewing agent name (default "" = the task's agent).
+func (c *ReviewConfig) GetAgent() string {
+	if c == nil || c.Agent == nil {
+		return ""
+	}
+	return *c.Agent
+}
+
+// GetModel returns the model override (default "").
+func (c *ReviewConfig) GetModel() string {
+	if c == nil || c.Model == nil {
+		return ""
+	}
+	return *c.Model
+}
+
+// GetTimeout returns the review timeout (default 10m).
+func (c *ReviewConfig) GetTimeout() time.Duration {
+	if c == nil || c.Timeout == nil {
+		return DefaultReviewTimeout
+	}
+	d, err := time.ParseDuration(*c.Timeout)
+	if err != nil {
+		return DefaultReviewTimeout
+	}
+	return d
+}
+
+// IsRequired returns whether unmet criteria fail verification (default true).
+func (c *ReviewConfig) IsRequired() bool {
+	if c == nil || c.Required == nil {
+		return true
+	}
+	return *c.Required
+}
+
+// Validate checks that config values are within sensible ranges.
+// Returns nil if valid, or an error describing the problem.
+func (c *ReviewConfig) Validate() error {
+	if c == nil || c.Timeout == nil {
+		return nil
+	}
+	d, err := time.ParseDuration(*c.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+	if d < 30*time.Second {
+		return fmt.Errorf("timeout must be at least 30s, got %v", d)
+	}
+	if d > time.Hour {
+		return fmt.Errorf("timeout must be at most 1h, got %v", d)
+	}
+	return nil
+}
+
+// DefaultMaxParallel is the default verifier concurrency (sequential).
+const DefaultMaxParallel = 1
+
+// CommandConfig configures a CommandVerifier.
+//
+// Example:
+//
+//	{
+//	  "verification": {
+//	    "commands": [
+//	      {"name": "build", "command": "go build ./..."},
+//	      {"name": "test", "command": "go test ./...", "timeout": "10m", "depends_on": ["build"]},
+//	      {"name": "lint", "command": "golangci-lint run", "required": false}
+//	    ]
+//	  }
+//	}
+type CommandConfig struct {
+	// Name identifies the verifier in results and notes (required).
+	Name string `json:"name"`
+
+	// Command is run with the system shell (required).
+	Command string `json:"command"`
+
+	// WorkDir is the directory to run in, relative to the verified
+	// directory (default "" = the verified directory itself).
+	WorkDir *string `json:"workdir,omitempty"`
+
+	// Timeout is the max duration as a string (default "10m").
+	Timeout *string `json:"timeout,omitempty"`
+
+	// Env holds extra environment variables for the command.
+	Env map[string]string `json:"env,omitempty"`
+
+	// Required controls whether a failure fails verification (default true).
+	// Failures of optional verifiers are reported but don't reopen the task.
+	Required *bool `json:"required,omitempty"`
+
+	// DependsOn names verifiers ("git" or other commands) that must pass
+	// before this one runs. If one fails, this verifier is skipped.
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// DefaultCommandTimeout is the default timeout for command verifiers.
+const DefaultCommandTimeout = 10 * time.Minute
+
+// GetWorkDir returns the working directory (default "").
+func (c *CommandConfig) GetWorkDir() string {
+	if c == nil || c.WorkDir == nil {
+		return ""
+	}
+	return *c.WorkDir
+}
+
+// GetTimeout returns the command timeout (default 10m).
+func (c *CommandConfig) GetTimeout() time.Duration {
+	if c == nil || c.Timeout == nil {
+		return DefaultCommandTimeout
+	}
+	d, err := time.ParseDuration(*c.Timeout)
+	if err != nil {
+		return DefaultCommandTimeout
+	}
+	return d
+}
+
+// IsRequired returns whether a failure fails verification (default true).
+func (c *CommandConfig) IsRequired() bool {
+	if c == nil || c.Required == nil {
+		return true
+	}
+	return *c.Required
+}
+
+// Validate checks that the command is well-formed.
+// Returns nil if valid, or an error describing the problem.
+func (c *CommandConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.Name == "git" || c.Name == CriteriaVerifierName {
+		return fmt.Errorf("name %q conflicts with a built-in verifier", c.Name)
+	}
+	if c.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+	if c.Timeout != nil {
+		d, err := time.ParseDuration(*c.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout: %w", err)
+		}
+		if d < time.Second {
+			return fmt.Errorf("timeout must be at least 1s, got %v", d)
+		}
+		if d > 2*time.Hour {
+			return fmt.Errorf("timeout must be at most 2h, got %v", d)
+		}
+	}
+	return nil
+}
+
+// GetReview returns the reviewer config (nil if not configured).
+func (c *Config) GetReview() *ReviewConfig {
+	if c == nil {
+		return nil
+	}
+	return c.Review
+}
+
+// GetMaxParallel returns the verifier concurrency (default 1).
+func (c *Config) GetMaxParallel() int {
+	if c == nil || c.MaxParallel == nil {
+		return DefaultMaxParallel
+	}
+	return *c.MaxParallel
+}
+
+// Validate checks that command verifiers are well-formed and uniquely named,
+// and that their dependencies exist and don't form a cycle.
+// Returns nil if valid, or an error describing the problem.
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.MaxParallel != nil {
+		if *c.MaxParallel < 1 {
+			return fmt.Errorf("max_parallel must be at least 1, got %d", *c.MaxParallel)
+		}
+		if *c.MaxParallel > 32 {
+			return fmt.Errorf("max_parallel must be at most 32, got %d", *c.MaxParallel)
+		}
+	}
+
+	if err := c.Review.Validate(); err != nil {
+		return fmt.Errorf("review: %w", err)
+	}
+
+	seen := map[string]bool{"git": true, CriteriaVerifierName: true}
+	for i, cmd := range c.Commands {
+		if cmd == nil {
+			return fmt.Errorf("command %d: missing config", i+1)
+		}
+		if err := cmd.Validate(); err != nil {
+			return fmt.Errorf("command %d: %w", i+1, err)
+		}
+		if seen[cmd.Name] {
+			return fmt.Errorf("command %d: duplicate name %q", i+1, cmd.Name)
+		}
+		seen[cmd.Name] = true
+	}
+
+	deps := make(map[string][]string)
+	for i, cmd := range c.Commands {
+		for _, dep := range cmd.DependsOn {
+			if dep == cmd.Name {
+				return fmt.Errorf("command %d: %q depends on itself", i+1, cmd.Name)
+			}
+			if !seen[dep] {
+				return fmt.Errorf("command %d: unknown dependency %q", i+1, dep)
+			}
+		}
+		deps[cmd.Name] = cmd.DependsOn
+	}
+	if cycle := findCycle(deps); cycle != nil {
+		return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// findCycle returns the names along a dependency cycle, or nil if there is none.
+func findCycle(deps map[string][]string) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visiting:
+			for i, n := range path {
+				if n == name {
+					return append(append([]string(nil), path[i:]...), name)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// CommandVerifiers creates a CommandVerifier for each configured command,
+// running relative to dir.
+func (c *Config) CommandVerifiers(dir string) []Verifier {
+	if c == nil {
+		return nil
+	}
+	verifiers := make([]Verifier, 0, len(c.Commands))
+	for _, cmd := range c.Commands {
+		verifiers = append(verifiers, NewCommandVerifier(dir, cmd))
+	}
+	return verifiers
 }
 
 // IsEnabled returns whether verification is enabled (default true).
@@ -36,17 +370,39 @@ type ContextConfig struct {
 	// AutoRefreshDays is how many days until auto-refresh (default 0 = never).
 	AutoRefreshDays *int `json:"auto_refresh_days,omitempty"`
 
+	// RefreshAfterCommits regenerates context once more than this many
+	// commits have landed since it was generated (default 0 = never).
+	RefreshAfterCommits *int `json:"refresh_after_commits,omitempty"`
+
 	// GenerationTimeout is the max duration for generation as a string (default "5m").
 	GenerationTimeout *string `json:"generation_timeout,omitempty"`
 
 	// GenerationModel overrides the model used for generation (default "" = use default agent).
 	GenerationModel *string `json:"generation_model,omitempty"`
+
+	// UpdateAfterTask revises the context after each verified task completion
+	// from the task's diff, so later tasks see current code (default false).
+	UpdateAfterTask *bool `json:"update_after_task,omitempty"`
+
+	// UpdateModel overrides the model used for updates (default "" = the
+	// generation model). Updates are small, so a cheaper model usually does.
+	UpdateModel *string `json:"update_model,omitempty"`
+
+	// RepoMap controls the repository-wide codebase map shared by all epics
+	// and included in every iteration prompt (default true).
+	RepoMap *bool `json:"repo_map,omitempty"`
+
+	// RepoMapRefreshCommits regenerates the codebase map once more than this
+	// many commits have landed since it was generated (default 100, 0 = never).
+	RepoMapRefreshCommits *int `json:"repo_map_refresh_commits,omitempty"`
 }
 
 // Default values for context configuration.
 const (
 	DefaultContextMaxTokens       = 4000
 	DefaultContextAutoRefreshDays = 0
+	DefaultContextRefreshCommits  = 0
+	DefaultRepoMapRefreshCommits  = 100
 	DefaultContextTimeout         = 5 * time.Minute
 )
 
@@ -74,6 +430,14 @@ func (c *ContextConfig) GetAutoRefreshDays() int {
 	return *c.AutoRefreshDays
 }
 
+// GetRefreshAfterCommits returns the commit-count refresh threshold (default 0).
+func (c *ContextConfig) GetRefreshAfterCommits() int {
+	if c == nil || c.RefreshAfterCommits == nil {
+		return DefaultContextRefreshCommits
+	}
+	return *c.RefreshAfterCommits
+}
+
 // GetGenerationTimeout returns the generation timeout (default 5m).
 func (c *ContextConfig) GetGenerationTimeout() time.Duration {
 	if c == nil || c.GenerationTimeout == nil {
@@ -94,6 +458,45 @@ func (c *ContextConfig) GetGenerationModel() string {
 	return *c.GenerationModel
 }
 
+// IsUpdateAfterTask returns whether context is updated after each verified
+// task completion (default false).
+func (c *ContextConfig) IsUpdateAfterTask() bool {
+	if c == nil || c.UpdateAfterTask == nil {
+		return false
+	}
+	return *c.UpdateAfterTask
+}
+
+// GetUpdateModel returns the model for context updates, falling back to the
+// generation model.
+func (c *ContextConfig) GetUpdateModel() string {
+	if c == nil || c.UpdateModel == nil {
+		return c.GetGenerationModel()
+	}
+	return *c.UpdateModel
+}
+
+// IsRepoMapEnabled returns whether the shared codebase map is used (default
+// true). It is off whenever context generation is disabled.
+func (c *ContextConfig) IsRepoMapEnabled() bool {
+	if !c.IsEnabled() {
+		return false
+	}
+	if c == nil || c.RepoMap == nil {
+		return true
+	}
+	return *c.RepoMap
+}
+
+// GetRepoMapRefreshCommits returns the codebase map's commit-count refresh
+// threshold (default 100).
+func (c *ContextConfig) GetRepoMapRefreshCommits() int {
+	if c == nil || c.RepoMapRefreshCommits == nil {
+		return DefaultRepoMapRefreshCommits
+	}
+	return *c.RepoMapRefreshCommits
+}
+
 // Validate checks that config values are within sensible ranges.
 // Returns nil if valid, or an error describing the problem.
 func (c *ContextConfig) Validate() error {
@@ -132,15 +535,211 @@ func (c *ContextConfig) Validate() error {
 		}
 	}
 
+	if c.RefreshAfterCommits != nil {
+		if *c.RefreshAfterCommits < 0 {
+			return fmt.Errorf("refresh_after_commits must be non-negative, got %d", *c.RefreshAfterCommits)
+		}
+		if *c.RefreshAfterCommits > 10000 {
+			return fmt.Errorf("refresh_after_commits must be at most 10000, got %d", *c.RefreshAfterCommits)
+		}
+	}
+
+	if c.RepoMapRefreshCommits != nil {
+		if *c.RepoMapRefreshCommits < 0 {
+			return fmt.Errorf("repo_map_refresh_commits must be non-negative, got %d", *c.RepoMapRefreshCommits)
+		}
+		if *c.RepoMapRefreshCommits > 10000 {
+			return fmt.Errorf("repo_map_refresh_commits must be at most 10000, got %d", *c.RepoMapRefreshCommits)
+		}
+	}
+
 	// generation_model is free-form string, no validation needed
 
 	return nil
 }
 
+// EscalationConfig holds the escalation ladder from .ticker/config.json.
+// When a task is retried or reopened by verification, the next attempt runs
+// at the next tier. The engine only gives up on a stuck task once the top
+// tier has failed.
+//
+// Example:
+//
+//	{
+//	  "escalation": {
+//	    "tiers": [
+//	      {"model": "sonnet", "timeout": "45m"},
+//	      {"model": "opus", "timeout": "1h", "extra_thinking": true}
+//	    ]
+//	  }
+//	}
+type EscalationConfig struct {
+	// Tiers are applied in order, one per failed attempt (default none).
+	Tiers []EscalationTier `json:"tiers,omitempty"`
+}
+
+// EscalationTier describes how an escalated attempt differs from the base run.
+// Unset fields keep the base setting.
+type EscalationTier struct {
+	// Model overrides the agent's model (e.g. "opus").
+	Model *string `json:"model,omitempty"`
+
+	// Timeout overrides the per-iteration agent timeout as a string (e.g. "1h").
+	Timeout *string `json:"timeout,omitempty"`
+
+	// ExtraThinking adds a prompt section asking the agent to think harder
+	// about why earlier attempts failed.
+	ExtraThinking *bool `json:"extra_thinking,omitempty"`
+}
+
+// GetTiers returns the configured tiers (default none).
+func (c *EscalationConfig) GetTiers() []EscalationTier {
+	if c == nil {
+		return nil
+	}
+	return c.Tiers
+}
+
+// GetModel returns the model override (default "" = agent default).
+func (t EscalationTier) GetModel() string {
+	if t.Model == nil {
+		return ""
+	}
+	return *t.Model
+}
+
+// GetTimeout returns the timeout override (default 0 = base timeout).
+func (t EscalationTier) GetTimeout() time.Duration {
+	if t.Timeout == nil {
+		return 0
+	}
+	d, err := time.ParseDuration(*t.Timeout)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// GetExtraThinking returns whether the extra-thinking prompt section is added (default false).
+func (t EscalationTier) GetExtraThinking() bool {
+	return t.ExtraThinking != nil && *t.ExtraThinking
+}
+
+// Validate checks that tier timeouts parse and are within sensible ranges.
+// Returns nil if valid, or an error describing the problem.
+func (c *EscalationConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	for i, tier := range c.Tiers {
+		if tier.Timeout != nil {
+			d, err := time.ParseDuration(*tier.Timeout)
+			if err != nil {
+				return fmt.Errorf("tier %d: invalid timeout: %w", i+1, err)
+			}
+			if d < time.Minute {
+				return fmt.Errorf("tier %d: timeout must be at least 1m, got %v", i+1, d)
+			}
+			if d > 24*time.Hour {
+				return fmt.Errorf("tier %d: timeout must be at most 24h, got %v", i+1, d)
+			}
+		}
+	}
+
+	return nil
+}
+
+// PromptsConfig holds prompt template settings from .ticker/config.json.
+// The templates themselves live in .ticker/prompts.
+type PromptsConfig struct {
+	// Vars are user-defined variables available to iteration prompt
+	// templates as {{.Vars.name}}.
+	Vars map[string]string `json:"vars,omitempty"`
+
+	// MaxTokens is the budget for the iteration prompt, in estimated tokens.
+	// When exceeded, the oldest epic notes are left out. The task and human
+	// feedback are always included. 0 or unset means no limit.
+	MaxTokens *int `json:"max_tokens,omitempty"`
+
+	// CompactNotesTokens is how large (in estimated tokens) the epic notes
+	// may grow before an agent summarizes them into a notes digest written
+	// back to the epic. 0 or unset disables compaction.
+	CompactNotesTokens *int `json:"compact_notes_tokens,omitempty"`
+
+	// CompactionModel is the model used to summarize notes.
+	// Empty means the agent's default model.
+	CompactionModel *string `json:"compaction_model,omitempty"`
+}
+
+// GetVars returns the template variables (nil if none).
+func (c *PromptsConfig) GetVars() map[string]string {
+	if c == nil {
+		return nil
+	}
+	return c.Vars
+}
+
+// GetMaxTokens returns the prompt token budget (0 = no limit).
+func (c *PromptsConfig) GetMaxTokens() int {
+	if c == nil || c.MaxTokens == nil {
+		return 0
+	}
+	return *c.MaxTokens
+}
+
+// GetCompactNotesTokens returns the notes size that triggers compaction
+// (0 = never compact).
+func (c *PromptsConfig) GetCompactNotesTokens() int {
+	if c == nil || c.CompactNotesTokens == nil {
+		return 0
+	}
+	return *c.CompactNotesTokens
+}
+
+// GetCompactionModel returns the model for notes compaction ("" = agent default).
+func (c *PromptsConfig) GetCompactionModel() string {
+	if c == nil || c.CompactionModel == nil {
+		return ""
+	}
+	return *c.CompactionModel
+}
+
+// Validate checks that the token settings are within sensible ranges.
+// Returns nil if valid, or an error describing the problem.
+func (c *PromptsConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.MaxTokens != nil && *c.MaxTokens != 0 {
+		if *c.MaxTokens < 1000 {
+			return fmt.Errorf("max_tokens must be 0 or at least 1000, got %d", *c.MaxTokens)
+		}
+		if *c.MaxTokens > 1000000 {
+			return fmt.Errorf("max_tokens must be at most 1000000, got %d", *c.MaxTokens)
+		}
+	}
+
+	if c.CompactNotesTokens != nil && *c.CompactNotesTokens != 0 {
+		if *c.CompactNotesTokens < 500 {
+			return fmt.Errorf("compact_notes_tokens must be 0 or at least 500, got %d", *c.CompactNotesTokens)
+		}
+		if *c.CompactNotesTokens > 1000000 {
+			return fmt.Errorf("compact_notes_tokens must be at most 1000000, got %d", *c.CompactNotesTokens)
+		}
+	}
+
+	return nil
+}
+
 // TickerConfig is the root config structure for .ticker/config.json.
 type TickerConfig struct {
-	Verification *Config        `json:"verification,omitempty"`
-	Context      *ContextConfig `json:"context,omitempty"`
+	Verification *Config           `json:"verification,omitempty"`
+	Context      *ContextConfig    `json:"context,omitempty"`
+	Agent        *agent.Config     `json:"agent,omitempty"`
+	Escalation   *EscalationConfig `json:"escalation,omitempty"`
+	Prompts      *PromptsConfig    `json:"prompts,omitempty"`
 }
 
 // LoadTickerConfig loads the full configuration from .ticker/config.json in the given directory.
@@ -163,6 +762,13 @@ func LoadTickerConfig(dir string) (*TickerConfig, error) {
 		return nil, err
 	}
 
+	// Validate verification config if present
+	if tickerConfig.Verification != nil {
+		if err := tickerConfig.Verification.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid verification config: %w", err)
+		}
+	}
+
 	// Validate context config if present
 	if tickerConfig.Context != nil {
 		if err := tickerConfig.Context.Validate(); err != nil {
@@ -170,6 +776,35 @@ func LoadTickerConfig(dir string) (*TickerConfig, error) {
 		}
 	}
 
+	// Validate agent config if present
+	if tickerConfig.Agent != nil {
+		if err := tickerConfig.Agent.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid agent config: %w", err)
+		}
+	}
+
+	// The reviewer must be an agent that exists and can run read-only;
+	// otherwise review would fail every task, or be silently skipped
+	if name := tickerConfig.Verification.GetReview().GetAgent(); name != "" {
+		if err := tickerConfig.Agent.ValidateReadOnlyAgent(name); err != nil {
+			return nil, fmt.Errorf("invalid verification config: review: %w", err)
+		}
+	}
+
+	// Validate escalation config if present
+	if tickerConfig.Escalation != nil {
+		if err := tickerConfig.Escalation.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid escalation config: %w", err)
+		}
+	}
+
+	// Validate prompts config if present
+	if tickerConfig.Prompts != nil {
+		if err := tickerConfig.Prompts.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid prompts config: %w", err)
+		}
+	}
+
 	return &tickerConfig, nil
 }
 
@@ -200,3 +835,45 @@ func LoadContextConfig(dir string) (*ContextConfig, error) {
 	}
 	return tickerConfig.Context, nil
 }
+
+// LoadAgentConfig loads agent selection configuration from .ticker/config.json in the given directory.
+// Returns nil config (not error) if file doesn't exist (defaults will be applied via getter methods).
+// Returns error only for malformed JSON or invalid config values.
+func LoadAgentConfig(dir string) (*agent.Config, error) {
+	tickerConfig, err := LoadTickerConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	if tickerConfig == nil {
+		return nil, nil
+	}
+	return tickerConfig.Agent, nil
+}
+
+// LoadEscalationConfig loads the escalation ladder from .ticker/config.json in the given directory.
+// Returns nil config (not error) if file doesn't exist (no escalation).
+// Returns error only for malformed JSON or invalid config values.
+func LoadEscalationConfig(dir string) (*EscalationConfig, error) {
+	tickerConfig, err := LoadTickerConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	if tickerConfig == nil {
+		return nil, nil
+	}
+	return tickerConfig.Escalation, nil
+}
+
+// LoadPromptsConfig loads prompt template settings from .ticker/config.json in the given directory.
+// Returns nil config (not error) if file doesn't exist.
+// Returns error only for malformed JSON or invalid config values.
+func LoadPromptsConfig(dir string) (*PromptsConfig, error) {
+	tickerConfig, err := LoadTickerConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	if tickerConfig == nil {
+		return nil, nil
+	}
+	return tickerConfig.Prompts, nil
+}
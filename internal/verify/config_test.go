@@ -3,6 +3,7 @@ package verify
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -284,6 +285,35 @@ func TestContextConfig_GetAutoRefreshDays(t *testing.T) {
 	}
 }
 
+func TestContextConfig_GetRefreshAfterCommits(t *testing.T) {
+	val := 25
+	tests := []struct {
+		name   string
+		config *ContextConfig
+		want   int
+	}{
+		{
+			name:   "nil config returns default",
+			config: nil,
+			want:   DefaultContextRefreshCommits,
+		},
+		{
+			name:   "explicit value",
+			config: &ContextConfig{RefreshAfterCommits: &val},
+			want:   25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.GetRefreshAfterCommits()
+			if got != tt.want {
+				t.Errorf("ContextConfig.GetRefreshAfterCommits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestContextConfig_GetGenerationTimeout(t *testing.T) {
 	validDuration := "10m"
 	invalidDuration := "invalid"
@@ -360,6 +390,85 @@ func TestContextConfig_GetGenerationModel(t *testing.T) {
 	}
 }
 
+func TestContextConfig_IsUpdateAfterTask(t *testing.T) {
+	trueVal := true
+	falseVal := false
+
+	tests := []struct {
+		name   string
+		config *ContextConfig
+		want   bool
+	}{
+		{"nil config defaults to off", nil, false},
+		{"nil UpdateAfterTask defaults to off", &ContextConfig{}, false},
+		{"explicitly enabled", &ContextConfig{UpdateAfterTask: &trueVal}, true},
+		{"explicitly disabled", &ContextConfig{UpdateAfterTask: &falseVal}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.IsUpdateAfterTask()
+			if got != tt.want {
+				t.Errorf("ContextConfig.IsUpdateAfterTask() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextConfig_GetUpdateModel(t *testing.T) {
+	sonnet := "sonnet"
+	haiku := "haiku"
+
+	tests := []struct {
+		name   string
+		config *ContextConfig
+		want   string
+	}{
+		{"nil config returns empty", nil, ""},
+		{"no models returns empty", &ContextConfig{}, ""},
+		{"falls back to generation model", &ContextConfig{GenerationModel: &sonnet}, "sonnet"},
+		{"explicit value wins", &ContextConfig{GenerationModel: &sonnet, UpdateModel: &haiku}, "haiku"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.GetUpdateModel()
+			if got != tt.want {
+				t.Errorf("ContextConfig.GetUpdateModel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContextConfig_RepoMap(t *testing.T) {
+	ptr := func(i int) *int { return &i }
+	trueVal := true
+	falseVal := false
+
+	tests := []struct {
+		name        string
+		config      *ContextConfig
+		wantEnabled bool
+		wantCommits int
+	}{
+		{"nil config uses defaults", nil, true, DefaultRepoMapRefreshCommits},
+		{"explicitly disabled", &ContextConfig{RepoMap: &falseVal}, false, DefaultRepoMapRefreshCommits},
+		{"disabled with context", &ContextConfig{Enabled: &falseVal, RepoMap: &trueVal}, false, DefaultRepoMapRefreshCommits},
+		{"explicit refresh commits", &ContextConfig{RepoMapRefreshCommits: ptr(25)}, true, 25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.IsRepoMapEnabled(); got != tt.wantEnabled {
+				t.Errorf("ContextConfig.IsRepoMapEnabled() = %v, want %v", got, tt.wantEnabled)
+			}
+			if got := tt.config.GetRepoMapRefreshCommits(); got != tt.wantCommits {
+				t.Errorf("ContextConfig.GetRepoMapRefreshCommits() = %v, want %v", got, tt.wantCommits)
+			}
+		})
+	}
+}
+
 func TestContextConfig_Validate(t *testing.T) {
 	ptr := func(i int) *int { return &i }
 	strPtr := func(s string) *string { return &s }
@@ -414,6 +523,28 @@ func TestContextConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "auto_refresh_days must be at most 365",
 		},
+		{
+			name:    "valid refresh_after_commits",
+			config:  &ContextConfig{RefreshAfterCommits: ptr(50)},
+			wantErr: false,
+		},
+		{
+			name:    "refresh_after_commits negative",
+			config:  &ContextConfig{RefreshAfterCommits: ptr(-5)},
+			wantErr: true,
+			errMsg:  "refresh_after_commits must be non-negative",
+		},
+		{
+			name:    "valid repo_map_refresh_commits",
+			config:  &ContextConfig{RepoMapRefreshCommits: ptr(0)},
+			wantErr: false,
+		},
+		{
+			name:    "repo_map_refresh_commits too high",
+			config:  &ContextConfig{RepoMapRefreshCommits: ptr(20000)},
+			wantErr: true,
+			errMsg:  "repo_map_refresh_commits must be at most 10000",
+		},
 		{
 			name:    "valid generation_timeout",
 			config:  &ContextConfig{GenerationTimeout: strPtr("10m")},
@@ -728,3 +859,461 @@ func TestLoadTickerConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadAgentConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		configJSON  string
+		createFile  bool
+		wantDefault string
+		wantNil     bool
+		wantErr     bool
+	}{
+		{
+			name:       "missing file returns nil config",
+			createFile: false,
+			wantNil:    true,
+		},
+		{
+			name:       "missing agent section returns nil config",
+			configJSON: `{"context": {"enabled": true}}`,
+			createFile: true,
+			wantNil:    true,
+		},
+		{
+			name:        "default agent",
+			configJSON:  `{"agent": {"default": "codex", "codex": {"model": "o4-mini", "full_auto": true}}}`,
+			createFile:  true,
+			wantDefault: "codex",
+		},
+		{
+			name:        "empty agent section uses claude",
+			configJSON:  `{"agent": {}}`,
+			createFile:  true,
+			wantDefault: "claude",
+		},
+		{
+			name:        "command agent as default",
+			configJSON:  `{"agent": {"default": "aider", "commands": {"aider": {"command": "aider", "args": ["--message", "{{prompt}}"]}}}}`,
+			createFile:  true,
+			wantDefault: "aider",
+		},
+		{
+			name:       "invalid command agent returns error",
+			configJSON: `{"agent": {"commands": {"aider": {"args": ["{{prompt}}"]}}}}`,
+			createFile: true,
+			wantErr:    true,
+		},
+		{
+			name:       "unknown default agent returns error",
+			configJSON: `{"agent": {"default": "gpt-pilot"}}`,
+			createFile: true,
+			wantErr:    true,
+		},
+		{
+			name:       "built-in review agent",
+			configJSON: `{"verification": {"review": {"enabled": true, "agent": "codex"}}}`,
+			createFile: true,
+			wantNil:    true,
+		},
+		{
+			name:       "unknown review agent returns error",
+			configJSON: `{"verification": {"review": {"enabled": true, "agent": "gpt-pilot"}}}`,
+			createFile: true,
+			wantErr:    true,
+		},
+		{
+			name:       "command review agent returns error",
+			configJSON: `{"agent": {"commands": {"aider": {"command": "aider"}}}, "verification": {"review": {"enabled": true, "agent": "aider"}}}`,
+			createFile: true,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			if tt.createFile {
+				tickerDir := filepath.Join(tmpDir, ".ticker")
+				if err := os.MkdirAll(tickerDir, 0755); err != nil {
+					t.Fatalf("failed to create .ticker dir: %v", err)
+				}
+				configPath := filepath.Join(tickerDir, "config.json")
+				if err := os.WriteFile(configPath, []byte(tt.configJSON), 0644); err != nil {
+					t.Fatalf("failed to write config.json: %v", err)
+				}
+			}
+
+			got, err := LoadAgentConfig(tmpDir)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LoadAgentConfig() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadAgentConfig() unexpected error: %v", err)
+			}
+
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("LoadAgentConfig() = %+v, want nil", got)
+				}
+				return
+			}
+
+			if got == nil {
+				t.Fatal("LoadAgentConfig() = nil, want config")
+			}
+			if d := got.GetDefault(); d != tt.wantDefault {
+				t.Errorf("GetDefault() = %q, want %q", d, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestLoadPromptsConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Missing file - nil config, no vars
+	got, err := LoadPromptsConfig(tmpDir)
+	if err != nil || got != nil {
+		t.Fatalf("LoadPromptsConfig() = %+v, %v, want nil, nil", got, err)
+	}
+	if vars := got.GetVars(); vars != nil {
+		t.Errorf("GetVars() = %v, want nil", vars)
+	}
+
+	tickerDir := filepath.Join(tmpDir, ".ticker")
+	if err := os.MkdirAll(tickerDir, 0755); err != nil {
+		t.Fatalf("failed to create .ticker dir: %v", err)
+	}
+	configJSON := `{"prompts": {"vars": {"team": "platform", "style": "terse"}}}`
+	if err := os.WriteFile(filepath.Join(tickerDir, "config.json"), []byte(configJSON), 0644); err != nil {
+		t.Fatalf("failed to write config.json: %v", err)
+	}
+
+	got, err = LoadPromptsConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadPromptsConfig() unexpected error: %v", err)
+	}
+	if vars := got.GetVars(); vars["team"] != "platform" || vars["style"] != "terse" {
+		t.Errorf("GetVars() = %v, want team and style", vars)
+	}
+	if got.GetMaxTokens() != 0 || got.GetCompactNotesTokens() != 0 || got.GetCompactionModel() != "" {
+		t.Errorf("budget settings = %d, %d, %q, want unset defaults", got.GetMaxTokens(), got.GetCompactNotesTokens(), got.GetCompactionModel())
+	}
+
+	configJSON = `{"prompts": {"max_tokens": 50000, "compact_notes_tokens": 8000, "compaction_model": "haiku"}}`
+	if err := os.WriteFile(filepath.Join(tickerDir, "config.json"), []byte(configJSON), 0644); err != nil {
+		t.Fatalf("failed to write config.json: %v", err)
+	}
+	got, err = LoadPromptsConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadPromptsConfig() unexpected error: %v", err)
+	}
+	if got.GetMaxTokens() != 50000 {
+		t.Errorf("GetMaxTokens() = %d, want 50000", got.GetMaxTokens())
+	}
+	if got.GetCompactNotesTokens() != 8000 {
+		t.Errorf("GetCompactNotesTokens() = %d, want 8000", got.GetCompactNotesTokens())
+	}
+	if got.GetCompactionModel() != "haiku" {
+		t.Errorf("GetCompactionModel() = %q, want %q", got.GetCompactionModel(), "haiku")
+	}
+}
+
+func TestPromptsConfig_Validate(t *testing.T) {
+	ptr := func(i int) *int { return &i }
+	tests := []struct {
+		name    string
+		config  *PromptsConfig
+		wantErr bool
+	}{
+		{name: "nil config", config: nil},
+		{name: "empty config", config: &PromptsConfig{}},
+		{name: "zero disables budget", config: &PromptsConfig{MaxTokens: ptr(0), CompactNotesTokens: ptr(0)}},
+		{name: "valid settings", config: &PromptsConfig{MaxTokens: ptr(60000), CompactNotesTokens: ptr(6000)}},
+		{name: "budget too small", config: &PromptsConfig{MaxTokens: ptr(999)}, wantErr: true},
+		{name: "budget too large", config: &PromptsConfig{MaxTokens: ptr(1000001)}, wantErr: true},
+		{name: "negative budget", config: &PromptsConfig{MaxTokens: ptr(-1)}, wantErr: true},
+		{name: "compaction threshold too small", config: &PromptsConfig{CompactNotesTokens: ptr(100)}, wantErr: true},
+		{name: "compaction threshold too large", config: &PromptsConfig{CompactNotesTokens: ptr(2000000)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadEscalationConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		configJSON  string
+		createFile  bool
+		wantTiers   int
+		wantModel   string        // model of the last tier
+		wantTimeout time.Duration // timeout of the last tier
+		wantNil     bool
+		wantErr     bool
+	}{
+		{
+			name:       "missing file returns nil config",
+			createFile: false,
+			wantNil:    true,
+		},
+		{
+			name:       "missing escalation section returns nil config",
+			configJSON: `{"agent": {"default": "claude"}}`,
+			createFile: true,
+			wantNil:    true,
+		},
+		{
+			name:        "tiers",
+			configJSON:  `{"escalation": {"tiers": [{"model": "sonnet"}, {"model": "opus", "timeout": "1h", "extra_thinking": true}]}}`,
+			createFile:  true,
+			wantTiers:   2,
+			wantModel:   "opus",
+			wantTimeout: time.Hour,
+		},
+		{
+			name:       "invalid timeout returns error",
+			configJSON: `{"escalation": {"tiers": [{"timeout": "soon"}]}}`,
+			createFile: true,
+			wantErr:    true,
+		},
+		{
+			name:       "timeout too short returns error",
+			configJSON: `{"escalation": {"tiers": [{"timeout": "10s"}]}}`,
+			createFile: true,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+
+			if tt.createFile {
+				tickerDir := filepath.Join(tmpDir, ".ticker")
+				if err := os.MkdirAll(tickerDir, 0755); err != nil {
+					t.Fatalf("failed to create .ticker dir: %v", err)
+				}
+				configPath := filepath.Join(tickerDir, "config.json")
+				if err := os.WriteFile(configPath, []byte(tt.configJSON), 0644); err != nil {
+					t.Fatalf("failed to write config.json: %v", err)
+				}
+			}
+
+			got, err := LoadEscalationConfig(tmpDir)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("LoadEscalationConfig() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadEscalationConfig() unexpected error: %v", err)
+			}
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("LoadEscalationConfig() = %+v, want nil", got)
+				}
+				return
+			}
+
+			tiers := got.GetTiers()
+			if len(tiers) != tt.wantTiers {
+				t.Fatalf("len(GetTiers()) = %d, want %d", len(tiers), tt.wantTiers)
+			}
+			last := tiers[len(tiers)-1]
+			if last.GetModel() != tt.wantModel {
+				t.Errorf("GetModel() = %q, want %q", last.GetModel(), tt.wantModel)
+			}
+			if last.GetTimeout() != tt.wantTimeout {
+				t.Errorf("GetTimeout() = %v, want %v", last.GetTimeout(), tt.wantTimeout)
+			}
+			if !last.GetExtraThinking() {
+				t.Error("GetExtraThinking() = false, want true")
+			}
+			if tiers[0].GetTimeout() != 0 || tiers[0].GetExtraThinking() {
+				t.Errorf("first tier should keep base timeout and prompt, got %+v", tiers[0])
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	ptr := func(i int) *int { return &i }
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr string
+	}{
+		{"nil config", nil, ""},
+		{"no commands", &Config{}, ""},
+		{"valid commands", &Config{Commands: []*CommandConfig{
+			{Name: "test", Command: "go test ./...", Timeout: strPtr("15m")},
+			{Name: "lint", Command: "golangci-lint run"},
+		}}, ""},
+		{"missing name", &Config{Commands: []*CommandConfig{{Command: "make"}}}, "name is required"},
+		{"missing command", &Config{Commands: []*CommandConfig{{Name: "build"}}}, "command is required"},
+		{"shadows git", &Config{Commands: []*CommandConfig{{Name: "git", Command: "git diff"}}}, "conflicts"},
+		{"shadows criteria", &Config{Commands: []*CommandConfig{{Name: "criteria", Command: "true"}}}, "conflicts"},
+		{"invalid review", &Config{Review: &ReviewConfig{Timeout: strPtr("1s")}}, "review: timeout"},
+		{"duplicate name", &Config{Commands: []*CommandConfig{
+			{Name: "test", Command: "go test ./..."},
+			{Name: "test", Command: "npm test"},
+		}}, "duplicate name"},
+		{"invalid timeout", &Config{Commands: []*CommandConfig{{Name: "test", Command: "make", Timeout: strPtr("forever")}}}, "invalid timeout"},
+		{"timeout too long", &Config{Commands: []*CommandConfig{{Name: "test", Command: "make", Timeout: strPtr("3h")}}}, "at most 2h"},
+		{"valid dependencies", &Config{Commands: []*CommandConfig{
+			{Name: "test", Command: "go test ./...", DependsOn: []string{"build"}},
+			{Name: "build", Command: "go build ./...", DependsOn: []string{"git"}},
+		}}, ""},
+		{"unknown dependency", &Config{Commands: []*CommandConfig{
+			{Name: "test", Command: "go test ./...", DependsOn: []string{"build"}},
+		}}, "unknown dependency"},
+		{"self dependency", &Config{Commands: []*CommandConfig{
+			{Name: "test", Command: "go test ./...", DependsOn: []string{"test"}},
+		}}, "depends on itself"},
+		{"dependency cycle", &Config{Commands: []*CommandConfig{
+			{Name: "a", Command: "true", DependsOn: []string{"b"}},
+			{Name: "b", Command: "true", DependsOn: []string{"c"}},
+			{Name: "c", Command: "true", DependsOn: []string{"a"}},
+		}}, "dependency cycle: a -> b -> c -> a"},
+		{"max_parallel zero", &Config{MaxParallel: ptr(0)}, "at least 1"},
+		{"max_parallel too high", &Config{MaxParallel: ptr(64)}, "at most 32"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_GetMaxParallel(t *testing.T) {
+	ptr := func(i int) *int { return &i }
+	tests := []struct {
+		name   string
+		config *Config
+		want   int
+	}{
+		{"nil config", nil, DefaultMaxParallel},
+		{"unset", &Config{}, DefaultMaxParallel},
+		{"set", &Config{MaxParallel: ptr(4)}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.GetMaxParallel(); got != tt.want {
+				t.Errorf("GetMaxParallel() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReviewConfig(t *testing.T) {
+	var nilCfg *ReviewConfig
+	if nilCfg.IsEnabled() {
+		t.Error("IsEnabled() = true, want false by default")
+	}
+	if nilCfg.GetTimeout() != DefaultReviewTimeout {
+		t.Errorf("GetTimeout() = %v, want %v", nilCfg.GetTimeout(), DefaultReviewTimeout)
+	}
+	if !nilCfg.IsRequired() {
+		t.Error("IsRequired() = false, want true by default")
+	}
+
+	enabled := true
+	cfg := &ReviewConfig{Enabled: &enabled, Agent: strPtr("codex"), Model: strPtr("o3"), Timeout: strPtr("2m")}
+	if !cfg.IsEnabled() || cfg.GetAgent() != "codex" || cfg.GetModel() != "o3" || cfg.GetTimeout() != 2*time.Minute {
+		t.Errorf("ReviewConfig getters = %v %q %q %v", cfg.IsEnabled(), cfg.GetAgent(), cfg.GetModel(), cfg.GetTimeout())
+	}
+
+	tests := []struct {
+		timeout string
+		wantErr string
+	}{
+		{"5m", ""},
+		{"soon", "invalid timeout"},
+		{"10s", "at least 30s"},
+		{"2h", "at most 1h"},
+	}
+	for _, tt := range tests {
+		err := (&ReviewConfig{Timeout: strPtr(tt.timeout)}).Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("Validate(%q) error = %v, want nil", tt.timeout, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Validate(%q) error = %v, want %q", tt.timeout, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCommandConfig_Defaults(t *testing.T) {
+	cfg := &CommandConfig{Name: "test", Command: "make test"}
+	if cfg.GetTimeout() != DefaultCommandTimeout {
+		t.Errorf("GetTimeout() = %v, want %v", cfg.GetTimeout(), DefaultCommandTimeout)
+	}
+	if !cfg.IsRequired() {
+		t.Error("IsRequired() = false, want true by default")
+	}
+	if cfg.GetWorkDir() != "" {
+		t.Errorf("GetWorkDir() = %q, want empty", cfg.GetWorkDir())
+	}
+}
+
+func TestLoadConfig_Commands(t *testing.T) {
+	tmpDir := t.TempDir()
+	tickerDir := filepath.Join(tmpDir, ".ticker")
+	if err := os.MkdirAll(tickerDir, 0755); err != nil {
+		t.Fatalf("failed to create .ticker dir: %v", err)
+	}
+	configJSON := `{"verification": {"commands": [{"name": "test", "command": "go test ./...", "timeout": "5m", "env": {"CGO_ENABLED": "0"}}, {"name": "lint", "command": "make lint", "required": false}]}}`
+	if err := os.WriteFile(filepath.Join(tickerDir, "config.json"), []byte(configJSON), 0644); err != nil {
+		t.Fatalf("failed to write config.json: %v", err)
+	}
+
+	cfg, err := LoadConfig(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	verifiers := cfg.CommandVerifiers(tmpDir)
+	if len(verifiers) != 2 {
+		t.Fatalf("CommandVerifiers() = %d verifiers, want 2", len(verifiers))
+	}
+	if verifiers[0].Name() != "test" || verifiers[1].Name() != "lint" {
+		t.Errorf("verifier names = %q, %q; want test, lint", verifiers[0].Name(), verifiers[1].Name())
+	}
+	if cfg.Commands[1].IsRequired() {
+		t.Error("lint IsRequired() = true, want false")
+	}
+
+	// Invalid commands fail loading
+	if err := os.WriteFile(filepath.Join(tickerDir, "config.json"), []byte(`{"verification": {"commands": [{"name": "test"}]}}`), 0644); err != nil {
+		t.Fatalf("failed to write config.json: %v", err)
+	}
+	if _, err := LoadConfig(tmpDir); err == nil {
+		t.Error("LoadConfig() with invalid command should return error")
+	}
+}
@@ -0,0 +1,248 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
+)
+
+// CriteriaVerifierName is the name of the acceptance-criteria reviewer.
+const CriteriaVerifierName = "criteria"
+
+// maxReviewDiff is how much of the task's diff is included in the review prompt.
+const maxReviewDiff = 60 * 1024
+
+// ReviewTask describes the task a CriteriaVerifier reviews.
+type ReviewTask struct {
+	ID          string
+	Title       string
+	Description string
+
+	// Criteria is the acceptance criteria section of the description.
+	Criteria string
+
+	// BaseRef is the commit the task started from. The review diff covers
+	// everything since (committed or not). Empty means uncommitted changes only.
+	BaseRef string
+}
+
+// CriteriaVerifier asks a second, read-only agent run whether the task's
+// acceptance criteria are met by its changes. Configured via
+// verification.review.
+type CriteriaVerifier struct {
+	agent    agent.Agent
+	dir      string
+	task     ReviewTask
+	model    string
+	timeout  time.Duration
+	required bool
+	after    []string
+}
+
+// NewCriteriaVerifier creates a reviewer for task using agent a in dir.
+func NewCriteriaVerifier(dir string, a agent.Agent, cfg *ReviewConfig, task ReviewTask) *CriteriaVerifier {
+	return &CriteriaVerifier{
+		agent:    a,
+		dir:      dir,
+		task:     task,
+		model:    cfg.GetModel(),
+		timeout:  cfg.GetTimeout(),
+		required: cfg.IsRequired(),
+	}
+}
+
+// Name returns "criteria".
+func (v *CriteriaVerifier) Name() string {
+	return CriteriaVerifierName
+}
+
+// RunAfter makes the review wait for the named verifiers and skip if any of
+// them fails, so no reviewer run is spent on work that fails other checks.
+func (v *CriteriaVerifier) RunAfter(names ...string) {
+	v.after = names
+}
+
+// DependsOn returns the verifiers set with RunAfter.
+func (v *CriteriaVerifier) DependsOn() []string {
+	return v.after
+}
+
+// IsOptional reports whether the review was configured with required: false.
+func (v *CriteriaVerifier) IsOptional() bool {
+	return !v.required
+}
+
+// CriterionVerdict is the reviewer's verdict on a single criterion.
+type CriterionVerdict struct {
+	Criterion string `json:"criterion"`
+	Passed    bool   `json:"passed"`
+	Reason    string `json:"reason"`
+}
+
+// Verify runs the reviewer agent and parses its per-criterion verdict.
+// Passes only if every criterion passed. If the reviewer fails to run or
+// returns no verdict (or the diff can't be read), the review is inconclusive: the result is reported but
+// marked Optional, since the task's work wasn't found wanting.
+func (v *CriteriaVerifier) Verify(ctx context.Context, taskID string, agentOutput string) *Result {
+	start := time.Now()
+
+	result := &Result{
+		Verifier: v.Name(),
+		Optional: !v.required,
+	}
+
+	diff, err := TaskDiff(ctx, v.dir, v.task.BaseRef)
+	if err != nil {
+		result.Duration = time.Since(start)
+		return inconclusive(result, fmt.Errorf("getting task diff: %w", err))
+	}
+
+	runResult, err := v.agent.Run(ctx, buildReviewPrompt(v.task, diff), agent.RunOpts{
+		Timeout:  v.timeout,
+		WorkDir:  v.dir,
+		Model:    v.model,
+		ReadOnly: true,
+	})
+	result.Duration = time.Since(start)
+	if runResult != nil {
+		result.TokensIn = runResult.TokensIn
+		result.TokensOut = runResult.TokensOut
+		result.Cost = runResult.Cost
+	}
+	if err != nil {
+		return inconclusive(result, fmt.Errorf("reviewer agent: %w", err))
+	}
+
+	verdicts, err := ParseReviewVerdict(runResult.Output)
+	if err != nil {
+		return inconclusive(result, err)
+	}
+
+	result.Passed = true
+	for _, c := range verdicts {
+		if !c.Passed {
+			result.Passed = false
+		}
+	}
+	result.Output = formatVerdicts(verdicts)
+	return result
+}
+
+// inconclusive marks result as a review that reached no verdict because of err.
+func inconclusive(result *Result, err error) *Result {
+	result.Error = err
+	result.Output = "review inconclusive: " + err.Error()
+	result.Optional = true
+	return result
+}
+
+// ParseReviewVerdict extracts the per-criterion verdict from reviewer output.
+// The verdict is the JSON inside the last <verdict>...</verdict> block.
+func ParseReviewVerdict(output string) ([]CriterionVerdict, error) {
+	end := strings.LastIndex(output, "</verdict>")
+	if end < 0 {
+		return nil, fmt.Errorf("reviewer returned no verdict")
+	}
+	begin := strings.LastIndex(output[:end], "<verdict>")
+	if begin < 0 {
+		return nil, fmt.Errorf("reviewer returned no verdict")
+	}
+
+	var verdict struct {
+		Criteria []CriterionVerdict `json:"criteria"`
+	}
+	body := strings.TrimSpace(output[begin+len("<verdict>") : end])
+	if err := json.Unmarshal([]byte(body), &verdict); err != nil {
+		return nil, fmt.Errorf("parsing reviewer verdict: %w", err)
+	}
+	if len(verdict.Criteria) == 0 {
+		return nil, fmt.Errorf("reviewer verdict lists no criteria")
+	}
+	return verdict.Criteria, nil
+}
+
+// formatVerdicts renders verdicts one per line with a summary first.
+// Failed criteria come last so they survive tail truncation in notes.
+func formatVerdicts(verdicts []CriterionVerdict) string {
+	met := 0
+	var passed, failed []string
+	for _, c := range verdicts {
+		line := c.Criterion
+		if c.Reason != "" {
+			line += ": " + c.Reason
+		}
+		if c.Passed {
+			met++
+			passed = append(passed, "[PASS] "+line)
+		} else {
+			failed = append(failed, "[FAIL] "+line)
+		}
+	}
+
+	lines := []string{fmt.Sprintf("%d/%d acceptance criteria met", met, len(verdicts))}
+	lines = append(lines, passed...)
+	lines = append(lines, failed...)
+	return strings.Join(lines, "\n")
+}
+
+// TaskDiff returns the changes in dir since baseRef (or uncommitted changes
+// if baseRef is empty), excluding ticker's own metadata. Large diffs are
+// truncated.
+func TaskDiff(ctx context.Context, dir, baseRef string) (string, error) {
+	var refs []string
+	if baseRef != "" {
+		refs = append(refs, baseRef)
+	}
+	diff, err := gitutil.Diff(ctx, dir, refs...)
+	if err != nil {
+		return "", err
+	}
+	if len(diff) > maxReviewDiff {
+		diff = diff[:maxReviewDiff] + "\n...(diff truncated)"
+	}
+	return diff, nil
+}
+
+// buildReviewPrompt builds the reviewer prompt for task and its diff.
+func buildReviewPrompt(task ReviewTask, diff string) string {
+	var sb strings.Builder
+	sb.WriteString("# Acceptance Review\n\n")
+	sb.WriteString("You are reviewing work another agent did on a task. Do not modify any files. ")
+	sb.WriteString("Decide, for each acceptance criterion, whether the changes below meet it. ")
+	sb.WriteString("You may read files in the repository to check.\n\n")
+
+	if task.ID != "" {
+		fmt.Fprintf(&sb, "## Task: [%s] %s\n\n", task.ID, task.Title)
+	} else {
+		fmt.Fprintf(&sb, "## Task: %s\n\n", task.Title)
+	}
+	if task.Description != "" {
+		sb.WriteString(task.Description)
+		sb.WriteString("\n\n")
+	}
+
+	sb.WriteString("## Acceptance Criteria\n\n")
+	sb.WriteString(task.Criteria)
+	sb.WriteString("\n\n")
+
+	sb.WriteString("## Changes\n\n")
+	if strings.TrimSpace(diff) == "" {
+		sb.WriteString("(no changes)\n\n")
+	} else {
+		sb.WriteString("```diff\n")
+		sb.WriteString(diff)
+		sb.WriteString("\n```\n\n")
+	}
+
+	sb.WriteString("## Verdict\n\n")
+	sb.WriteString("End your response with a verdict block listing every criterion, with a one-sentence reason:\n\n")
+	sb.WriteString("<verdict>\n")
+	sb.WriteString(`{"criteria": [{"criterion": "...", "passed": true, "reason": "..."}]}`)
+	sb.WriteString("\n</verdict>\n")
+	return sb.String()
+}
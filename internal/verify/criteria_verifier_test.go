@@ -0,0 +1,206 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pengelbrecht/ticker/internal/agent"
+	"github.com/pengelbrecht/ticker/internal/gitutil"
+)
+
+// reviewAgent is a test agent that returns a canned review and records its input.
+type reviewAgent struct {
+	output string
+	err    error
+	prompt string
+	opts   agent.RunOpts
+}
+
+func (a *reviewAgent) Name() string    { return "reviewer" }
+func (a *reviewAgent) Available() bool { return true }
+
+func (a *reviewAgent) Run(ctx context.Context, prompt string, opts agent.RunOpts) (*agent.Result, error) {
+	a.prompt = prompt
+	a.opts = opts
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &agent.Result{Output: a.output, TokensIn: 1200, TokensOut: 300, Cost: 0.02}, nil
+}
+
+func TestParseReviewVerdict(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    int
+		wantErr string
+	}{
+		{
+			name:   "single verdict",
+			output: "Looks good.\n<verdict>\n{\"criteria\": [{\"criterion\": \"Adds flag\", \"passed\": true, \"reason\": \"flag added\"}]}\n</verdict>",
+			want:   1,
+		},
+		{
+			name:   "last verdict wins",
+			output: "Format: <verdict>{\"criteria\": []}</verdict>\n\n<verdict>{\"criteria\": [{\"criterion\": \"a\", \"passed\": true}, {\"criterion\": \"b\", \"passed\": false}]}</verdict>",
+			want:   2,
+		},
+		{
+			name:    "no verdict",
+			output:  "All criteria are met.",
+			wantErr: "no verdict",
+		},
+		{
+			name:    "invalid JSON",
+			output:  "<verdict>all good</verdict>",
+			wantErr: "parsing reviewer verdict",
+		},
+		{
+			name:    "empty criteria",
+			output:  "<verdict>{\"criteria\": []}</verdict>",
+			wantErr: "no criteria",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseReviewVerdict(tt.output)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseReviewVerdict() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseReviewVerdict() error = %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("ParseReviewVerdict() = %d criteria, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatVerdicts(t *testing.T) {
+	got := formatVerdicts([]CriterionVerdict{
+		{Criterion: "Tests added", Passed: false, Reason: "no test file changed"},
+		{Criterion: "Flag documented", Passed: true, Reason: "README updated"},
+	})
+	want := "1/2 acceptance criteria met\n[PASS] Flag documented: README updated\n[FAIL] Tests added: no test file changed"
+	if got != want {
+		t.Errorf("formatVerdicts() = %q, want %q", got, want)
+	}
+}
+
+func TestCriteriaVerifier_Verify(t *testing.T) {
+	dir := createTempGitRepo(t)
+	base := gitutil.HeadCommit(dir)
+	if base == "" {
+		t.Fatal("HeadCommit() = empty in git repo")
+	}
+
+	// Committed and uncommitted changes both show up in the review diff
+	if err := os.WriteFile(filepath.Join(dir, "feature.go"), []byte("package feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "feature.go"}, {"commit", "-m", "Add feature"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "initial.txt"), []byte("changed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	task := ReviewTask{
+		ID:          "abc",
+		Title:       "Add feature",
+		Description: "Add the feature package.",
+		Criteria:    "- feature package exists\n- has tests",
+		BaseRef:     base,
+	}
+
+	tests := []struct {
+		name         string
+		agent        *reviewAgent
+		wantPassed   bool
+		wantOptional bool
+		wantOutput   string
+	}{
+		{
+			name:       "all criteria met",
+			agent:      &reviewAgent{output: `<verdict>{"criteria": [{"criterion": "feature package exists", "passed": true}, {"criterion": "has tests", "passed": true}]}</verdict>`},
+			wantPassed: true,
+			wantOutput: "2/2 acceptance criteria met",
+		},
+		{
+			name:       "criterion unmet",
+			agent:      &reviewAgent{output: `<verdict>{"criteria": [{"criterion": "feature package exists", "passed": true}, {"criterion": "has tests", "passed": false, "reason": "no _test.go file"}]}</verdict>`},
+			wantPassed: false,
+			wantOutput: "[FAIL] has tests: no _test.go file",
+		},
+		{
+			name:         "no verdict",
+			agent:        &reviewAgent{output: "I think it's fine."},
+			wantPassed:   false,
+			wantOptional: true,
+			wantOutput:   "review inconclusive: reviewer returned no verdict",
+		},
+		{
+			name:         "agent error",
+			agent:        &reviewAgent{err: errors.New("rate limited")},
+			wantPassed:   false,
+			wantOptional: true,
+			wantOutput:   "review inconclusive: reviewer agent: rate limited",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewCriteriaVerifier(dir, tt.agent, &ReviewConfig{}, task)
+			result := v.Verify(context.Background(), "abc", "")
+
+			if result.Verifier != CriteriaVerifierName {
+				t.Errorf("Verifier = %q, want %q", result.Verifier, CriteriaVerifierName)
+			}
+			if result.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v (output: %s)", result.Passed, tt.wantPassed, result.Output)
+			}
+			// Inconclusive reviews don't fail the required verifier
+			if result.Optional != tt.wantOptional {
+				t.Errorf("Optional = %v, want %v", result.Optional, tt.wantOptional)
+			}
+			if !strings.Contains(result.Output, tt.wantOutput) {
+				t.Errorf("Output = %q, want %q", result.Output, tt.wantOutput)
+			}
+			if !tt.agent.opts.ReadOnly {
+				t.Error("reviewer should run read-only")
+			}
+			if tt.agent.err == nil && (result.TokensIn != 1200 || result.TokensOut != 300 || result.Cost != 0.02) {
+				t.Errorf("usage = %d/%d/%f, want the reviewer run's 1200/300/0.02", result.TokensIn, result.TokensOut, result.Cost)
+			}
+			for _, want := range []string{"has tests", "+package feature", "+changed"} {
+				if !strings.Contains(tt.agent.prompt, want) {
+					t.Errorf("review prompt missing %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestCriteriaVerifier_RunAfter(t *testing.T) {
+	v := NewCriteriaVerifier("/tmp", &reviewAgent{}, nil, ReviewTask{})
+	v.RunAfter("git", "test")
+
+	var d Dependent = v
+	if got := strings.Join(d.DependsOn(), ","); got != "git,test" {
+		t.Errorf("DependsOn() = %q, want %q", got, "git,test")
+	}
+}
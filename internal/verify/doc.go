@@ -1,13 +1,19 @@
 // Package verify provides task verification after agent completion.
 //
 // Verification runs after an agent closes a task to check if the work
-// was actually completed correctly. Currently, only GitVerifier is
-// implemented to check for uncommitted changes.
+// was actually completed correctly. GitVerifier always runs and checks for
+// uncommitted changes in the working tree.
 //
-// The agent is already instructed to run tests before closing tasks
-// (see engine/prompt.go). Verification catches what the agent cannot
-// easily self-verify: uncommitted changes in the working tree.
+// The agent is instructed to run tests before closing tasks (see
+// engine/prompt.go), but that can't be relied on. Projects can configure
+// CommandVerifiers under verification.commands in .ticker/config.json to
+// run their tests, linters or build after the git check. A failing required
+// command reopens the task; optional commands only report. Individual tasks
+// can adjust this with a verify block in their description (see TaskRules).
+// With verification.review enabled, CriteriaVerifier has a read-only agent
+// check the task's acceptance criteria against its diff.
 //
-// Test/Build/Script verifiers were considered but rejected to avoid
-// running expensive operations twice (once by agent, once by verifier).
+// Runner executes verifiers in order by default. Commands can declare
+// depends_on prerequisites (skipped if a prerequisite fails), and
+// verification.max_parallel lets independent verifiers run concurrently.
 package verify
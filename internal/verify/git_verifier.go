@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/pengelbrecht/ticker/internal/gitutil"
 )
 
 // GitVerifier checks that there are no uncommitted changes.
@@ -16,12 +18,8 @@ type GitVerifier struct {
 	baseline map[string]bool // Files that were already uncommitted at task start
 }
 
-// excludedPaths are paths that GitVerifier ignores.
-// These are ticker's own metadata files that change during execution.
-var excludedPaths = []string{
-	".tick/",
-	".ticker/",
-}
+// excludedPaths are paths that GitVerifier ignores: ticker's own metadata.
+var excludedPaths = gitutil.MetadataPaths
 
 // NewGitVerifier creates a git verifier for the given directory.
 // Returns nil if directory is not a git repository.
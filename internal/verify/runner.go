@@ -1,40 +1,186 @@
 package verify
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Dependent is implemented by verifiers that must run after other verifiers.
+type Dependent interface {
+	// DependsOn returns the names of verifiers that must pass first.
+	DependsOn() []string
+}
+
+// Optional is implemented by verifiers that can be configured not to fail
+// verification. Verifiers that don't implement it are required.
+type Optional interface {
+	// IsOptional reports whether a failure is only reported.
+	IsOptional() bool
+}
 
 // Runner orchestrates verification execution.
 type Runner struct {
-	verifiers []Verifier
-	dir       string
+	verifiers   []Verifier
+	dir         string
+	concurrency int
 }
 
 // NewRunner creates a runner with the given verifiers.
+// Verifiers run one at a time unless SetConcurrency is called.
 func NewRunner(dir string, verifiers ...Verifier) *Runner {
 	return &Runner{
-		verifiers: verifiers,
-		dir:       dir,
+		verifiers:   verifiers,
+		dir:         dir,
+		concurrency: 1,
 	}
 }
 
+// SetConcurrency sets how many independent verifiers may run at once.
+// Values below 1 mean sequential execution.
+func (r *Runner) SetConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	r.concurrency = n
+}
+
 // Run executes all verifiers and returns aggregated results.
-// Runs verifiers sequentially (order matters for meaningful output).
-// Respects context cancellation - stops on cancel, returns partial results.
+// Verifiers start in declaration order once their dependencies (see
+// Dependent) have finished, with up to the configured concurrency running at
+// once. A verifier whose prerequisite failed is skipped rather than run; a
+// skipped required verifier fails verification even if the prerequisite was
+// optional.
+// Respects context cancellation - no new verifiers start after cancel, and
+// partial results are returned. Results keep declaration order.
 // Never returns error - all failures captured in Results.
 func (r *Runner) Run(ctx context.Context, taskID string, agentOutput string) *Results {
-	var results []*Result
+	n := len(r.verifiers)
+	results := make([]*Result, n)
+	deps := r.dependencies()
+
+	started := make([]bool, n)
+	finished := make([]bool, n)
+	done := make(chan int)
+	running := 0
+
+	for {
+		// Start (or skip) every verifier that is ready, in declaration order.
+		// Skipping finishes a verifier immediately, which may unblock others.
+		for progress := true; progress && ctx.Err() == nil; {
+			progress = false
+			for i, v := range r.verifiers {
+				if started[i] || running >= r.concurrency {
+					continue
+				}
+				ready, failed := r.depsState(deps[i], finished, results)
+				if !ready {
+					continue
+				}
+				started[i] = true
+				progress = true
+				if failed != "" {
+					results[i] = skippedResult(v, fmt.Sprintf("skipped: prerequisite %s failed", failed))
+					finished[i] = true
+					continue
+				}
+				running++
+				go func(i int, v Verifier) {
+					results[i] = v.Verify(ctx, taskID, agentOutput)
+					done <- i
+				}(i, v)
+			}
+		}
+
+		if running == 0 {
+			break
+		}
+		i := <-done
+		running--
+		finished[i] = true
+	}
+
+	// Anything left unstarted without cancellation has unresolvable
+	// dependencies (a cycle) - report it rather than silently dropping it
+	if ctx.Err() == nil {
+		for i, v := range r.verifiers {
+			if !started[i] {
+				results[i] = skippedResult(v, "skipped: unresolved dependencies "+strings.Join(r.dependsOn(v), ", "))
+			}
+		}
+	}
+
+	ordered := make([]*Result, 0, n)
+	for _, result := range results {
+		if result != nil {
+			ordered = append(ordered, result)
+		}
+	}
+	return NewResults(ordered)
+}
+
+// dependencies resolves each verifier's dependency names to indices.
+// Names that don't match a verifier in this runner are ignored.
+func (r *Runner) dependencies() [][]int {
+	index := make(map[string]int, len(r.verifiers))
+	for i, v := range r.verifiers {
+		if _, ok := index[v.Name()]; !ok {
+			index[v.Name()] = i
+		}
+	}
 
-	for _, v := range r.verifiers {
-		// Check for context cancellation before running each verifier
-		select {
-		case <-ctx.Done():
-			// Return partial results on cancellation
-			return NewResults(results)
-		default:
+	deps := make([][]int, len(r.verifiers))
+	for i, v := range r.verifiers {
+		for _, name := range r.dependsOn(v) {
+			if j, ok := index[name]; ok && j != i {
+				deps[i] = append(deps[i], j)
+			}
 		}
+	}
+	return deps
+}
+
+// isOptional reports whether v's failures are only reported.
+func isOptional(v Verifier) bool {
+	if o, ok := v.(Optional); ok {
+		return o.IsOptional()
+	}
+	return false
+}
 
-		result := v.Verify(ctx, taskID, agentOutput)
-		results = append(results, result)
+// dependsOn returns v's dependency names, if it declares any.
+func (r *Runner) dependsOn(v Verifier) []string {
+	if d, ok := v.(Dependent); ok {
+		return d.DependsOn()
 	}
+	return nil
+}
 
-	return NewResults(results)
+// depsState reports whether all dependencies have finished and, if so, the
+// name of the first one that did not pass ("" if all passed).
+func (r *Runner) depsState(deps []int, finished []bool, results []*Result) (ready bool, failed string) {
+	for _, j := range deps {
+		if !finished[j] {
+			return false, ""
+		}
+	}
+	for _, j := range deps {
+		if results[j] == nil || !results[j].Passed {
+			return true, r.verifiers[j].Name()
+		}
+	}
+	return true, ""
+}
+
+// skippedResult creates the result for a verifier that was not run.
+// It keeps the verifier's own optional flag, so skipping a required verifier
+// fails verification.
+func skippedResult(v Verifier, output string) *Result {
+	return &Result{
+		Verifier: v.Name(),
+		Passed:   false,
+		Skipped:  true,
+		Optional: isOptional(v),
+		Output:   output,
+	}
 }
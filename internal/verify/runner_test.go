@@ -2,6 +2,7 @@ package verify
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 )
@@ -210,3 +211,318 @@ func TestRunner_Run_PassesContextToVerifiers(t *testing.T) {
 		t.Error("Result should indicate context was cancelled")
 	}
 }
+
+// trackingVerifier records when it runs and how many verifiers run at once.
+type trackingVerifier struct {
+	mockVerifier
+	deps    []string
+	tracker *runTracker
+}
+
+func (v *trackingVerifier) DependsOn() []string {
+	return v.deps
+}
+
+func (v *trackingVerifier) Verify(ctx context.Context, taskID string, agentOutput string) *Result {
+	v.tracker.start(v.name)
+	defer v.tracker.finish(v.name)
+	return v.mockVerifier.Verify(ctx, taskID, agentOutput)
+}
+
+// runTracker is shared by trackingVerifiers in a test.
+type runTracker struct {
+	mu      sync.Mutex
+	events  []string
+	running int
+	peak    int
+}
+
+func (t *runTracker) start(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, "start "+name)
+	t.running++
+	if t.running > t.peak {
+		t.peak = t.running
+	}
+}
+
+func (t *runTracker) finish(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, "end "+name)
+	t.running--
+}
+
+func (t *runTracker) index(event string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, e := range t.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestRunner_SetConcurrency(t *testing.T) {
+	tests := []struct {
+		n    int
+		want int
+	}{
+		{0, 1},
+		{-3, 1},
+		{1, 1},
+		{4, 4},
+	}
+	for _, tt := range tests {
+		runner := NewRunner("/tmp")
+		runner.SetConcurrency(tt.n)
+		if runner.concurrency != tt.want {
+			t.Errorf("SetConcurrency(%d) concurrency = %d, want %d", tt.n, runner.concurrency, tt.want)
+		}
+	}
+}
+
+func TestRunner_Run_Parallel(t *testing.T) {
+	tracker := &runTracker{}
+	var verifiers []Verifier
+	for _, name := range []string{"a", "b", "c", "d"} {
+		verifiers = append(verifiers, &trackingVerifier{
+			mockVerifier: mockVerifier{name: name, passed: true, delay: 100 * time.Millisecond},
+			tracker:      tracker,
+		})
+	}
+	runner := NewRunner("/tmp", verifiers...)
+	runner.SetConcurrency(2)
+
+	start := time.Now()
+	results := runner.Run(context.Background(), "task-1", "")
+	elapsed := time.Since(start)
+
+	if !results.AllPassed || len(results.Results) != 4 {
+		t.Fatalf("Run() = %d results (AllPassed=%v), want 4 passing", len(results.Results), results.AllPassed)
+	}
+	if tracker.peak != 2 {
+		t.Errorf("peak concurrency = %d, want 2", tracker.peak)
+	}
+	// Two batches of two: well under the 400ms sequential time
+	if elapsed >= 350*time.Millisecond {
+		t.Errorf("Run() took %v, want parallel execution", elapsed)
+	}
+}
+
+func TestRunner_Run_ParallelKeepsOrder(t *testing.T) {
+	runner := NewRunner("/tmp",
+		&mockVerifier{name: "slow", passed: true, delay: 100 * time.Millisecond},
+		&mockVerifier{name: "medium", passed: true, delay: 50 * time.Millisecond},
+		&mockVerifier{name: "fast", passed: true},
+	)
+	runner.SetConcurrency(3)
+
+	results := runner.Run(context.Background(), "task-1", "")
+
+	expected := []string{"slow", "medium", "fast"}
+	if len(results.Results) != len(expected) {
+		t.Fatalf("Expected %d results, got %d", len(expected), len(results.Results))
+	}
+	for i, name := range expected {
+		if results.Results[i].Verifier != name {
+			t.Errorf("Result[%d] = %q, want %q", i, results.Results[i].Verifier, name)
+		}
+	}
+}
+
+func TestRunner_Run_Dependencies(t *testing.T) {
+	tracker := &runTracker{}
+	runner := NewRunner("/tmp",
+		&trackingVerifier{mockVerifier: mockVerifier{name: "test", passed: true}, deps: []string{"build"}, tracker: tracker},
+		&trackingVerifier{mockVerifier: mockVerifier{name: "build", passed: true, delay: 50 * time.Millisecond}, tracker: tracker},
+		&trackingVerifier{mockVerifier: mockVerifier{name: "lint", passed: true}, tracker: tracker},
+	)
+	runner.SetConcurrency(3)
+
+	results := runner.Run(context.Background(), "task-1", "")
+
+	if !results.AllPassed || len(results.Results) != 3 {
+		t.Fatalf("Run() = %d results (AllPassed=%v), want 3 passing", len(results.Results), results.AllPassed)
+	}
+	if tracker.index("start test") < tracker.index("end build") {
+		t.Errorf("test started before build finished: %v", tracker.events)
+	}
+	// Independent lint doesn't wait for build
+	if tracker.index("start lint") > tracker.index("end build") {
+		t.Errorf("lint waited for build: %v", tracker.events)
+	}
+	// Results keep declaration order, not execution order
+	for i, name := range []string{"test", "build", "lint"} {
+		if results.Results[i].Verifier != name {
+			t.Errorf("Result[%d] = %q, want %q", i, results.Results[i].Verifier, name)
+		}
+	}
+}
+
+func TestRunner_Run_FailedPrerequisite(t *testing.T) {
+	tracker := &runTracker{}
+	runner := NewRunner("/tmp",
+		&trackingVerifier{mockVerifier: mockVerifier{name: "build", passed: false, output: "compile error"}, tracker: tracker},
+		&trackingVerifier{mockVerifier: mockVerifier{name: "test", passed: true}, deps: []string{"build"}, tracker: tracker},
+		&trackingVerifier{mockVerifier: mockVerifier{name: "e2e", passed: true}, deps: []string{"test"}, tracker: tracker},
+		&trackingVerifier{mockVerifier: mockVerifier{name: "lint", passed: true}, tracker: tracker},
+	)
+	runner.SetConcurrency(2)
+
+	results := runner.Run(context.Background(), "task-1", "")
+
+	if results.AllPassed {
+		t.Error("Run() AllPassed = true, want false when prerequisite fails")
+	}
+	if len(results.Results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results.Results))
+	}
+	if tracker.index("start test") != -1 || tracker.index("start e2e") != -1 {
+		t.Errorf("dependents of failed build should not run: %v", tracker.events)
+	}
+
+	tests := []struct {
+		name    string
+		skipped bool
+		output  string
+	}{
+		{"build", false, "compile error"},
+		{"test", true, "skipped: prerequisite build failed"},
+		{"e2e", true, "skipped: prerequisite test failed"},
+		{"lint", false, ""},
+	}
+	for i, tt := range tests {
+		r := results.Results[i]
+		if r.Verifier != tt.name || r.Skipped != tt.skipped || r.Output != tt.output {
+			t.Errorf("Result[%d] = {%q skipped=%v %q}, want {%q skipped=%v %q}",
+				i, r.Verifier, r.Skipped, r.Output, tt.name, tt.skipped, tt.output)
+		}
+	}
+}
+
+func TestRunner_Run_FailedOptionalPrerequisite(t *testing.T) {
+	runner := NewRunner("/tmp",
+		&optionalVerifier{mockVerifier{name: "lint", passed: false}},
+		&trackingVerifier{mockVerifier: mockVerifier{name: "fmt", passed: true}, deps: []string{"lint"}, tracker: &runTracker{}},
+	)
+
+	results := runner.Run(context.Background(), "task-1", "")
+
+	// The optional failure only warns, but skipping required fmt fails verification
+	if results.AllPassed {
+		t.Errorf("Run() AllPassed = true, want false: %s", results.Summary())
+	}
+	if len(results.Results) != 2 || !results.Results[1].Skipped || results.Results[1].Optional {
+		t.Errorf("Expected fmt to be skipped and required, got %s", results.Summary())
+	}
+}
+
+func TestRunner_Run_FailedOptionalPrerequisite_OptionalDependent(t *testing.T) {
+	runner := NewRunner("/tmp",
+		&optionalVerifier{mockVerifier{name: "lint", passed: false}},
+		&optionalDependent{optionalVerifier{mockVerifier{name: "fmt", passed: true}}, []string{"lint"}},
+	)
+
+	results := runner.Run(context.Background(), "task-1", "")
+
+	// Neither the optional failure nor the optional skip fails verification
+	if !results.AllPassed {
+		t.Errorf("Run() AllPassed = false, want true: %s", results.Summary())
+	}
+	if len(results.Results) != 2 || !results.Results[1].Skipped || !results.Results[1].Optional {
+		t.Errorf("Expected fmt to be skipped and optional, got %s", results.Summary())
+	}
+}
+
+func TestRunner_Run_UnknownDependencyIgnored(t *testing.T) {
+	// "git" isn't present outside a git repo - dependents still run
+	runner := NewRunner("/tmp",
+		&trackingVerifier{mockVerifier: mockVerifier{name: "test", passed: true}, deps: []string{"git"}, tracker: &runTracker{}},
+	)
+
+	results := runner.Run(context.Background(), "task-1", "")
+
+	if !results.AllPassed || len(results.Results) != 1 || results.Results[0].Skipped {
+		t.Errorf("Run() = %s, want test to run and pass", results.Summary())
+	}
+}
+
+func TestRunner_Run_DependencyCycle(t *testing.T) {
+	runner := NewRunner("/tmp",
+		&trackingVerifier{mockVerifier: mockVerifier{name: "a", passed: true}, deps: []string{"b"}, tracker: &runTracker{}},
+		&trackingVerifier{mockVerifier: mockVerifier{name: "b", passed: true}, deps: []string{"a"}, tracker: &runTracker{}},
+		&mockVerifier{name: "c", passed: true},
+	)
+
+	results := runner.Run(context.Background(), "task-1", "")
+
+	if len(results.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results.Results))
+	}
+	for i, want := range []bool{true, true, false} {
+		if results.Results[i].Skipped != want {
+			t.Errorf("Result[%d] Skipped = %v, want %v", i, results.Results[i].Skipped, want)
+		}
+	}
+}
+
+func TestRunner_Run_ParallelCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tracker := &runTracker{}
+	runner := NewRunner("/tmp",
+		&trackingVerifier{mockVerifier: mockVerifier{name: "a", passed: true, delay: time.Second}, tracker: tracker},
+		&trackingVerifier{mockVerifier: mockVerifier{name: "b", passed: true, delay: time.Second}, tracker: tracker},
+		&trackingVerifier{mockVerifier: mockVerifier{name: "c", passed: true}, tracker: tracker},
+	)
+	runner.SetConcurrency(2)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	results := runner.Run(ctx, "task-1", "")
+	elapsed := time.Since(start)
+
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("Run() took %v, should stop on cancellation", elapsed)
+	}
+	// The two running verifiers report cancellation; c never starts
+	if len(results.Results) != 2 {
+		t.Errorf("Expected 2 results, got %d", len(results.Results))
+	}
+	if tracker.index("start c") != -1 {
+		t.Errorf("c should not start after cancellation: %v", tracker.events)
+	}
+}
+
+// optionalVerifier marks its results optional, like a non-required CommandVerifier.
+type optionalVerifier struct {
+	mockVerifier
+}
+
+func (v *optionalVerifier) IsOptional() bool {
+	return true
+}
+
+func (v *optionalVerifier) Verify(ctx context.Context, taskID string, agentOutput string) *Result {
+	r := v.mockVerifier.Verify(ctx, taskID, agentOutput)
+	r.Optional = true
+	return r
+}
+
+// optionalDependent is an optionalVerifier with prerequisites.
+type optionalDependent struct {
+	optionalVerifier
+	deps []string
+}
+
+func (v *optionalDependent) DependsOn() []string {
+	return v.deps
+}
@@ -0,0 +1,141 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// taskRulesFence opens the fenced block holding a task's verification rules.
+const taskRulesFence = "```verify"
+
+// TaskRules are verification rules declared by a single task, adjusting the
+// repo-level verification config for that task only. They are written as a
+// JSON fenced block in the task description:
+//
+//	```verify
+//	{"skip": ["test"], "commands": [{"name": "migrations", "command": "make check-migrations"}]}
+//	```
+type TaskRules struct {
+	// Run limits verification to the named verifiers ("git" or command
+	// names, including the task's own commands). Empty means all.
+	Run []string `json:"run,omitempty"`
+
+	// Skip names verifiers not to run for this task.
+	Skip []string `json:"skip,omitempty"`
+
+	// Commands are extra command verifiers for this task. A command with the
+	// same name as a configured one replaces it.
+	Commands []*CommandConfig `json:"commands,omitempty"`
+}
+
+// ParseTaskRules extracts verification rules from a task description.
+// Returns nil, nil if the description has no verify block.
+func ParseTaskRules(description string) (*TaskRules, error) {
+	block, ok := fencedBlock(description, taskRulesFence)
+	if !ok {
+		return nil, nil
+	}
+
+	var rules TaskRules
+	if err := json.Unmarshal([]byte(block), &rules); err != nil {
+		return nil, fmt.Errorf("parsing verify block: %w", err)
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid verify block: %w", err)
+	}
+	return &rules, nil
+}
+
+// fencedBlock returns the content of the first fenced block opened by fence.
+// The opening line must be exactly the fence (ignoring surrounding spaces).
+func fencedBlock(text, fence string) (string, bool) {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != fence {
+			continue
+		}
+		var body []string
+		for _, l := range lines[i+1:] {
+			if strings.TrimSpace(l) == "```" {
+				return strings.Join(body, "\n"), true
+			}
+			body = append(body, l)
+		}
+		// Unterminated block - take the rest
+		return strings.Join(body, "\n"), true
+	}
+	return "", false
+}
+
+// Validate checks that the task's commands are well-formed and uniquely named.
+// Returns nil if valid, or an error describing the problem.
+func (r *TaskRules) Validate() error {
+	if r == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for i, cmd := range r.Commands {
+		if cmd == nil {
+			return fmt.Errorf("command %d: missing config", i+1)
+		}
+		if err := cmd.Validate(); err != nil {
+			return fmt.Errorf("command %d: %w", i+1, err)
+		}
+		if seen[cmd.Name] {
+			return fmt.Errorf("command %d: duplicate name %q", i+1, cmd.Name)
+		}
+		seen[cmd.Name] = true
+	}
+	return nil
+}
+
+// Apply merges the rules with the repo-level verifiers. The task's commands
+// replace configured verifiers of the same name (or are appended), then Run
+// and Skip filter the result. Names in Run or Skip that match no verifier are
+// ignored. A nil TaskRules returns verifiers unchanged.
+func (r *TaskRules) Apply(dir string, verifiers []Verifier) []Verifier {
+	if r == nil {
+		return verifiers
+	}
+
+	merged := make([]Verifier, len(verifiers))
+	copy(merged, verifiers)
+	for _, cmd := range r.Commands {
+		v := NewCommandVerifier(dir, cmd)
+		replaced := false
+		for i, existing := range merged {
+			if existing.Name() == cmd.Name {
+				merged[i] = v
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, v)
+		}
+	}
+
+	run := nameSet(r.Run)
+	skip := nameSet(r.Skip)
+	filtered := make([]Verifier, 0, len(merged))
+	for _, v := range merged {
+		if len(run) > 0 && !run[v.Name()] {
+			continue
+		}
+		if skip[v.Name()] {
+			continue
+		}
+		filtered = append(filtered, v)
+	}
+	return filtered
+}
+
+// nameSet builds a lookup set from names.
+func nameSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, n := range names {
+		set[n] = true
+	}
+	return set
+}
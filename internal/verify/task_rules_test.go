@@ -0,0 +1,136 @@
+package verify
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTaskRules(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        *TaskRules
+		wantErr     string
+	}{
+		{
+			name:        "no block",
+			description: "Update the README.\n\nAcceptance Criteria:\n- Docs updated",
+			want:        nil,
+		},
+		{
+			name:        "other fenced block",
+			description: "Example:\n```json\n{\"skip\": [\"test\"]}\n```",
+			want:        nil,
+		},
+		{
+			name:        "skip",
+			description: "Update the README.\n\n```verify\n{\"skip\": [\"test\", \"lint\"]}\n```\n",
+			want:        &TaskRules{Skip: []string{"test", "lint"}},
+		},
+		{
+			name:        "run and commands",
+			description: "Add migration.\n  ```verify\n  {\"run\": [\"git\", \"migrations\"],\n   \"commands\": [{\"name\": \"migrations\", \"command\": \"make check-migrations\"}]}\n  ```",
+			want: &TaskRules{
+				Run:      []string{"git", "migrations"},
+				Commands: []*CommandConfig{{Name: "migrations", Command: "make check-migrations"}},
+			},
+		},
+		{
+			name:        "unterminated block",
+			description: "```verify\n{\"skip\": [\"test\"]}",
+			want:        &TaskRules{Skip: []string{"test"}},
+		},
+		{
+			name:        "invalid JSON",
+			description: "```verify\nskip: test\n```",
+			wantErr:     "parsing verify block",
+		},
+		{
+			name:        "invalid command",
+			description: "```verify\n{\"commands\": [{\"name\": \"check\"}]}\n```",
+			wantErr:     "command is required",
+		},
+		{
+			name:        "duplicate command",
+			description: "```verify\n{\"commands\": [{\"name\": \"a\", \"command\": \"true\"}, {\"name\": \"a\", \"command\": \"false\"}]}\n```",
+			wantErr:     "duplicate name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTaskRules(tt.description)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseTaskRules() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTaskRules() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTaskRules() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskRules_Apply(t *testing.T) {
+	base := func() []Verifier {
+		return []Verifier{
+			&mockVerifier{name: "git"},
+			&mockVerifier{name: "build"},
+			&mockVerifier{name: "test"},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		rules *TaskRules
+		want  []string
+	}{
+		{"nil rules", nil, []string{"git", "build", "test"}},
+		{"empty rules", &TaskRules{}, []string{"git", "build", "test"}},
+		{"skip", &TaskRules{Skip: []string{"test", "unknown"}}, []string{"git", "build"}},
+		{"run", &TaskRules{Run: []string{"git"}}, []string{"git"}},
+		{"extra command", &TaskRules{
+			Commands: []*CommandConfig{{Name: "migrations", Command: "make check-migrations"}},
+		}, []string{"git", "build", "test", "migrations"}},
+		{"run includes task command", &TaskRules{
+			Run:      []string{"migrations"},
+			Commands: []*CommandConfig{{Name: "migrations", Command: "make check-migrations"}},
+		}, []string{"migrations"}},
+		{"run and skip", &TaskRules{Run: []string{"git", "build"}, Skip: []string{"git"}}, []string{"build"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range tt.rules.Apply("/tmp", base()) {
+				got = append(got, v.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTaskRules_Apply_ReplacesCommand(t *testing.T) {
+	rules := &TaskRules{Commands: []*CommandConfig{{Name: "test", Command: "go test ./db/..."}}}
+
+	got := rules.Apply("/tmp", []Verifier{&mockVerifier{name: "git"}, &mockVerifier{name: "test"}})
+
+	if len(got) != 2 {
+		t.Fatalf("Apply() returned %d verifiers, want 2", len(got))
+	}
+	cv, ok := got[1].(*CommandVerifier)
+	if !ok {
+		t.Fatalf("Apply()[1] = %T, want *CommandVerifier", got[1])
+	}
+	if cv.command != "go test ./db/..." {
+		t.Errorf("command = %q, want task's command", cv.command)
+	}
+}
@@ -8,7 +8,8 @@ import (
 )
 
 // Verifier defines the interface for task verification.
-// Currently only GitVerifier is implemented - agent handles testing.
+// GitVerifier checks for uncommitted changes; CommandVerifier runs configured
+// test, lint and build commands.
 type Verifier interface {
 	// Name returns a human-readable name (e.g., "git").
 	Name() string
@@ -35,24 +36,40 @@ type Result struct {
 
 	// Error holds the underlying error if verification failed due to an error.
 	Error error
+
+	// Optional indicates a failure is reported but doesn't fail verification.
+	Optional bool
+
+	// Skipped indicates the verifier didn't run because a prerequisite failed.
+	Skipped bool
+
+	// TokensIn, TokensOut and Cost are the usage of any agent run the
+	// verifier made (the acceptance review).
+	TokensIn  int
+	TokensOut int
+	Cost      float64
 }
 
 // String returns a human-readable representation of the result.
 func (r *Result) String() string {
 	status := "PASS"
-	if !r.Passed {
+	switch {
+	case r.Skipped:
+		status = "SKIP"
+	case !r.Passed && r.Optional:
+		status = "WARN"
+	case !r.Passed:
 		status = "FAIL"
 	}
 	return fmt.Sprintf("[%s] %s (%v)", status, r.Verifier, r.Duration.Round(time.Millisecond))
 }
 
 // Results aggregates multiple verification results.
-// Currently only one verifier (git), but structure supports future extension.
 type Results struct {
 	// Results contains individual verifier results.
 	Results []*Result
 
-	// AllPassed indicates whether all verifications passed.
+	// AllPassed indicates whether all required verifications passed.
 	AllPassed bool
 }
 
@@ -65,16 +82,26 @@ func NewResults(results []*Result) *Results {
 	}
 }
 
-// allPassed returns true if all results passed.
+// allPassed returns true if all required results passed.
 func allPassed(results []*Result) bool {
 	for _, r := range results {
-		if !r.Passed {
+		if !r.Passed && !r.Optional {
 			return false
 		}
 	}
 	return true
 }
 
+// Usage returns the total agent usage of the verifiers.
+func (r *Results) Usage() (tokensIn, tokensOut int, cost float64) {
+	for _, result := range r.Results {
+		tokensIn += result.TokensIn
+		tokensOut += result.TokensOut
+		cost += result.Cost
+	}
+	return tokensIn, tokensOut, cost
+}
+
 // Summary returns a human-readable summary of all results.
 func (r *Results) Summary() string {
 	if len(r.Results) == 0 {
@@ -30,6 +30,26 @@ func TestResult_String(t *testing.T) {
 			},
 			want: "[FAIL] git (50ms)",
 		},
+		{
+			name: "failed optional result",
+			result: &Result{
+				Verifier: "lint",
+				Passed:   false,
+				Optional: true,
+				Duration: 50 * time.Millisecond,
+			},
+			want: "[WARN] lint (50ms)",
+		},
+		{
+			name: "skipped result",
+			result: &Result{
+				Verifier: "test",
+				Passed:   false,
+				Optional: true,
+				Skipped:  true,
+			},
+			want: "[SKIP] test (0s)",
+		},
 		{
 			name: "result with sub-millisecond duration",
 			result: &Result{
@@ -92,6 +112,14 @@ func TestNewResults(t *testing.T) {
 			},
 			allPassed: true,
 		},
+		{
+			name: "optional failure ignored",
+			results: []*Result{
+				{Verifier: "git", Passed: true},
+				{Verifier: "lint", Passed: false, Optional: true},
+			},
+			allPassed: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -169,6 +197,18 @@ func TestResults_Summary(t *testing.T) {
 	}
 }
 
+func TestResults_Usage(t *testing.T) {
+	results := NewResults([]*Result{
+		{Verifier: "git", Passed: true},
+		{Verifier: "criteria", Passed: true, TokensIn: 1000, TokensOut: 200, Cost: 0.03},
+	})
+
+	tokensIn, tokensOut, cost := results.Usage()
+	if tokensIn != 1000 || tokensOut != 200 || cost != 0.03 {
+		t.Errorf("Usage() = %d, %d, %f; want 1000, 200, 0.03", tokensIn, tokensOut, cost)
+	}
+}
+
 func TestResults_FailedResults(t *testing.T) {
 	tests := []struct {
 		name      string